@@ -0,0 +1,60 @@
+package benchmarks
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/chrisconley/metron/internal"
+	"github.com/chrisconley/metron/specs"
+)
+
+// Benchmark time-weighted-avg over chronological input, which skips the sort,
+// against the same records shuffled
+func BenchmarkTimeWeightedAvg_PreSorted_vs_Random(b *testing.B) {
+	const n = 1000
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	config := specs.AggregateConfigSpec{
+		Aggregation: "time-weighted-avg",
+		Window:      specs.TimeWindowSpec{Start: start, End: start.AddDate(0, 1, 0)},
+	}
+
+	sorted := make([]specs.MeterRecordSpec, n)
+	for i := range sorted {
+		observedAt := start.Add(time.Duration(i) * 30 * time.Minute)
+		sorted[i] = specs.MeterRecordSpec{
+			ID:            "rec_" + strconv.Itoa(i),
+			WorkspaceID:   "ws_a1b2c3d4",
+			UniverseID:    "prod",
+			Subject:       "customer:cust_abc123",
+			ObservedAt:    observedAt,
+			Observations:  []specs.ObservationSpec{specs.NewInstantObservation(strconv.Itoa(10+i%7), "seats", observedAt)},
+			SourceEventID: "evt_" + strconv.Itoa(i),
+			MeteredAt:     observedAt,
+		}
+	}
+
+	random := make([]specs.MeterRecordSpec, n)
+	copy(random, sorted)
+	rand.New(rand.NewSource(1)).Shuffle(n, func(i, j int) {
+		random[i], random[j] = random[j], random[i]
+	})
+
+	for _, bm := range []struct {
+		name    string
+		records []specs.MeterRecordSpec
+	}{
+		{"PreSorted", sorted},
+		{"Random", random},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := internal.Aggregate(bm.records, nil, config); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package benchmarks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/chrisconley/metron/internal/export"
+	"github.com/chrisconley/metron/specs"
+)
+
+// generateReadings creates n realistic single-value readings for export benchmarks
+func generateReadings(n int) []specs.MeterReadingSpec {
+	readings := make([]specs.MeterReadingSpec, n)
+	for i := range readings {
+		readings[i] = specs.MeterReadingSpec{
+			ID:          fmt.Sprintf("mrd_%032d", i),
+			WorkspaceID: "ws_a1b2c3d4",
+			UniverseID:  "prod",
+			Subject:     fmt.Sprintf("customer:cust_%06d", i),
+			Window: specs.TimeWindowSpec{
+				Start: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			},
+			ComputedValues: []specs.ComputedValueSpec{
+				{Quantity: "12500", Unit: "tokens", Aggregation: "sum"},
+			},
+			Aggregation:  "sum",
+			RecordCount:  1250,
+			CreatedAt:    time.Now(),
+			MaxMeteredAt: time.Now(),
+		}
+	}
+	return readings
+}
+
+// Benchmark exporting 10k readings as an Arrow IPC stream
+func BenchmarkReadingsExport_ArrowIPC_10k(b *testing.B) {
+	readings := generateReadings(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var size int
+	for i := 0; i < b.N; i++ {
+		var buf bytes.Buffer
+		if err := export.ReadingsToArrowIPC(readings, &buf); err != nil {
+			b.Fatal(err)
+		}
+		size = buf.Len()
+	}
+	b.ReportMetric(float64(size), "bytes")
+}
+
+// Benchmark exporting 10k readings as a JSON array (baseline)
+func BenchmarkReadingsExport_JSON_10k(b *testing.B) {
+	readings := generateReadings(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var size int
+	for i := 0; i < b.N; i++ {
+		data, err := json.Marshal(readings)
+		if err != nil {
+			b.Fatal(err)
+		}
+		size = len(data)
+	}
+	b.ReportMetric(float64(size), "bytes")
+}
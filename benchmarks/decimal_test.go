@@ -0,0 +1,42 @@
+package benchmarks
+
+import (
+	"testing"
+
+	"github.com/chrisconley/metron/internal"
+	"github.com/cockroachdb/apd/v3"
+)
+
+// Benchmark Decimal.Add, which computes into a pooled scratch value
+func BenchmarkDecimal_Add_WithPool(b *testing.B) {
+	sum := internal.NewDecimalFromInt64(0)
+	quantity, err := internal.NewDecimal("1250.75")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sum = sum.Add(quantity)
+	}
+}
+
+// Benchmark the unpooled pattern Decimal.Add used previously (baseline):
+// a fresh apd.Decimal per operation
+func BenchmarkDecimal_Add_NoPool(b *testing.B) {
+	var sum apd.Decimal
+	var quantity apd.Decimal
+	if _, _, err := quantity.SetString("1250.75"); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var result apd.Decimal
+		ctx := apd.BaseContext.WithPrecision(34)
+		ctx.Add(&result, &sum, &quantity)
+		sum = result
+	}
+}
@@ -3,8 +3,11 @@ package internal
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	specs "github.com/chrisconley/metron/specs"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -25,17 +28,9 @@ func unbundleObservations(recordSpecs []specs.MeterRecordSpec) []specs.MeterReco
 
 		// Create one spec per observation
 		for _, observation := range observations {
-			unbundledSpec := specs.MeterRecordSpec{
-				ID:            spec.ID,
-				WorkspaceID:   spec.WorkspaceID,
-				UniverseID:    spec.UniverseID,
-				Subject:       spec.Subject,
-				ObservedAt:    spec.ObservedAt,
-				Observations:  []specs.ObservationSpec{observation}, // Single observation in array
-				Dimensions:    spec.Dimensions,
-				SourceEventID: spec.SourceEventID,
-				MeteredAt:     spec.MeteredAt,
-			}
+			// Copy all record fields, keeping a single observation in the array
+			unbundledSpec := spec
+			unbundledSpec.Observations = []specs.ObservationSpec{observation}
 			result = append(result, unbundledSpec)
 		}
 	}
@@ -45,70 +40,263 @@ func unbundleObservations(recordSpecs []specs.MeterRecordSpec) []specs.MeterReco
 
 // Aggregate implements specs.Aggregate.
 // Converts specs to domain objects, transforms, and converts back to specs.
+//
+// Kept for callers that expect a single reading; delegates to AggregateGrouped.
+// Returns error if the config's GroupBy partitions the records into more than
+// one group.
+//
+// If the reading falls below the config's MinRecordCount, returns the reading
+// together with an *InsufficientDataError.
 func Aggregate(
 	recordsInWindowSpec []specs.MeterRecordSpec,
 	lastBeforeWindowSpec *specs.MeterRecordSpec,
 	configSpec specs.AggregateConfigSpec,
 ) (specs.MeterReadingSpec, error) {
+	readings, err := AggregateGrouped(recordsInWindowSpec, lastBeforeWindowSpec, configSpec)
+	if err != nil && !(IsInsufficientDataError(err) && len(readings) == 1) {
+		return specs.MeterReadingSpec{}, err
+	}
+	if len(readings) != 1 {
+		return specs.MeterReadingSpec{}, fmt.Errorf("group by produced %d readings; use AggregateGrouped", len(readings))
+	}
+	return readings[0], err
+}
+
+// InsufficientDataError reports a reading built from fewer records than the
+// config's MinRecordCount, which may indicate data loss upstream. It is
+// returned alongside the reading rather than instead of it.
+type InsufficientDataError struct {
+	ReadingID      string
+	RecordCount    int
+	MinRecordCount int
+}
+
+func (e *InsufficientDataError) Error() string {
+	return fmt.Sprintf("insufficient data for reading %s: %d records, minimum %d",
+		e.ReadingID, e.RecordCount, e.MinRecordCount)
+}
+
+// IsInsufficientDataError reports whether err is or wraps an
+// *InsufficientDataError.
+func IsInsufficientDataError(err error) bool {
+	var insufficient *InsufficientDataError
+	return errors.As(err, &insufficient)
+}
+
+// checkMinRecordCount returns an *InsufficientDataError if the reading's
+// record count is below the config's minimum.
+func checkMinRecordCount(reading MeterReading, config AggregationConfig) error {
+	if reading.RecordCount.ToInt() >= config.MinRecordCount() {
+		return nil
+	}
+	return &InsufficientDataError{
+		ReadingID:      reading.ID.ToString(),
+		RecordCount:    reading.RecordCount.ToInt(),
+		MinRecordCount: config.MinRecordCount(),
+	}
+}
+
+// AggregateGrouped aggregates records into one reading per group.
+//
+// Records are partitioned by the values of the config's GroupBy dimensions;
+// records missing a dimension group under the empty value. Each partition of
+// the Cartesian product that contains records produces a reading carrying
+// its group values as Dimensions. lastBeforeWindow is only carried into the
+// partition whose dimensions it matches. Readings are ordered by group values.
+//
+// When GroupBy is empty, returns a single-element slice.
+//
+// Records observed outside the window by more than the config's
+// ClockSkewTolerance are excluded; use AggregateWithWarnings to learn which.
+// Inputs larger than the config's MaxRecordsPerAggregate are aggregated in
+// chunks (see ChunkedAggregate).
+//
+// If any reading falls below the config's MinRecordCount, returns all readings
+// together with an *InsufficientDataError for each such reading.
+func AggregateGrouped(
+	recordsInWindowSpec []specs.MeterRecordSpec,
+	lastBeforeWindowSpec *specs.MeterRecordSpec,
+	configSpec specs.AggregateConfigSpec,
+) ([]specs.MeterReadingSpec, error) {
+	readings, _, err := aggregateGrouped(recordsInWindowSpec, lastBeforeWindowSpec, configSpec)
+	return readings, err
+}
+
+// aggregateGrouped is AggregateGrouped, also returning warnings for records
+// excluded as out of window.
+func aggregateGrouped(
+	recordsInWindowSpec []specs.MeterRecordSpec,
+	lastBeforeWindowSpec *specs.MeterRecordSpec,
+	configSpec specs.AggregateConfigSpec,
+) ([]specs.MeterReadingSpec, []OutOfWindowWarning, error) {
+	// Convert config spec to domain object
+	config, err := NewAggregationConfig(configSpec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	// Bound memory for large inputs by aggregating them in chunks
+	if chunkSize := config.MaxRecordsPerAggregate(); chunkSize > 0 && len(recordsInWindowSpec) > chunkSize {
+		reading, warnings, err := chunkedAggregate(recordsInWindowSpec, lastBeforeWindowSpec, config, chunkSize)
+		if err != nil && !IsInsufficientDataError(err) {
+			return nil, nil, err
+		}
+		return []specs.MeterReadingSpec{reading}, warnings, err
+	}
+
 	// Unbundle observations: convert each MeterRecordSpec with multiple observations
 	// into separate records (one per observation) for aggregation processing
 	unbundledSpecs := unbundleObservations(recordsInWindowSpec)
 
-	// Convert record specs to domain objects
+	// Convert record specs to domain objects, resolving unit aliases
 	recordsInWindow := make([]MeterRecord, len(unbundledSpecs))
 	for i, spec := range unbundledSpecs {
-		record, err := NewMeterRecord(spec)
+		record, err := NewMeterRecord(normalizeObservationUnits(spec, config.UnitAliases()))
 		if err != nil {
-			return specs.MeterReadingSpec{}, fmt.Errorf("invalid record at index %d: %w", i, err)
+			return nil, nil, fmt.Errorf("invalid record at index %d: %w", i, err)
 		}
 		recordsInWindow[i] = record
 	}
 
+	// Drop records too far outside the window to be explained by clock skew
+	recordsInWindow, warnings, err := excludeOutOfWindow(recordsInWindow, config.Window(), config.ClockSkewTolerance(), config.StrictMode())
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Convert lastBefore spec if provided (unbundle if needed)
 	var lastBeforeWindow *MeterRecord
 	if lastBeforeWindowSpec != nil {
 		// Unbundle observations and use first one (for time-weighted-avg)
 		unbundledLast := unbundleObservations([]specs.MeterRecordSpec{*lastBeforeWindowSpec})
 		if len(unbundledLast) > 0 {
-			record, err := NewMeterRecord(unbundledLast[0])
+			record, err := NewMeterRecord(normalizeObservationUnits(unbundledLast[0], config.UnitAliases()))
 			if err != nil {
-				return specs.MeterReadingSpec{}, fmt.Errorf("invalid lastBeforeWindow: %w", err)
+				return nil, nil, fmt.Errorf("invalid lastBeforeWindow: %w", err)
 			}
 			lastBeforeWindow = &record
 		}
 	}
 
-	// Convert config spec to domain object
-	config, err := NewAggregationConfig(configSpec)
-	if err != nil {
-		return specs.MeterReadingSpec{}, fmt.Errorf("invalid config: %w", err)
+	// Perform aggregation per group using domain objects
+	groups := groupRecords(recordsInWindow, lastBeforeWindow, config.GroupBy())
+	readings := make([]specs.MeterReadingSpec, 0, len(groups))
+	var insufficient []error
+	for _, group := range groups {
+		reading, err := aggregate(group.recordsInWindow, group.lastBeforeWindow, config)
+		if err != nil {
+			if len(config.GroupBy()) > 0 {
+				return nil, nil, fmt.Errorf("group %s: %w", group.key, err)
+			}
+			return nil, nil, err
+		}
+		if err := checkMinRecordCount(reading, config); err != nil {
+			insufficient = append(insufficient, err)
+		}
+		readings = append(readings, meterReadingToSpec(reading))
 	}
 
-	// Perform aggregation using domain objects
-	reading, err := aggregate(recordsInWindow, lastBeforeWindow, config)
-	if err != nil {
-		return specs.MeterReadingSpec{}, err
+	if len(insufficient) == 1 {
+		// Unjoined, so single-reading callers can type-assert the error
+		return readings, warnings, insufficient[0]
+	}
+	return readings, warnings, errors.Join(insufficient...)
+}
+
+// recordGroup is one partition of records sharing the same group-by values.
+type recordGroup struct {
+	key              string
+	recordsInWindow  []MeterRecord
+	lastBeforeWindow *MeterRecord
+}
+
+// groupRecords partitions records by the values of the groupBy dimensions,
+// ordered by group key. With no groupBy keys, returns a single group.
+func groupRecords(recordsInWindow []MeterRecord, lastBeforeWindow *MeterRecord, groupBy []string) []recordGroup {
+	if len(groupBy) == 0 {
+		return []recordGroup{{recordsInWindow: recordsInWindow, lastBeforeWindow: lastBeforeWindow}}
 	}
 
-	// Convert domain object back to spec
+	byKey := make(map[string]*recordGroup)
+	groupFor := func(record MeterRecord) *recordGroup {
+		key := groupKey(record.Dimensions, groupBy)
+		group, ok := byKey[key]
+		if !ok {
+			group = &recordGroup{key: key}
+			byKey[key] = group
+		}
+		return group
+	}
+
+	for _, record := range recordsInWindow {
+		group := groupFor(record)
+		group.recordsInWindow = append(group.recordsInWindow, record)
+	}
+	if lastBeforeWindow != nil {
+		groupFor(*lastBeforeWindow).lastBeforeWindow = lastBeforeWindow
+	}
+
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	groups := make([]recordGroup, len(keys))
+	for i, key := range keys {
+		groups[i] = *byKey[key]
+	}
+	return groups
+}
+
+// groupKey renders the groupBy dimension values as a stable, unambiguous key.
+func groupKey(dimensions MeterRecordDimensions, groupBy []string) string {
+	var b strings.Builder
+	for i, name := range groupBy {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		value, _ := dimensions.Get(name)
+		fmt.Fprintf(&b, "%s=%q", name, value)
+	}
+	return b.String()
+}
+
+// meterReadingToSpec converts a MeterReading domain object to its spec.
+func meterReadingToSpec(reading MeterReading) specs.MeterReadingSpec {
 	// Build ComputedValues from the reading's computed values
 	computedValuesSpec := make([]specs.ComputedValueSpec, len(reading.ComputedValues))
 	for i, cv := range reading.ComputedValues {
 		computedValuesSpec[i] = cv.ToSpec()
 	}
 
-	return specs.MeterReadingSpec{
-		ID:             reading.ID.ToString(),
-		WorkspaceID:    reading.WorkspaceID.ToString(),
-		UniverseID:     reading.UniverseID.ToString(),
-		Subject:        reading.Subject.ToString(),
-		Window:         configSpec.Window,
-		ComputedValues: computedValuesSpec,
-		Aggregation:    reading.Aggregation.ToString(),
-		RecordCount:    reading.RecordCount.ToInt(),
-		CreatedAt:      reading.CreatedAt.ToTime(),
-		MaxMeteredAt:   reading.MaxMeteredAt.ToTime(),
-	}, nil
+	spec := specs.MeterReadingSpec{
+		ID:                       reading.ID.ToString(),
+		WorkspaceID:              reading.WorkspaceID.ToString(),
+		UniverseID:               reading.UniverseID.ToString(),
+		Subject:                  reading.Subject.ToString(),
+		Dimensions:               reading.Dimensions.ToMap(),
+		Window:                   reading.Window.ToSpec(),
+		ComputedValues:           computedValuesSpec,
+		Unit:                     reading.Unit.ToString(),
+		Aggregation:              reading.Aggregation.ToString(),
+		RecordCount:              reading.RecordCount.ToInt(),
+		CreatedAt:                reading.CreatedAt.ToTime(),
+		MaxMeteredAt:             reading.MaxMeteredAt.ToTime(),
+		Version:                  reading.Version.ToInt64(),
+		WasCapped:                reading.WasCapped,
+		Metadata:                 reading.Metadata.ToMap(),
+		SourceRecordIDs:          reading.SourceRecordIDs,
+		SourceRecordIDsTruncated: reading.SourceRecordIDsTruncated,
+		ResetCount:               reading.ResetCount,
+		Timezone:                 reading.Timezone,
+	}
+	if ci := reading.ConfidenceInterval; ci != nil {
+		spec.ConfidenceIntervalLower = ci.Lower().String()
+		spec.ConfidenceIntervalUpper = ci.Upper().String()
+		spec.ConfidenceLevel = ci.Level().String()
+	}
+	return spec
 }
 
 // aggregate transforms MeterRecords into a MeterReading by applying aggregation.
@@ -128,22 +316,105 @@ func aggregate(
 		return MeterReading{}, fmt.Errorf("cannot create meter reading: no records in window and no prior record")
 	}
 
+	// Undo counter resets before averaging, so drops do not read as real changes
+	var resetCount int
+	if threshold := config.ResetDetectionThreshold(); threshold != nil {
+		recordsInWindow, lastBeforeWindow, resetCount = adjustForResets(recordsInWindow, lastBeforeWindow, *threshold)
+	}
+
 	// Perform aggregation - each type uses the parameters it needs
 	quantity, unit, recordCount, err := config.Aggregation().Aggregate(recordsInWindow, lastBeforeWindow, config.Window())
 	if err != nil {
 		return MeterReading{}, fmt.Errorf("failed to aggregate with %s: %w", config.Aggregation().ToString(), err)
 	}
 
+	// The carried-forward record contributed if the aggregation counted it
+	var contributingIDs []string
+	if config.IncludeSourceIDs() {
+		for _, record := range recordsInWindow {
+			contributingIDs = append(contributingIDs, record.ID.ToString())
+		}
+		if lastBeforeWindow != nil && recordCount > len(recordsInWindow) {
+			contributingIDs = append(contributingIDs, lastBeforeWindow.ID.ToString())
+		}
+	}
+
+	// Sampled records stand for more than one event; report the estimated event count
+	recordCount, err = estimateEventCount(recordsInWindow, lastBeforeWindow, recordCount)
+	if err != nil {
+		return MeterReading{}, fmt.Errorf("failed to estimate record count: %w", err)
+	}
+
 	// Compute MaxMeteredAt from all records (for watermarking)
 	maxMeteredAt := computeMaxMeteredAt(recordsInWindow, lastBeforeWindow)
 
+	reading, err := buildMeterReading(metadataSource, quantity, unit, recordCount, maxMeteredAt, config)
+	if err != nil {
+		return MeterReading{}, err
+	}
+	if config.IncludeSourceIDs() {
+		reading.SourceRecordIDs, reading.SourceRecordIDsTruncated = selectSourceRecordIDs(contributingIDs, config.MaxSourceIDs())
+	}
+	reading.ResetCount = resetCount
+	return reading, nil
+}
+
+// selectSourceRecordIDs sorts and deduplicates record IDs (unbundled
+// observations share their record's ID) and keeps the first max, reporting
+// whether any were dropped. A max of zero means no limit.
+func selectSourceRecordIDs(ids []string, max int) ([]string, bool) {
+	sorted := append([]string(nil), ids...)
+	sort.Strings(sorted)
+	unique := sorted[:0]
+	for i, id := range sorted {
+		if i == 0 || id != sorted[i-1] {
+			unique = append(unique, id)
+		}
+	}
+	if max > 0 && len(unique) > max {
+		return unique[:max], true
+	}
+	return unique, false
+}
+
+// buildMeterReading applies the config's free tier and cap to an aggregated
+// quantity and assembles the reading. metadataSource supplies the workspace,
+// universe, subject, and group-by dimensions.
+func buildMeterReading(
+	metadataSource MeterRecord,
+	quantity Decimal,
+	unit Unit,
+	recordCount int,
+	maxMeteredAt time.Time,
+	config AggregationConfig,
+) (MeterReading, error) {
+	// Apply per-window free tier, flooring at zero
+	if free := config.FreeQuantity(); free != nil {
+		quantity = quantity.Sub(*free)
+		if zero := NewDecimalFromInt64(0); quantity.Cmp(zero) < 0 {
+			quantity = zero
+		}
+	}
+
+	// Apply cap; a value equal to the cap is not capped
+	wasCapped := false
+	if max := config.MaxValue(); max != nil && quantity.Cmp(*max) > 0 {
+		quantity = *max
+		wasCapped = true
+	}
+
+	// Group-by values are shared by every record in the group
+	dimensions := NewMeterReadingDimensions()
+	for _, name := range config.GroupBy() {
+		value, _ := metadataSource.Dimensions.Get(name)
+		dimensions.Set(name, value)
+	}
+
 	// Build MeterReading
-	id := computeMeterReadingID(
-		metadataSource.Subject,
-		unit,
-		config.Window(),
-		config.Aggregation(),
-	)
+	id, err := applyIDStrategy(config, metadataSource.Subject, unit, dimensions)
+	if err != nil {
+		return MeterReading{}, fmt.Errorf("invalid ID: %w", err)
+	}
 
 	workspaceID, err := NewMeterReadingWorkspaceID(metadataSource.WorkspaceID.ToString())
 	if err != nil {
@@ -187,12 +458,18 @@ func aggregate(
 		WorkspaceID:    workspaceID,
 		UniverseID:     universeID,
 		Subject:        subject,
+		Dimensions:     dimensions,
 		Window:         config.Window(),
 		ComputedValues: []ComputedValue{computedValue},
+		Unit:           unit,
 		Aggregation:    config.Aggregation(),
 		RecordCount:    recordCountVO,
 		CreatedAt:      createdAt,
 		MaxMeteredAt:   maxMeteredAtVO,
+		Version:        InitialMeterReadingVersion(),
+		WasCapped:      wasCapped,
+		Metadata:       config.OutputMetadata(),
+		Timezone:       config.OutputTimezone(),
 	}, nil
 }
 
@@ -216,11 +493,14 @@ func computeMaxMeteredAt(recordsInWindow []MeterRecord, lastBeforeWindow *MeterR
 }
 
 // computeMeterReadingID generates a deterministic ID from the reading's key fields.
+// Group-by dimensions are only part of the input when present, so ungrouped
+// readings keep the same IDs they had before grouping existed.
 func computeMeterReadingID(
 	subject MeterRecordSubject,
 	unit Unit,
 	window TimeWindow,
 	aggregation MeterReadingAggregation,
+	dimensions MeterReadingDimensions,
 ) MeterReadingID {
 	input := fmt.Sprintf("%s|%s|%s|%s|%s",
 		subject.ToString(),
@@ -229,6 +509,13 @@ func computeMeterReadingID(
 		window.End().ToTime().UTC().Format(time.RFC3339),
 		aggregation.ToString(),
 	)
+	if names := dimensions.Names(); len(names) > 0 {
+		sort.Strings(names)
+		for _, name := range names {
+			value, _ := dimensions.Get(name)
+			input += fmt.Sprintf("|%q=%q", name, value)
+		}
+	}
 	hash := sha256.Sum256([]byte(input))
 	hashStr := hex.EncodeToString(hash[:16])
 	return MeterReadingID{value: hashStr}
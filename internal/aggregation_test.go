@@ -0,0 +1,757 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/chrisconley/metron/internal/testutil"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test helpers
+
+// newTestRecordSpec creates a fixture MeterRecordSpec with a single instant
+// observation.
+func newTestRecordSpec(id, quantity, unit string, observedAt time.Time) specs.MeterRecordSpec {
+	return testutil.FixtureMeterRecord(
+		testutil.WithRecordID(id),
+		testutil.WithRecordObservedAt(observedAt),
+		testutil.WithRecordObservations(testutil.FixtureObservation(quantity, unit)),
+	)
+}
+
+// newTestAggregateConfig creates an AggregateConfigSpec over January 2024.
+func newTestAggregateConfig(aggregation string) specs.AggregateConfigSpec {
+	return specs.AggregateConfigSpec{
+		Aggregation: aggregation,
+		Window: specs.TimeWindowSpec{
+			Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+func TestAggregate_Version(t *testing.T) {
+	t.Run("produces readings at version 1", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newTestRecordSpec("event-1", "100", "tokens", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+			newTestRecordSpec("event-2", "200", "tokens", time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)),
+		}
+
+		reading, err := Aggregate(records, nil, newTestAggregateConfig("sum"))
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), reading.Version)
+	})
+
+	t.Run("aggregated reading round-trips through NewMeterReading", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newTestRecordSpec("event-1", "100", "tokens", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+		}
+
+		readingSpec, err := Aggregate(records, nil, newTestAggregateConfig("sum"))
+		require.NoError(t, err)
+
+		reading, err := NewMeterReading(specs.IncrementVersion(readingSpec))
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), reading.Version.ToInt64())
+	})
+}
+
+func TestAggregate_FirstNonZero(t *testing.T) {
+	t.Run("returns earliest non-zero quantity", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newTestRecordSpec("event-3", "12", "seats", time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)),
+			newTestRecordSpec("event-1", "0", "seats", time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)),
+			newTestRecordSpec("event-2", "8", "seats", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+		}
+
+		reading, err := Aggregate(records, nil, newTestAggregateConfig("first-non-zero"))
+
+		require.NoError(t, err)
+		require.Len(t, reading.ComputedValues, 1)
+		assert.Equal(t, "8", reading.ComputedValues[0].Quantity)
+		assert.Equal(t, "seats", reading.ComputedValues[0].Unit)
+		assert.Equal(t, "first-non-zero", reading.ComputedValues[0].Aggregation)
+		assert.Equal(t, 3, reading.RecordCount)
+	})
+
+	t.Run("with all zero quantities returns zero", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newTestRecordSpec("event-1", "0", "seats", time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)),
+			newTestRecordSpec("event-2", "0", "seats", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+		}
+
+		reading, err := Aggregate(records, nil, newTestAggregateConfig("first-non-zero"))
+
+		require.NoError(t, err)
+		require.Len(t, reading.ComputedValues, 1)
+		assert.Equal(t, "0", reading.ComputedValues[0].Quantity)
+	})
+
+	t.Run("skips negative quantities", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newTestRecordSpec("event-1", "-5", "seats", time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)),
+			newTestRecordSpec("event-2", "0", "seats", time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)),
+			newTestRecordSpec("event-3", "8", "seats", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+		}
+
+		reading, err := Aggregate(records, nil, newTestAggregateConfig("first-non-zero"))
+
+		require.NoError(t, err)
+		require.Len(t, reading.ComputedValues, 1)
+		assert.Equal(t, "8", reading.ComputedValues[0].Quantity)
+	})
+
+	t.Run("with only negative and zero quantities returns zero", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newTestRecordSpec("event-1", "-5", "seats", time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)),
+			newTestRecordSpec("event-2", "0", "seats", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+		}
+
+		reading, err := Aggregate(records, nil, newTestAggregateConfig("first-non-zero"))
+
+		require.NoError(t, err)
+		require.Len(t, reading.ComputedValues, 1)
+		assert.Equal(t, "0", reading.ComputedValues[0].Quantity)
+		assert.Equal(t, "seats", reading.ComputedValues[0].Unit)
+	})
+
+	t.Run("with empty records returns error", func(t *testing.T) {
+		_, _, err := firstNonZeroRecord(nil)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot find first non-zero of empty records")
+	})
+}
+
+func TestAggregate_Count(t *testing.T) {
+	jan := func(d int) time.Time {
+		return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("returns number of records ignoring quantities", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newTestRecordSpec("event-1", "1250", "api-calls", jan(2)),
+			newTestRecordSpec("event-2", "0", "api-calls", jan(5)),
+			newTestRecordSpec("event-3", "3.5", "api-calls", jan(9)),
+		}
+
+		reading, err := Aggregate(records, nil, newTestAggregateConfig("count"))
+
+		require.NoError(t, err)
+		require.Len(t, reading.ComputedValues, 1)
+		assert.Equal(t, "3", reading.ComputedValues[0].Quantity)
+		assert.Equal(t, "api-calls", reading.ComputedValues[0].Unit)
+		assert.Equal(t, "count", reading.ComputedValues[0].Aggregation)
+		assert.Equal(t, 3, reading.RecordCount)
+	})
+
+	t.Run("sampled records count the events they represent", func(t *testing.T) {
+		var records []specs.MeterRecordSpec
+		for i := 1; i <= 10; i++ {
+			record := newTestRecordSpec(fmt.Sprintf("event-%d", i), "1", "api-calls", jan(i))
+			record.SampleRate = "0.1"
+			records = append(records, record)
+		}
+
+		reading, err := Aggregate(records, nil, newTestAggregateConfig("count"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "100", reading.ComputedValues[0].Quantity)
+		assert.Equal(t, 100, reading.RecordCount)
+	})
+
+	t.Run("with empty records returns error", func(t *testing.T) {
+		_, _, err := countRecords(nil)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot count empty records")
+	})
+}
+
+func TestAggregate_Average(t *testing.T) {
+	jan := func(d int) time.Time {
+		return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("returns arithmetic mean ignoring time between records", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newTestRecordSpec("event-1", "10", "ms", jan(2)),
+			newTestRecordSpec("event-2", "20", "ms", jan(3)),
+			newTestRecordSpec("event-3", "45", "ms", jan(30)),
+		}
+
+		reading, err := Aggregate(records, nil, newTestAggregateConfig("average"))
+
+		require.NoError(t, err)
+		require.Len(t, reading.ComputedValues, 1)
+		assert.Equal(t, "25", reading.ComputedValues[0].Quantity)
+		assert.Equal(t, "ms", reading.ComputedValues[0].Unit)
+		assert.Equal(t, "average", reading.ComputedValues[0].Aggregation)
+		assert.Equal(t, 3, reading.RecordCount)
+	})
+
+	t.Run("fractional mean", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newTestRecordSpec("event-1", "1", "ms", jan(2)),
+			newTestRecordSpec("event-2", "2", "ms", jan(5)),
+		}
+
+		reading, err := Aggregate(records, nil, newTestAggregateConfig("average"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "1.5", reading.ComputedValues[0].Quantity)
+	})
+
+	t.Run("sampled records average per event, normalized or not", func(t *testing.T) {
+		var records []specs.MeterRecordSpec
+		for i := 1; i <= 10; i++ {
+			record := newTestRecordSpec(fmt.Sprintf("event-%d", i), "1", "ms", jan(i))
+			record.SampleRate = "0.1"
+			records = append(records, record)
+		}
+		normalized, err := NormalizeForSampling(records)
+		require.NoError(t, err)
+
+		raw, err := Aggregate(records, nil, newTestAggregateConfig("average"))
+		require.NoError(t, err)
+		scaled, err := Aggregate(normalized, nil, newTestAggregateConfig("average"))
+		require.NoError(t, err)
+
+		assert.Equal(t, "1", raw.ComputedValues[0].Quantity)
+		assert.Equal(t, "1", scaled.ComputedValues[0].Quantity)
+	})
+
+	t.Run("mixed sample rates weight records by events represented", func(t *testing.T) {
+		sampled := newTestRecordSpec("event-1", "10", "ms", jan(2))
+		sampled.SampleRate = "0.5"
+		records := []specs.MeterRecordSpec{sampled, newTestRecordSpec("event-2", "40", "ms", jan(3))}
+
+		reading, err := Aggregate(records, nil, newTestAggregateConfig("average"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "20", reading.ComputedValues[0].Quantity)
+	})
+
+	t.Run("with empty records returns error", func(t *testing.T) {
+		_, _, err := avgRecords(nil)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot average empty records")
+	})
+}
+
+func TestAggregate_Mode(t *testing.T) {
+	jan := func(d int) time.Time {
+		return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("returns most frequent quantity", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newTestRecordSpec("event-1", "4", "instances", jan(2)),
+			newTestRecordSpec("event-2", "8", "instances", jan(5)),
+			newTestRecordSpec("event-3", "8", "instances", jan(9)),
+			newTestRecordSpec("event-4", "2", "instances", jan(12)),
+		}
+
+		reading, err := Aggregate(records, nil, newTestAggregateConfig("mode"))
+
+		require.NoError(t, err)
+		require.Len(t, reading.ComputedValues, 1)
+		assert.Equal(t, "8", reading.ComputedValues[0].Quantity)
+		assert.Equal(t, "instances", reading.ComputedValues[0].Unit)
+		assert.Equal(t, "mode", reading.ComputedValues[0].Aggregation)
+		assert.Equal(t, 4, reading.RecordCount)
+	})
+
+	t.Run("bimodal tie returns smallest quantity string", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newTestRecordSpec("event-1", "5", "instances", jan(2)),
+			newTestRecordSpec("event-2", "3", "instances", jan(5)),
+			newTestRecordSpec("event-3", "5", "instances", jan(9)),
+			newTestRecordSpec("event-4", "3", "instances", jan(12)),
+		}
+
+		reading, err := Aggregate(records, nil, newTestAggregateConfig("mode"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "3", reading.ComputedValues[0].Quantity)
+	})
+
+	t.Run("all distinct returns smallest quantity string", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newTestRecordSpec("event-1", "9", "instances", jan(2)),
+			newTestRecordSpec("event-2", "12", "instances", jan(5)),
+			newTestRecordSpec("event-3", "7", "instances", jan(9)),
+		}
+
+		reading, err := Aggregate(records, nil, newTestAggregateConfig("mode"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "12", reading.ComputedValues[0].Quantity)
+	})
+
+	t.Run("with empty records returns error", func(t *testing.T) {
+		_, _, err := modeRecords(nil)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot find mode of empty records")
+	})
+}
+
+func TestAggregate_FreeQuantity(t *testing.T) {
+	records := []specs.MeterRecordSpec{
+		newTestRecordSpec("event-1", "600", "api-calls", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+		newTestRecordSpec("event-2", "700", "api-calls", time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)),
+	}
+
+	t.Run("subtracts free quantity once per window", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.FreeQuantity = "1000"
+
+		reading, err := Aggregate(records, nil, config)
+
+		require.NoError(t, err)
+		assert.Equal(t, "300", reading.ComputedValues[0].Quantity)
+	})
+
+	t.Run("floors at zero when usage is within free quantity", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.FreeQuantity = "5000"
+
+		reading, err := Aggregate(records, nil, config)
+
+		require.NoError(t, err)
+		assert.Equal(t, "0", reading.ComputedValues[0].Quantity)
+	})
+}
+
+func TestAggregate_MaxValue(t *testing.T) {
+	records := []specs.MeterRecordSpec{
+		newTestRecordSpec("event-1", "600", "tokens", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+		newTestRecordSpec("event-2", "400", "tokens", time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)),
+	}
+
+	tests := []struct {
+		name         string
+		maxValue     string
+		wantQuantity string
+		wantCapped   bool
+	}{
+		{name: "sum below cap", maxValue: "2000", wantQuantity: "1000", wantCapped: false},
+		{name: "sum exactly at cap is not capped", maxValue: "1000", wantQuantity: "1000", wantCapped: false},
+		{name: "sum above cap is truncated", maxValue: "750", wantQuantity: "750", wantCapped: true},
+		{name: "no cap configured", maxValue: "", wantQuantity: "1000", wantCapped: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newTestAggregateConfig("sum")
+			config.MaxValue = tt.maxValue
+
+			reading, err := Aggregate(records, nil, config)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantQuantity, reading.ComputedValues[0].Quantity)
+			assert.Equal(t, tt.wantCapped, reading.WasCapped)
+		})
+	}
+
+	t.Run("applies cap after free quantity", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.FreeQuantity = "200"
+		config.MaxValue = "900"
+
+		reading, err := Aggregate(records, nil, config)
+
+		require.NoError(t, err)
+		assert.Equal(t, "800", reading.ComputedValues[0].Quantity)
+		assert.False(t, reading.WasCapped)
+	})
+
+	t.Run("rejects invalid max value", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.MaxValue = "lots"
+
+		_, err := Aggregate(records, nil, config)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid max value")
+	})
+}
+
+func TestAggregateGrouped(t *testing.T) {
+	withDimensions := func(spec specs.MeterRecordSpec, dimensions map[string]string) specs.MeterRecordSpec {
+		spec.Dimensions = dimensions
+		return spec
+	}
+	jan10 := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("no group by returns a single reading", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			withDimensions(newTestRecordSpec("event-1", "100", "tokens", jan10), map[string]string{"model": "gpt-4"}),
+			withDimensions(newTestRecordSpec("event-2", "200", "tokens", jan10), map[string]string{"model": "claude"}),
+		}
+
+		readings, err := AggregateGrouped(records, nil, newTestAggregateConfig("sum"))
+
+		require.NoError(t, err)
+		require.Len(t, readings, 1)
+		assert.Equal(t, "300", readings[0].ComputedValues[0].Quantity)
+		assert.Empty(t, readings[0].Dimensions)
+
+		single, err := Aggregate(records, nil, newTestAggregateConfig("sum"))
+		require.NoError(t, err)
+		assert.Equal(t, single.ID, readings[0].ID)
+	})
+
+	t.Run("group by model returns one reading per model", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			withDimensions(newTestRecordSpec("event-1", "100", "tokens", jan10), map[string]string{"model": "gpt-4"}),
+			withDimensions(newTestRecordSpec("event-2", "200", "tokens", jan10), map[string]string{"model": "claude"}),
+			withDimensions(newTestRecordSpec("event-3", "50", "tokens", jan10), map[string]string{"model": "gpt-4"}),
+			newTestRecordSpec("event-4", "7", "tokens", jan10),
+		}
+		config := newTestAggregateConfig("sum")
+		config.GroupBy = []string{"model"}
+
+		readings, err := AggregateGrouped(records, nil, config)
+
+		require.NoError(t, err)
+		require.Len(t, readings, 3)
+		assert.Equal(t, map[string]string{"model": ""}, readings[0].Dimensions)
+		assert.Equal(t, "7", readings[0].ComputedValues[0].Quantity)
+		assert.Equal(t, map[string]string{"model": "claude"}, readings[1].Dimensions)
+		assert.Equal(t, "200", readings[1].ComputedValues[0].Quantity)
+		assert.Equal(t, map[string]string{"model": "gpt-4"}, readings[2].Dimensions)
+		assert.Equal(t, "150", readings[2].ComputedValues[0].Quantity)
+		assert.Equal(t, 2, readings[2].RecordCount)
+		assert.NotEqual(t, readings[1].ID, readings[2].ID)
+	})
+
+	t.Run("multiple group by keys partition by each combination", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			withDimensions(newTestRecordSpec("event-1", "1", "tokens", jan10), map[string]string{"model": "gpt-4", "region": "us"}),
+			withDimensions(newTestRecordSpec("event-2", "2", "tokens", jan10), map[string]string{"model": "gpt-4", "region": "eu"}),
+			withDimensions(newTestRecordSpec("event-3", "4", "tokens", jan10), map[string]string{"model": "claude", "region": "us"}),
+			withDimensions(newTestRecordSpec("event-4", "8", "tokens", jan10), map[string]string{"model": "claude", "region": "eu"}),
+			withDimensions(newTestRecordSpec("event-5", "16", "tokens", jan10), map[string]string{"model": "claude", "region": "eu"}),
+		}
+		config := newTestAggregateConfig("sum")
+		config.GroupBy = []string{"model", "region"}
+
+		readings, err := AggregateGrouped(records, nil, config)
+
+		require.NoError(t, err)
+		require.Len(t, readings, 4)
+		quantities := make(map[string]string)
+		for _, reading := range readings {
+			quantities[reading.Dimensions["model"]+"/"+reading.Dimensions["region"]] = reading.ComputedValues[0].Quantity
+		}
+		assert.Equal(t, map[string]string{
+			"gpt-4/us":  "1",
+			"gpt-4/eu":  "2",
+			"claude/us": "4",
+			"claude/eu": "24",
+		}, quantities)
+	})
+
+	t.Run("Aggregate rejects multiple groups", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			withDimensions(newTestRecordSpec("event-1", "100", "tokens", jan10), map[string]string{"model": "gpt-4"}),
+			withDimensions(newTestRecordSpec("event-2", "200", "tokens", jan10), map[string]string{"model": "claude"}),
+		}
+		config := newTestAggregateConfig("sum")
+		config.GroupBy = []string{"model"}
+
+		_, err := Aggregate(records, nil, config)
+
+		assert.ErrorContains(t, err, "AggregateGrouped")
+	})
+
+	t.Run("rejects duplicate group by dimensions", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.GroupBy = []string{"model", "model"}
+
+		_, err := AggregateGrouped([]specs.MeterRecordSpec{newTestRecordSpec("event-1", "1", "tokens", jan10)}, nil, config)
+
+		assert.ErrorContains(t, err, "duplicate dimension")
+	})
+}
+
+func TestAggregate_OutputMetadata(t *testing.T) {
+	records := []specs.MeterRecordSpec{
+		newTestRecordSpec("event-1", "100", "tokens", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+	}
+
+	t.Run("sets output metadata on the reading", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.OutputMetadata = map[string]string{"pipeline_run_id": "run-42"}
+
+		reading, err := Aggregate(records, nil, config)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"pipeline_run_id": "run-42"}, reading.Metadata)
+	})
+
+	t.Run("metadata is not part of the reading ID", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.OutputMetadata = map[string]string{"pipeline_run_id": "run-42"}
+
+		withMetadata, err := Aggregate(records, nil, config)
+		require.NoError(t, err)
+		withoutMetadata, err := Aggregate(records, nil, newTestAggregateConfig("sum"))
+		require.NoError(t, err)
+
+		assert.Equal(t, withoutMetadata.ID, withMetadata.ID)
+		assert.Nil(t, withoutMetadata.Metadata)
+	})
+}
+
+func TestAggregate_MinRecordCount(t *testing.T) {
+	recordsAt := func(days ...int) []specs.MeterRecordSpec {
+		var records []specs.MeterRecordSpec
+		for i, day := range days {
+			id := fmt.Sprintf("event-%d", i+1)
+			records = append(records, newTestRecordSpec(id, "10", "tokens", time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)))
+		}
+		return records
+	}
+
+	t.Run("enough records returns no error", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.MinRecordCount = 3
+
+		reading, err := Aggregate(recordsAt(1, 2, 3, 4, 5), nil, config)
+
+		require.NoError(t, err)
+		assert.Equal(t, 5, reading.RecordCount)
+	})
+
+	t.Run("too few records returns reading with InsufficientDataError", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.MinRecordCount = 3
+
+		reading, err := Aggregate(recordsAt(1, 2), nil, config)
+
+		require.Error(t, err)
+		assert.True(t, IsInsufficientDataError(err))
+		var insufficient *InsufficientDataError
+		require.ErrorAs(t, err, &insufficient)
+		assert.Equal(t, 2, insufficient.RecordCount)
+		assert.Equal(t, 3, insufficient.MinRecordCount)
+		assert.Equal(t, reading.ID, insufficient.ReadingID)
+		assert.Equal(t, "20", reading.ComputedValues[0].Quantity)
+	})
+
+	t.Run("time-weighted-avg counts the carried-forward record", func(t *testing.T) {
+		config := newTestAggregateConfig("time-weighted-avg")
+		config.MinRecordCount = 3
+		lastBefore := newTestRecordSpec("event-0", "10", "tokens", time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC))
+
+		reading, err := Aggregate(recordsAt(10, 20), &lastBefore, config)
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, reading.RecordCount)
+	})
+
+	t.Run("grouped readings below minimum are all returned", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.MinRecordCount = 2
+		config.GroupBy = []string{"model"}
+		records := recordsAt(1, 2, 3)
+		records[0].Dimensions = map[string]string{"model": "gpt-4"}
+		records[1].Dimensions = map[string]string{"model": "gpt-4"}
+		records[2].Dimensions = map[string]string{"model": "claude"}
+
+		readings, err := AggregateGrouped(records, nil, config)
+
+		assert.True(t, IsInsufficientDataError(err))
+		assert.Len(t, readings, 2)
+	})
+
+	t.Run("rejects negative minimum", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.MinRecordCount = -1
+
+		_, err := Aggregate(recordsAt(1), nil, config)
+
+		assert.Error(t, err)
+		assert.False(t, IsInsufficientDataError(err))
+	})
+}
+
+func TestAggregate_SourceRecordIDs(t *testing.T) {
+	records := []specs.MeterRecordSpec{
+		newTestRecordSpec("event-c", "10", "tokens", time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)),
+		newTestRecordSpec("event-a", "10", "tokens", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+		newTestRecordSpec("event-b", "10", "tokens", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)),
+	}
+
+	t.Run("opt-in produces all IDs in sorted order", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.IncludeSourceIDs = true
+
+		reading, err := Aggregate(records, nil, config)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"event-a", "event-b", "event-c"}, reading.SourceRecordIDs)
+		assert.False(t, reading.SourceRecordIDsTruncated)
+	})
+
+	t.Run("truncates at MaxSourceIDs", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.IncludeSourceIDs = true
+		config.MaxSourceIDs = 2
+
+		reading, err := Aggregate(records, nil, config)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"event-a", "event-b"}, reading.SourceRecordIDs)
+		assert.True(t, reading.SourceRecordIDsTruncated)
+		assert.Equal(t, 3, reading.RecordCount)
+	})
+
+	t.Run("opt-out leaves nil", func(t *testing.T) {
+		reading, err := Aggregate(records, nil, newTestAggregateConfig("sum"))
+
+		require.NoError(t, err)
+		assert.Nil(t, reading.SourceRecordIDs)
+	})
+
+	t.Run("bundled observations list their record once", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.IncludeSourceIDs = true
+		bundled := records[1]
+		bundled.Observations = append(bundled.Observations, bundled.Observations[0])
+
+		reading, err := Aggregate([]specs.MeterRecordSpec{bundled}, nil, config)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"event-a"}, reading.SourceRecordIDs)
+	})
+
+	t.Run("includes carried-forward record used by time-weighted-avg", func(t *testing.T) {
+		config := newTestAggregateConfig("time-weighted-avg")
+		config.IncludeSourceIDs = true
+		lastBefore := newTestRecordSpec("event-0", "10", "tokens", time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC))
+
+		reading, err := Aggregate(records, &lastBefore, config)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"event-0", "event-a", "event-b", "event-c"}, reading.SourceRecordIDs)
+	})
+
+	t.Run("rejects MaxSourceIDs without IncludeSourceIDs", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.MaxSourceIDs = 2
+
+		_, err := Aggregate(records, nil, config)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestAggregate_ResetDetection(t *testing.T) {
+	jan := func(d int) time.Time {
+		return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	series := func(quantities ...string) []specs.MeterRecordSpec {
+		records := make([]specs.MeterRecordSpec, len(quantities))
+		for i, q := range quantities {
+			records[i] = newTestRecordSpec(fmt.Sprintf("event-%d", i+1), q, "requests", jan(1+i*7))
+		}
+		return records
+	}
+	aggregateWithThreshold := func(t *testing.T, records []specs.MeterRecordSpec, threshold string) specs.MeterReadingSpec {
+		t.Helper()
+		config := newTestAggregateConfig("time-weighted-avg")
+		config.ResetDetectionThreshold = threshold
+		reading, err := Aggregate(records, nil, config)
+		require.NoError(t, err)
+		return reading
+	}
+	assertSameQuantity := func(t *testing.T, expected, actual specs.MeterReadingSpec) {
+		t.Helper()
+		e, err := NewDecimal(expected.ComputedValues[0].Quantity)
+		require.NoError(t, err)
+		a, err := NewDecimal(actual.ComputedValues[0].Quantity)
+		require.NoError(t, err)
+		assert.Equal(t, 0, e.Cmp(a), "expected %s, got %s", e, a)
+	}
+
+	t.Run("smooth series has no resets", func(t *testing.T) {
+		records := series("100", "200", "300")
+
+		reading := aggregateWithThreshold(t, records, "50")
+
+		assert.Equal(t, 0, reading.ResetCount)
+		assertSameQuantity(t, aggregateWithThreshold(t, records, ""), reading)
+	})
+
+	t.Run("drop exceeding threshold is a reset", func(t *testing.T) {
+		reading := aggregateWithThreshold(t, series("100", "1000", "0", "200"), "500")
+
+		assert.Equal(t, 1, reading.ResetCount)
+		assertSameQuantity(t, aggregateWithThreshold(t, series("100", "1000", "1000", "1200"), ""), reading)
+	})
+
+	t.Run("drop within threshold is a real change", func(t *testing.T) {
+		records := series("1000", "800", "900")
+
+		reading := aggregateWithThreshold(t, records, "500")
+
+		assert.Equal(t, 0, reading.ResetCount)
+		assertSameQuantity(t, aggregateWithThreshold(t, records, ""), reading)
+	})
+
+	t.Run("multiple resets are counted", func(t *testing.T) {
+		reading := aggregateWithThreshold(t, series("500", "0", "400", "0"), "100")
+
+		assert.Equal(t, 2, reading.ResetCount)
+		assertSameQuantity(t, aggregateWithThreshold(t, series("500", "500", "900", "900"), ""), reading)
+	})
+
+	t.Run("negative threshold means no reset detection", func(t *testing.T) {
+		records := series("100", "1000", "0", "200")
+
+		reading := aggregateWithThreshold(t, records, "-1")
+
+		assert.Equal(t, 0, reading.ResetCount)
+		assertSameQuantity(t, aggregateWithThreshold(t, records, ""), reading)
+	})
+
+	t.Run("zero at start is not a reset", func(t *testing.T) {
+		reading := aggregateWithThreshold(t, series("0", "100", "200"), "0")
+
+		assert.Equal(t, 0, reading.ResetCount)
+	})
+
+	t.Run("drop from carried-forward record is a reset", func(t *testing.T) {
+		lastBefore := newTestRecordSpec("event-0", "1000", "requests", time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC))
+		config := newTestAggregateConfig("time-weighted-avg")
+		config.ResetDetectionThreshold = "500"
+
+		reading, err := Aggregate(series("0", "100"), &lastBefore, config)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, reading.ResetCount)
+		expected := aggregateWithThreshold(t, series("1000", "1100"), "")
+		assertSameQuantity(t, expected, reading)
+	})
+
+	t.Run("requires time-weighted-avg", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.ResetDetectionThreshold = "100"
+
+		_, err := Aggregate(series("100"), nil, config)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "requires time-weighted-avg")
+	})
+}
@@ -1,13 +1,36 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
+	"time"
+
 	specs "github.com/chrisconley/metron/specs"
 )
 
+// ErrInstantWindowForTemporalAggregation is returned when an aggregation that
+// weights values by elapsed time is configured with a zero-duration window.
+var ErrInstantWindowForTemporalAggregation = errors.New("instant window cannot be used with temporal aggregation")
+
 type AggregationConfig struct {
-	aggregation MeterReadingAggregation
-	window      TimeWindow
+	aggregation    MeterReadingAggregation
+	window         TimeWindow
+	freeQuantity   *Decimal
+	maxValue       *Decimal
+	groupBy        []string
+	metadata       MeterReadingMetadata
+	minRecordCount int
+	includeIDs     bool
+	maxSourceIDs   int
+	unitAliases    map[string]string
+	resetThreshold *Decimal
+	skewTolerance  time.Duration
+	strictMode     bool
+	timezone       string
+	maxPerChunk    int
+	idStrategy     string
+	outputID       string
+	windowOrigin   time.Duration
 }
 
 func NewAggregationConfig(spec specs.AggregateConfigSpec) (AggregationConfig, error) {
@@ -20,10 +43,118 @@ func NewAggregationConfig(spec specs.AggregateConfigSpec) (AggregationConfig, er
 	if err != nil {
 		return AggregationConfig{}, fmt.Errorf("invalid window: %w", err)
 	}
+	if aggregation.IsTimeWeightedAvg() && !spec.Window.Start.Before(spec.Window.End) {
+		return AggregationConfig{}, fmt.Errorf("invalid window: %w: %s", ErrInstantWindowForTemporalAggregation, aggregation.ToString())
+	}
+
+	var freeQuantity *Decimal
+	if spec.FreeQuantity != "" {
+		free, err := NewFreeQuantity(spec.FreeQuantity)
+		if err != nil {
+			return AggregationConfig{}, err
+		}
+		freeQuantity = &free
+	}
+
+	var maxValue *Decimal
+	if spec.MaxValue != "" {
+		max, err := NewDecimal(spec.MaxValue)
+		if err != nil {
+			return AggregationConfig{}, fmt.Errorf("invalid max value: %w", err)
+		}
+		maxValue = &max
+	}
+
+	seen := make(map[string]bool, len(spec.GroupBy))
+	for i, name := range spec.GroupBy {
+		if name == "" {
+			return AggregationConfig{}, fmt.Errorf("group by %d: dimension name is required", i)
+		}
+		if seen[name] {
+			return AggregationConfig{}, fmt.Errorf("group by %d: duplicate dimension %q", i, name)
+		}
+		seen[name] = true
+	}
+
+	if spec.MinRecordCount < 0 {
+		return AggregationConfig{}, fmt.Errorf("invalid min record count: must be non-negative, got %d", spec.MinRecordCount)
+	}
+
+	if spec.MaxSourceIDs < 0 {
+		return AggregationConfig{}, fmt.Errorf("invalid max source IDs: must be non-negative, got %d", spec.MaxSourceIDs)
+	}
+	if spec.MaxSourceIDs > 0 && !spec.IncludeSourceIDs {
+		return AggregationConfig{}, fmt.Errorf("max source IDs requires include source IDs")
+	}
+
+	unitAliases, err := newUnitAliases(spec.UnitAliases)
+	if err != nil {
+		return AggregationConfig{}, err
+	}
+
+	var resetThreshold *Decimal
+	if spec.ResetDetectionThreshold != "" {
+		threshold, err := NewDecimal(spec.ResetDetectionThreshold)
+		if err != nil {
+			return AggregationConfig{}, fmt.Errorf("invalid reset detection threshold: %w", err)
+		}
+		if !aggregation.IsTimeWeightedAvg() {
+			return AggregationConfig{}, fmt.Errorf("reset detection threshold requires time-weighted-avg aggregation")
+		}
+		// A negative threshold disables detection
+		if threshold.Cmp(NewDecimalFromInt64(0)) >= 0 {
+			resetThreshold = &threshold
+		}
+	}
+
+	if spec.ClockSkewTolerance < 0 {
+		return AggregationConfig{}, fmt.Errorf("invalid clock skew tolerance: must be non-negative, got %s", spec.ClockSkewTolerance)
+	}
+
+	if spec.OutputTimezone != "" {
+		if _, err := time.LoadLocation(spec.OutputTimezone); err != nil {
+			return AggregationConfig{}, fmt.Errorf("invalid output timezone: %w", err)
+		}
+	}
+
+	if spec.MaxRecordsPerAggregate < 0 {
+		return AggregationConfig{}, fmt.Errorf("invalid max records per aggregate: must be non-negative, got %d", spec.MaxRecordsPerAggregate)
+	}
+	if spec.MaxRecordsPerAggregate > 0 {
+		if err := checkChunkable(aggregation, spec.GroupBy); err != nil {
+			return AggregationConfig{}, fmt.Errorf("invalid max records per aggregate: %w", err)
+		}
+	}
+
+	idStrategy, err := newOutputIDStrategy(spec.OutputIDStrategy, spec.OutputID, spec.GroupBy)
+	if err != nil {
+		return AggregationConfig{}, fmt.Errorf("invalid output ID strategy: %w", err)
+	}
+
+	windowOrigin, err := NewWindowOrigin(spec.WindowOrigin)
+	if err != nil {
+		return AggregationConfig{}, fmt.Errorf("invalid window origin: %w", err)
+	}
 
 	return AggregationConfig{
-		aggregation: aggregation,
-		window:      window,
+		aggregation:    aggregation,
+		window:         window,
+		freeQuantity:   freeQuantity,
+		maxValue:       maxValue,
+		groupBy:        append([]string(nil), spec.GroupBy...),
+		metadata:       NewMeterReadingMetadata(spec.OutputMetadata),
+		minRecordCount: spec.MinRecordCount,
+		includeIDs:     spec.IncludeSourceIDs,
+		maxSourceIDs:   spec.MaxSourceIDs,
+		unitAliases:    unitAliases,
+		resetThreshold: resetThreshold,
+		skewTolerance:  spec.ClockSkewTolerance,
+		strictMode:     spec.StrictMode || spec.StrictWindowValidation,
+		timezone:       spec.OutputTimezone,
+		maxPerChunk:    spec.MaxRecordsPerAggregate,
+		idStrategy:     idStrategy,
+		outputID:       spec.OutputID,
+		windowOrigin:   windowOrigin,
 	}, nil
 }
 
@@ -34,3 +165,111 @@ func (c AggregationConfig) Aggregation() MeterReadingAggregation {
 func (c AggregationConfig) Window() TimeWindow {
 	return c.window
 }
+
+// FreeQuantity returns the per-window free quantity, or nil if none is configured.
+func (c AggregationConfig) FreeQuantity() *Decimal {
+	return c.freeQuantity
+}
+
+// MaxValue returns the cap on the aggregated value, or nil if none is configured.
+func (c AggregationConfig) MaxValue() *Decimal {
+	return c.maxValue
+}
+
+// GroupBy returns the dimension names readings are partitioned by, in order.
+func (c AggregationConfig) GroupBy() []string {
+	return c.groupBy
+}
+
+// OutputMetadata returns the metadata to set on output readings.
+func (c AggregationConfig) OutputMetadata() MeterReadingMetadata {
+	return c.metadata
+}
+
+// MinRecordCount returns the minimum record count for a reading to be
+// trustworthy, or zero if none is configured.
+func (c AggregationConfig) MinRecordCount() int {
+	return c.minRecordCount
+}
+
+// IncludeSourceIDs reports whether readings record their source record IDs.
+func (c AggregationConfig) IncludeSourceIDs() bool {
+	return c.includeIDs
+}
+
+// MaxSourceIDs returns the limit on stored source record IDs, or zero for no limit.
+func (c AggregationConfig) MaxSourceIDs() int {
+	return c.maxSourceIDs
+}
+
+// UnitAliases returns the map from unit aliases to canonical units applied to
+// input records, or nil if none are configured.
+func (c AggregationConfig) UnitAliases() map[string]string {
+	return c.unitAliases
+}
+
+// ResetDetectionThreshold returns the drop size that marks a counter reset,
+// or nil if reset detection is off.
+func (c AggregationConfig) ResetDetectionThreshold() *Decimal {
+	return c.resetThreshold
+}
+
+// ClockSkewTolerance returns how far outside the window a record may be
+// observed and still be aggregated.
+func (c AggregationConfig) ClockSkewTolerance() time.Duration {
+	return c.skewTolerance
+}
+
+// StrictMode reports whether out-of-window records fail the aggregation
+// instead of being excluded with a warning, as set by StrictMode or
+// StrictWindowValidation.
+func (c AggregationConfig) StrictMode() bool {
+	return c.strictMode
+}
+
+// OutputTimezone returns the IANA timezone name to set on output readings, or
+// empty for UTC.
+func (c AggregationConfig) OutputTimezone() string {
+	return c.timezone
+}
+
+// MaxRecordsPerAggregate returns the chunk size large inputs are split into,
+// or zero for no limit.
+func (c AggregationConfig) MaxRecordsPerAggregate() int {
+	return c.maxPerChunk
+}
+
+// OutputIDStrategy returns how output reading IDs are assigned: "sha256",
+// "ulid", or "passthrough".
+func (c AggregationConfig) OutputIDStrategy() string {
+	return c.idStrategy
+}
+
+// OutputID returns the reading ID used by the "passthrough" strategy, or
+// empty for other strategies.
+func (c AggregationConfig) OutputID() string {
+	return c.outputID
+}
+
+// WindowOrigin returns the offset from midnight, in [0, 24h), at which daily
+// and monthly billing periods begin.
+func (c AggregationConfig) WindowOrigin() time.Duration {
+	return c.windowOrigin
+}
+
+// CalendarWindowSequence returns the windows covering [start, end) to
+// aggregate with this config, one per calendar unit, as
+// CalendarWindowSequenceWithOrigin does with the config's WindowOrigin.
+// Boundaries are taken in the config's OutputTimezone, or UTC when it has
+// none. Returns error if start is not before end.
+func (c AggregationConfig) CalendarWindowSequence(start, end time.Time, unit CalendarUnit) ([]specs.TimeWindowSpec, error) {
+	loc := time.UTC
+	if c.timezone != "" {
+		var err error
+		loc, err = time.LoadLocation(c.timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output timezone: %w", err)
+		}
+	}
+	return CalendarWindowSequenceWithOrigin(start.In(loc), end.In(loc), unit, c.windowOrigin)
+}
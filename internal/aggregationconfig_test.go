@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAggregationConfig_InstantWindow(t *testing.T) {
+	instant := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	t.Run("sum with instant window succeeds", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.Window.Start = instant
+		config.Window.End = instant
+
+		_, err := NewAggregationConfig(config)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("time-weighted-avg with instant window returns sentinel error", func(t *testing.T) {
+		config := newTestAggregateConfig("time-weighted-avg")
+		config.Window.Start = instant
+		config.Window.End = instant
+
+		_, err := NewAggregationConfig(config)
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInstantWindowForTemporalAggregation)
+	})
+
+	t.Run("time-weighted-avg with one second window succeeds", func(t *testing.T) {
+		config := newTestAggregateConfig("time-weighted-avg")
+		config.Window.Start = instant
+		config.Window.End = instant.Add(time.Second)
+
+		_, err := NewAggregationConfig(config)
+
+		assert.NoError(t, err)
+	})
+}
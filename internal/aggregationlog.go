@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// AggregationStep is one intermediate step of an aggregation, for debugging.
+type AggregationStep struct {
+	// StepType names the step: "sort", "clamp", "weight", "sum", and "divide"
+	// for time-weighted-avg; "aggregate" for other aggregations.
+	StepType string
+	// RecordIndex is the record's position after sorting, or -1 for steps
+	// over all records.
+	RecordIndex int
+	// Value is the step's result, as a decimal string or RFC 3339 timestamp.
+	Value string
+	// Duration is the step's duration in seconds, or empty if not applicable.
+	Duration string
+}
+
+// AggregationLogger receives aggregation steps as they happen.
+type AggregationLogger interface {
+	LogStep(step AggregationStep)
+}
+
+type textAggregationLogger struct {
+	w io.Writer
+}
+
+// TextAggregationLogger returns an AggregationLogger that writes one line per
+// step to w, such as "weight record=0 value=864000 duration=86400".
+// Write errors are ignored; logging never fails an aggregation.
+func TextAggregationLogger(w io.Writer) AggregationLogger {
+	return textAggregationLogger{w: w}
+}
+
+func (l textAggregationLogger) LogStep(step AggregationStep) {
+	var b strings.Builder
+	b.WriteString(step.StepType)
+	if step.RecordIndex >= 0 {
+		fmt.Fprintf(&b, " record=%d", step.RecordIndex)
+	}
+	if step.Value != "" {
+		fmt.Fprintf(&b, " value=%s", step.Value)
+	}
+	if step.Duration != "" {
+		fmt.Fprintf(&b, " duration=%s", step.Duration)
+	}
+	b.WriteString("\n")
+	_, _ = io.WriteString(l.w, b.String())
+}
+
+type noopAggregationLogger struct{}
+
+// NoopAggregationLogger returns an AggregationLogger that discards every step.
+func NoopAggregationLogger() AggregationLogger {
+	return noopAggregationLogger{}
+}
+
+func (noopAggregationLogger) LogStep(AggregationStep) {}
+
+// AggregateWithLogging aggregates like Aggregate, reporting intermediate steps
+// to logger. time-weighted-avg reports each step of its computation; other
+// aggregations report only their result.
+//
+// Returns the computed value and the record count.
+func (a MeterReadingAggregation) AggregateWithLogging(
+	records []MeterRecord,
+	lastBefore *MeterRecord,
+	window TimeWindow,
+	logger AggregationLogger,
+) (ComputedValue, int, error) {
+	if a.IsTimeWeightedAvg() {
+		quantity, unit, err := timeWeightedAvgRecordsWithLogger(records, lastBefore, window, logger)
+		if err != nil {
+			return ComputedValue{}, 0, err
+		}
+		recordCount := len(records)
+		if lastBefore != nil {
+			recordCount++ // Count the carry-forward record
+		}
+		return NewComputedValue(quantity, unit, a), recordCount, nil
+	}
+
+	quantity, unit, recordCount, err := a.Aggregate(records, lastBefore, window)
+	if err != nil {
+		return ComputedValue{}, 0, err
+	}
+	logger.LogStep(AggregationStep{StepType: "aggregate", RecordIndex: -1, Value: quantity.String()})
+	return NewComputedValue(quantity, unit, a), recordCount, nil
+}
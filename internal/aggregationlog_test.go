@@ -0,0 +1,101 @@
+package internal
+
+import (
+	"bytes"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAggregationLogger collects steps for assertions.
+type recordingAggregationLogger struct {
+	steps []AggregationStep
+}
+
+func (l *recordingAggregationLogger) LogStep(step AggregationStep) {
+	l.steps = append(l.steps, step)
+}
+
+func TestMeterReadingAggregation_AggregateWithLogging(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	window, err := NewTimeWindow(specs.TimeWindowSpec{Start: day(1), End: day(5)})
+	require.NoError(t, err)
+	newRecord := func(id, quantity string, observedAt time.Time) MeterRecord {
+		record, err := NewMeterRecord(newTestRecordSpec(id, quantity, "seats", observedAt))
+		require.NoError(t, err)
+		return record
+	}
+	twa, err := NewMeterReadingAggregation("time-weighted-avg")
+	require.NoError(t, err)
+	seats, err := NewUnit("seats")
+	require.NoError(t, err)
+	expected := NewComputedValue(NewDecimalFromInt64(15), seats, twa)
+
+	// 10 seats carried in from before the window, 20 seats from day 3:
+	// 2 days at 10 and 2 days at 20 average to 15.
+	lastBefore := newRecord("rec-0", "10", time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC))
+	records := []MeterRecord{newRecord("rec-1", "20", day(3))}
+
+	t.Run("logs every time-weighted-avg step", func(t *testing.T) {
+		logger := &recordingAggregationLogger{}
+
+		value, recordCount, err := twa.AggregateWithLogging(records, &lastBefore, window, logger)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, recordCount)
+		assertComputedValueEqual(t, expected, value)
+
+		var stepTypes []string
+		for _, step := range logger.steps {
+			stepTypes = append(stepTypes, step.StepType)
+		}
+		assert.Equal(t, []string{"sort", "sort", "clamp", "weight", "weight", "sum", "divide"}, stepTypes)
+
+		assert.Equal(t, AggregationStep{StepType: "clamp", RecordIndex: 0, Value: "2024-01-01T00:00:00Z"}, logger.steps[2])
+		for i, weight := range logger.steps[3:5] {
+			assert.Equal(t, i, weight.RecordIndex)
+			duration, err := NewDecimal(weight.Duration)
+			require.NoError(t, err)
+			assert.Zero(t, duration.Cmp(NewDecimalFromInt64(2*86400)))
+		}
+		sum, err := NewDecimal(logger.steps[5].Value)
+		require.NoError(t, err)
+		assert.Zero(t, sum.Cmp(NewDecimalFromInt64(60*86400)))
+		assert.Equal(t, -1, logger.steps[6].RecordIndex)
+	})
+
+	t.Run("text logger writes one line per step", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		_, _, err := twa.AggregateWithLogging(records, &lastBefore, window, TextAggregationLogger(&buf))
+
+		require.NoError(t, err)
+		lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+		assert.Len(t, lines, 7)
+		assert.Equal(t, "sort record=0 value=10", string(lines[0]))
+		assert.Equal(t, "clamp record=0 value=2024-01-01T00:00:00Z", string(lines[2]))
+	})
+
+	t.Run("noop logger produces no output", func(t *testing.T) {
+		value, _, err := twa.AggregateWithLogging(records, &lastBefore, window, NoopAggregationLogger())
+
+		require.NoError(t, err)
+		assertComputedValueEqual(t, expected, value)
+	})
+
+	t.Run("other aggregations log their result", func(t *testing.T) {
+		sum, err := NewMeterReadingAggregation("sum")
+		require.NoError(t, err)
+		logger := &recordingAggregationLogger{}
+
+		_, _, err = sum.AggregateWithLogging([]MeterRecord{newRecord("rec-1", "20", day(3)), newRecord("rec-2", "5", day(4))}, nil, window, logger)
+
+		require.NoError(t, err)
+		assert.Equal(t, []AggregationStep{{StepType: "aggregate", RecordIndex: -1, Value: "25"}}, logger.steps)
+	})
+}
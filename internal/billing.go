@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// BillingSummary is an invoice-like view of a subject's usage for one
+// billing period, with one line item per unit. Quantities and amounts are
+// decimal strings.
+type BillingSummary struct {
+	Subject   string
+	Period    specs.TimeWindowSpec
+	LineItems []LineItem
+}
+
+// LineItem is a unit's usage for the period and what it costs.
+type LineItem struct {
+	Unit         string
+	Quantity     string
+	PricePerUnit string
+
+	// Quantity times PricePerUnit.
+	Total string
+}
+
+// MonthlyBillingSummary prices subject's readings for the calendar month
+// (in UTC) with ratecards.
+//
+// Computed values of the same unit are added, so a month may be covered by
+// several readings (e.g., one per day). Only "sum" and "count" values add up;
+// a unit with a value of any other aggregation, such as max or
+// time-weighted-avg, must have a single value. Line items are sorted by unit.
+// Returns error if month is invalid, a reading's subject is not subject, a
+// reading's window is not within the month, a unit has several values and
+// one is not additive, a unit has no rate card or more than one, or a
+// quantity or price is not a valid decimal.
+func MonthlyBillingSummary(
+	subject string,
+	year int,
+	month time.Month,
+	readings []specs.MeterReadingSpec,
+	ratecards []specs.RateCardSpec,
+) (BillingSummary, error) {
+	if month < time.January || month > time.December {
+		return BillingSummary{}, fmt.Errorf("invalid month: %d", month)
+	}
+	start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	period := specs.TimeWindowSpec{Start: start, End: start.AddDate(0, 1, 0)}
+
+	prices := make(map[string]Decimal, len(ratecards))
+	for i, ratecard := range ratecards {
+		if _, ok := prices[ratecard.Unit]; ok {
+			return BillingSummary{}, fmt.Errorf("duplicate rate card for unit %q", ratecard.Unit)
+		}
+		price, err := NewDecimal(ratecard.PricePerUnit)
+		if err != nil {
+			return BillingSummary{}, fmt.Errorf("invalid rate card %d price: %w", i, err)
+		}
+		prices[ratecard.Unit] = price
+	}
+
+	quantities := make(map[string]Decimal)
+	valueCounts := make(map[string]int)
+	nonAdditive := make(map[string]string)
+	for i, reading := range readings {
+		if reading.Subject != subject {
+			return BillingSummary{}, fmt.Errorf("reading %d subject %q does not match %q", i, reading.Subject, subject)
+		}
+		if reading.Window.Start.Before(period.Start) || reading.Window.End.After(period.End) {
+			return BillingSummary{}, fmt.Errorf("reading %d window [%s, %s) is not within %s %d",
+				i, reading.Window.Start.Format(time.RFC3339), reading.Window.End.Format(time.RFC3339), month, year)
+		}
+		for j, value := range reading.ComputedValues {
+			quantity, err := NewDecimal(value.Quantity)
+			if err != nil {
+				return BillingSummary{}, fmt.Errorf("reading %d: invalid computed value %d quantity: %w", i, j, err)
+			}
+			aggregation := value.Aggregation
+			if aggregation == "" {
+				aggregation = reading.Aggregation
+			}
+			if !isAdditiveAggregation(aggregation) {
+				nonAdditive[value.Unit] = aggregation
+			}
+			valueCounts[value.Unit]++
+			if valueCounts[value.Unit] > 1 && nonAdditive[value.Unit] != "" {
+				return BillingSummary{}, fmt.Errorf("unit %q has several readings and %q values do not add up",
+					value.Unit, nonAdditive[value.Unit])
+			}
+			if total, ok := quantities[value.Unit]; ok {
+				quantity = total.Add(quantity)
+			}
+			quantities[value.Unit] = quantity
+		}
+	}
+
+	units := make([]string, 0, len(quantities))
+	for unit := range quantities {
+		units = append(units, unit)
+	}
+	sort.Strings(units)
+
+	lineItems := make([]LineItem, 0, len(units))
+	for _, unit := range units {
+		price, ok := prices[unit]
+		if !ok {
+			return BillingSummary{}, fmt.Errorf("no rate card for unit %q", unit)
+		}
+		quantity := quantities[unit]
+		lineItems = append(lineItems, LineItem{
+			Unit:         unit,
+			Quantity:     quantity.String(),
+			PricePerUnit: price.String(),
+			Total:        quantity.Mul(price).String(),
+		})
+	}
+
+	return BillingSummary{
+		Subject:   subject,
+		Period:    period,
+		LineItems: lineItems,
+	}, nil
+}
@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/internal/testutil"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonthlyBillingSummary(t *testing.T) {
+	ratecards := []specs.RateCardSpec{
+		{Unit: "input-tokens", PricePerUnit: "0.002"},
+		{Unit: "output-tokens", PricePerUnit: "0.006"},
+		{Unit: "seats", PricePerUnit: "15"},
+	}
+	january := specs.TimeWindowSpec{
+		Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("3 units produce 3 line items", func(t *testing.T) {
+		readings := []specs.MeterReadingSpec{
+			testutil.FixtureMeterReading(testutil.WithReadingValue("1000", "input-tokens")),
+			testutil.FixtureMeterReading(testutil.WithReadingValue("500", "output-tokens")),
+			testutil.FixtureMeterReading(testutil.WithReadingAggregation("max"), testutil.WithReadingValue("4", "seats")),
+		}
+
+		summary, err := MonthlyBillingSummary(testutil.FixtureSubject, 2024, time.January, readings, ratecards)
+
+		require.NoError(t, err)
+		assert.Equal(t, testutil.FixtureSubject, summary.Subject)
+		assert.Equal(t, january, summary.Period)
+		assert.Equal(t, []LineItem{
+			{Unit: "input-tokens", Quantity: "1000", PricePerUnit: "0.002", Total: "2.000"},
+			{Unit: "output-tokens", Quantity: "500", PricePerUnit: "0.006", Total: "3.000"},
+			{Unit: "seats", Quantity: "4", PricePerUnit: "15", Total: "60"},
+		}, summary.LineItems)
+	})
+
+	t.Run("readings of the same unit are added", func(t *testing.T) {
+		firstHalf := specs.TimeWindowSpec{Start: january.Start, End: time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)}
+		secondHalf := specs.TimeWindowSpec{Start: firstHalf.End, End: january.End}
+		readings := []specs.MeterReadingSpec{
+			testutil.FixtureMeterReading(testutil.WithReadingWindow(firstHalf), testutil.WithReadingValue("100", "input-tokens")),
+			testutil.FixtureMeterReading(testutil.WithReadingWindow(secondHalf), testutil.WithReadingValue("150", "input-tokens")),
+		}
+
+		summary, err := MonthlyBillingSummary(testutil.FixtureSubject, 2024, time.January, readings, ratecards)
+
+		require.NoError(t, err)
+		require.Len(t, summary.LineItems, 1)
+		assert.Equal(t, "250", summary.LineItems[0].Quantity)
+		assert.Equal(t, "0.500", summary.LineItems[0].Total)
+	})
+
+	t.Run("several non-additive readings of a unit return error", func(t *testing.T) {
+		firstHalf := specs.TimeWindowSpec{Start: january.Start, End: time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)}
+		secondHalf := specs.TimeWindowSpec{Start: firstHalf.End, End: january.End}
+		for _, aggregation := range []string{"max", "time-weighted-avg"} {
+			readings := []specs.MeterReadingSpec{
+				testutil.FixtureMeterReading(testutil.WithReadingWindow(firstHalf),
+					testutil.WithReadingAggregation(aggregation), testutil.WithReadingValue("4", "seats")),
+				testutil.FixtureMeterReading(testutil.WithReadingWindow(secondHalf),
+					testutil.WithReadingAggregation(aggregation), testutil.WithReadingValue("5", "seats")),
+			}
+
+			_, err := MonthlyBillingSummary(testutil.FixtureSubject, 2024, time.January, readings, ratecards)
+
+			assert.ErrorContains(t, err, `unit "seats" has several readings`, aggregation)
+		}
+	})
+
+	t.Run("count readings of the same unit are added", func(t *testing.T) {
+		firstHalf := specs.TimeWindowSpec{Start: january.Start, End: time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)}
+		secondHalf := specs.TimeWindowSpec{Start: firstHalf.End, End: january.End}
+		readings := []specs.MeterReadingSpec{
+			testutil.FixtureMeterReading(testutil.WithReadingWindow(firstHalf),
+				testutil.WithReadingAggregation("count"), testutil.WithReadingValue("10", "input-tokens")),
+			testutil.FixtureMeterReading(testutil.WithReadingWindow(secondHalf),
+				testutil.WithReadingAggregation("sum"), testutil.WithReadingValue("15", "input-tokens")),
+		}
+
+		summary, err := MonthlyBillingSummary(testutil.FixtureSubject, 2024, time.January, readings, ratecards)
+
+		require.NoError(t, err)
+		assert.Equal(t, "25", summary.LineItems[0].Quantity)
+	})
+
+	t.Run("missing rate card for a unit returns error", func(t *testing.T) {
+		readings := []specs.MeterReadingSpec{testutil.FixtureMeterReading(testutil.WithReadingValue("3", "gpu-hours"))}
+
+		_, err := MonthlyBillingSummary(testutil.FixtureSubject, 2024, time.January, readings, ratecards)
+
+		assert.ErrorContains(t, err, `no rate card for unit "gpu-hours"`)
+	})
+
+	t.Run("empty readings returns empty summary", func(t *testing.T) {
+		summary, err := MonthlyBillingSummary(testutil.FixtureSubject, 2024, time.January, nil, ratecards)
+
+		require.NoError(t, err)
+		assert.Equal(t, january, summary.Period)
+		assert.Empty(t, summary.LineItems)
+	})
+
+	t.Run("reading for another subject returns error", func(t *testing.T) {
+		readings := []specs.MeterReadingSpec{testutil.FixtureMeterReading(testutil.WithReadingSubject("customer:other"))}
+
+		_, err := MonthlyBillingSummary(testutil.FixtureSubject, 2024, time.January, readings, ratecards)
+
+		assert.ErrorContains(t, err, `subject "customer:other" does not match`)
+	})
+
+	t.Run("reading outside the month returns error", func(t *testing.T) {
+		_, err := MonthlyBillingSummary(testutil.FixtureSubject, 2024, time.February,
+			[]specs.MeterReadingSpec{testutil.FixtureMeterReading()}, ratecards)
+
+		assert.ErrorContains(t, err, "is not within February 2024")
+	})
+}
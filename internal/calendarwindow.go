@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// NormalizeWindowToCalendar returns the reading with its window widened to
+// whole calendar periods ("hour", "day", or "month") with
+// TimeWindowSpec.FloorTo, for readings whose windows were cut short by late
+// records or sliding windows. Period boundaries are taken in the reading's
+// Timezone, or UTC when it has none.
+//
+// "sum" quantities are projected to the whole period at the rate measured,
+// scaled by normalized duration / original duration as NormalizeForComparison
+// scales them: 30 units over the half hour 10:00–10:30 become 60 units for the
+// hour. A "count" is a number of events that actually happened and must stay
+// whole and agree with RecordCount, so it is kept. Other aggregations
+// describe a level rather than an amount and keep their quantities.
+// RecordCount and the reading ID are unchanged.
+//
+// The input reading is not modified. Returns error if period is unknown, the
+// reading's timezone or a quantity is invalid, or the window is empty.
+func NormalizeWindowToCalendar(reading specs.MeterReadingSpec, period string) (specs.MeterReadingSpec, error) {
+	loc := time.UTC
+	if reading.Timezone != "" {
+		var err error
+		loc, err = time.LoadLocation(reading.Timezone)
+		if err != nil {
+			return specs.MeterReadingSpec{}, fmt.Errorf("invalid timezone: %w", err)
+		}
+	}
+	window, err := reading.Window.FloorTo(period, loc)
+	if err != nil {
+		return specs.MeterReadingSpec{}, fmt.Errorf("invalid window: %w", err)
+	}
+
+	actual := reading.Window.End.Sub(reading.Window.Start)
+	normalized := window.End.Sub(window.Start)
+	if actual <= 0 {
+		return specs.MeterReadingSpec{}, fmt.Errorf("invalid window: cannot prorate an empty window")
+	}
+
+	result := reading
+	result.Window = specs.TimeWindowSpec{Start: window.Start.UTC(), End: window.End.UTC()}
+	if actual == normalized {
+		return result, nil
+	}
+
+	result.ComputedValues = make([]specs.ComputedValueSpec, len(reading.ComputedValues))
+	for i, value := range reading.ComputedValues {
+		aggregation := value.Aggregation
+		if aggregation == "" {
+			aggregation = reading.Aggregation
+		}
+		if aggregation != "sum" {
+			result.ComputedValues[i] = value
+			continue
+		}
+		quantity, err := NewDecimal(value.Quantity)
+		if err != nil {
+			return specs.MeterReadingSpec{}, fmt.Errorf("invalid computed value %d quantity: %w", i, err)
+		}
+		value.Quantity = prorate(quantity, normalized, actual).String()
+		result.ComputedValues[i] = value
+	}
+	return result, nil
+}
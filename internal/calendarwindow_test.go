@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCalendarTestReading(aggregation, quantity string, start, end time.Time) specs.MeterReadingSpec {
+	return specs.MeterReadingSpec{
+		ID:             "reading-1",
+		Subject:        "customer:acme",
+		Window:         specs.TimeWindowSpec{Start: start, End: end},
+		ComputedValues: []specs.ComputedValueSpec{{Quantity: quantity, Unit: "tokens"}},
+		Aggregation:    aggregation,
+		RecordCount:    3,
+	}
+}
+
+func TestNormalizeWindowToCalendar(t *testing.T) {
+	t.Run("window already aligned is unchanged", func(t *testing.T) {
+		reading := newCalendarTestReading("sum", "100",
+			time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC))
+
+		normalized, err := NormalizeWindowToCalendar(reading, "hour")
+
+		require.NoError(t, err)
+		assert.Equal(t, reading, normalized)
+	})
+
+	t.Run("partial hour is projected to the full hour", func(t *testing.T) {
+		reading := newCalendarTestReading("sum", "30",
+			time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC))
+
+		normalized, err := NormalizeWindowToCalendar(reading, "hour")
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), normalized.Window.Start)
+		assert.Equal(t, time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC), normalized.Window.End)
+		assert.Equal(t, "60", normalized.ComputedValues[0].Quantity)
+		assert.Equal(t, 3, normalized.RecordCount)
+		assert.Equal(t, "30", reading.ComputedValues[0].Quantity, "input reading should be unchanged")
+	})
+
+	t.Run("count values keep the number of events", func(t *testing.T) {
+		reading := newCalendarTestReading("count", "3",
+			time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), time.Date(2024, 1, 15, 10, 40, 0, 0, time.UTC))
+
+		normalized, err := NormalizeWindowToCalendar(reading, "hour")
+
+		require.NoError(t, err)
+		assert.Equal(t, "3", normalized.ComputedValues[0].Quantity)
+		assert.Equal(t, 3, normalized.RecordCount)
+	})
+
+	t.Run("month with DST uses the local month's duration", func(t *testing.T) {
+		// Local midnight March 1 to local midnight March 16 in New York spans
+		// the spring-forward change: 359 hours of a 743-hour month.
+		reading := newCalendarTestReading("sum", "359",
+			time.Date(2024, 3, 1, 5, 0, 0, 0, time.UTC), time.Date(2024, 3, 16, 4, 0, 0, 0, time.UTC))
+		reading.Timezone = "America/New_York"
+
+		normalized, err := NormalizeWindowToCalendar(reading, "month")
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 3, 1, 5, 0, 0, 0, time.UTC), normalized.Window.Start)
+		assert.Equal(t, time.Date(2024, 4, 1, 4, 0, 0, 0, time.UTC), normalized.Window.End)
+		assert.Equal(t, "743", normalized.ComputedValues[0].Quantity)
+	})
+
+	t.Run("level aggregations keep their quantity", func(t *testing.T) {
+		reading := newCalendarTestReading("max", "30",
+			time.Date(2024, 1, 15, 10, 15, 0, 0, time.UTC), time.Date(2024, 1, 15, 10, 45, 0, 0, time.UTC))
+
+		normalized, err := NormalizeWindowToCalendar(reading, "day")
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC), normalized.Window.End)
+		assert.Equal(t, "30", normalized.ComputedValues[0].Quantity)
+	})
+
+	t.Run("unknown period returns error", func(t *testing.T) {
+		reading := newCalendarTestReading("sum", "30",
+			time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC))
+
+		_, err := NormalizeWindowToCalendar(reading, "week")
+
+		assert.ErrorContains(t, err, `invalid period "week"`)
+	})
+}
+
+func TestNormalizeWindowToCalendar_MatchesComparison(t *testing.T) {
+	reading := newCalendarTestReading("sum", "30",
+		time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC))
+	reading.ComputedValues[0].Aggregation = "sum"
+
+	calendar, err := NormalizeWindowToCalendar(reading, "hour")
+	require.NoError(t, err)
+	comparison, err := NormalizeForComparison(reading, time.Hour)
+	require.NoError(t, err)
+
+	assert.Equal(t, comparison.ComputedValues[0].Quantity, calendar.ComputedValues[0].Quantity)
+}
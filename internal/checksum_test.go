@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignReading(t *testing.T) {
+	key := []byte("billing-signing-key")
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	reading := specs.MeterReadingSpec{
+		ID:             "reading-1",
+		WorkspaceID:    "workspace-test",
+		UniverseID:     "universe-test",
+		Subject:        "customer:test",
+		Dimensions:     map[string]string{"model": "gpt-4", "region": "us-east-1"},
+		Window:         specs.TimeWindowSpec{Start: start, End: start.AddDate(0, 1, 0)},
+		ComputedValues: []specs.ComputedValueSpec{{Quantity: "1250.50", Unit: "tokens", Aggregation: "sum"}},
+		Aggregation:    "sum",
+		RecordCount:    12,
+	}
+
+	t.Run("sign then verify passes", func(t *testing.T) {
+		signed, err := SignReading(reading, key)
+		require.NoError(t, err)
+		assert.Len(t, signed.Checksum, 64)
+		assert.Empty(t, reading.Checksum, "input reading is not modified")
+
+		ok, err := VerifyReading(signed, key)
+
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("canonical form ignores representation", func(t *testing.T) {
+		signed, err := SignReading(reading, key)
+		require.NoError(t, err)
+
+		equivalent := signed
+		equivalent.ComputedValues = []specs.ComputedValueSpec{{Quantity: "1250.5", Unit: "tokens", Aggregation: "sum"}}
+		equivalent.Window.Start = start.In(time.FixedZone("EST", -5*60*60))
+
+		ok, err := VerifyReading(equivalent, key)
+
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("modified quantity fails verification", func(t *testing.T) {
+		signed, err := SignReading(reading, key)
+		require.NoError(t, err)
+
+		signed.ComputedValues = []specs.ComputedValueSpec{{Quantity: "125.05", Unit: "tokens", Aggregation: "sum"}}
+		ok, err := VerifyReading(signed, key)
+
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("different key fails verification", func(t *testing.T) {
+		signed, err := SignReading(reading, key)
+		require.NoError(t, err)
+
+		ok, err := VerifyReading(signed, []byte("another-key"))
+
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("missing checksum fails", func(t *testing.T) {
+		ok, err := VerifyReading(reading, key)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "has no checksum")
+		assert.False(t, ok)
+	})
+
+	t.Run("empty key returns error", func(t *testing.T) {
+		_, err := SignReading(reading, nil)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "signing key is required")
+	})
+}
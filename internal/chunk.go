@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"fmt"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// ChunkedAggregate is Aggregate for inputs too large to convert at once. It
+// aggregates records chunkSize at a time and combines the chunk results, so
+// only one chunk of domain records is held in memory. The reading is the same
+// as Aggregate's for the same records.
+//
+// Only "sum", "max", and "min" combine exactly across chunks; other
+// aggregations, which need every record at once (time-weighted-avg sorts by
+// time), return an error, as does a config with GroupBy. Returns error if
+// chunkSize is not positive.
+func ChunkedAggregate(
+	records []specs.MeterRecordSpec,
+	last *specs.MeterRecordSpec,
+	config specs.AggregateConfigSpec,
+	chunkSize int,
+) (specs.MeterReadingSpec, error) {
+	if chunkSize <= 0 {
+		return specs.MeterReadingSpec{}, fmt.Errorf("chunk size must be positive, got %d", chunkSize)
+	}
+	validated, err := NewAggregationConfig(config)
+	if err != nil {
+		return specs.MeterReadingSpec{}, fmt.Errorf("invalid config: %w", err)
+	}
+	if err := checkChunkable(validated.Aggregation(), validated.GroupBy()); err != nil {
+		return specs.MeterReadingSpec{}, err
+	}
+
+	reading, _, err := chunkedAggregate(records, last, validated, chunkSize)
+	return reading, err
+}
+
+// chunkedAggregate aggregates each chunk of records into its own accumulator
+// and merges them in order, collecting warnings for records excluded as out
+// of window. The caller has checked that the config can be chunked.
+func chunkedAggregate(
+	records []specs.MeterRecordSpec,
+	last *specs.MeterRecordSpec,
+	config AggregationConfig,
+	chunkSize int,
+) (specs.MeterReadingSpec, []OutOfWindowWarning, error) {
+	total := newRecordAccumulator(config)
+	if err := total.addLastBefore(last); err != nil {
+		return specs.MeterReadingSpec{}, nil, err
+	}
+
+	index := 0
+	for start := 0; start < len(records); start += chunkSize {
+		chunk := records[start:min(start+chunkSize, len(records))]
+
+		acc := newRecordAccumulator(config)
+		for _, recordSpec := range chunk {
+			var err error
+			index, err = acc.addSpec(recordSpec, config, index)
+			if err != nil {
+				return specs.MeterReadingSpec{}, nil, err
+			}
+		}
+		if err := total.merge(acc); err != nil {
+			return specs.MeterReadingSpec{}, nil, err
+		}
+	}
+
+	reading, err := total.reading(config)
+	return reading, total.warnings, err
+}
+
+// checkChunkable returns error unless results of the aggregation over separate
+// chunks combine exactly into the result over all records.
+func checkChunkable(aggregation MeterReadingAggregation, groupBy []string) error {
+	if !aggregation.SupportsConcurrentEvaluation() {
+		return fmt.Errorf("chunked aggregation requires sum, max, or min; %s needs all records at once", aggregation.ToString())
+	}
+	if len(groupBy) > 0 {
+		return fmt.Errorf("chunked aggregation does not support group by")
+	}
+	return nil
+}
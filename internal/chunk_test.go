@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkedAggregate(t *testing.T) {
+	series := func(n int) []specs.MeterRecordSpec {
+		records := make([]specs.MeterRecordSpec, n)
+		for i := range records {
+			observedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(i) * time.Hour)
+			records[i] = newTestRecordSpec(fmt.Sprintf("event-%d", i), fmt.Sprintf("%d.5", i), "tokens", observedAt)
+		}
+		return records
+	}
+	assertSameReading := func(t *testing.T, expected, actual specs.MeterReadingSpec) {
+		t.Helper()
+		assert.Equal(t, expected.ID, actual.ID)
+		assert.Equal(t, expected.ComputedValues, actual.ComputedValues)
+		assert.Equal(t, expected.RecordCount, actual.RecordCount)
+		assert.Equal(t, expected.MaxMeteredAt, actual.MaxMeteredAt)
+	}
+
+	t.Run("exact chunk boundary", func(t *testing.T) {
+		records := series(9)
+		expected, err := Aggregate(records, nil, newTestAggregateConfig("sum"))
+		require.NoError(t, err)
+
+		reading, err := ChunkedAggregate(records, nil, newTestAggregateConfig("sum"), 3)
+
+		require.NoError(t, err)
+		assertSameReading(t, expected, reading)
+	})
+
+	t.Run("last chunk smaller than chunk size", func(t *testing.T) {
+		records := series(10)
+		expected, err := Aggregate(records, nil, newTestAggregateConfig("sum"))
+		require.NoError(t, err)
+
+		reading, err := ChunkedAggregate(records, nil, newTestAggregateConfig("sum"), 4)
+
+		require.NoError(t, err)
+		assertSameReading(t, expected, reading)
+		assert.Equal(t, "50.0", reading.ComputedValues[0].Quantity)
+	})
+
+	t.Run("max and min match unchunked results", func(t *testing.T) {
+		records := series(7)
+		for _, aggregation := range []string{"max", "min"} {
+			expected, err := Aggregate(records, nil, newTestAggregateConfig(aggregation))
+			require.NoError(t, err)
+
+			reading, err := ChunkedAggregate(records, nil, newTestAggregateConfig(aggregation), 2)
+
+			require.NoError(t, err)
+			assertSameReading(t, expected, reading)
+		}
+	})
+
+	t.Run("sum matches unchunked sum through config limit", func(t *testing.T) {
+		records := series(25)
+		expected, err := Aggregate(records, nil, newTestAggregateConfig("sum"))
+		require.NoError(t, err)
+		config := newTestAggregateConfig("sum")
+		config.MaxRecordsPerAggregate = 4
+		config.IncludeSourceIDs = true
+
+		reading, err := Aggregate(records, nil, config)
+
+		require.NoError(t, err)
+		assertSameReading(t, expected, reading)
+		assert.Len(t, reading.SourceRecordIDs, 25)
+	})
+
+	t.Run("mixed units across chunks return error", func(t *testing.T) {
+		records := append(series(3), newTestRecordSpec("event-requests", "1", "requests", time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)))
+
+		_, err := ChunkedAggregate(records, nil, newTestAggregateConfig("sum"), 2)
+
+		assert.ErrorIs(t, err, ErrMixedUnits)
+	})
+
+	t.Run("time-weighted-avg with chunking returns error", func(t *testing.T) {
+		_, err := ChunkedAggregate(series(4), nil, newTestAggregateConfig("time-weighted-avg"), 2)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "requires sum, max, or min")
+
+		config := newTestAggregateConfig("time-weighted-avg")
+		config.MaxRecordsPerAggregate = 2
+		_, err = Aggregate(series(4), nil, config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "requires sum, max, or min")
+	})
+
+	t.Run("rejects non-positive chunk size", func(t *testing.T) {
+		_, err := ChunkedAggregate(series(2), nil, newTestAggregateConfig("sum"), 0)
+
+		require.Error(t, err)
+	})
+}
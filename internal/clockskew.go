@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// ErrRecordOutOfWindow is returned in strict mode when a record is observed
+// outside the aggregation window by more than the clock skew tolerance.
+var ErrRecordOutOfWindow = errors.New("record outside aggregation window")
+
+// OutOfWindowWarning reports a record excluded from aggregation because it was
+// observed outside the window by more than the clock skew tolerance.
+type OutOfWindowWarning struct {
+	RecordID   string
+	ObservedAt time.Time
+	Window     specs.TimeWindowSpec
+}
+
+func (w OutOfWindowWarning) String() string {
+	return fmt.Sprintf("record %s observed at %s is outside window [%s, %s)",
+		w.RecordID, w.ObservedAt.Format(time.RFC3339Nano),
+		w.Window.Start.Format(time.RFC3339Nano), w.Window.End.Format(time.RFC3339Nano))
+}
+
+// AggregateWithWarnings is Aggregate, also returning a warning for each record
+// excluded for falling outside the window by more than the config's
+// ClockSkewTolerance. In StrictMode such records fail with an error wrapping
+// ErrRecordOutOfWindow instead.
+func AggregateWithWarnings(
+	recordsInWindowSpec []specs.MeterRecordSpec,
+	lastBeforeWindowSpec *specs.MeterRecordSpec,
+	configSpec specs.AggregateConfigSpec,
+) (specs.MeterReadingSpec, []OutOfWindowWarning, error) {
+	readings, warnings, err := aggregateGrouped(recordsInWindowSpec, lastBeforeWindowSpec, configSpec)
+	if err != nil && !(IsInsufficientDataError(err) && len(readings) == 1) {
+		return specs.MeterReadingSpec{}, nil, err
+	}
+	if len(readings) != 1 {
+		return specs.MeterReadingSpec{}, nil, fmt.Errorf("group by produced %d readings; use AggregateGrouped", len(readings))
+	}
+	return readings[0], warnings, err
+}
+
+// OutOfWindowRecord identifies a record observed outside a window.
+type OutOfWindowRecord struct {
+	RecordIndex int
+	RecordID    string
+	ObservedAt  time.Time
+}
+
+// ValidateRecordsInWindow returns the records not observed within window's
+// [Start, End), in input order, or nil if all are. Unlike aggregation, it
+// allows no clock skew tolerance. Only pass records meant to be in the window;
+// the last record before the window is outside it by definition.
+func ValidateRecordsInWindow(records []MeterRecord, window TimeWindow) []OutOfWindowRecord {
+	var outside []OutOfWindowRecord
+	for i, record := range records {
+		if _, ok := checkInWindow(record, window, 0); !ok {
+			outside = append(outside, OutOfWindowRecord{
+				RecordIndex: i,
+				RecordID:    record.ID.ToString(),
+				ObservedAt:  record.ObservedAt.ToTime(),
+			})
+		}
+	}
+	return outside
+}
+
+// excludeOutOfWindow returns the records observed within the window widened by
+// tolerance on both sides, and a warning for each record left out. In strict
+// mode the first such record is returned as an error instead.
+func excludeOutOfWindow(
+	records []MeterRecord,
+	window TimeWindow,
+	tolerance time.Duration,
+	strict bool,
+) ([]MeterRecord, []OutOfWindowWarning, error) {
+	var warnings []OutOfWindowWarning
+	kept := records[:0:0]
+	for _, record := range records {
+		warning, ok := checkInWindow(record, window, tolerance)
+		if ok {
+			kept = append(kept, record)
+			continue
+		}
+		if strict {
+			return nil, nil, fmt.Errorf("%w: %s", ErrRecordOutOfWindow, warning)
+		}
+		warnings = append(warnings, warning)
+	}
+	return kept, warnings, nil
+}
+
+// checkInWindow reports whether record was observed within the window widened
+// by tolerance on both sides, returning a warning describing it if not.
+func checkInWindow(record MeterRecord, window TimeWindow, tolerance time.Duration) (OutOfWindowWarning, bool) {
+	observedAt := record.ObservedAt.ToTime()
+	if !observedAt.Before(window.Start().ToTime().Add(-tolerance)) && observedAt.Before(window.End().ToTime().Add(tolerance)) {
+		return OutOfWindowWarning{}, true
+	}
+	return OutOfWindowWarning{
+		RecordID:   record.ID.ToString(),
+		ObservedAt: observedAt,
+		Window:     window.ToSpec(),
+	}, false
+}
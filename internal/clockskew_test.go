@@ -0,0 +1,159 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateWithWarnings_ClockSkew(t *testing.T) {
+	windowStart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := func(skewedAt time.Time) []specs.MeterRecordSpec {
+		return []specs.MeterRecordSpec{
+			newTestRecordSpec("event-skewed", "50", "tokens", skewedAt),
+			newTestRecordSpec("event-1", "100", "tokens", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+		}
+	}
+	configWithTolerance := func(tolerance time.Duration) specs.AggregateConfigSpec {
+		config := newTestAggregateConfig("sum")
+		config.ClockSkewTolerance = tolerance
+		return config
+	}
+
+	t.Run("record 1 second before window with 5 second tolerance is included", func(t *testing.T) {
+		reading, warnings, err := AggregateWithWarnings(records(windowStart.Add(-time.Second)), nil, configWithTolerance(5*time.Second))
+
+		require.NoError(t, err)
+		assert.Empty(t, warnings)
+		assert.Equal(t, "150", reading.ComputedValues[0].Quantity)
+		assert.Equal(t, 2, reading.RecordCount)
+	})
+
+	t.Run("record 10 seconds before window with 5 second tolerance is excluded with warning", func(t *testing.T) {
+		skewedAt := windowStart.Add(-10 * time.Second)
+
+		reading, warnings, err := AggregateWithWarnings(records(skewedAt), nil, configWithTolerance(5*time.Second))
+
+		require.NoError(t, err)
+		assert.Equal(t, "100", reading.ComputedValues[0].Quantity)
+		require.Len(t, warnings, 1)
+		assert.Equal(t, "event-skewed", warnings[0].RecordID)
+		assert.Equal(t, skewedAt, warnings[0].ObservedAt)
+	})
+
+	t.Run("tolerance also applies after window end", func(t *testing.T) {
+		windowEnd := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+		_, included, err := AggregateWithWarnings(records(windowEnd.Add(time.Second)), nil, configWithTolerance(5*time.Second))
+		require.NoError(t, err)
+		_, excluded, err := AggregateWithWarnings(records(windowEnd.Add(10*time.Second)), nil, configWithTolerance(5*time.Second))
+		require.NoError(t, err)
+
+		assert.Empty(t, included)
+		assert.Len(t, excluded, 1)
+	})
+
+	t.Run("strict mode turns warning into error", func(t *testing.T) {
+		config := configWithTolerance(5 * time.Second)
+		config.StrictMode = true
+
+		_, _, err := AggregateWithWarnings(records(windowStart.Add(-10*time.Second)), nil, config)
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrRecordOutOfWindow)
+		assert.Contains(t, err.Error(), "event-skewed")
+	})
+
+	t.Run("reader path excludes the same records", func(t *testing.T) {
+		input := records(windowStart.Add(-10 * time.Second))
+
+		fromReader, err := AggregateFromReader(SliceRecordReader(input), nil, configWithTolerance(5*time.Second))
+		require.NoError(t, err)
+		fromSlice, err := Aggregate(input, nil, configWithTolerance(5*time.Second))
+		require.NoError(t, err)
+
+		assert.Equal(t, fromSlice.ComputedValues, fromReader.ComputedValues)
+		assert.Equal(t, 1, fromReader.RecordCount)
+	})
+
+	t.Run("rejects negative tolerance", func(t *testing.T) {
+		_, err := NewAggregationConfig(configWithTolerance(-time.Second))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "clock skew tolerance")
+	})
+}
+
+func TestValidateRecordsInWindow(t *testing.T) {
+	window, err := NewTimeWindow(newTestAggregateConfig("sum").Window)
+	require.NoError(t, err)
+	record := func(id string, observedAt time.Time) MeterRecord {
+		record, err := NewMeterRecord(newTestRecordSpec(id, "1", "tokens", observedAt))
+		require.NoError(t, err)
+		return record
+	}
+
+	t.Run("all records in window passes", func(t *testing.T) {
+		records := []MeterRecord{
+			record("rec-1", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+			record("rec-2", time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)),
+		}
+
+		assert.Empty(t, ValidateRecordsInWindow(records, window))
+	})
+
+	t.Run("record at or after window end is reported", func(t *testing.T) {
+		windowEnd := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+		records := []MeterRecord{
+			record("rec-1", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+			record("rec-late", windowEnd),
+		}
+
+		outside := ValidateRecordsInWindow(records, window)
+
+		assert.Equal(t, []OutOfWindowRecord{{RecordIndex: 1, RecordID: "rec-late", ObservedAt: windowEnd}}, outside)
+	})
+}
+
+func TestAggregate_RecordAfterWindowEnd(t *testing.T) {
+	late := time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)
+	records := []specs.MeterRecordSpec{
+		newTestRecordSpec("rec-1", "100", "tokens", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+		newTestRecordSpec("rec-late", "50", "tokens", late),
+	}
+
+	t.Run("one record outside is excluded and reported to hooks", func(t *testing.T) {
+		var reported []OutOfWindowWarning
+		hooks := AggregationHooks{OnOutOfWindow: func(warnings []OutOfWindowWarning) { reported = warnings }}
+
+		reading, err := AggregateWithHooks(records, nil, newTestAggregateConfig("sum"), hooks)
+
+		require.NoError(t, err)
+		assert.Equal(t, "100", reading.ComputedValues[0].Quantity)
+		require.Len(t, reported, 1)
+		assert.Equal(t, "rec-late", reported[0].RecordID)
+	})
+
+	t.Run("strict window validation returns error", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.StrictWindowValidation = true
+
+		_, err := Aggregate(records, nil, config)
+
+		assert.ErrorIs(t, err, ErrRecordOutOfWindow)
+		assert.ErrorContains(t, err, "rec-late")
+	})
+
+	t.Run("last before window outside window is allowed", func(t *testing.T) {
+		config := newTestAggregateConfig("time-weighted-avg")
+		config.StrictWindowValidation = true
+		last := newTestRecordSpec("rec-dec", "10", "tokens", time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC))
+
+		_, err := Aggregate(records[:1], &last, config)
+
+		assert.NoError(t, err)
+	})
+}
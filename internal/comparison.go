@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// PeriodComparison compares a reading's value with the same meter's value in
+// an earlier period, for billing dashboards ("this month vs last month").
+// Quantities are decimal strings.
+type PeriodComparison struct {
+	Unit          string
+	CurrentValue  string
+	PreviousValue string
+
+	// CurrentValue minus PreviousValue.
+	AbsoluteChange string
+
+	// AbsoluteChange as a percentage of PreviousValue (e.g., "25" for 25%).
+	// Empty when PreviousValue is zero and CurrentValue is not, since the
+	// change is then undefined.
+	PercentageChange string
+
+	// "up", "down", or "flat".
+	Trend string
+
+	// Current window duration minus previous window duration. Non-zero means
+	// the periods differ in length (e.g., 31-day March vs 28-day February);
+	// see NormalizeForComparison.
+	WindowDelta time.Duration
+}
+
+// ComparePeriods compares the computed value of current with that of
+// previous.
+//
+// Returns error unless each reading has exactly one computed value and both
+// share a unit, or if a quantity is not a valid decimal.
+func ComparePeriods(current, previous specs.MeterReadingSpec) (PeriodComparison, error) {
+	if len(current.ComputedValues) != 1 {
+		return PeriodComparison{}, fmt.Errorf("current reading must have one computed value, got %d", len(current.ComputedValues))
+	}
+	if len(previous.ComputedValues) != 1 {
+		return PeriodComparison{}, fmt.Errorf("previous reading must have one computed value, got %d", len(previous.ComputedValues))
+	}
+	currentValue, previousValue := current.ComputedValues[0], previous.ComputedValues[0]
+	if currentValue.Unit != previousValue.Unit {
+		return PeriodComparison{}, fmt.Errorf("unit mismatch: current %q, previous %q", currentValue.Unit, previousValue.Unit)
+	}
+
+	currentQuantity, err := NewDecimal(currentValue.Quantity)
+	if err != nil {
+		return PeriodComparison{}, fmt.Errorf("invalid current quantity: %w", err)
+	}
+	previousQuantity, err := NewDecimal(previousValue.Quantity)
+	if err != nil {
+		return PeriodComparison{}, fmt.Errorf("invalid previous quantity: %w", err)
+	}
+
+	change := currentQuantity.Sub(previousQuantity)
+
+	var percentage string
+	switch {
+	case !previousQuantity.IsZero():
+		percentage = change.Mul(NewDecimalFromInt64(100)).Div(previousQuantity).Normalize().String()
+	case change.IsZero():
+		percentage = "0"
+	}
+
+	trend := "flat"
+	switch change.Cmp(NewDecimalFromInt64(0)) {
+	case 1:
+		trend = "up"
+	case -1:
+		trend = "down"
+	}
+
+	return PeriodComparison{
+		Unit:             currentValue.Unit,
+		CurrentValue:     currentValue.Quantity,
+		PreviousValue:    previousValue.Quantity,
+		AbsoluteChange:   change.String(),
+		PercentageChange: percentage,
+		Trend:            trend,
+		WindowDelta:      windowDuration(current.Window) - windowDuration(previous.Window),
+	}, nil
+}
+
+// prorate scales an accumulated quantity measured over actual to a window of
+// target at the same rate, by target/actual: 30 units in half an hour become
+// 60 for the hour. NormalizeForComparison and NormalizeWindowToCalendar both
+// use it, so a sum means the same thing after either.
+func prorate(quantity Decimal, target, actual time.Duration) Decimal {
+	return quantity.Mul(NewDecimalFromInt64(int64(target))).Div(NewDecimalFromInt64(int64(actual))).Normalize()
+}
+
+// NormalizeForComparison prorates a reading to a window of targetDuration, so
+// periods of different lengths compare fairly: a sum over a 28-day February
+// normalized to 31 days is scaled by 31/28.
+//
+// Only "sum" and "count" values accumulate with window length and are scaled;
+// the other aggregations (max, min, latest, time-weighted-avg, ...) describe a
+// level rather than a total and are returned unchanged. The reading's window is
+// not modified. Returns error if targetDuration or the reading's window is
+// not positive, or a quantity is not a valid decimal.
+func NormalizeForComparison(reading specs.MeterReadingSpec, targetDuration time.Duration) (specs.MeterReadingSpec, error) {
+	if targetDuration <= 0 {
+		return specs.MeterReadingSpec{}, fmt.Errorf("target duration must be positive, got %s", targetDuration)
+	}
+	duration := windowDuration(reading.Window)
+	if duration <= 0 {
+		return specs.MeterReadingSpec{}, fmt.Errorf("reading window must have a positive duration")
+	}
+
+	normalized := reading
+	normalized.ComputedValues = make([]specs.ComputedValueSpec, len(reading.ComputedValues))
+	for i, value := range reading.ComputedValues {
+		if isAdditiveAggregation(value.Aggregation) {
+			quantity, err := NewDecimal(value.Quantity)
+			if err != nil {
+				return specs.MeterReadingSpec{}, fmt.Errorf("invalid computed value %d quantity: %w", i, err)
+			}
+			value.Quantity = prorate(quantity, targetDuration, duration).String()
+		}
+		normalized.ComputedValues[i] = value
+	}
+	return normalized, nil
+}
+
+func windowDuration(window specs.TimeWindowSpec) time.Duration {
+	return window.End.Sub(window.Start)
+}
@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComparePeriods(t *testing.T) {
+	month := func(m time.Month) specs.TimeWindowSpec {
+		start := time.Date(2024, m, 1, 0, 0, 0, 0, time.UTC)
+		return specs.TimeWindowSpec{Start: start, End: start.AddDate(0, 1, 0)}
+	}
+	reading := func(m time.Month, quantity string) specs.MeterReadingSpec {
+		return specs.MeterReadingSpec{
+			Window:         month(m),
+			ComputedValues: []specs.ComputedValueSpec{{Quantity: quantity, Unit: "api-calls", Aggregation: "sum"}},
+		}
+	}
+
+	t.Run("same-length windows", func(t *testing.T) {
+		comparison, err := ComparePeriods(reading(time.March, "1250"), reading(time.January, "1000"))
+
+		require.NoError(t, err)
+		assert.Equal(t, PeriodComparison{
+			Unit:             "api-calls",
+			CurrentValue:     "1250",
+			PreviousValue:    "1000",
+			AbsoluteChange:   "250",
+			PercentageChange: "25",
+			Trend:            "up",
+			WindowDelta:      0,
+		}, comparison)
+	})
+
+	t.Run("decrease trends down", func(t *testing.T) {
+		comparison, err := ComparePeriods(reading(time.March, "750"), reading(time.January, "1000"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "-250", comparison.AbsoluteChange)
+		assert.Equal(t, "-25", comparison.PercentageChange)
+		assert.Equal(t, "down", comparison.Trend)
+	})
+
+	t.Run("different-length windows with normalization", func(t *testing.T) {
+		february, march := reading(time.February, "2900"), reading(time.March, "3100")
+
+		comparison, err := ComparePeriods(march, february)
+		require.NoError(t, err)
+		assert.Equal(t, 2*24*time.Hour, comparison.WindowDelta)
+
+		normalized, err := NormalizeForComparison(february, windowDuration(march.Window))
+		require.NoError(t, err)
+		assert.Equal(t, "3100", normalized.ComputedValues[0].Quantity)
+		assert.Equal(t, "2900", february.ComputedValues[0].Quantity, "input reading is not modified")
+
+		comparison, err = ComparePeriods(march, normalized)
+		require.NoError(t, err)
+		assert.Equal(t, "flat", comparison.Trend)
+		assert.Equal(t, "0", comparison.PercentageChange)
+	})
+
+	t.Run("normalization leaves non-sum values unchanged", func(t *testing.T) {
+		february := reading(time.February, "12")
+		february.ComputedValues[0].Aggregation = "max"
+
+		normalized, err := NormalizeForComparison(february, 31*24*time.Hour)
+
+		require.NoError(t, err)
+		assert.Equal(t, "12", normalized.ComputedValues[0].Quantity)
+	})
+
+	t.Run("normalization scales count values", func(t *testing.T) {
+		february := reading(time.February, "2900")
+		february.ComputedValues[0].Aggregation = "count"
+
+		normalized, err := NormalizeForComparison(february, 31*24*time.Hour)
+
+		require.NoError(t, err)
+		assert.Equal(t, "3100", normalized.ComputedValues[0].Quantity)
+	})
+
+	t.Run("zero previous value", func(t *testing.T) {
+		comparison, err := ComparePeriods(reading(time.March, "500"), reading(time.February, "0"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "500", comparison.AbsoluteChange)
+		assert.Empty(t, comparison.PercentageChange)
+		assert.Equal(t, "up", comparison.Trend)
+
+		comparison, err = ComparePeriods(reading(time.March, "0"), reading(time.February, "0"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "0", comparison.PercentageChange)
+		assert.Equal(t, "flat", comparison.Trend)
+	})
+
+	t.Run("with unit mismatch returns error", func(t *testing.T) {
+		previous := reading(time.February, "10")
+		previous.ComputedValues[0].Unit = "seats"
+
+		_, err := ComparePeriods(reading(time.March, "10"), previous)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unit mismatch")
+	})
+
+	t.Run("with non-positive target duration returns error", func(t *testing.T) {
+		_, err := NormalizeForComparison(reading(time.March, "10"), 0)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "target duration must be positive")
+	})
+}
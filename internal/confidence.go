@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// MinRecordsForConfidenceInterval is the fewest records AggregateWithCI will
+// bootstrap. Below this the resampled distribution is too coarse to be useful.
+const MinRecordsForConfidenceInterval = 30
+
+// DefaultConfidenceLevel is the confidence level AggregateWithCI reports.
+const DefaultConfidenceLevel = "0.95"
+
+// bootstrapSeed makes confidence intervals reproducible: re-aggregating the
+// same records always yields the same interval.
+const bootstrapSeed = 0x6d6574726f6e
+
+// ConfidenceInterval bounds an aggregated value at a confidence level.
+type ConfidenceInterval struct {
+	lower Decimal
+	upper Decimal
+	level Decimal
+}
+
+func NewConfidenceInterval(lower, upper, level Decimal) (ConfidenceInterval, error) {
+	if lower.Cmp(upper) > 0 {
+		return ConfidenceInterval{}, fmt.Errorf("confidence interval lower bound exceeds upper bound")
+	}
+	if level.Cmp(NewDecimalFromInt64(0)) <= 0 || level.Cmp(NewDecimalFromInt64(1)) >= 0 {
+		return ConfidenceInterval{}, fmt.Errorf("confidence level must be between 0 and 1")
+	}
+	return ConfidenceInterval{lower: lower, upper: upper, level: level}, nil
+}
+
+// ConfidenceIntervalFromSpec returns the reading's confidence interval, or nil
+// if it has none. Returns error if only some of the fields are set.
+func ConfidenceIntervalFromSpec(spec specs.MeterReadingSpec) (*ConfidenceInterval, error) {
+	if spec.ConfidenceIntervalLower == "" && spec.ConfidenceIntervalUpper == "" && spec.ConfidenceLevel == "" {
+		return nil, nil
+	}
+	if spec.ConfidenceIntervalLower == "" || spec.ConfidenceIntervalUpper == "" || spec.ConfidenceLevel == "" {
+		return nil, fmt.Errorf("confidence interval lower, upper, and level must be set together")
+	}
+
+	lower, err := NewDecimal(spec.ConfidenceIntervalLower)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lower bound: %w", err)
+	}
+	upper, err := NewDecimal(spec.ConfidenceIntervalUpper)
+	if err != nil {
+		return nil, fmt.Errorf("invalid upper bound: %w", err)
+	}
+	level, err := NewDecimal(spec.ConfidenceLevel)
+	if err != nil {
+		return nil, fmt.Errorf("invalid level: %w", err)
+	}
+
+	ci, err := NewConfidenceInterval(lower, upper, level)
+	if err != nil {
+		return nil, err
+	}
+	return &ci, nil
+}
+
+func (c ConfidenceInterval) Lower() Decimal {
+	return c.lower
+}
+
+func (c ConfidenceInterval) Upper() Decimal {
+	return c.upper
+}
+
+func (c ConfidenceInterval) Level() Decimal {
+	return c.level
+}
+
+// Contains reports whether value lies within the interval, inclusive.
+func (c ConfidenceInterval) Contains(value Decimal) bool {
+	return c.lower.Cmp(value) <= 0 && value.Cmp(c.upper) <= 0
+}
+
+// AggregateWithCI aggregates like Aggregate and, for aggregations that support
+// it, estimates a 95% confidence interval by bootstrap resampling.
+//
+// Only time-weighted-avg supports confidence intervals. Each record's value and
+// active duration in the window form a segment (uncovered time is a zero
+// segment); bootstrapSamples resamples of the segments, drawn with replacement,
+// give a distribution of duration-weighted means whose 2.5th and 97.5th
+// percentiles bound the interval. Resampling uses a fixed seed, so results are
+// reproducible.
+//
+// The interval is nil for other aggregations, when bootstrapSamples is not
+// positive, or when there are fewer than MinRecordsForConfidenceInterval
+// records; the last case also returns a warning.
+func (a MeterReadingAggregation) AggregateWithCI(
+	records []MeterRecord,
+	lastBefore *MeterRecord,
+	window TimeWindow,
+	bootstrapSamples int,
+) (ComputedValue, *ConfidenceInterval, []AggregationWarning, error) {
+	quantity, unit, _, err := a.Aggregate(records, lastBefore, window)
+	if err != nil {
+		return ComputedValue{}, nil, nil, err
+	}
+	value := NewComputedValue(quantity, unit, a)
+
+	if !a.IsTimeWeightedAvg() || bootstrapSamples <= 0 {
+		return value, nil, nil, nil
+	}
+
+	if len(records) < MinRecordsForConfidenceInterval {
+		warning := AggregationWarning{
+			Message: fmt.Sprintf("%d records is fewer than the %d needed for a confidence interval",
+				len(records), MinRecordsForConfidenceInterval),
+		}
+		return value, nil, []AggregationWarning{warning}, nil
+	}
+
+	ci, err := bootstrapTimeWeightedAvg(records, lastBefore, window, bootstrapSamples)
+	if err != nil {
+		return ComputedValue{}, nil, nil, fmt.Errorf("failed to compute confidence interval: %w", err)
+	}
+	return value, &ci, nil, nil
+}
+
+func bootstrapTimeWeightedAvg(
+	records []MeterRecord,
+	lastBefore *MeterRecord,
+	window TimeWindow,
+	samples int,
+) (ConfidenceInterval, error) {
+	sorted := make([]MeterRecord, 0, len(records)+1)
+	if lastBefore != nil {
+		sorted = append(sorted, *lastBefore)
+	}
+	sorted = append(sorted, records...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ObservedAt.ToTime().Before(sorted[j].ObservedAt.ToTime())
+	})
+
+	segments := timeWeightedSegments(sorted, window)
+
+	// Time with no active value counts as zero, matching timeWeightedAvgRecords
+	totalSeconds := window.End().ToTime().Sub(window.Start().ToTime()).Seconds()
+	uncovered, _ := NewDecimal(fmt.Sprintf("%.15f", totalSeconds))
+	for _, segment := range segments {
+		uncovered = uncovered.Sub(segment.duration)
+	}
+	if uncovered.Cmp(NewDecimalFromInt64(0)) > 0 {
+		segments = append(segments, timeWeightedSegment{quantity: NewDecimalFromInt64(0), duration: uncovered})
+	}
+	if len(segments) == 0 {
+		return ConfidenceInterval{}, fmt.Errorf("no time-weighted segments in window")
+	}
+
+	rng := rand.New(rand.NewPCG(bootstrapSeed, uint64(len(segments))))
+	means := make([]Decimal, samples)
+	for s := range means {
+		weightedSum := NewDecimalFromInt64(0)
+		totalDuration := NewDecimalFromInt64(0)
+		for range segments {
+			segment := segments[rng.IntN(len(segments))]
+			weightedSum = weightedSum.Add(segment.quantity.Mul(segment.duration))
+			totalDuration = totalDuration.Add(segment.duration)
+		}
+		if totalDuration.IsZero() {
+			means[s] = NewDecimalFromInt64(0)
+			continue
+		}
+		means[s] = weightedSum.Div(totalDuration)
+	}
+	sort.Slice(means, func(i, j int) bool { return means[i].Cmp(means[j]) < 0 })
+
+	lowerIndex := int(float64(samples-1) * 0.025)
+	upperIndex := int(float64(samples-1)*0.975 + 0.5)
+	level, _ := NewDecimal(DefaultConfidenceLevel)
+	return NewConfidenceInterval(means[lowerIndex], means[upperIndex], level)
+}
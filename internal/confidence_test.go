@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeterReadingAggregation_AggregateWithCI(t *testing.T) {
+	windowStart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	window, err := NewTimeWindow(specs.TimeWindowSpec{Start: windowStart, End: windowStart.AddDate(0, 0, 31)})
+	require.NoError(t, err)
+	twa, err := NewMeterReadingAggregation("time-weighted-avg")
+	require.NoError(t, err)
+
+	// newDailyRecords creates one seat-count record per day, cycling through
+	// a few values so the bootstrap distribution has spread.
+	newDailyRecords := func(t *testing.T, days int) []MeterRecord {
+		t.Helper()
+		values := []int{40, 42, 45, 41, 48, 39, 44}
+		records := make([]MeterRecord, days)
+		for i := range records {
+			spec := newTestRecordSpec(fmt.Sprintf("rec-%d", i), fmt.Sprint(values[i%len(values)]), "seats", windowStart.AddDate(0, 0, i))
+			record, err := NewMeterRecord(spec)
+			require.NoError(t, err)
+			records[i] = record
+		}
+		return records
+	}
+
+	t.Run("30 or more records produce a confidence interval", func(t *testing.T) {
+		value, ci, warnings, err := twa.AggregateWithCI(newDailyRecords(t, 31), nil, window, 500)
+
+		require.NoError(t, err)
+		require.NotNil(t, ci)
+		assert.Empty(t, warnings)
+		assert.Equal(t, "0.95", ci.Level().String())
+		assert.True(t, ci.Lower().Cmp(ci.Upper()) < 0)
+		assert.True(t, ci.Contains(value.Quantity()), "CI [%s, %s] should contain %s", ci.Lower(), ci.Upper(), value.Quantity())
+	})
+
+	t.Run("interval is reproducible", func(t *testing.T) {
+		records := newDailyRecords(t, 31)
+
+		_, first, _, err := twa.AggregateWithCI(records, nil, window, 200)
+		require.NoError(t, err)
+		_, second, _, err := twa.AggregateWithCI(records, nil, window, 200)
+		require.NoError(t, err)
+
+		assert.Equal(t, first.Lower().String(), second.Lower().String())
+		assert.Equal(t, first.Upper().String(), second.Upper().String())
+	})
+
+	t.Run("fewer than 30 records returns nil CI with warning", func(t *testing.T) {
+		value, ci, warnings, err := twa.AggregateWithCI(newDailyRecords(t, 10), nil, window, 500)
+
+		require.NoError(t, err)
+		assert.Nil(t, ci)
+		require.Len(t, warnings, 1)
+		assert.Contains(t, warnings[0].Message, "confidence interval")
+		assert.False(t, value.Quantity().IsZero())
+	})
+
+	t.Run("other aggregations return nil CI", func(t *testing.T) {
+		sum, err := NewMeterReadingAggregation("sum")
+		require.NoError(t, err)
+
+		value, ci, warnings, err := sum.AggregateWithCI(newDailyRecords(t, 31), nil, window, 500)
+
+		require.NoError(t, err)
+		assert.Nil(t, ci)
+		assert.Empty(t, warnings)
+		assert.Equal(t, "sum", value.Aggregation().ToString())
+	})
+}
+
+func TestConfidenceIntervalFromSpec(t *testing.T) {
+	t.Run("no fields returns nil", func(t *testing.T) {
+		ci, err := ConfidenceIntervalFromSpec(specs.MeterReadingSpec{})
+
+		require.NoError(t, err)
+		assert.Nil(t, ci)
+	})
+
+	t.Run("parses all fields", func(t *testing.T) {
+		ci, err := ConfidenceIntervalFromSpec(specs.MeterReadingSpec{
+			ConfidenceIntervalLower: "41.5",
+			ConfidenceIntervalUpper: "43.25",
+			ConfidenceLevel:         "0.95",
+		})
+
+		require.NoError(t, err)
+		require.NotNil(t, ci)
+		assert.Equal(t, "41.5", ci.Lower().String())
+		assert.Equal(t, "43.25", ci.Upper().String())
+	})
+
+	t.Run("partial fields return error", func(t *testing.T) {
+		_, err := ConfidenceIntervalFromSpec(specs.MeterReadingSpec{ConfidenceIntervalLower: "41.5"})
+
+		assert.ErrorContains(t, err, "set together")
+	})
+
+	t.Run("lower above upper returns error", func(t *testing.T) {
+		_, err := ConfidenceIntervalFromSpec(specs.MeterReadingSpec{
+			ConfidenceIntervalLower: "50",
+			ConfidenceIntervalUpper: "40",
+			ConfidenceLevel:         "0.95",
+		})
+
+		assert.ErrorContains(t, err, "exceeds upper")
+	})
+}
@@ -2,6 +2,11 @@ package internal
 
 import (
 	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/cockroachdb/apd/v3"
 )
@@ -14,7 +19,7 @@ func NewDecimal(s string) (Decimal, error) {
 	var d apd.Decimal
 	_, _, err := d.SetString(s)
 	if err != nil {
-		return Decimal{}, fmt.Errorf("invalid decimal: %w", err)
+		return Decimal{}, fmt.Errorf("%w: %w", ErrInvalidDecimal, err)
 	}
 	return Decimal{value: d}, nil
 }
@@ -25,6 +30,26 @@ func NewDecimalFromInt64(i int64) Decimal {
 	return Decimal{value: d}
 }
 
+// NewDecimalFromFloat64 converts f to a Decimal with precision digits after the
+// decimal point, rounding as fmt does.
+//
+// Prefer NewDecimal with a decimal string. float64 cannot represent most
+// decimal fractions exactly, so billing quantities should not pass through it;
+// this constructor exists for interop with libraries that only produce float64
+// metrics. Formatting at a fixed precision avoids exposing binary rounding
+// artifacts (0.1 becomes "0.10" at precision 2, not 0.1000000000000000055...).
+//
+// Returns error if f is NaN or infinite, or precision is negative.
+func NewDecimalFromFloat64(f float64, precision int32) (Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}, fmt.Errorf("%w: %v is not a finite number", ErrInvalidDecimal, f)
+	}
+	if precision < 0 {
+		return Decimal{}, fmt.Errorf("%w: precision cannot be negative", ErrInvalidDecimal)
+	}
+	return NewDecimal(fmt.Sprintf("%.*f", precision, f))
+}
+
 func (d Decimal) String() string {
 	return d.value.String()
 }
@@ -37,26 +62,212 @@ func (d Decimal) Cmp(other Decimal) int {
 	return d.value.Cmp(&other.value)
 }
 
+// decimalPool holds scratch values for arithmetic, so high-throughput
+// aggregation reuses their coefficient buffers instead of growing new ones.
+var decimalPool = sync.Pool{
+	New: func() any { return new(apd.Decimal) },
+}
+
+// fromScratch returns a Decimal holding a copy of scratch and returns
+// scratch to decimalPool. The copy must not share the scratch value's
+// coefficient storage, since the pool hands it to the next caller.
+func fromScratch(scratch *apd.Decimal) Decimal {
+	var result apd.Decimal
+	result.Set(scratch)
+	decimalPool.Put(scratch)
+	return Decimal{value: result}
+}
+
 // Add returns the sum of d and other.
 func (d Decimal) Add(other Decimal) Decimal {
-	var result apd.Decimal
+	scratch := decimalPool.Get().(*apd.Decimal)
 	ctx := apd.BaseContext.WithPrecision(34)
-	ctx.Add(&result, &d.value, &other.value)
-	return Decimal{value: result}
+	ctx.Add(scratch, &d.value, &other.value)
+	return fromScratch(scratch)
+}
+
+// Sub returns the difference of d and other.
+func (d Decimal) Sub(other Decimal) Decimal {
+	scratch := decimalPool.Get().(*apd.Decimal)
+	ctx := apd.BaseContext.WithPrecision(34)
+	ctx.Sub(scratch, &d.value, &other.value)
+	return fromScratch(scratch)
 }
 
 // Mul returns the product of d and other.
 func (d Decimal) Mul(other Decimal) Decimal {
-	var result apd.Decimal
+	scratch := decimalPool.Get().(*apd.Decimal)
 	ctx := apd.BaseContext.WithPrecision(34)
-	ctx.Mul(&result, &d.value, &other.value)
-	return Decimal{value: result}
+	ctx.Mul(scratch, &d.value, &other.value)
+	return fromScratch(scratch)
 }
 
 // Div returns the quotient of d divided by other.
 func (d Decimal) Div(other Decimal) Decimal {
-	var result apd.Decimal
+	scratch := decimalPool.Get().(*apd.Decimal)
 	ctx := apd.BaseContext.WithPrecision(34)
-	ctx.Quo(&result, &d.value, &other.value)
-	return Decimal{value: result}
+	ctx.Quo(scratch, &d.value, &other.value)
+	return fromScratch(scratch)
+}
+
+// Normalize returns d without trailing fractional zeros, so "25.00" becomes
+// "25" and "2.50" becomes "2.5". Integer digits are kept ("100" stays "100"
+// rather than "1E+2"). The value is unchanged.
+func (d Decimal) Normalize() Decimal {
+	if d.value.Exponent >= 0 {
+		return d
+	}
+	var result Decimal
+	result.value.Reduce(&d.value)
+	if result.value.Exponent > 0 {
+		ctx := apd.BaseContext.WithPrecision(34)
+		ctx.Quantize(&result.value, &result.value, 0)
+	}
+	return result
+}
+
+// RoundToInt64 rounds d half-up to the nearest integer and returns it as an int64.
+// Returns error if the result does not fit in an int64.
+func (d Decimal) RoundToInt64() (int64, error) {
+	var rounded apd.Decimal
+	ctx := apd.BaseContext.WithPrecision(34)
+	ctx.Rounding = apd.RoundHalfUp
+	if _, err := ctx.RoundToIntegralValue(&rounded, &d.value); err != nil {
+		return 0, fmt.Errorf("failed to round decimal: %w", err)
+	}
+	return rounded.Int64()
+}
+
+// ToFloat64 converts d to the nearest float64.
+//
+// Precision may be lost: float64 holds about 15-17 significant digits and
+// cannot represent most decimal fractions exactly. Use only at boundaries with
+// float-based libraries, never for billing arithmetic. Returns error if d is
+// out of float64 range.
+func (d Decimal) ToFloat64() (float64, error) {
+	f, err := d.value.Float64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert decimal to float64: %w", err)
+	}
+	return f, nil
+}
+
+// FormatOptions controls how FormatWith renders a Decimal for display, such as
+// amounts on billing statements.
+type FormatOptions struct {
+	// Groups integer digits in threes, e.g. ',' for "1,250". Zero means no
+	// grouping.
+	ThousandsSep rune
+
+	// Separates the integer and fractional digits. Zero means '.'.
+	DecimalSep rune
+
+	// Currency symbol, e.g. "$" or "€". Empty means none.
+	Symbol string
+
+	// Where Symbol goes: "before" ("$5") or "after" ("5 kr" with Symbol
+	// " kr"). Empty means "before". A minus sign always comes first.
+	SymbolPosition string
+
+	// Digits after the decimal separator; the value is rounded half-up.
+	// Negative means zero.
+	Precision int32
+}
+
+// FormatWith renders d for display as opts describes, e.g. "$1,250.50" or
+// "€1.250,50". Only for display: the result is not parseable by NewDecimal.
+func (d Decimal) FormatWith(opts FormatOptions) string {
+	precision := max(opts.Precision, 0)
+	number := d.formatNumber(opts.ThousandsSep, opts.DecimalSep, &precision)
+
+	sign := ""
+	if unsigned, ok := strings.CutPrefix(number, "-"); ok {
+		sign, number = "-", unsigned
+	}
+	if opts.SymbolPosition == "after" {
+		return sign + number + opts.Symbol
+	}
+	return sign + opts.Symbol + number
+}
+
+// formatPlaceholder matches the {value} placeholders Format replaces.
+var formatPlaceholder = regexp.MustCompile(`\{value(?::([^0-9{}]{0,2})([0-9]*))?\}`)
+
+// Format renders d into template, replacing each value placeholder:
+//   - {value}: d as a plain decimal, e.g. "1250.5"
+//   - {value:2}: rounded half-up to 2 decimal places, e.g. "1250.50"
+//   - {value:,}: with ',' grouping thousands, e.g. "1,250.5"
+//   - {value:.,2}: '.' grouping thousands, ',' before 2 decimal places, e.g.
+//     "1.250,50"
+//
+// After the colon, one character sets the thousands separator, a second sets
+// the decimal separator, and trailing digits set the precision. {symbol} and
+// any other text are left as is, so a template such as "{symbol}{value:,2}"
+// can be shared across currencies with the symbol filled in by the caller.
+func (d Decimal) Format(template string) string {
+	return formatPlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		match := formatPlaceholder.FindStringSubmatch(placeholder)
+		separators := []rune(match[1])
+		var thousands, decimal rune
+		if len(separators) > 0 {
+			thousands = separators[0]
+		}
+		if len(separators) > 1 {
+			decimal = separators[1]
+		}
+		if match[2] == "" {
+			return d.formatNumber(thousands, decimal, nil)
+		}
+		precision, err := strconv.ParseInt(match[2], 10, 32)
+		if err != nil {
+			return placeholder
+		}
+		p := int32(precision)
+		return d.formatNumber(thousands, decimal, &p)
+	})
+}
+
+// formatNumber renders d in plain notation with the given separators. A nil
+// precision keeps d's own fractional digits; otherwise d is rounded half-up to
+// that many. A zero decimal separator means '.'.
+func (d Decimal) formatNumber(thousands, decimal rune, precision *int32) string {
+	value := d.value
+	if precision != nil {
+		// Quantize fails if the result needs more digits than the context
+		// allows, so size it to the integer digits plus the fraction, with
+		// one to spare for a rounding carry.
+		integerDigits := max(d.value.NumDigits()+int64(d.value.Exponent), 1)
+		ctx := apd.BaseContext.WithPrecision(uint32(integerDigits + int64(*precision) + 1))
+		ctx.Rounding = apd.RoundHalfUp
+		if _, err := ctx.Quantize(&value, &d.value, -*precision); err != nil {
+			// Only reachable beyond apd's exponent limits; show d unrounded
+			// rather than NaN.
+			value = d.value
+		}
+	}
+	text := value.Text('f')
+
+	sign := ""
+	if value.Negative && !value.IsZero() {
+		sign = "-"
+	}
+	text = strings.TrimPrefix(text, "-")
+	integer, fraction, _ := strings.Cut(text, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range integer {
+		if thousands != 0 && i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteRune(thousands)
+		}
+		b.WriteRune(digit)
+	}
+	if fraction != "" {
+		if decimal == 0 {
+			decimal = '.'
+		}
+		b.WriteRune(decimal)
+		b.WriteString(fraction)
+	}
+	return b.String()
 }
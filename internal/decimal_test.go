@@ -0,0 +1,163 @@
+package internal
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDecimalFromFloat64(t *testing.T) {
+	t.Run("formats at the given precision", func(t *testing.T) {
+		d, err := NewDecimalFromFloat64(1.5, 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, "1.50", d.String())
+	})
+
+	t.Run("avoids binary rounding artifacts", func(t *testing.T) {
+		d, err := NewDecimalFromFloat64(0.1, 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, "0.10", d.String())
+	})
+
+	t.Run("very large float", func(t *testing.T) {
+		d, err := NewDecimalFromFloat64(1e20, 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, "100000000000000000000", d.String())
+	})
+
+	t.Run("NaN returns error", func(t *testing.T) {
+		_, err := NewDecimalFromFloat64(math.NaN(), 2)
+
+		assert.ErrorContains(t, err, "not a finite number")
+	})
+
+	t.Run("Inf returns error", func(t *testing.T) {
+		_, err := NewDecimalFromFloat64(math.Inf(1), 2)
+		assert.Error(t, err)
+
+		_, err = NewDecimalFromFloat64(math.Inf(-1), 2)
+		assert.Error(t, err)
+	})
+
+	t.Run("negative precision returns error", func(t *testing.T) {
+		_, err := NewDecimalFromFloat64(1.5, -1)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestDecimal_ToFloat64(t *testing.T) {
+	d, err := NewDecimal("1234.5")
+	require.NoError(t, err)
+
+	f, err := d.ToFloat64()
+
+	require.NoError(t, err)
+	assert.Equal(t, 1234.5, f)
+}
+
+func TestDecimal_Normalize(t *testing.T) {
+	for input, expected := range map[string]string{
+		"25.00":   "25",
+		"2.50":    "2.5",
+		"100":     "100",
+		"100.000": "100",
+		"0.000":   "0",
+		"-3.10":   "-3.1",
+	} {
+		d, err := NewDecimal(input)
+		require.NoError(t, err)
+
+		assert.Equal(t, expected, d.Normalize().String(), input)
+		assert.Equal(t, 0, d.Normalize().Cmp(d), input)
+	}
+}
+
+func TestDecimal_ConcurrentArithmetic(t *testing.T) {
+	// Pooled scratch values are shared across goroutines; results must not be
+	// affected by other goroutines reusing them.
+	large, err := NewDecimal("123456789012345678901234567890.5")
+	require.NoError(t, err)
+	two := NewDecimalFromInt64(2)
+
+	var wg sync.WaitGroup
+	results := make([]Decimal, 50)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			n := NewDecimalFromInt64(int64(i))
+			result := NewDecimalFromInt64(0)
+			for j := 0; j < 100; j++ {
+				result = result.Add(large.Mul(n)).Sub(large.Mul(n)).Add(n.Mul(two).Div(two))
+			}
+			results[i] = result
+		}(i)
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		assert.Zero(t, result.Cmp(NewDecimalFromInt64(int64(i*100))), "goroutine %d: got %s", i, result)
+	}
+}
+
+func TestDecimal_FormatWith(t *testing.T) {
+	usd := FormatOptions{ThousandsSep: ',', DecimalSep: '.', Symbol: "$", Precision: 2}
+	eur := FormatOptions{ThousandsSep: '.', DecimalSep: ',', Symbol: "€", Precision: 2}
+	jpy := FormatOptions{ThousandsSep: ',', Symbol: "¥"}
+	sek := FormatOptions{ThousandsSep: ' ', DecimalSep: ',', Symbol: " kr", SymbolPosition: "after", Precision: 2}
+
+	tests := []struct {
+		name     string
+		value    string
+		opts     FormatOptions
+		expected string
+	}{
+		{"USD", "1250.5", usd, "$1,250.50"},
+		{"USD rounds half-up", "1250.505", usd, "$1,250.51"},
+		{"EUR", "1250.5", eur, "€1.250,50"},
+		{"JPY has no decimal places", "125050.4", jpy, "¥125,050"},
+		{"symbol after value", "1250.5", sek, "1 250,50 kr"},
+		{"negative value puts sign before symbol", "-1250.5", usd, "-$1,250.50"},
+		{"negative rounding to zero drops sign", "-0.001", usd, "$0.00"},
+		{"zero value", "0", usd, "$0.00"},
+		{"millions", "1234567.891", usd, "$1,234,567.89"},
+		{"more digits than arithmetic precision", "1e40", usd, "$10,000,000,000,000,000,000,000,000,000,000,000,000,000.00"},
+		{"rounding carries into a new digit", "99999999999999999999999999999999999.999", usd, "$100,000,000,000,000,000,000,000,000,000,000,000.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := NewDecimal(tt.value)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, d.FormatWith(tt.opts))
+		})
+	}
+}
+
+func TestDecimal_Format(t *testing.T) {
+	d, err := NewDecimal("1250.5")
+	require.NoError(t, err)
+
+	assert.Equal(t, "1250.5", d.Format("{value}"))
+	assert.Equal(t, "1250.50", d.Format("{value:2}"))
+	assert.Equal(t, "1,250.5", d.Format("{value:,}"))
+	assert.Equal(t, "$1,250.50 USD", d.Format("${value:,2} USD"))
+	assert.Equal(t, "€1.250,50", d.Format("€{value:.,2}"))
+	assert.Equal(t, "{symbol}1250.50", d.Format("{symbol}{value:2}"))
+	assert.Equal(t, "{value:x2y}", d.Format("{value:x2y}"))
+
+	t.Run("large values are not NaN", func(t *testing.T) {
+		large, err := NewDecimal("1e40")
+		require.NoError(t, err)
+
+		assert.Equal(t, "1"+strings.Repeat("0", 40)+"."+strings.Repeat("0", 40), large.Format("{value:40}"))
+	})
+}
@@ -0,0 +1,206 @@
+package internal
+
+import (
+	"container/list"
+	"sort"
+	"sync"
+	"time"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// DeduplicateByContentHash removes records whose content hash matches an
+// earlier record, keeping the first occurrence and preserving order.
+//
+// Records without a ContentHash have it computed. Records that cannot be
+// hashed because they are invalid are kept, since their content cannot be
+// compared.
+func DeduplicateByContentHash(records []specs.MeterRecordSpec) []specs.MeterRecordSpec {
+	seen := make(map[string]bool, len(records))
+	result := make([]specs.MeterRecordSpec, 0, len(records))
+
+	for _, spec := range records {
+		hash := spec.ContentHash
+		if hash == "" {
+			record, err := NewMeterRecord(spec)
+			if err != nil {
+				result = append(result, spec)
+				continue
+			}
+			hash = record.ContentHash()
+		}
+
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		result = append(result, spec)
+	}
+
+	return result
+}
+
+// DeduplicateObservations returns the record without observations that repeat
+// an earlier observation's quantity, unit, and window, keeping the first
+// occurrence and preserving order. Quantities are compared as written, so "1"
+// and "1.0" are distinct. The input record is not modified. ContentHash is
+// cleared if an observation is removed, since it no longer matches.
+func DeduplicateObservations(record specs.MeterRecordSpec) specs.MeterRecordSpec {
+	if len(record.Observations) == 0 {
+		return record
+	}
+	seen := make(map[observationKey]bool, len(record.Observations))
+	observations := make([]specs.ObservationSpec, 0, len(record.Observations))
+	for _, o := range record.Observations {
+		key := newObservationKey(o.Quantity, o.Unit, o.Window.Start, o.Window.End)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		observations = append(observations, o)
+	}
+	if len(observations) < len(record.Observations) {
+		record.ContentHash = ""
+	}
+	record.Observations = observations
+	return record
+}
+
+// observationKey identifies an observation by its content.
+type observationKey struct {
+	quantity string
+	unit     string
+	start    int64
+	end      int64
+}
+
+func newObservationKey(quantity, unit string, start, end time.Time) observationKey {
+	return observationKey{quantity: quantity, unit: unit, start: start.UnixNano(), end: end.UnixNano()}
+}
+
+// DeduplicateBatch splits payloads into unique events and duplicates, where a
+// duplicate repeats the ID of an earlier event (by Time) within window, such
+// as a retry. The first occurrence by Time is kept; ties keep input order.
+// An ID repeated after the window has passed is kept as a new event, and
+// later repeats are measured from it. A non-positive window treats every
+// repeat of an ID as a duplicate.
+//
+// Both slices preserve input order.
+func DeduplicateBatch(payloads []specs.EventPayloadSpec, window time.Duration) (unique, duplicates []specs.EventPayloadSpec) {
+	order := make([]int, len(payloads))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return payloads[order[i]].Time.Before(payloads[order[j]].Time)
+	})
+
+	cache := NewBoundedDeduplicationCache(window, 0)
+	duplicate := make([]bool, len(payloads))
+	for _, i := range order {
+		duplicate[i] = cache.Seen(payloads[i].ID, payloads[i].Time)
+	}
+
+	unique = make([]specs.EventPayloadSpec, 0, len(payloads))
+	duplicates = make([]specs.EventPayloadSpec, 0)
+	for i, payload := range payloads {
+		if duplicate[i] {
+			duplicates = append(duplicates, payload)
+		} else {
+			unique = append(unique, payload)
+		}
+	}
+	return unique, duplicates
+}
+
+// BoundedDeduplicationCache remembers event IDs seen within a sliding time
+// window, for deduplicating a stream without keeping every ID forever.
+//
+// Entries are kept in a list from oldest to newest alongside a map from ID to
+// list element, so lookups, inserts, and evictions are O(1). Entries expire
+// once they are more than window older than the newest event seen, and the
+// oldest entries are evicted when the cache is over capacity. Safe for
+// concurrent use.
+type BoundedDeduplicationCache struct {
+	mu       sync.Mutex
+	window   time.Duration
+	capacity int
+	entries  *list.List
+	byID     map[string]*list.Element
+	latest   time.Time
+}
+
+// dedupEntry is the first time an ID was seen in the current window.
+type dedupEntry struct {
+	id     string
+	seenAt time.Time
+}
+
+// NewBoundedDeduplicationCache returns an empty cache. A non-positive window
+// means entries never expire; a non-positive capacity means no size limit.
+func NewBoundedDeduplicationCache(window time.Duration, capacity int) *BoundedDeduplicationCache {
+	return &BoundedDeduplicationCache{
+		window:   window,
+		capacity: capacity,
+		entries:  list.New(),
+		byID:     make(map[string]*list.Element),
+	}
+}
+
+// Seen reports whether id was already seen within the window before at, and
+// records it if not. An ID whose earlier sighting is more than window before
+// at counts as new and restarts its window.
+//
+// Events should arrive in roughly chronological order: expiry is measured
+// from the newest time seen so far.
+func (c *BoundedDeduplicationCache) Seen(id string, at time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if at.After(c.latest) {
+		c.latest = at
+	}
+	c.expire()
+
+	if element, ok := c.byID[id]; ok {
+		entry := element.Value.(*dedupEntry)
+		if c.window <= 0 || at.Sub(entry.seenAt) <= c.window {
+			return true
+		}
+		entry.seenAt = at
+		c.entries.MoveToBack(element)
+		return false
+	}
+
+	c.byID[id] = c.entries.PushBack(&dedupEntry{id: id, seenAt: at})
+	if c.capacity > 0 && c.entries.Len() > c.capacity {
+		c.remove(c.entries.Front())
+	}
+	return false
+}
+
+// Len returns the number of IDs currently remembered.
+func (c *BoundedDeduplicationCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries.Len()
+}
+
+// expire removes entries more than window older than the newest event seen.
+func (c *BoundedDeduplicationCache) expire() {
+	if c.window <= 0 {
+		return
+	}
+	cutoff := c.latest.Add(-c.window)
+	for front := c.entries.Front(); front != nil; front = c.entries.Front() {
+		if !front.Value.(*dedupEntry).seenAt.Before(cutoff) {
+			return
+		}
+		c.remove(front)
+	}
+}
+
+func (c *BoundedDeduplicationCache) remove(element *list.Element) {
+	c.entries.Remove(element)
+	delete(c.byID, element.Value.(*dedupEntry).id)
+}
@@ -0,0 +1,270 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeduplicateByContentHash(t *testing.T) {
+	t.Run("keeps first occurrence of duplicate content", func(t *testing.T) {
+		config := specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{{SourceProperty: "tokens", Unit: "tokens"}},
+		}
+		eventTime := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+
+		var records []specs.MeterRecordSpec
+		for _, payload := range []struct{ id, tokens string }{
+			{"event-1", "100"},
+			{"event-2", "100"}, // Same content as event-1
+			{"event-3", "200"},
+		} {
+			recs, err := Meter(specs.EventPayloadSpec{
+				ID:          payload.id,
+				WorkspaceID: "workspace-test",
+				UniverseID:  "universe-test",
+				Type:        "llm.completion",
+				Subject:     "customer:test",
+				Time:        eventTime,
+				Properties:  map[string]string{"tokens": payload.tokens},
+			}, config)
+			require.NoError(t, err)
+			require.Len(t, recs, 1)
+			assert.NotEmpty(t, recs[0].ContentHash, "Meter should populate content hash")
+			records = append(records, recs...)
+		}
+
+		deduplicated := DeduplicateByContentHash(records)
+
+		require.Len(t, deduplicated, 2)
+		assert.Equal(t, "event-1", deduplicated[0].ID)
+		assert.Equal(t, "event-3", deduplicated[1].ID)
+	})
+
+	t.Run("computes missing content hash", func(t *testing.T) {
+		observedAt := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+		records := []specs.MeterRecordSpec{
+			newTestRecordSpec("event-1", "100", "tokens", observedAt),
+			newTestRecordSpec("event-2", "100", "tokens", observedAt),
+		}
+
+		deduplicated := DeduplicateByContentHash(records)
+
+		require.Len(t, deduplicated, 1)
+		assert.Equal(t, "event-1", deduplicated[0].ID)
+	})
+
+	withHash := func(t *testing.T, spec specs.MeterRecordSpec) specs.MeterRecordSpec {
+		t.Helper()
+		record, err := NewMeterRecord(spec)
+		require.NoError(t, err)
+		spec.ContentHash = record.ContentHash()
+		return spec
+	}
+
+	t.Run("sampled records do not hash like unsampled ones", func(t *testing.T) {
+		observedAt := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+		sampled := newTestRecordSpec("event-1", "100", "tokens", observedAt)
+		sampled.SampleRate = "0.01"
+
+		deduplicated := DeduplicateByContentHash([]specs.MeterRecordSpec{
+			withHash(t, sampled),
+			withHash(t, newTestRecordSpec("event-2", "100", "tokens", observedAt)),
+		})
+
+		assert.Len(t, deduplicated, 2)
+	})
+
+	t.Run("hash follows quantities rewritten by sampling normalization", func(t *testing.T) {
+		observedAt := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+		sampled := newTestRecordSpec("event-1", "1", "tokens", observedAt)
+		sampled.SampleRate = "0.5"
+		sampled = withHash(t, sampled)
+		sampledOriginalHash := sampled.ContentHash
+		unsampled := withHash(t, newTestRecordSpec("event-2", "1", "tokens", observedAt))
+
+		normalized, err := NormalizeForSampling([]specs.MeterRecordSpec{sampled})
+		require.NoError(t, err)
+		deduplicated := DeduplicateByContentHash(append(normalized, unsampled))
+
+		assert.NotEqual(t, sampledOriginalHash, normalized[0].ContentHash)
+		assert.Equal(t, withHash(t, normalized[0]).ContentHash, normalized[0].ContentHash)
+		assert.Len(t, deduplicated, 2)
+	})
+}
+
+func TestDeduplicateBatch(t *testing.T) {
+	base := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	event := func(id string, offset time.Duration) specs.EventPayloadSpec {
+		return specs.EventPayloadSpec{ID: id, Time: base.Add(offset)}
+	}
+	ids := func(payloads []specs.EventPayloadSpec) []string {
+		result := make([]string, len(payloads))
+		for i, p := range payloads {
+			result[i] = p.ID
+		}
+		return result
+	}
+
+	t.Run("no duplicates passes through", func(t *testing.T) {
+		payloads := []specs.EventPayloadSpec{event("a", 0), event("b", time.Minute), event("c", 2*time.Minute)}
+
+		unique, duplicates := DeduplicateBatch(payloads, 5*time.Minute)
+
+		assert.Equal(t, payloads, unique)
+		assert.Empty(t, duplicates)
+	})
+
+	t.Run("exact duplicate removed keeping earliest by time", func(t *testing.T) {
+		retry := event("a", 2*time.Minute)
+		original := event("a", 0)
+		payloads := []specs.EventPayloadSpec{retry, event("b", time.Minute), original}
+
+		unique, duplicates := DeduplicateBatch(payloads, 5*time.Minute)
+
+		assert.Equal(t, []specs.EventPayloadSpec{payloads[1], original}, unique)
+		assert.Equal(t, []specs.EventPayloadSpec{retry}, duplicates)
+	})
+
+	t.Run("IDs colliding across window boundary are kept", func(t *testing.T) {
+		payloads := []specs.EventPayloadSpec{
+			event("a", 0),
+			event("a", 4*time.Minute),
+			event("a", 6*time.Minute),
+			event("a", 10*time.Minute),
+		}
+
+		unique, duplicates := DeduplicateBatch(payloads, 5*time.Minute)
+
+		assert.Equal(t, []specs.EventPayloadSpec{payloads[0], payloads[2]}, unique)
+		assert.Equal(t, []specs.EventPayloadSpec{payloads[1], payloads[3]}, duplicates)
+	})
+
+	t.Run("non-positive window removes every repeat", func(t *testing.T) {
+		payloads := []specs.EventPayloadSpec{event("a", 0), event("a", 24*time.Hour)}
+
+		unique, duplicates := DeduplicateBatch(payloads, 0)
+
+		assert.Equal(t, []string{"a"}, ids(unique))
+		assert.Equal(t, []specs.EventPayloadSpec{payloads[1]}, duplicates)
+	})
+}
+
+func TestBoundedDeduplicationCache(t *testing.T) {
+	base := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+
+	t.Run("detects repeat within window", func(t *testing.T) {
+		cache := NewBoundedDeduplicationCache(5*time.Minute, 0)
+
+		assert.False(t, cache.Seen("a", base))
+		assert.True(t, cache.Seen("a", base.Add(5*time.Minute)))
+	})
+
+	t.Run("expires entries older than window", func(t *testing.T) {
+		cache := NewBoundedDeduplicationCache(5*time.Minute, 0)
+		cache.Seen("a", base)
+		cache.Seen("b", base.Add(time.Minute))
+
+		assert.False(t, cache.Seen("c", base.Add(6*time.Minute)))
+
+		assert.Equal(t, 2, cache.Len())
+		assert.False(t, cache.Seen("a", base.Add(7*time.Minute)))
+	})
+
+	t.Run("capacity limit evicts oldest", func(t *testing.T) {
+		cache := NewBoundedDeduplicationCache(time.Hour, 2)
+		cache.Seen("a", base)
+		cache.Seen("b", base.Add(time.Second))
+		cache.Seen("c", base.Add(2*time.Second))
+
+		assert.Equal(t, 2, cache.Len())
+		assert.True(t, cache.Seen("c", base.Add(3*time.Second)))
+		assert.True(t, cache.Seen("b", base.Add(3*time.Second)))
+		assert.False(t, cache.Seen("a", base.Add(3*time.Second)), "evicted ID is treated as new")
+	})
+}
+
+func TestDeduplicateObservations(t *testing.T) {
+	observedAt := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	newRecordSpec := func(observations ...specs.ObservationSpec) specs.MeterRecordSpec {
+		spec := newTestRecordSpec("event-1", "0", "unused", observedAt)
+		spec.Observations = observations
+		return spec
+	}
+	tokens := func(quantity string) specs.ObservationSpec {
+		return specs.NewInstantObservation(quantity, "tokens", observedAt)
+	}
+
+	t.Run("two identical observations reduced to one", func(t *testing.T) {
+		spec := newRecordSpec(tokens("100"), tokens("100"))
+		record, err := NewMeterRecord(spec)
+		require.NoError(t, err)
+		spec.ContentHash = record.ContentHash()
+
+		deduplicated := DeduplicateObservations(spec)
+
+		assert.True(t, record.HasDuplicateObservations())
+		assert.Equal(t, []specs.ObservationSpec{tokens("100")}, deduplicated.Observations)
+		assert.Empty(t, deduplicated.ContentHash, "hash of the removed observation is cleared")
+		assert.Len(t, spec.Observations, 2, "input record should be unchanged")
+	})
+
+	t.Run("same unit with different quantity both kept", func(t *testing.T) {
+		spec := newRecordSpec(tokens("100"), tokens("200"))
+		record, err := NewMeterRecord(spec)
+		require.NoError(t, err)
+
+		deduplicated := DeduplicateObservations(spec)
+
+		assert.False(t, record.HasDuplicateObservations())
+		assert.Equal(t, spec.Observations, deduplicated.Observations)
+	})
+
+	t.Run("three where two are duplicates keeps two", func(t *testing.T) {
+		spec := newRecordSpec(tokens("100"), tokens("200"), tokens("100"))
+
+		deduplicated := DeduplicateObservations(spec)
+
+		assert.Equal(t, []specs.ObservationSpec{tokens("100"), tokens("200")}, deduplicated.Observations)
+	})
+
+	t.Run("empty observations list unchanged", func(t *testing.T) {
+		spec := newRecordSpec()
+
+		deduplicated := DeduplicateObservations(spec)
+
+		assert.Equal(t, spec, deduplicated)
+	})
+
+	t.Run("meter drops duplicates from overlapping extractions when configured", func(t *testing.T) {
+		payload := specs.EventPayloadSpec{
+			ID:          "event-1",
+			WorkspaceID: "workspace-test",
+			UniverseID:  "universe-test",
+			Type:        "api.request",
+			Subject:     "customer:test",
+			Time:        observedAt,
+			Properties:  map[string]string{"tokens": "100"},
+		}
+		config := specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "tokens", Unit: "tokens"},
+				{SourceProperty: "tokens", Unit: "tokens"},
+			},
+		}
+
+		records, err := Meter(payload, config)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Len(t, records[0].Observations, 2)
+
+		config.DeduplicateObservations = true
+		records, err = Meter(payload, config)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Len(t, records[0].Observations, 1)
+	})
+}
@@ -0,0 +1,12 @@
+package internal
+
+import (
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// Register the reference implementation as the spec-level default so callers
+// can use specs.DefaultMeter() and specs.DefaultAggregate().
+func init() {
+	specs.SetDefaultMeter(Meter)
+	specs.SetDefaultAggregate(Aggregate)
+}
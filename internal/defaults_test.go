@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultRegistration(t *testing.T) {
+	t.Run("registers Meter as the spec default", func(t *testing.T) {
+		meter := specs.DefaultMeter()
+		require.NotNil(t, meter)
+
+		payload := specs.EventPayloadSpec{
+			ID:          "event-123",
+			WorkspaceID: "workspace-prod",
+			UniverseID:  "production",
+			Type:        "api.request",
+			Subject:     "customer:acme",
+			Time:        time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC),
+			Properties:  map[string]string{"tokens": "1250"},
+		}
+		config := specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "tokens", Unit: "api-tokens"},
+			},
+		}
+
+		records, err := meter(payload, config)
+
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "event-123", records[0].ID)
+		assert.Equal(t, "1250", records[0].Observations[0].Quantity)
+	})
+
+	t.Run("registers Aggregate as the spec default", func(t *testing.T) {
+		aggregate := specs.DefaultAggregate()
+		require.NotNil(t, aggregate)
+
+		observedAt := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+		records := []specs.MeterRecordSpec{
+			{
+				ID:            "event-123",
+				WorkspaceID:   "workspace-prod",
+				UniverseID:    "production",
+				Subject:       "customer:acme",
+				ObservedAt:    observedAt,
+				Observations:  []specs.ObservationSpec{specs.NewInstantObservation("1250", "api-tokens", observedAt)},
+				SourceEventID: "event-123",
+				MeteredAt:     observedAt,
+			},
+		}
+		config := specs.AggregateConfigSpec{
+			Aggregation: "sum",
+			Window: specs.TimeWindowSpec{
+				Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			},
+		}
+
+		reading, err := aggregate(records, nil, config)
+
+		require.NoError(t, err)
+		require.Len(t, reading.ComputedValues, 1)
+		assert.Equal(t, "1250", reading.ComputedValues[0].Quantity)
+		assert.Equal(t, 1, reading.RecordCount)
+	})
+}
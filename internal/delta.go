@@ -0,0 +1,17 @@
+package internal
+
+import (
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// ReadingDelta computes the change of each computed value since the previous
+// period: ComputedValues minus PreviousValues, matched by unit.
+//
+// It is reading.Delta(), kept for callers working with internal types.
+// Returns nil if the reading has no PreviousValues, including an empty
+// slice. Each delta keeps the unit and aggregation of the current value.
+// Returns error if a current value has no previous value with the same unit,
+// or a quantity is not a valid decimal.
+func ReadingDelta(reading specs.MeterReadingSpec) ([]specs.ComputedValueSpec, error) {
+	return reading.Delta()
+}
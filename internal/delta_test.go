@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadingDelta(t *testing.T) {
+	previous := specs.MeterReadingSpec{
+		ID:             "reading-december",
+		ComputedValues: []specs.ComputedValueSpec{{Quantity: "1000", Unit: "api-calls", Aggregation: "sum"}},
+	}
+
+	t.Run("computes change since previous period", func(t *testing.T) {
+		current := specs.EnrichWithPreviousPeriod(specs.MeterReadingSpec{
+			ID:             "reading-january",
+			ComputedValues: []specs.ComputedValueSpec{{Quantity: "1250", Unit: "api-calls", Aggregation: "sum"}},
+		}, previous)
+
+		deltas, err := ReadingDelta(current)
+
+		require.NoError(t, err)
+		require.Len(t, deltas, 1)
+		assert.Equal(t, "250", deltas[0].Quantity)
+		assert.Equal(t, "api-calls", deltas[0].Unit)
+		assert.Equal(t, "sum", deltas[0].Aggregation)
+	})
+
+	t.Run("with nil PreviousValues returns nil delta", func(t *testing.T) {
+		deltas, err := ReadingDelta(specs.MeterReadingSpec{
+			ComputedValues: []specs.ComputedValueSpec{{Quantity: "1250", Unit: "api-calls", Aggregation: "sum"}},
+		})
+
+		require.NoError(t, err)
+		assert.Nil(t, deltas)
+	})
+
+	t.Run("with empty PreviousValues returns nil delta", func(t *testing.T) {
+		deltas, err := ReadingDelta(specs.MeterReadingSpec{
+			ComputedValues: []specs.ComputedValueSpec{{Quantity: "1250", Unit: "api-calls", Aggregation: "sum"}},
+			PreviousValues: []specs.ComputedValueSpec{},
+		})
+
+		require.NoError(t, err)
+		assert.Nil(t, deltas)
+	})
+
+	t.Run("with unit mismatch returns error", func(t *testing.T) {
+		current := specs.EnrichWithPreviousPeriod(specs.MeterReadingSpec{
+			ComputedValues: []specs.ComputedValueSpec{{Quantity: "12", Unit: "seats", Aggregation: "max"}},
+		}, previous)
+
+		_, err := ReadingDelta(current)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unit mismatch")
+	})
+}
@@ -0,0 +1,348 @@
+package internal
+
+import (
+	"fmt"
+	specs "github.com/chrisconley/metron/specs"
+	"strings"
+)
+
+// Enricher computes derived properties on an EventPayload before metering.
+//
+// Enrichers return a new payload rather than mutating the input. Properties
+// they add are available for observation extraction and, when not extracted,
+// pass through as dimensions like any other property.
+type Enricher interface {
+	Enrich(payload specs.EventPayloadSpec) (specs.EventPayloadSpec, error)
+}
+
+// EnrichAndMeter applies enrichers in order, then meters the enriched payload.
+// The first enricher error halts the pipeline; no records are produced.
+func EnrichAndMeter(
+	payload specs.EventPayloadSpec,
+	enrichers []Enricher,
+	config specs.MeteringConfigSpec,
+) ([]specs.MeterRecordSpec, error) {
+	enriched := payload
+	for i, enricher := range enrichers {
+		var err error
+		enriched, err = enricher.Enrich(enriched)
+		if err != nil {
+			return nil, fmt.Errorf("enricher %d: %w", i, err)
+		}
+	}
+
+	return Meter(enriched, config)
+}
+
+// withProperty returns a copy of the payload with key set to value.
+// The input payload's properties map is never modified.
+func withProperty(payload specs.EventPayloadSpec, key, value string) specs.EventPayloadSpec {
+	properties := make(map[string]string, len(payload.Properties)+1)
+	for k, v := range payload.Properties {
+		properties[k] = v
+	}
+	properties[key] = value
+	payload.Properties = properties
+	return payload
+}
+
+type constantEnricher struct {
+	key   string
+	value string
+}
+
+// ConstantEnricher returns an Enricher that sets key to a fixed value,
+// overwriting any existing property with the same key.
+func ConstantEnricher(key, value string) Enricher {
+	return constantEnricher{key: key, value: value}
+}
+
+func (e constantEnricher) Enrich(payload specs.EventPayloadSpec) (specs.EventPayloadSpec, error) {
+	return withProperty(payload, e.key, e.value), nil
+}
+
+type formulaEnricher struct {
+	key        string
+	expression formulaNode
+}
+
+// FormulaEnricher returns an Enricher that sets key to the result of an
+// arithmetic expression over other properties.
+//
+// The expression supports decimal literals, property names, the operators
+// + - * /, unary minus, and parentheses. Bare property names may contain
+// letters, digits, underscores, and dots; any other name can be referenced in
+// braces, as in "{model-tokens}". Arithmetic uses Decimal, so results are exact
+// to 34 significant digits.
+//
+// Examples:
+//   - "input_tokens + output_tokens"
+//   - "{input_tokens} + {output_tokens}"
+//   - "tokens * 0.002"
+//   - "(bytes_in + bytes_out) / 1073741824"
+//
+// Returns error if key is empty or the expression cannot be parsed. Enrich
+// returns error if a referenced property is missing, not a decimal, or the
+// expression divides by zero.
+func FormulaEnricher(key, expression string) (Enricher, error) {
+	if key == "" {
+		return nil, fmt.Errorf("formula key is required")
+	}
+
+	node, err := parseFormula(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid formula %q: %w", expression, err)
+	}
+
+	return formulaEnricher{key: key, expression: node}, nil
+}
+
+func (e formulaEnricher) Enrich(payload specs.EventPayloadSpec) (specs.EventPayloadSpec, error) {
+	value, err := e.expression.eval(NewEventPayloadProperties(payload.Properties))
+	if err != nil {
+		return specs.EventPayloadSpec{}, fmt.Errorf("failed to compute property %q: %w", e.key, err)
+	}
+	return withProperty(payload, e.key, value.String()), nil
+}
+
+// formulaNode is a node in a parsed formula expression tree.
+type formulaNode interface {
+	eval(properties EventPayloadProperties) (Decimal, error)
+}
+
+type formulaLiteral struct {
+	value Decimal
+}
+
+func (n formulaLiteral) eval(EventPayloadProperties) (Decimal, error) {
+	return n.value, nil
+}
+
+type formulaProperty struct {
+	name string
+}
+
+func (n formulaProperty) eval(properties EventPayloadProperties) (Decimal, error) {
+	raw, ok := properties.Get(n.name)
+	if !ok {
+		return Decimal{}, fmt.Errorf("property %q not found in payload", n.name)
+	}
+	value, err := NewDecimal(raw)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("failed to parse property %q value %q as decimal: %w", n.name, raw, err)
+	}
+	return value, nil
+}
+
+type formulaNegate struct {
+	operand formulaNode
+}
+
+func (n formulaNegate) eval(properties EventPayloadProperties) (Decimal, error) {
+	value, err := n.operand.eval(properties)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return NewDecimalFromInt64(0).Sub(value), nil
+}
+
+type formulaBinary struct {
+	op          byte
+	left, right formulaNode
+}
+
+func (n formulaBinary) eval(properties EventPayloadProperties) (Decimal, error) {
+	left, err := n.left.eval(properties)
+	if err != nil {
+		return Decimal{}, err
+	}
+	right, err := n.right.eval(properties)
+	if err != nil {
+		return Decimal{}, err
+	}
+
+	switch n.op {
+	case '+':
+		return left.Add(right), nil
+	case '-':
+		return left.Sub(right), nil
+	case '*':
+		return left.Mul(right), nil
+	case '/':
+		if right.IsZero() {
+			return Decimal{}, fmt.Errorf("division by zero")
+		}
+		return left.Div(right), nil
+	default:
+		return Decimal{}, fmt.Errorf("unsupported operator %q", n.op)
+	}
+}
+
+// formulaParser is a recursive-descent parser for the grammar:
+//
+//	expr   = term { ("+" | "-") term }
+//	term   = factor { ("*" | "/") factor }
+//	factor = number | property | "{" name "}" | "(" expr ")" | "-" factor
+type formulaParser struct {
+	input string
+	pos   int
+}
+
+func parseFormula(expression string) (formulaNode, error) {
+	p := &formulaParser{input: expression}
+	node, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos)
+	}
+	return node, nil
+}
+
+func (p *formulaParser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *formulaParser) peek() (byte, bool) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return 0, false
+	}
+	return p.input[p.pos], true
+}
+
+func (p *formulaParser) parseExpr() (formulaNode, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.peek()
+		if !ok || (op != '+' && op != '-') {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = formulaBinary{op: op, left: left, right: right}
+	}
+}
+
+func (p *formulaParser) parseTerm() (formulaNode, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.peek()
+		if !ok || (op != '*' && op != '/') {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = formulaBinary{op: op, left: left, right: right}
+	}
+}
+
+func (p *formulaParser) parseFactor() (formulaNode, error) {
+	c, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	switch {
+	case c == '(':
+		p.pos++
+		node, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if c, ok := p.peek(); !ok || c != ')' {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return node, nil
+
+	case c == '-':
+		p.pos++
+		operand, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return formulaNegate{operand: operand}, nil
+
+	case c == '{':
+		start := p.pos + 1
+		end := strings.IndexByte(p.input[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("missing closing brace for property at position %d", p.pos)
+		}
+		if end == 0 {
+			return nil, fmt.Errorf("empty property reference at position %d", p.pos)
+		}
+		p.pos = start + end + 1
+		return formulaProperty{name: p.input[start : start+end]}, nil
+
+	case isFormulaDigit(c) || c == '.':
+		start := p.pos
+		for p.pos < len(p.input) && (isFormulaDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		value, err := NewDecimal(p.input[start:p.pos])
+		if err != nil {
+			return nil, fmt.Errorf("invalid number at position %d: %w", start, err)
+		}
+		return formulaLiteral{value: value}, nil
+
+	case isFormulaIdentStart(c):
+		start := p.pos
+		for p.pos < len(p.input) && (isFormulaIdentStart(p.input[p.pos]) || isFormulaDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		return formulaProperty{name: p.input[start:p.pos]}, nil
+
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", c, p.pos)
+	}
+}
+
+// formulaReferences returns the property names an expression reads, in the
+// order they appear, without duplicates.
+func formulaReferences(node formulaNode) []string {
+	var names []string
+	seen := make(map[string]bool)
+	var walk func(formulaNode)
+	walk = func(node formulaNode) {
+		switch n := node.(type) {
+		case formulaProperty:
+			if !seen[n.name] {
+				seen[n.name] = true
+				names = append(names, n.name)
+			}
+		case formulaNegate:
+			walk(n.operand)
+		case formulaBinary:
+			walk(n.left)
+			walk(n.right)
+		}
+	}
+	walk(node)
+	return names
+}
+
+func isFormulaDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isFormulaIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
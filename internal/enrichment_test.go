@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/chrisconley/metron/internal/testutil"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingEnricher struct{}
+
+func (failingEnricher) Enrich(specs.EventPayloadSpec) (specs.EventPayloadSpec, error) {
+	return specs.EventPayloadSpec{}, fmt.Errorf("pricing service unavailable")
+}
+
+func newTestEnrichmentPayload(properties map[string]string) specs.EventPayloadSpec {
+	return testutil.FixtureEventPayload(
+		testutil.WithPayloadType("llm.completion"),
+		testutil.WithPayloadProperties(properties),
+	)
+}
+
+func TestConstantEnricher(t *testing.T) {
+	t.Run("adds property without modifying input", func(t *testing.T) {
+		payload := newTestEnrichmentPayload(map[string]string{"tokens": "100"})
+
+		enriched, err := ConstantEnricher("region", "us-east-1").Enrich(payload)
+
+		require.NoError(t, err)
+		assert.Equal(t, "us-east-1", enriched.Properties["region"])
+		assert.Equal(t, "100", enriched.Properties["tokens"])
+		_, hasRegion := payload.Properties["region"]
+		assert.False(t, hasRegion, "input payload should not be modified")
+	})
+}
+
+func TestFormulaEnricher(t *testing.T) {
+	t.Run("computes value from properties", func(t *testing.T) {
+		enricher, err := FormulaEnricher("total_tokens", "input_tokens + output_tokens")
+		require.NoError(t, err)
+
+		enriched, err := enricher.Enrich(newTestEnrichmentPayload(map[string]string{
+			"input_tokens":  "450",
+			"output_tokens": "890",
+		}))
+
+		require.NoError(t, err)
+		assert.Equal(t, "1340", enriched.Properties["total_tokens"])
+	})
+
+	t.Run("respects precedence, parentheses, and unary minus", func(t *testing.T) {
+		enricher, err := FormulaEnricher("price", "(tokens + 100) * 0.002 - -1 * 0.25")
+		require.NoError(t, err)
+
+		enriched, err := enricher.Enrich(newTestEnrichmentPayload(map[string]string{"tokens": "900"}))
+
+		require.NoError(t, err)
+		assert.Equal(t, "2.250", enriched.Properties["price"])
+	})
+
+	t.Run("rejects malformed expression", func(t *testing.T) {
+		_, err := FormulaEnricher("price", "tokens * (0.002")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing closing parenthesis")
+	})
+
+	t.Run("with missing property returns error", func(t *testing.T) {
+		enricher, err := FormulaEnricher("price", "tokens * 0.002")
+		require.NoError(t, err)
+
+		_, err = enricher.Enrich(newTestEnrichmentPayload(map[string]string{}))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `property "tokens" not found`)
+	})
+
+	t.Run("with division by zero returns error", func(t *testing.T) {
+		enricher, err := FormulaEnricher("ratio", "tokens / requests")
+		require.NoError(t, err)
+
+		_, err = enricher.Enrich(newTestEnrichmentPayload(map[string]string{"tokens": "10", "requests": "0"}))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "division by zero")
+	})
+}
+
+func TestEnrichAndMeter(t *testing.T) {
+	t.Run("enriched properties are extractable and pass through as dimensions", func(t *testing.T) {
+		price, err := FormulaEnricher("price", "tokens * 0.002")
+		require.NoError(t, err)
+
+		config := specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "price", Unit: "usd"},
+			},
+		}
+
+		records, err := EnrichAndMeter(
+			newTestEnrichmentPayload(map[string]string{"tokens": "1000", "model": "gpt-4"}),
+			[]Enricher{ConstantEnricher("pricing_version", "2024-01"), price},
+			config,
+		)
+
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "2.000", records[0].Observations[0].Quantity)
+		assert.Equal(t, "2024-01", records[0].Dimensions["pricing_version"])
+		assert.Equal(t, "gpt-4", records[0].Dimensions["model"])
+	})
+
+	t.Run("enricher error halts pipeline", func(t *testing.T) {
+		config := specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "tokens", Unit: "tokens"},
+			},
+		}
+
+		records, err := EnrichAndMeter(
+			newTestEnrichmentPayload(map[string]string{"tokens": "1000"}),
+			[]Enricher{ConstantEnricher("region", "us-east-1"), failingEnricher{}},
+			config,
+		)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "enricher 1")
+		assert.Contains(t, err.Error(), "pricing service unavailable")
+		assert.Nil(t, records)
+	})
+}
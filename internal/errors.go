@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors for validation failures, for callers that branch on the
+// kind of failure with errors.Is rather than matching messages. Constructors
+// wrap these without changing their messages, so a constructor may report
+// "observed at is required" while matching ErrZeroTime.
+var (
+	ErrEmptyID             = errors.New("ID is required")
+	ErrEmptyWorkspaceID    = errors.New("workspace ID is required")
+	ErrEmptySubject        = errors.New("subject is required")
+	ErrZeroTime            = errors.New("time is required")
+	ErrInvalidAggregation  = errors.New("invalid aggregation")
+	ErrNegativeRecordCount = errors.New("record count cannot be negative")
+	ErrEmptyObservations   = errors.New("observations array is empty")
+	ErrInvalidDecimal      = errors.New("invalid decimal")
+	ErrInvalidUnit         = errors.New("invalid unit")
+	ErrBaseConfigCycle     = errors.New("base config cycle")
+)
+
+// validationError reports a validation failure with its own message while
+// matching sentinel under errors.Is.
+type validationError struct {
+	sentinel error
+	message  string
+}
+
+func (e *validationError) Error() string {
+	return e.message
+}
+
+func (e *validationError) Unwrap() error {
+	return e.sentinel
+}
+
+// newValidationError returns an error with the formatted message that wraps
+// sentinel.
+func newValidationError(sentinel error, format string, args ...any) error {
+	return &validationError{sentinel: sentinel, message: fmt.Sprintf(format, args...)}
+}
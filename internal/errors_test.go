@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		sentinel error
+		message  string
+	}{
+		{"empty payload ID", errorOf(NewEventPayloadID("")), ErrEmptyID, "ID is required"},
+		{"empty source event ID", errorOf(NewMeterRecordSourceEventID("")), ErrEmptyID, "source event ID is required"},
+		{"empty workspace ID", errorOf(NewMeterRecordWorkspaceID("")), ErrEmptyWorkspaceID, "workspace ID is required"},
+		{"empty subject", errorOf(NewMeterReadingSubject("")), ErrEmptySubject, "subject is required"},
+		{"zero payload time", errorOf(NewEventPayloadTime(time.Time{})), ErrZeroTime, "time is required"},
+		{"zero observed at", errorOf(NewMeterRecordObservedAt(time.Time{})), ErrZeroTime, "observed at is required"},
+		{"unknown aggregation", errorOf(NewMeterReadingAggregation("median")), ErrInvalidAggregation, `invalid aggregation type: "median"`},
+		{"negative record count", errorOf(NewMeterReadingRecordCount(-1)), ErrNegativeRecordCount, "record count cannot be negative"},
+		{"invalid decimal", errorOf(NewDecimal("abc")), ErrInvalidDecimal, ""},
+		{"empty unit", errorOf(NewUnit("")), ErrInvalidUnit, "unit is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := fmt.Errorf("outer: %w", tt.err)
+
+			require.Error(t, tt.err)
+			assert.ErrorIs(t, wrapped, tt.sentinel)
+			if tt.message != "" {
+				assert.Equal(t, tt.message, tt.err.Error(), "message should be unchanged")
+			}
+		})
+	}
+
+	t.Run("errors unwrap through constructors", func(t *testing.T) {
+		_, err := NewMeterRecord(specs.MeterRecordSpec{ID: "record-1", WorkspaceID: "workspace-test", UniverseID: "universe-test"})
+
+		assert.ErrorIs(t, err, ErrEmptySubject)
+		assert.Equal(t, "invalid subject: subject is required", err.Error())
+	})
+
+	t.Run("empty observations", func(t *testing.T) {
+		_, err := NewMeterRecord(specs.MeterRecordSpec{
+			ID: "record-1", WorkspaceID: "workspace-test", UniverseID: "universe-test", Subject: "customer:test",
+		})
+
+		assert.ErrorIs(t, err, ErrEmptyObservations)
+	})
+
+	t.Run("invalid decimal keeps message and cause", func(t *testing.T) {
+		_, err := NewDecimal("abc")
+
+		assert.Contains(t, err.Error(), "invalid decimal: ")
+	})
+
+	t.Run("sentinels are not confused", func(t *testing.T) {
+		_, err := NewMeterRecordSourceEventID("")
+
+		assert.False(t, errors.Is(err, ErrEmptyWorkspaceID))
+		assert.False(t, errors.Is(err, ErrZeroTime))
+		assert.False(t, errors.Is(errors.New("ID is required"), ErrEmptyID), "matching is by identity, not message")
+	})
+}
+
+// errorOf returns the error from a constructor's results.
+func errorOf[T any](_ T, err error) error {
+	return err
+}
@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// GenerateEventPayloadID returns a new ULID for callers that let the metering
+// system assign event IDs. ULIDs are globally unique and sort
+// lexicographically by generation time, to the millisecond.
+func GenerateEventPayloadID() string {
+	return ulid.Make().String()
+}
+
+// NewEventPayloadWithGeneratedID returns a payload whose ID is a ULID
+// timestamped with the event time t, so IDs of these payloads sort by when
+// the events happened. Returns error if the payload is invalid or t cannot be
+// encoded in a ULID (before the Unix epoch).
+func NewEventPayloadWithGeneratedID(
+	workspaceID, universeID, eventType, subject string,
+	t time.Time,
+	properties map[string]string,
+) (specs.EventPayloadSpec, error) {
+	id, err := ulid.New(ulid.Timestamp(t), ulid.DefaultEntropy())
+	if err != nil {
+		return specs.EventPayloadSpec{}, fmt.Errorf("failed to generate ID: %w", err)
+	}
+
+	spec := specs.EventPayloadSpec{
+		ID:          id.String(),
+		WorkspaceID: workspaceID,
+		UniverseID:  universeID,
+		Type:        eventType,
+		Subject:     subject,
+		Time:        t,
+		Properties:  properties,
+	}
+	if _, err := NewEventPayload(spec); err != nil {
+		return specs.EventPayloadSpec{}, err
+	}
+	return spec, nil
+}
+
+// IsGeneratedID reports whether id is a ULID, as produced by
+// GenerateEventPayloadID and NewEventPayloadWithGeneratedID. Caller-provided
+// IDs such as UUIDs are not.
+func IsGeneratedID(id string) bool {
+	_, err := ulid.ParseStrict(id)
+	return err == nil
+}
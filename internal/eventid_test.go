@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateEventPayloadID(t *testing.T) {
+	t.Run("generated IDs are unique", func(t *testing.T) {
+		seen := make(map[string]bool)
+
+		for i := 0; i < 100; i++ {
+			seen[GenerateEventPayloadID()] = true
+		}
+
+		assert.Len(t, seen, 100)
+	})
+}
+
+func TestNewEventPayloadWithGeneratedID(t *testing.T) {
+	t.Run("IDs sort lexicographically by event time", func(t *testing.T) {
+		base := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+		timeByID := make(map[string]time.Time)
+		var ids []string
+		for _, offset := range []time.Duration{3 * time.Hour, time.Millisecond, 2 * time.Minute, 0} {
+			payload, err := NewEventPayloadWithGeneratedID("workspace-test", "universe-test", "api.request", "customer:test",
+				base.Add(offset), map[string]string{"tokens": "10"})
+			require.NoError(t, err)
+			timeByID[payload.ID] = payload.Time
+			ids = append(ids, payload.ID)
+		}
+
+		sort.Strings(ids)
+
+		for i := 1; i < len(ids); i++ {
+			assert.True(t, timeByID[ids[i-1]].Before(timeByID[ids[i]]), "IDs should sort in event time order")
+		}
+	})
+
+	t.Run("invalid payload returns error", func(t *testing.T) {
+		_, err := NewEventPayloadWithGeneratedID("", "universe-test", "api.request", "customer:test", time.Now(), nil)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid workspace ID")
+	})
+}
+
+func TestIsGeneratedID(t *testing.T) {
+	t.Run("identifies ULIDs", func(t *testing.T) {
+		assert.True(t, IsGeneratedID(GenerateEventPayloadID()))
+		assert.True(t, IsGeneratedID("01ARZ3NDEKTSV4RRFFQ69G5FAV"))
+	})
+
+	t.Run("rejects UUIDs and custom IDs", func(t *testing.T) {
+		assert.False(t, IsGeneratedID("550e8400-e29b-41d4-a716-446655440000"))
+		assert.False(t, IsGeneratedID("evt-123"))
+		assert.False(t, IsGeneratedID(""))
+	})
+}
@@ -3,6 +3,7 @@ package internal
 import (
 	"fmt"
 	specs "github.com/chrisconley/metron/specs"
+	"strings"
 	"time"
 )
 
@@ -60,13 +61,59 @@ func NewEventPayload(spec specs.EventPayloadSpec) (EventPayload, error) {
 	}, nil
 }
 
+// MergeEventPayloads combines two payloads describing the same event, such as
+// properties gathered from HTTP headers and from the request body.
+//
+// The result takes base's ID, or overlay's if base has none. Scalar fields take
+// overlay's value when it is non-zero and base's otherwise. Properties are
+// merged with overlay winning on conflicting keys. Neither input is modified.
+// Returns an error if both payloads have IDs and they differ.
+func MergeEventPayloads(base, overlay specs.EventPayloadSpec) (specs.EventPayloadSpec, error) {
+	if base.ID != "" && overlay.ID != "" && base.ID != overlay.ID {
+		return specs.EventPayloadSpec{}, fmt.Errorf("cannot merge payloads with different IDs %q and %q", base.ID, overlay.ID)
+	}
+
+	merged := base
+	if merged.ID == "" {
+		merged.ID = overlay.ID
+	}
+	if overlay.WorkspaceID != "" {
+		merged.WorkspaceID = overlay.WorkspaceID
+	}
+	if overlay.UniverseID != "" {
+		merged.UniverseID = overlay.UniverseID
+	}
+	if overlay.Type != "" {
+		merged.Type = overlay.Type
+	}
+	if overlay.Subject != "" {
+		merged.Subject = overlay.Subject
+	}
+	if !overlay.Time.IsZero() {
+		merged.Time = overlay.Time
+	}
+	if overlay.SequenceNumber != 0 {
+		merged.SequenceNumber = overlay.SequenceNumber
+	}
+	if overlay.ParentID != "" {
+		merged.ParentID = overlay.ParentID
+	}
+
+	properties := NewEventPayloadProperties(base.Properties).MergeProperties(NewEventPayloadProperties(overlay.Properties))
+	merged.Properties = nil
+	if len(properties.values) > 0 {
+		merged.Properties = properties.values
+	}
+	return merged, nil
+}
+
 type EventPayloadID struct {
 	value string
 }
 
 func NewEventPayloadID(value string) (EventPayloadID, error) {
 	if value == "" {
-		return EventPayloadID{}, fmt.Errorf("ID is required")
+		return EventPayloadID{}, ErrEmptyID
 	}
 	return EventPayloadID{value: value}, nil
 }
@@ -79,9 +126,14 @@ type EventPayloadWorkspaceID struct {
 	value string
 }
 
+// NewEventPayloadWorkspaceID returns error if value is empty or fails a
+// registered WorkspaceIDValidator.
 func NewEventPayloadWorkspaceID(value string) (EventPayloadWorkspaceID, error) {
 	if value == "" {
-		return EventPayloadWorkspaceID{}, fmt.Errorf("workspace ID is required")
+		return EventPayloadWorkspaceID{}, ErrEmptyWorkspaceID
+	}
+	if err := validateWorkspaceID(value); err != nil {
+		return EventPayloadWorkspaceID{}, err
 	}
 	return EventPayloadWorkspaceID{value: value}, nil
 }
@@ -126,7 +178,7 @@ type EventPayloadSubject struct {
 
 func NewEventPayloadSubject(value string) (EventPayloadSubject, error) {
 	if value == "" {
-		return EventPayloadSubject{}, fmt.Errorf("subject is required")
+		return EventPayloadSubject{}, ErrEmptySubject
 	}
 	return EventPayloadSubject{value: value}, nil
 }
@@ -141,7 +193,7 @@ type EventPayloadTime struct {
 
 func NewEventPayloadTime(value time.Time) (EventPayloadTime, error) {
 	if value.IsZero() {
-		return EventPayloadTime{}, fmt.Errorf("time is required")
+		return EventPayloadTime{}, ErrZeroTime
 	}
 	return EventPayloadTime{value: value}, nil
 }
@@ -182,3 +234,95 @@ func (p EventPayloadProperties) Keys() []string {
 	}
 	return keys
 }
+
+// MergeProperties returns a new property set containing p's properties and
+// other's, with other winning on conflicting keys. Neither p nor other is
+// modified.
+func (p EventPayloadProperties) MergeProperties(other EventPayloadProperties) EventPayloadProperties {
+	merged := make(map[string]string, len(p.values)+len(other.values))
+	for key, value := range p.values {
+		merged[key] = value
+	}
+	for key, value := range other.values {
+		merged[key] = value
+	}
+	return EventPayloadProperties{values: merged}
+}
+
+// ExtractNamespacedProperties returns the properties whose keys begin with
+// namespace followed by ".", with that prefix stripped. For namespace "llm",
+// "llm.input_tokens" becomes "input_tokens"; unprefixed properties and those
+// in other namespaces are left out. An empty namespace returns a copy of all
+// properties.
+func ExtractNamespacedProperties(properties EventPayloadProperties, namespace string) EventPayloadProperties {
+	prefix := namespace + "."
+	extracted := make(map[string]string)
+	for key, value := range properties.values {
+		if namespace == "" {
+			extracted[key] = value
+		} else if name, ok := strings.CutPrefix(key, prefix); ok && name != "" {
+			extracted[name] = value
+		}
+	}
+	return EventPayloadProperties{values: extracted}
+}
+
+// Freeze returns an independent, read-only copy of the properties. Later Set
+// calls on p do not affect the copy.
+func (p EventPayloadProperties) Freeze() ImmutableProperties {
+	return NewImmutableProperties(p.values)
+}
+
+// ImmutableProperties is a read-only set of event properties, safe to share
+// between goroutines. It has no Set method, so code holding one cannot mutate
+// shared state.
+type ImmutableProperties struct {
+	values map[string]string
+}
+
+// NewImmutableProperties copies values into a read-only property set.
+func NewImmutableProperties(values map[string]string) ImmutableProperties {
+	copied := make(map[string]string, len(values))
+	for key, value := range values {
+		copied[key] = value
+	}
+	return ImmutableProperties{values: copied}
+}
+
+func (p ImmutableProperties) Get(key string) (string, bool) {
+	val, ok := p.values[key]
+	return val, ok
+}
+
+func (p ImmutableProperties) Has(key string) bool {
+	_, ok := p.values[key]
+	return ok
+}
+
+func (p ImmutableProperties) Keys() []string {
+	keys := make([]string, 0, len(p.values))
+	for key := range p.values {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// ToMap returns a copy of the properties as a map.
+func (p ImmutableProperties) ToMap() map[string]string {
+	result := make(map[string]string, len(p.values))
+	for key, value := range p.values {
+		result[key] = value
+	}
+	return result
+}
+
+// Filter returns the properties for which keep returns true.
+func (p ImmutableProperties) Filter(keep func(key, value string) bool) ImmutableProperties {
+	filtered := make(map[string]string)
+	for key, value := range p.values {
+		if keep(key, value) {
+			filtered[key] = value
+		}
+	}
+	return ImmutableProperties{values: filtered}
+}
@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmutableProperties(t *testing.T) {
+	t.Run("has no Set method", func(t *testing.T) {
+		_, hasSet := reflect.TypeOf(ImmutableProperties{}).MethodByName("Set")
+		_, hasSetOnPointer := reflect.TypeOf(&ImmutableProperties{}).MethodByName("Set")
+
+		assert.False(t, hasSet)
+		assert.False(t, hasSetOnPointer)
+	})
+
+	t.Run("read operations match the mutable properties", func(t *testing.T) {
+		values := map[string]string{"model": "gpt-4", "region": "us-east-1"}
+		mutable := NewEventPayloadProperties(values)
+
+		frozen := mutable.Freeze()
+
+		value, ok := frozen.Get("model")
+		assert.True(t, ok)
+		assert.Equal(t, "gpt-4", value)
+		_, ok = frozen.Get("missing")
+		assert.False(t, ok)
+		assert.True(t, frozen.Has("region"))
+		assert.False(t, frozen.Has("missing"))
+		assert.ElementsMatch(t, mutable.Keys(), frozen.Keys())
+		assert.Equal(t, values, frozen.ToMap())
+	})
+
+	t.Run("frozen copy is independent of later Set", func(t *testing.T) {
+		mutable := NewEventPayloadProperties(map[string]string{"model": "gpt-4"})
+		frozen := mutable.Freeze()
+
+		mutable.Set("model", "gpt-4o")
+		mutable.Set("region", "eu-west-1")
+
+		value, _ := frozen.Get("model")
+		assert.Equal(t, "gpt-4", value)
+		assert.False(t, frozen.Has("region"))
+	})
+
+	t.Run("constructor and ToMap copy their maps", func(t *testing.T) {
+		values := map[string]string{"model": "gpt-4"}
+		properties := NewImmutableProperties(values)
+
+		values["model"] = "changed"
+		properties.ToMap()["model"] = "changed"
+
+		value, _ := properties.Get("model")
+		assert.Equal(t, "gpt-4", value)
+	})
+
+	t.Run("filter keeps matching properties", func(t *testing.T) {
+		properties := NewImmutableProperties(map[string]string{"input_tokens": "10", "model": "gpt-4"})
+
+		filtered := properties.Filter(func(key, _ string) bool { return key != "input_tokens" })
+
+		assert.Equal(t, map[string]string{"model": "gpt-4"}, filtered.ToMap())
+		assert.True(t, properties.Has("input_tokens"))
+	})
+}
+
+func TestEventPayloadProperties_MergeProperties(t *testing.T) {
+	t.Run("other wins on conflict and neither input is modified", func(t *testing.T) {
+		base := NewEventPayloadProperties(map[string]string{"model": "gpt-4", "region": "us-east-1"})
+		other := NewEventPayloadProperties(map[string]string{"model": "gpt-4o"})
+
+		merged := base.MergeProperties(other)
+
+		model, _ := merged.Get("model")
+		region, _ := merged.Get("region")
+		assert.Equal(t, "gpt-4o", model)
+		assert.Equal(t, "us-east-1", region)
+		original, _ := base.Get("model")
+		assert.Equal(t, "gpt-4", original)
+	})
+}
+
+func TestMergeEventPayloads(t *testing.T) {
+	baseTime := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	t.Run("overlay property wins and base property is preserved", func(t *testing.T) {
+		base := specs.EventPayloadSpec{
+			ID:          "evt-1",
+			WorkspaceID: "ws-1",
+			Subject:     "customer:acme",
+			Time:        baseTime,
+			Properties:  map[string]string{"model": "gpt-4", "region": "us-east-1"},
+		}
+		overlay := specs.EventPayloadSpec{
+			Type:       "api.request",
+			Properties: map[string]string{"model": "gpt-4o"},
+		}
+
+		merged, err := MergeEventPayloads(base, overlay)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"model": "gpt-4o", "region": "us-east-1"}, merged.Properties)
+		assert.Equal(t, "evt-1", merged.ID)
+		assert.Equal(t, "ws-1", merged.WorkspaceID)
+		assert.Equal(t, "api.request", merged.Type)
+		assert.Equal(t, baseTime, merged.Time)
+		assert.Equal(t, "gpt-4", base.Properties["model"], "base should be unchanged")
+	})
+
+	t.Run("overlay scalar fields win when set", func(t *testing.T) {
+		base := specs.EventPayloadSpec{Subject: "customer:acme", Time: baseTime}
+		overlay := specs.EventPayloadSpec{ID: "evt-2", Subject: "customer:globex", Time: baseTime.Add(time.Hour)}
+
+		merged, err := MergeEventPayloads(base, overlay)
+
+		require.NoError(t, err)
+		assert.Equal(t, "evt-2", merged.ID)
+		assert.Equal(t, "customer:globex", merged.Subject)
+		assert.Equal(t, baseTime.Add(time.Hour), merged.Time)
+	})
+
+	t.Run("differing IDs return error", func(t *testing.T) {
+		_, err := MergeEventPayloads(specs.EventPayloadSpec{ID: "evt-1"}, specs.EventPayloadSpec{ID: "evt-2"})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "different IDs")
+	})
+}
+
+func TestExtractNamespacedProperties(t *testing.T) {
+	t.Run("returns namespaced properties with the prefix stripped", func(t *testing.T) {
+		properties := NewEventPayloadProperties(map[string]string{
+			"llm.input_tokens": "10",
+			"llm.model":        "gpt-4",
+			"llmx.other":       "1",
+			"storage.bytes":    "42",
+			"region":           "us-east-1",
+		})
+
+		extracted := ExtractNamespacedProperties(properties, "llm")
+
+		assert.ElementsMatch(t, []string{"input_tokens", "model"}, extracted.Keys())
+		value, _ := extracted.Get("input_tokens")
+		assert.Equal(t, "10", value)
+	})
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// EventTree is a node in the hierarchy of events linked by ParentID.
+//
+// The tree returned by BuildEventTree has a synthetic root with a zero Event
+// whose children are the top-level events. Children are ordered by event
+// time, then ID.
+type EventTree struct {
+	Event    specs.EventPayloadSpec
+	Children []*EventTree
+}
+
+// BuildEventTree arranges payloads into a tree by their ParentID.
+//
+// Events without a ParentID become children of the root. So do orphaned events
+// whose parent is not among payloads, so no event is dropped. Returns error if
+// two payloads share an ID or if ParentIDs form a cycle (including an event
+// that is its own parent), since such events can never be reached from the root.
+func BuildEventTree(payloads []specs.EventPayloadSpec) (*EventTree, error) {
+	nodes := make(map[string]*EventTree, len(payloads))
+	for _, payload := range payloads {
+		if _, exists := nodes[payload.ID]; exists {
+			return nil, fmt.Errorf("duplicate event ID %q", payload.ID)
+		}
+		nodes[payload.ID] = &EventTree{Event: payload}
+	}
+
+	root := &EventTree{}
+	for _, payload := range payloads {
+		node := nodes[payload.ID]
+		parent, ok := nodes[payload.ParentID]
+		if payload.ParentID == "" || !ok {
+			parent = root
+		}
+		parent.Children = append(parent.Children, node)
+	}
+
+	reached := make(map[string]bool, len(payloads))
+	var visit func(node *EventTree)
+	visit = func(node *EventTree) {
+		sort.SliceStable(node.Children, func(i, j int) bool {
+			a, b := node.Children[i].Event, node.Children[j].Event
+			if !a.Time.Equal(b.Time) {
+				return a.Time.Before(b.Time)
+			}
+			return a.ID < b.ID
+		})
+		for _, child := range node.Children {
+			reached[child.Event.ID] = true
+			visit(child)
+		}
+	}
+	visit(root)
+
+	if len(reached) < len(nodes) {
+		var cyclic []string
+		for id := range nodes {
+			if !reached[id] {
+				cyclic = append(cyclic, id)
+			}
+		}
+		sort.Strings(cyclic)
+		return nil, fmt.Errorf("parent IDs form a cycle among events %v", cyclic)
+	}
+
+	return root, nil
+}
+
+// TotalQuantityInSubtree sums the quantities for unit across every event in
+// tree, including the tree's own event.
+//
+// Event payloads carry raw properties rather than quantities, so records
+// supplies the metered observations; each record is attributed to the event
+// named by its SourceEventID. Records for events outside the tree are ignored.
+// Returns error if the tree contains a cycle or a quantity is not a decimal.
+func TotalQuantityInSubtree(tree *EventTree, records []specs.MeterRecordSpec, unit string) (Decimal, error) {
+	recordsByEvent := make(map[string][]specs.MeterRecordSpec)
+	for _, record := range records {
+		recordsByEvent[record.SourceEventID] = append(recordsByEvent[record.SourceEventID], record)
+	}
+
+	total := NewDecimalFromInt64(0)
+	onPath := make(map[*EventTree]bool)
+	var visit func(node *EventTree) error
+	visit = func(node *EventTree) error {
+		if onPath[node] {
+			return fmt.Errorf("event tree contains a cycle at event %q", node.Event.ID)
+		}
+		onPath[node] = true
+		defer delete(onPath, node)
+
+		if node.Event.ID != "" {
+			for _, record := range recordsByEvent[node.Event.ID] {
+				for _, observation := range record.Observations {
+					if observation.Unit != unit {
+						continue
+					}
+					quantity, err := NewDecimal(observation.Quantity)
+					if err != nil {
+						return fmt.Errorf("invalid quantity in record %q: %w", record.ID, err)
+					}
+					total = total.Add(quantity)
+				}
+			}
+		}
+
+		for _, child := range node.Children {
+			if err := visit(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := visit(tree); err != nil {
+		return Decimal{}, err
+	}
+
+	return total, nil
+}
@@ -0,0 +1,132 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildEventTree(t *testing.T) {
+	baseTime := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	event := func(id, parentID string, offset time.Duration) specs.EventPayloadSpec {
+		return specs.EventPayloadSpec{ID: id, ParentID: parentID, Time: baseTime.Add(offset)}
+	}
+	span := func(id, cpuMs string) specs.MeterRecordSpec {
+		record := newTestRecordSpec(id, cpuMs, "cpu-ms", baseTime)
+		record.SourceEventID = id
+		return record
+	}
+
+	t.Run("linear parent-child chain", func(t *testing.T) {
+		payloads := []specs.EventPayloadSpec{
+			event("c", "b", 2*time.Second),
+			event("a", "", 0),
+			event("b", "a", time.Second),
+		}
+
+		tree, err := BuildEventTree(payloads)
+
+		require.NoError(t, err)
+		require.Len(t, tree.Children, 1)
+		a := tree.Children[0]
+		assert.Equal(t, "a", a.Event.ID)
+		require.Len(t, a.Children, 1)
+		assert.Equal(t, "b", a.Children[0].Event.ID)
+		require.Len(t, a.Children[0].Children, 1)
+		assert.Equal(t, "c", a.Children[0].Children[0].Event.ID)
+
+		records := []specs.MeterRecordSpec{span("a", "100"), span("b", "40"), span("c", "10")}
+		total, err := TotalQuantityInSubtree(tree, records, "cpu-ms")
+		require.NoError(t, err)
+		assert.Equal(t, 0, total.Cmp(NewDecimalFromInt64(150)))
+
+		subtotal, err := TotalQuantityInSubtree(a.Children[0], records, "cpu-ms")
+		require.NoError(t, err)
+		assert.Equal(t, 0, subtotal.Cmp(NewDecimalFromInt64(50)))
+	})
+
+	t.Run("branching tree", func(t *testing.T) {
+		payloads := []specs.EventPayloadSpec{
+			event("req", "", 0),
+			event("span-2", "req", 2*time.Second),
+			event("span-1", "req", time.Second),
+			event("span-1a", "span-1", 3*time.Second),
+		}
+
+		tree, err := BuildEventTree(payloads)
+
+		require.NoError(t, err)
+		require.Len(t, tree.Children, 1)
+		req := tree.Children[0]
+		require.Len(t, req.Children, 2)
+		assert.Equal(t, "span-1", req.Children[0].Event.ID)
+		assert.Equal(t, "span-2", req.Children[1].Event.ID)
+		require.Len(t, req.Children[0].Children, 1)
+		assert.Equal(t, "span-1a", req.Children[0].Children[0].Event.ID)
+
+		records := []specs.MeterRecordSpec{
+			span("span-1", "40"),
+			span("span-2", "25"),
+			span("span-1a", "10"),
+			newTestRecordSpec("req", "1", "requests", baseTime),
+		}
+		total, err := TotalQuantityInSubtree(req, records, "cpu-ms")
+		require.NoError(t, err)
+		assert.Equal(t, 0, total.Cmp(NewDecimalFromInt64(75)))
+	})
+
+	t.Run("orphaned child becomes a top-level event", func(t *testing.T) {
+		payloads := []specs.EventPayloadSpec{
+			event("a", "", 0),
+			event("orphan", "missing", time.Second),
+		}
+
+		tree, err := BuildEventTree(payloads)
+
+		require.NoError(t, err)
+		require.Len(t, tree.Children, 2)
+		assert.Equal(t, "a", tree.Children[0].Event.ID)
+		assert.Equal(t, "orphan", tree.Children[1].Event.ID)
+	})
+
+	t.Run("cycle returns error", func(t *testing.T) {
+		payloads := []specs.EventPayloadSpec{
+			event("root", "", 0),
+			event("a", "b", time.Second),
+			event("b", "a", 2*time.Second),
+		}
+
+		_, err := BuildEventTree(payloads)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cycle among events [a b]")
+	})
+
+	t.Run("self-parent returns error", func(t *testing.T) {
+		_, err := BuildEventTree([]specs.EventPayloadSpec{event("a", "a", 0)})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cycle")
+	})
+
+	t.Run("duplicate event ID returns error", func(t *testing.T) {
+		_, err := BuildEventTree([]specs.EventPayloadSpec{event("a", "", 0), event("a", "", time.Second)})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicate event ID")
+	})
+
+	t.Run("TotalQuantityInSubtree detects hand-built cycles", func(t *testing.T) {
+		a := &EventTree{Event: event("a", "b", 0)}
+		b := &EventTree{Event: event("b", "a", 0), Children: []*EventTree{a}}
+		a.Children = []*EventTree{b}
+
+		_, err := TotalQuantityInSubtree(a, nil, "cpu-ms")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cycle")
+	})
+}
@@ -1,10 +1,12 @@
 package examples
 
 import (
+	"context"
 	"fmt"
 	"github.com/chrisconley/metron/internal"
 	"github.com/chrisconley/metron/internal/infra"
 	"github.com/chrisconley/metron/specs"
+	"sync"
 	"testing"
 	"time"
 
@@ -96,7 +98,7 @@ func (h *MeteringHandler) Handle(e infra.Event) {
 	payload := e.(EventPayloadEvent).Payload
 	config := h.configRepo.GetMeteringConfig()
 
-	records, err := internal.Meter(payload, config)
+	records, err := specs.DefaultMeter()(payload, config)
 	if err != nil {
 		panic(fmt.Sprintf("Failed to meter payload: %v", err))
 	}
@@ -109,12 +111,15 @@ func (h *MeteringHandler) Handle(e infra.Event) {
 type InFlightAggregator struct {
 	bus         *infra.Bus
 	configRepo  ConfigRepo
+	mu          sync.Mutex // Guards batch state against FlushIdle's goroutine
 	currentTick time.Time
 	batch       []specs.MeterRecordSpec
 }
 
 func (h *InFlightAggregator) Handle(e infra.Event) {
 	record := e.(InFlightMeterRecordedEvent).Record
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
 	// Determine which tick (1-second window) this record belongs to
 	recordTick := record.ObservedAt.Truncate(time.Second)
@@ -149,7 +154,7 @@ func (h *InFlightAggregator) flushBatch() {
 	}
 
 	// Aggregate all batched records into a single reading
-	reading, err := internal.Aggregate(h.batch, nil, config)
+	reading, err := specs.DefaultAggregate()(h.batch, nil, config)
 	if err != nil {
 		panic(fmt.Sprintf("Failed to aggregate batch: %v", err))
 	}
@@ -163,9 +168,18 @@ func (h *InFlightAggregator) flushBatch() {
 }
 
 func (h *InFlightAggregator) Flush() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	h.flushBatch()
 }
 
+// FlushIdle flushes the current batch once no records have arrived on bus for
+// idleTimeout, so the final partial window is published when events pause.
+// The returned channel is closed when ctx is cancelled and monitoring stops.
+func (h *InFlightAggregator) FlushIdle(ctx context.Context, idleTimeout time.Duration, bus *infra.Bus) <-chan struct{} {
+	return flushWhenIdle(ctx, idleTimeout, bus, h.Flush)
+}
+
 type RatingHandler struct {
 	configRepo         ConfigRepo
 	accumulatedRevenue internal.Decimal
@@ -222,12 +236,15 @@ func (h *RatingHandler) Handle(e infra.Event) {
 type PostFlightAggregator struct {
 	bus         *infra.Bus
 	configRepo  ConfigRepo
+	mu          sync.Mutex // Guards batch state against FlushIdle's goroutine
 	currentTick time.Time
 	batch       []specs.MeterRecordSpec
 }
 
 func (h *PostFlightAggregator) Handle(e infra.Event) {
 	record := e.(InFlightMeterRecordedEvent).Record
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
 	// Determine which tick (10-second window) this record belongs to
 	recordTick := record.ObservedAt.Truncate(10 * time.Second)
@@ -262,7 +279,7 @@ func (h *PostFlightAggregator) flushBatch() {
 	}
 
 	// Aggregate all batched records into a single reading
-	reading, err := internal.Aggregate(h.batch, nil, config)
+	reading, err := specs.DefaultAggregate()(h.batch, nil, config)
 	if err != nil {
 		panic(fmt.Sprintf("Failed to aggregate batch: %v", err))
 	}
@@ -276,9 +293,54 @@ func (h *PostFlightAggregator) flushBatch() {
 }
 
 func (h *PostFlightAggregator) Flush() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	h.flushBatch()
 }
 
+// FlushIdle flushes the current batch once no records have arrived on bus for
+// idleTimeout, so the final partial window is published when events pause.
+// The returned channel is closed when ctx is cancelled and monitoring stops.
+func (h *PostFlightAggregator) FlushIdle(ctx context.Context, idleTimeout time.Duration, bus *infra.Bus) <-chan struct{} {
+	return flushWhenIdle(ctx, idleTimeout, bus, h.Flush)
+}
+
+// flushWhenIdle calls flush each time idleTimeout passes without an
+// InFlightMeterRecorded event on bus. The returned channel is closed once ctx
+// is cancelled and the goroutine has exited.
+func flushWhenIdle(ctx context.Context, idleTimeout time.Duration, bus *infra.Bus, flush func()) <-chan struct{} {
+	activity := make(chan struct{}, 1)
+	token := bus.Subscribe(infra.InFlightMeterRecorded, func(infra.Event) {
+		// Non-blocking: one pending signal is enough to reset the timer
+		select {
+		case activity <- struct{}{}:
+		default:
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer bus.Unsubscribe(token)
+
+		timer := time.NewTimer(idleTimeout)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-activity:
+				timer.Reset(idleTimeout)
+			case <-timer.C:
+				// Wait for the next record before arming again; flushing an
+				// empty batch is a no-op anyway
+				flush()
+			}
+		}
+	}()
+	return done
+}
+
 type CustomerBalanceHandler struct{}
 
 func (h *CustomerBalanceHandler) Handle(e infra.Event) {
@@ -371,7 +433,7 @@ func TestHighThroughputMeteringPipeline(t *testing.T) {
 		}
 	}
 
-	// Flush any partial batches (in production, a timer would handle this)
+	// Flush any partial batches (in production, FlushIdle would handle this)
 	inFlightAgg.Flush()
 	postFlightAgg.Flush()
 
@@ -401,6 +463,93 @@ func TestHighThroughputMeteringPipeline(t *testing.T) {
 		len(publishedRecords), len(postFlightReadings))
 }
 
+func TestIdleFlush(t *testing.T) {
+	const idleTimeout = 50 * time.Millisecond
+	startTime := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// setup wires an InFlightAggregator to a fresh bus, returning both and a
+	// channel receiving every 1-second reading the aggregator publishes.
+	setup := func() (*infra.Bus, *InFlightAggregator, chan specs.MeterReadingSpec) {
+		bus := infra.NewBus()
+		configRepo := &HardcodedConfigRepo{}
+		bus.Subscribe(infra.EventPayloadPublished, (&MeteringHandler{bus: bus, configRepo: configRepo}).Handle)
+		agg := &InFlightAggregator{bus: bus, configRepo: configRepo}
+		bus.Subscribe(infra.InFlightMeterRecorded, agg.Handle)
+
+		readings := make(chan specs.MeterReadingSpec, 10)
+		bus.Subscribe(infra.InFlightMeterRead, func(e infra.Event) {
+			readings <- e.(InFlightMeterReadEvent).Reading
+		})
+		return bus, agg, readings
+	}
+
+	t.Run("flushes partial window after idle timeout", func(t *testing.T) {
+		bus, agg, readings := setup()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		agg.FlushIdle(ctx, idleTimeout, bus)
+
+		for _, event := range generateAPIRequestEventsWithBatching(startTime, 1, 5) {
+			bus.Publish(EventPayloadEvent{Payload: event})
+		}
+
+		select {
+		case reading := <-readings:
+			assert.Equal(t, "5", reading.ComputedValues[0].Quantity)
+		case <-time.After(time.Second):
+			t.Fatal("expected idle flush to publish a reading")
+		}
+	})
+
+	t.Run("does not flush while events keep arriving", func(t *testing.T) {
+		bus, agg, readings := setup()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		agg.FlushIdle(ctx, idleTimeout, bus)
+
+		// All events fall in the same 1-second tick, so only an idle flush
+		// could publish a reading
+		for _, event := range generateAPIRequestEventsWithBatching(startTime, 1, 10) {
+			bus.Publish(EventPayloadEvent{Payload: event})
+			time.Sleep(idleTimeout / 5)
+		}
+
+		select {
+		case <-readings:
+			t.Fatal("expected no flush while events keep arriving")
+		default:
+		}
+		select {
+		case reading := <-readings:
+			assert.Equal(t, "10", reading.ComputedValues[0].Quantity)
+		case <-time.After(time.Second):
+			t.Fatal("expected idle flush once events stop")
+		}
+	})
+
+	t.Run("context cancellation stops monitoring", func(t *testing.T) {
+		bus, agg, readings := setup()
+		ctx, cancel := context.WithCancel(context.Background())
+		done := agg.FlushIdle(ctx, idleTimeout, bus)
+
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("expected goroutine to stop after cancel")
+		}
+		for _, event := range generateAPIRequestEventsWithBatching(startTime, 1, 5) {
+			bus.Publish(EventPayloadEvent{Payload: event})
+		}
+
+		select {
+		case <-readings:
+			t.Fatal("expected no flush after cancel")
+		case <-time.After(2 * idleTimeout):
+		}
+	})
+}
+
 // === HELPER FUNCTIONS ===
 
 func generateAPIRequestEvents(startTime time.Time, count int) []specs.EventPayloadSpec {
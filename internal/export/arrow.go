@@ -0,0 +1,213 @@
+// Package export converts metering spec types into formats consumed by
+// analytics tools.
+//
+// It lives outside specs so the spec layer stays free of third-party
+// dependencies.
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/apache/arrow/go/v17/arrow"
+	"github.com/apache/arrow/go/v17/arrow/array"
+	"github.com/apache/arrow/go/v17/arrow/ipc"
+	"github.com/apache/arrow/go/v17/arrow/memory"
+
+	"github.com/chrisconley/metron/specs"
+)
+
+// Column names of the reading schema, in schema order.
+const (
+	columnID          = "id"
+	columnWorkspaceID = "workspace_id"
+	columnUniverseID  = "universe_id"
+	columnSubject     = "subject"
+	columnUnit        = "unit"
+	columnQuantity    = "quantity"
+	columnAggregation = "aggregation"
+	columnWindowStart = "window_start"
+	columnWindowEnd   = "window_end"
+	columnRecordCount = "record_count"
+)
+
+var timestampType = &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}
+
+// ReadingSchema is the Arrow schema produced by ReadingsToArrowRecord.
+//
+// Each row is one ComputedValue of a reading, so a reading with input-tokens
+// and output-tokens produces two rows sharing the same id. Quantity is a
+// float64 for compatibility with dataframe tools (Pandas, DuckDB); the exact
+// decimal string remains the source of truth in MeterReadingSpec.
+var ReadingSchema = arrow.NewSchema([]arrow.Field{
+	{Name: columnID, Type: arrow.BinaryTypes.String},
+	{Name: columnWorkspaceID, Type: arrow.BinaryTypes.String},
+	{Name: columnUniverseID, Type: arrow.BinaryTypes.String},
+	{Name: columnSubject, Type: arrow.BinaryTypes.String},
+	{Name: columnUnit, Type: arrow.BinaryTypes.String},
+	{Name: columnQuantity, Type: arrow.PrimitiveTypes.Float64},
+	{Name: columnAggregation, Type: arrow.BinaryTypes.String},
+	{Name: columnWindowStart, Type: timestampType},
+	{Name: columnWindowEnd, Type: timestampType},
+	{Name: columnRecordCount, Type: arrow.PrimitiveTypes.Int64},
+}, nil)
+
+// ReadingsToArrowRecord converts readings into a single Arrow record with
+// ReadingSchema. The caller must call Release on the returned record.
+//
+// Returns error if any computed value quantity is not a valid number.
+func ReadingsToArrowRecord(readings []specs.MeterReadingSpec) (arrow.Record, error) {
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), ReadingSchema)
+	defer builder.Release()
+
+	ids := builder.Field(0).(*array.StringBuilder)
+	workspaceIDs := builder.Field(1).(*array.StringBuilder)
+	universeIDs := builder.Field(2).(*array.StringBuilder)
+	subjects := builder.Field(3).(*array.StringBuilder)
+	units := builder.Field(4).(*array.StringBuilder)
+	quantities := builder.Field(5).(*array.Float64Builder)
+	aggregations := builder.Field(6).(*array.StringBuilder)
+	windowStarts := builder.Field(7).(*array.TimestampBuilder)
+	windowEnds := builder.Field(8).(*array.TimestampBuilder)
+	recordCounts := builder.Field(9).(*array.Int64Builder)
+
+	for i, reading := range readings {
+		for j, cv := range reading.ComputedValues {
+			quantity, err := strconv.ParseFloat(cv.Quantity, 64)
+			if err != nil {
+				return nil, fmt.Errorf("reading %d computed value %d: invalid quantity %q: %w", i, j, cv.Quantity, err)
+			}
+
+			ids.Append(reading.ID)
+			workspaceIDs.Append(reading.WorkspaceID)
+			universeIDs.Append(reading.UniverseID)
+			subjects.Append(reading.Subject)
+			units.Append(cv.Unit)
+			quantities.Append(quantity)
+			aggregations.Append(cv.Aggregation)
+			windowStarts.Append(arrow.Timestamp(reading.Window.Start.UnixMicro()))
+			windowEnds.Append(arrow.Timestamp(reading.Window.End.UnixMicro()))
+			recordCounts.Append(int64(reading.RecordCount))
+		}
+	}
+
+	return builder.NewRecord(), nil
+}
+
+// ReadingsToArrowIPC writes readings to w in the Arrow IPC streaming format.
+func ReadingsToArrowIPC(readings []specs.MeterReadingSpec, w io.Writer) error {
+	record, err := ReadingsToArrowRecord(readings)
+	if err != nil {
+		return err
+	}
+	defer record.Release()
+
+	writer := ipc.NewWriter(w, ipc.WithSchema(ReadingSchema))
+	if err := writer.Write(record); err != nil {
+		_ = writer.Close()
+		return fmt.Errorf("failed to write arrow record: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to close arrow writer: %w", err)
+	}
+	return nil
+}
+
+// ArrowRecordToReadings converts an Arrow record with ReadingSchema back into readings.
+//
+// Consecutive rows sharing an id are grouped into one reading with multiple
+// computed values. The conversion is lossy: quantities round-trip through
+// float64, and CreatedAt, MaxMeteredAt, and Version are not part of the schema.
+//
+// Returns error if the record is missing a column or a column has the wrong type.
+func ArrowRecordToReadings(record arrow.Record) ([]specs.MeterReadingSpec, error) {
+	ids, err := stringColumn(record, columnID)
+	if err != nil {
+		return nil, err
+	}
+	workspaceIDs, err := stringColumn(record, columnWorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	universeIDs, err := stringColumn(record, columnUniverseID)
+	if err != nil {
+		return nil, err
+	}
+	subjects, err := stringColumn(record, columnSubject)
+	if err != nil {
+		return nil, err
+	}
+	units, err := stringColumn(record, columnUnit)
+	if err != nil {
+		return nil, err
+	}
+	aggregations, err := stringColumn(record, columnAggregation)
+	if err != nil {
+		return nil, err
+	}
+	quantities, err := typedColumn[*array.Float64](record, columnQuantity)
+	if err != nil {
+		return nil, err
+	}
+	windowStarts, err := typedColumn[*array.Timestamp](record, columnWindowStart)
+	if err != nil {
+		return nil, err
+	}
+	windowEnds, err := typedColumn[*array.Timestamp](record, columnWindowEnd)
+	if err != nil {
+		return nil, err
+	}
+	recordCounts, err := typedColumn[*array.Int64](record, columnRecordCount)
+	if err != nil {
+		return nil, err
+	}
+
+	var readings []specs.MeterReadingSpec
+	for row := 0; row < int(record.NumRows()); row++ {
+		cv := specs.ComputedValueSpec{
+			Quantity:    strconv.FormatFloat(quantities.Value(row), 'f', -1, 64),
+			Unit:        units.Value(row),
+			Aggregation: aggregations.Value(row),
+		}
+
+		if n := len(readings); n > 0 && readings[n-1].ID == ids.Value(row) {
+			readings[n-1].ComputedValues = append(readings[n-1].ComputedValues, cv)
+			continue
+		}
+
+		readings = append(readings, specs.MeterReadingSpec{
+			ID:          ids.Value(row),
+			WorkspaceID: workspaceIDs.Value(row),
+			UniverseID:  universeIDs.Value(row),
+			Subject:     subjects.Value(row),
+			Window: specs.TimeWindowSpec{
+				Start: time.UnixMicro(int64(windowStarts.Value(row))).UTC(),
+				End:   time.UnixMicro(int64(windowEnds.Value(row))).UTC(),
+			},
+			ComputedValues: []specs.ComputedValueSpec{cv},
+			Aggregation:    cv.Aggregation,
+			RecordCount:    int(recordCounts.Value(row)),
+		})
+	}
+
+	return readings, nil
+}
+
+func stringColumn(record arrow.Record, name string) (*array.String, error) {
+	return typedColumn[*array.String](record, name)
+}
+
+func typedColumn[T arrow.Array](record arrow.Record, name string) (T, error) {
+	var zero T
+	indices := record.Schema().FieldIndices(name)
+	if len(indices) == 0 {
+		return zero, fmt.Errorf("missing column %q", name)
+	}
+	column, ok := record.Column(indices[0]).(T)
+	if !ok {
+		return zero, fmt.Errorf("column %q has unexpected type %s", name, record.Column(indices[0]).DataType())
+	}
+	return column, nil
+}
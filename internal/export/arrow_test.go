@@ -0,0 +1,97 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v17/arrow/ipc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chrisconley/metron/specs"
+)
+
+func newTestReadings() []specs.MeterReadingSpec {
+	window := specs.TimeWindowSpec{
+		Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+	return []specs.MeterReadingSpec{
+		{
+			ID:          "reading-1",
+			WorkspaceID: "workspace-prod",
+			UniverseID:  "production",
+			Subject:     "customer:acme",
+			Window:      window,
+			ComputedValues: []specs.ComputedValueSpec{
+				{Quantity: "1250", Unit: "input-tokens", Aggregation: "sum"},
+				{Quantity: "340.5", Unit: "output-tokens", Aggregation: "sum"},
+			},
+			Aggregation: "sum",
+			RecordCount: 12,
+		},
+		{
+			ID:             "reading-2",
+			WorkspaceID:    "workspace-prod",
+			UniverseID:     "production",
+			Subject:        "customer:globex",
+			Window:         window,
+			ComputedValues: []specs.ComputedValueSpec{{Quantity: "11.5", Unit: "seats", Aggregation: "time-weighted-avg"}},
+			Aggregation:    "time-weighted-avg",
+			RecordCount:    3,
+		},
+	}
+}
+
+func TestReadingsToArrowRecord(t *testing.T) {
+	t.Run("produces one row per computed value", func(t *testing.T) {
+		record, err := ReadingsToArrowRecord(newTestReadings())
+		require.NoError(t, err)
+		defer record.Release()
+
+		assert.Equal(t, int64(3), record.NumRows())
+		assert.True(t, record.Schema().Equal(ReadingSchema))
+	})
+
+	t.Run("round-trips through ArrowRecordToReadings", func(t *testing.T) {
+		readings := newTestReadings()
+		record, err := ReadingsToArrowRecord(readings)
+		require.NoError(t, err)
+		defer record.Release()
+
+		got, err := ArrowRecordToReadings(record)
+
+		require.NoError(t, err)
+		assert.Equal(t, readings, got)
+	})
+
+	t.Run("with invalid quantity returns error", func(t *testing.T) {
+		readings := newTestReadings()
+		readings[1].ComputedValues[0].Quantity = "not-a-number"
+
+		_, err := ReadingsToArrowRecord(readings)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "reading 1 computed value 0")
+	})
+}
+
+func TestReadingsToArrowIPC(t *testing.T) {
+	t.Run("writes a readable IPC stream", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := ReadingsToArrowIPC(newTestReadings(), &buf)
+		require.NoError(t, err)
+
+		reader, err := ipc.NewReader(&buf)
+		require.NoError(t, err)
+		defer reader.Release()
+
+		require.True(t, reader.Next())
+		got, err := ArrowRecordToReadings(reader.Record())
+		require.NoError(t, err)
+		assert.Len(t, got, 2)
+		assert.False(t, reader.Next())
+	})
+}
@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"fmt"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// WithPerWindowFreeTier returns aggregate with FreeQuantity set to the
+// "per-window" free quantity that metering declares for unit, so aggregation
+// subtracts it once from each window's value. Units are compared after
+// metering's unit aliases. aggregate is returned unchanged if no extraction of
+// unit has a per-window free tier.
+//
+// Returns error if metering is invalid, extractions of unit declare different
+// per-window free quantities, or aggregate already sets a different
+// FreeQuantity.
+func WithPerWindowFreeTier(aggregate specs.AggregateConfigSpec, metering specs.MeteringConfigSpec, unit string) (specs.AggregateConfigSpec, error) {
+	config, err := NewMeteringConfig(metering)
+	if err != nil {
+		return specs.AggregateConfigSpec{}, fmt.Errorf("invalid metering config: %w", err)
+	}
+
+	unit = NormalizeUnit(unit, config.UnitAliases())
+	var free *Decimal
+	for i, extraction := range config.Observations() {
+		freeTier := extraction.FreeTier()
+		if freeTier == nil || !freeTier.IsPerWindow() {
+			continue
+		}
+		if NormalizeUnit(extraction.Unit().ToString(), config.UnitAliases()) != unit {
+			continue
+		}
+		quantity := freeTier.Quantity()
+		if free != nil && free.Cmp(quantity) != 0 {
+			return specs.AggregateConfigSpec{}, fmt.Errorf("observation %d: per-window free quantity %s for unit %q differs from %s",
+				i, quantity, unit, *free)
+		}
+		free = &quantity
+	}
+	if free == nil {
+		return aggregate, nil
+	}
+
+	if aggregate.FreeQuantity != "" {
+		existing, err := NewFreeQuantity(aggregate.FreeQuantity)
+		if err != nil {
+			return specs.AggregateConfigSpec{}, err
+		}
+		if existing.Cmp(*free) != 0 {
+			return specs.AggregateConfigSpec{}, fmt.Errorf("aggregate free quantity %s conflicts with per-window free quantity %s for unit %q",
+				existing, *free, unit)
+		}
+	}
+	aggregate.FreeQuantity = free.String()
+	return aggregate, nil
+}
@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/internal/testutil"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPerWindowFreeTier(t *testing.T) {
+	metering := specs.MeteringConfigSpec{
+		Observations: []specs.ObservationExtractionSpec{
+			{SourceProperty: "tokens", Unit: "tk", FreeQuantity: "1000", FreeTierStrategy: "per-window"},
+			{SourceProperty: "requests", Unit: "api-calls", FreeQuantity: "1"},
+		},
+		UnitAliases: map[string]string{"tk": "tokens"},
+	}
+
+	t.Run("free quantity is subtracted once per window", func(t *testing.T) {
+		var records []specs.MeterRecordSpec
+		for _, id := range []string{"event-1", "event-2", "event-3"} {
+			payload := testutil.FixtureEventPayload(
+				testutil.WithPayloadID(id),
+				testutil.WithPayloadProperties(map[string]string{"tokens": "600"}),
+			)
+			metered, err := Meter(payload, specs.MeteringConfigSpec{Observations: metering.Observations[:1], UnitAliases: metering.UnitAliases})
+			require.NoError(t, err)
+			records = append(records, metered...)
+		}
+		require.Equal(t, "600", records[0].Observations[0].Quantity, "metering leaves per-window quantities unchanged")
+
+		config, err := WithPerWindowFreeTier(newTestAggregateConfig("sum"), metering, "tokens")
+		require.NoError(t, err)
+		reading, err := Aggregate(records, nil, config)
+
+		require.NoError(t, err)
+		assert.Equal(t, "1000", config.FreeQuantity)
+		assert.Equal(t, "800", reading.ComputedValues[0].Quantity)
+	})
+
+	t.Run("units without a per-window free tier are unchanged", func(t *testing.T) {
+		aggregate := newTestAggregateConfig("sum")
+
+		config, err := WithPerWindowFreeTier(aggregate, metering, "api-calls")
+
+		require.NoError(t, err)
+		assert.Equal(t, aggregate, config)
+	})
+
+	t.Run("conflicting aggregate free quantity returns error", func(t *testing.T) {
+		aggregate := newTestAggregateConfig("sum")
+		aggregate.FreeQuantity = "500"
+
+		_, err := WithPerWindowFreeTier(aggregate, metering, "tokens")
+
+		assert.ErrorContains(t, err, "conflicts with per-window free quantity")
+	})
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"time"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// AggregationHooks are callbacks invoked around an aggregation, for reporting
+// metrics such as records processed, latency, and failures. Nil hooks are
+// skipped. Hooks run synchronously on the aggregating goroutine, so they
+// should return quickly.
+type AggregationHooks struct {
+	// OnStart is called before aggregating, with the number of records passed
+	// in (not counting lastBeforeWindow).
+	OnStart func(config specs.AggregateConfigSpec, recordCount int)
+
+	// OnOutOfWindow is called with the records excluded as out of window,
+	// when there are any.
+	OnOutOfWindow func(warnings []OutOfWindowWarning)
+
+	// OnComplete is called with the reading and how long aggregation took.
+	OnComplete func(reading specs.MeterReadingSpec, duration time.Duration)
+
+	// OnError is called instead of OnComplete when aggregation fails.
+	OnError func(err error, config specs.AggregateConfigSpec)
+}
+
+// NoopAggregationHooks returns hooks that do nothing.
+func NoopAggregationHooks() AggregationHooks {
+	return AggregationHooks{}
+}
+
+// hooksClock reads the time for measuring aggregation duration.
+var hooksClock = time.Now
+
+// AggregateWithHooks is Aggregate, invoking hooks as it runs.
+//
+// A reading returned with an *InsufficientDataError still counts as
+// complete: OnComplete is called, and the error is returned as from Aggregate.
+func AggregateWithHooks(
+	records []specs.MeterRecordSpec,
+	last *specs.MeterRecordSpec,
+	config specs.AggregateConfigSpec,
+	hooks AggregationHooks,
+) (specs.MeterReadingSpec, error) {
+	if hooks.OnStart != nil {
+		hooks.OnStart(config, len(records))
+	}
+
+	started := hooksClock()
+	reading, warnings, err := AggregateWithWarnings(records, last, config)
+	duration := hooksClock().Sub(started)
+
+	if err != nil && !IsInsufficientDataError(err) {
+		if hooks.OnError != nil {
+			hooks.OnError(err, config)
+		}
+		return reading, err
+	}
+	if len(warnings) > 0 && hooks.OnOutOfWindow != nil {
+		hooks.OnOutOfWindow(warnings)
+	}
+	if hooks.OnComplete != nil {
+		hooks.OnComplete(reading, duration)
+	}
+	return reading, err
+}
@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"errors"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withHooksClock makes hooksClock return each of times in turn for the
+// duration of a test.
+func withHooksClock(t *testing.T, times ...time.Time) {
+	t.Helper()
+	previous := hooksClock
+	hooksClock = func() time.Time {
+		next := times[0]
+		times = times[1:]
+		return next
+	}
+	t.Cleanup(func() { hooksClock = previous })
+}
+
+func TestAggregateWithHooks(t *testing.T) {
+	records := []specs.MeterRecordSpec{
+		newTestRecordSpec("event-1", "100", "tokens", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+		newTestRecordSpec("event-2", "200", "tokens", time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)),
+	}
+
+	t.Run("OnStart called with record count", func(t *testing.T) {
+		var started int
+		hooks := AggregationHooks{OnStart: func(_ specs.AggregateConfigSpec, recordCount int) { started = recordCount }}
+
+		_, err := AggregateWithHooks(records, nil, newTestAggregateConfig("sum"), hooks)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, started)
+	})
+
+	t.Run("OnComplete called with reading and duration", func(t *testing.T) {
+		start := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+		withHooksClock(t, start, start.Add(250*time.Millisecond))
+		var completed specs.MeterReadingSpec
+		var took time.Duration
+		hooks := AggregationHooks{OnComplete: func(reading specs.MeterReadingSpec, duration time.Duration) {
+			completed, took = reading, duration
+		}}
+
+		reading, err := AggregateWithHooks(records, nil, newTestAggregateConfig("sum"), hooks)
+
+		require.NoError(t, err)
+		assert.Equal(t, 250*time.Millisecond, took)
+		assert.Equal(t, reading.ID, completed.ID)
+	})
+
+	t.Run("OnError called on failure", func(t *testing.T) {
+		var failed error
+		completed := false
+		hooks := AggregationHooks{
+			OnError:    func(err error, _ specs.AggregateConfigSpec) { failed = err },
+			OnComplete: func(specs.MeterReadingSpec, time.Duration) { completed = true },
+		}
+		mixed := append(records, newTestRecordSpec("event-3", "1", "requests", time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC)))
+
+		_, err := AggregateWithHooks(mixed, nil, newTestAggregateConfig("sum"), hooks)
+
+		require.Error(t, err)
+		assert.True(t, errors.Is(failed, ErrMixedUnits))
+		assert.False(t, completed)
+	})
+
+	t.Run("OnOutOfWindow called with excluded records", func(t *testing.T) {
+		var excluded []OutOfWindowWarning
+		hooks := AggregationHooks{OnOutOfWindow: func(warnings []OutOfWindowWarning) { excluded = warnings }}
+		late := append(records, newTestRecordSpec("event-late", "1", "tokens", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)))
+
+		_, err := AggregateWithHooks(late, nil, newTestAggregateConfig("sum"), hooks)
+
+		require.NoError(t, err)
+		require.Len(t, excluded, 1)
+		assert.Equal(t, "event-late", excluded[0].RecordID)
+	})
+
+	t.Run("noop hooks do not panic", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			_, _ = AggregateWithHooks(records, nil, newTestAggregateConfig("sum"), NoopAggregationHooks())
+			_, _ = AggregateWithHooks(nil, nil, newTestAggregateConfig("sum"), NoopAggregationHooks())
+		})
+	})
+}
@@ -1,5 +1,12 @@
 package infra
 
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
 // EventType represents the type of event in the system
 type EventType int
 
@@ -34,12 +41,167 @@ func (et EventType) String() string {
 
 type Event interface{ EventType() EventType }
 type Handler func(Event)
-type Bus struct{ subs map[EventType][]Handler }
 
-func NewBus() *Bus { return &Bus{subs: map[EventType][]Handler{}} }
+// SubscriptionToken identifies a subscription so it can be removed with Unsubscribe.
+type SubscriptionToken struct {
+	eventType EventType
+	id        uint64
+}
+
+type subscription struct {
+	id      uint64
+	handler Handler
+}
+
+// Bus dispatches events synchronously to subscribed handlers. It is safe for
+// concurrent use; handlers may publish, subscribe, or unsubscribe.
+type Bus struct {
+	*busState
+	observer BusObserver
+}
+
+// busState holds the subscriptions and counters shared by a Bus and the
+// buses derived from it with WithObserver.
+type busState struct {
+	mu        sync.RWMutex
+	nextID    uint64
+	subs      map[EventType][]subscription
+	published atomic.Int64
+}
+
+// BusStats is a snapshot of a Bus's subscriptions and activity, for debugging.
+type BusStats struct {
+	TotalSubscriptions int
+	EventTypeCount     int   // Event types with at least one subscriber
+	PublishedCount     int64 // Events published since the bus was created
+}
+
+func NewBus() *Bus { return &Bus{busState: &busState{subs: map[EventType][]subscription{}}} }
+
+// WithObserver returns a Bus that reports its dispatches to observer. The
+// returned Bus shares subscriptions and stats with b: subscribing through
+// either affects both, but only events published through the returned Bus
+// are observed.
+func (b *Bus) WithObserver(observer BusObserver) *Bus {
+	return &Bus{busState: b.busState, observer: observer}
+}
+
+// Publish calls each handler subscribed to the event's type, in subscription
+// order. Subscription changes made by handlers apply to later publishes.
 func (b *Bus) Publish(e Event) {
-	for _, h := range b.subs[e.EventType()] {
-		h(e)
+	b.published.Add(1)
+	b.mu.RLock()
+	subs := b.subs[e.EventType()]
+	b.mu.RUnlock()
+	if b.observer == nil {
+		for _, s := range subs {
+			s.handler(e)
+		}
+		return
+	}
+
+	b.observer.OnPublish(e.EventType(), len(subs))
+	for _, s := range subs {
+		b.dispatchObserved(s.handler, e)
+	}
+}
+
+// dispatchObserved calls h, reporting its latency or panic to the observer.
+// A panic is re-raised after it is reported, as it would be without an
+// observer.
+func (b *Bus) dispatchObserved(h Handler, e Event) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			b.observer.OnHandlerPanic(e.EventType(), r)
+			panic(r)
+		}
+	}()
+	h(e)
+	b.observer.OnHandlerComplete(e.EventType(), time.Since(start))
+}
+
+func (b *Bus) Subscribe(evt EventType, h Handler) SubscriptionToken {
+	return b.subscribe(evt, func(SubscriptionToken) Handler { return h })
+}
+
+// SubscribeOnce subscribes a handler that is called for at most one event and
+// then unsubscribed. Concurrent publishes call it only once. The returned token
+// can unsubscribe it before it fires.
+func (b *Bus) SubscribeOnce(evt EventType, h Handler) SubscriptionToken {
+	return b.subscribe(evt, func(token SubscriptionToken) Handler {
+		var once sync.Once
+		return func(e Event) {
+			once.Do(func() {
+				b.Unsubscribe(token)
+				h(e)
+			})
+		}
+	})
+}
+
+// subscribe registers the handler built for the new subscription's token.
+func (b *Bus) subscribe(evt EventType, build func(SubscriptionToken) Handler) SubscriptionToken {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	token := SubscriptionToken{eventType: evt, id: b.nextID}
+	// Copy on write so in-flight publishes keep iterating their snapshot
+	subs := make([]subscription, len(b.subs[evt]), len(b.subs[evt])+1)
+	copy(subs, b.subs[evt])
+	b.subs[evt] = append(subs, subscription{id: token.id, handler: build(token)})
+	return token
+}
+
+// Unsubscribe removes the subscription, returning false if it was already removed.
+func (b *Bus) Unsubscribe(token SubscriptionToken) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	subs := b.subs[token.eventType]
+	for i, s := range subs {
+		if s.id == token.id {
+			if len(subs) == 1 {
+				delete(b.subs, token.eventType)
+				return true
+			}
+			remaining := make([]subscription, 0, len(subs)-1)
+			remaining = append(remaining, subs[:i]...)
+			b.subs[token.eventType] = append(remaining, subs[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Topics returns the event types with at least one subscriber, in ascending order.
+func (b *Bus) Topics() []EventType {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	topics := make([]EventType, 0, len(b.subs))
+	for evt := range b.subs {
+		topics = append(topics, evt)
+	}
+	sort.Slice(topics, func(i, j int) bool { return topics[i] < topics[j] })
+	return topics
+}
+
+// SubscriberCount returns the number of handlers subscribed to the event type.
+func (b *Bus) SubscriberCount(evt EventType) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs[evt])
+}
+
+// Stats returns a snapshot of the bus's subscriptions and published count.
+func (b *Bus) Stats() BusStats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	stats := BusStats{
+		EventTypeCount: len(b.subs),
+		PublishedCount: b.published.Load(),
+	}
+	for _, subs := range b.subs {
+		stats.TotalSubscriptions += len(subs)
 	}
+	return stats
 }
-func (b *Bus) Subscribe(evt EventType, h Handler) { b.subs[evt] = append(b.subs[evt], h) }
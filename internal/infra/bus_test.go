@@ -1,6 +1,8 @@
 package infra
 
 import (
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -92,3 +94,141 @@ func TestBusWithEnumEventTypes(t *testing.T) {
 		assert.Equal(t, MeterRead, readEvents[0].EventType())
 	})
 }
+
+func TestBusSubscribeOnce(t *testing.T) {
+	t.Run("handler fires exactly once", func(t *testing.T) {
+		// Arrange
+		bus := NewBus()
+		var calls []Event
+		bus.SubscribeOnce(MeterRecorded, func(e Event) {
+			calls = append(calls, e)
+		})
+
+		// Act
+		bus.Publish(TestMeterRecordedEvent{MeterID: "meter-1"})
+		bus.Publish(TestMeterRecordedEvent{MeterID: "meter-2"})
+
+		// Assert
+		assert.Len(t, calls, 1)
+		assert.Equal(t, "meter-1", calls[0].(TestMeterRecordedEvent).MeterID)
+	})
+
+	t.Run("token unsubscribes before the first event", func(t *testing.T) {
+		// Arrange
+		bus := NewBus()
+		called := false
+		token := bus.SubscribeOnce(MeterRecorded, func(e Event) {
+			called = true
+		})
+
+		// Act
+		removed := bus.Unsubscribe(token)
+		bus.Publish(TestMeterRecordedEvent{MeterID: "meter-1"})
+
+		// Assert
+		assert.True(t, removed)
+		assert.False(t, called)
+		assert.False(t, bus.Unsubscribe(token))
+	})
+
+	t.Run("concurrent publishes fire the handler at most once", func(t *testing.T) {
+		// Arrange
+		bus := NewBus()
+		var calls atomic.Int32
+		bus.SubscribeOnce(MeterRecorded, func(e Event) {
+			calls.Add(1)
+		})
+
+		// Act
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				bus.Publish(TestMeterRecordedEvent{MeterID: "meter-1"})
+			}()
+		}
+		wg.Wait()
+
+		// Assert
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("other subscribers keep receiving events", func(t *testing.T) {
+		// Arrange
+		bus := NewBus()
+		var persistent int
+		bus.Subscribe(MeterRecorded, func(e Event) { persistent++ })
+		bus.SubscribeOnce(MeterRecorded, func(e Event) {})
+
+		// Act
+		bus.Publish(TestMeterRecordedEvent{MeterID: "meter-1"})
+		bus.Publish(TestMeterRecordedEvent{MeterID: "meter-2"})
+
+		// Assert
+		assert.Equal(t, 2, persistent)
+	})
+}
+
+func TestBusIntrospection(t *testing.T) {
+	t.Run("empty bus has no topics", func(t *testing.T) {
+		// Arrange
+		bus := NewBus()
+
+		// Act & Assert
+		assert.Empty(t, bus.Topics())
+		assert.Equal(t, 0, bus.SubscriberCount(MeterRecorded))
+		assert.Equal(t, BusStats{}, bus.Stats())
+	})
+
+	t.Run("subscribing adds topic", func(t *testing.T) {
+		// Arrange
+		bus := NewBus()
+
+		// Act
+		bus.Subscribe(MeterRead, func(e Event) {})
+		bus.Subscribe(MeterRecorded, func(e Event) {})
+		bus.Subscribe(MeterRecorded, func(e Event) {})
+
+		// Assert
+		assert.Equal(t, []EventType{MeterRecorded, MeterRead}, bus.Topics())
+		assert.Equal(t, 2, bus.SubscriberCount(MeterRecorded))
+		assert.Equal(t, BusStats{TotalSubscriptions: 3, EventTypeCount: 2}, bus.Stats())
+	})
+
+	t.Run("unsubscribing removes topic when count drops to zero", func(t *testing.T) {
+		// Arrange
+		bus := NewBus()
+		first := bus.Subscribe(MeterRecorded, func(e Event) {})
+		second := bus.Subscribe(MeterRecorded, func(e Event) {})
+
+		// Act & Assert
+		bus.Unsubscribe(first)
+		assert.Equal(t, []EventType{MeterRecorded}, bus.Topics())
+		assert.Equal(t, 1, bus.SubscriberCount(MeterRecorded))
+
+		bus.Unsubscribe(second)
+		assert.Empty(t, bus.Topics())
+		assert.Equal(t, 0, bus.Stats().EventTypeCount)
+	})
+
+	t.Run("concurrent publishes increment published count", func(t *testing.T) {
+		// Arrange
+		bus := NewBus()
+		bus.Subscribe(MeterRecorded, func(e Event) {})
+
+		// Act
+		var wg sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				bus.Publish(TestMeterRecordedEvent{MeterID: "meter-1"})
+			}()
+		}
+		wg.Wait()
+
+		// Assert
+		assert.Equal(t, int64(100), bus.Stats().PublishedCount)
+	})
+}
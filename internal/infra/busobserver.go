@@ -0,0 +1,108 @@
+package infra
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// BusObserver is notified of a Bus's dispatches, for metrics. Methods are
+// called synchronously from Publish, so they should return quickly, and
+// concurrently when events are published concurrently.
+type BusObserver interface {
+	// OnPublish is called before an event is dispatched to its handlerCount
+	// handlers.
+	OnPublish(eventType EventType, handlerCount int)
+	// OnHandlerComplete is called after each handler returns.
+	OnHandlerComplete(eventType EventType, duration time.Duration)
+	// OnHandlerPanic is called with the recovered value when a handler
+	// panics, before the panic continues.
+	OnHandlerPanic(eventType EventType, err interface{})
+}
+
+// histogramWindowSize is how many recent handler latencies are kept per event type.
+const histogramWindowSize = 1000
+
+// LatencyStats summarizes recent handler latencies for one event type.
+type LatencyStats struct {
+	Count int // Samples the stats are computed from, at most 1000
+	Min   time.Duration
+	Max   time.Duration
+	Mean  time.Duration
+	P95   time.Duration
+}
+
+// HistogramBusObserver is a BusObserver that tracks handler latency per event
+// type over a rolling window of the last 1000 handler calls. It is safe for
+// concurrent use.
+type HistogramBusObserver struct {
+	mu      sync.Mutex
+	samples map[EventType]*latencyWindow
+}
+
+// latencyWindow is a ring buffer of the most recent latencies.
+type latencyWindow struct {
+	durations []time.Duration
+	next      int
+}
+
+func NewHistogramBusObserver() *HistogramBusObserver {
+	return &HistogramBusObserver{samples: map[EventType]*latencyWindow{}}
+}
+
+// OnPublish does nothing; only handler latencies are tracked.
+func (o *HistogramBusObserver) OnPublish(EventType, int) {}
+
+// OnHandlerComplete records the handler's latency, evicting the oldest
+// sample once the window is full.
+func (o *HistogramBusObserver) OnHandlerComplete(eventType EventType, duration time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	w, ok := o.samples[eventType]
+	if !ok {
+		w = &latencyWindow{durations: make([]time.Duration, 0, histogramWindowSize)}
+		o.samples[eventType] = w
+	}
+	if len(w.durations) < histogramWindowSize {
+		w.durations = append(w.durations, duration)
+		return
+	}
+	w.durations[w.next] = duration
+	w.next = (w.next + 1) % histogramWindowSize
+}
+
+// OnHandlerPanic does nothing; panicking handlers have no latency to record.
+func (o *HistogramBusObserver) OnHandlerPanic(EventType, interface{}) {}
+
+// Report returns latency stats for each event type with at least one
+// completed handler call.
+func (o *HistogramBusObserver) Report() map[EventType]LatencyStats {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	report := make(map[EventType]LatencyStats, len(o.samples))
+	for eventType, w := range o.samples {
+		report[eventType] = newLatencyStats(w.durations)
+	}
+	return report
+}
+
+// newLatencyStats computes stats over durations, which must not be empty.
+// P95 uses the nearest-rank method.
+func newLatencyStats(durations []time.Duration) LatencyStats {
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	rank := (len(sorted)*95 + 99) / 100
+	return LatencyStats{
+		Count: len(sorted),
+		Min:   sorted[0],
+		Max:   sorted[len(sorted)-1],
+		Mean:  total / time.Duration(len(sorted)),
+		P95:   sorted[rank-1],
+	}
+}
@@ -0,0 +1,138 @@
+package infra
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingObserver records every BusObserver call.
+type recordingObserver struct {
+	mu        sync.Mutex
+	publishes []int
+	completes []EventType
+	panics    []interface{}
+}
+
+func (o *recordingObserver) OnPublish(_ EventType, handlerCount int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.publishes = append(o.publishes, handlerCount)
+}
+
+func (o *recordingObserver) OnHandlerComplete(eventType EventType, _ time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.completes = append(o.completes, eventType)
+}
+
+func (o *recordingObserver) OnHandlerPanic(_ EventType, err interface{}) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.panics = append(o.panics, err)
+}
+
+func TestBusWithObserver(t *testing.T) {
+	t.Run("observer is called for each handler invocation", func(t *testing.T) {
+		observer := &recordingObserver{}
+		bus := NewBus().WithObserver(observer)
+		bus.Subscribe(MeterRecorded, func(Event) {})
+
+		bus.Publish(TestMeterRecordedEvent{MeterID: "meter-1"})
+		bus.Publish(TestMeterRecordedEvent{MeterID: "meter-2"})
+
+		assert.Equal(t, []int{1, 1}, observer.publishes)
+		assert.Equal(t, []EventType{MeterRecorded, MeterRecorded}, observer.completes)
+	})
+
+	t.Run("multiple handlers for same event type each produce a call", func(t *testing.T) {
+		observer := &recordingObserver{}
+		bus := NewBus().WithObserver(observer)
+		for range 3 {
+			bus.Subscribe(MeterRead, func(Event) {})
+		}
+
+		bus.Publish(TestMeterReadEvent{MeterID: "meter-1"})
+
+		assert.Equal(t, []int{3}, observer.publishes)
+		assert.Len(t, observer.completes, 3)
+	})
+
+	t.Run("panic in handler calls OnHandlerPanic", func(t *testing.T) {
+		observer := &recordingObserver{}
+		bus := NewBus().WithObserver(observer)
+		bus.Subscribe(MeterRecorded, func(Event) { panic("boom") })
+
+		assert.PanicsWithValue(t, "boom", func() {
+			bus.Publish(TestMeterRecordedEvent{MeterID: "meter-1"})
+		})
+
+		assert.Equal(t, []interface{}{"boom"}, observer.panics)
+		assert.Empty(t, observer.completes)
+	})
+
+	t.Run("shares subscriptions with the original bus", func(t *testing.T) {
+		observer := &recordingObserver{}
+		original := NewBus()
+		observed := original.WithObserver(observer)
+		calls := 0
+		original.Subscribe(MeterRecorded, func(Event) { calls++ })
+
+		observed.Publish(TestMeterRecordedEvent{MeterID: "meter-1"})
+		original.Publish(TestMeterRecordedEvent{MeterID: "meter-2"})
+
+		assert.Equal(t, 2, calls)
+		assert.Len(t, observer.completes, 1, "only the observed bus reports")
+		assert.Equal(t, int64(2), original.Stats().PublishedCount)
+	})
+}
+
+func TestHistogramBusObserver(t *testing.T) {
+	t.Run("latency stats update correctly", func(t *testing.T) {
+		observer := NewHistogramBusObserver()
+		for i := 1; i <= 100; i++ {
+			observer.OnHandlerComplete(MeterRecorded, time.Duration(i)*time.Millisecond)
+		}
+		observer.OnHandlerComplete(MeterRead, 7*time.Millisecond)
+
+		report := observer.Report()
+
+		require.Contains(t, report, MeterRecorded)
+		assert.Equal(t, LatencyStats{
+			Count: 100,
+			Min:   time.Millisecond,
+			Max:   100 * time.Millisecond,
+			Mean:  50500 * time.Microsecond,
+			P95:   95 * time.Millisecond,
+		}, report[MeterRecorded])
+		assert.Equal(t, 7*time.Millisecond, report[MeterRead].P95)
+	})
+
+	t.Run("keeps only the last 1000 samples", func(t *testing.T) {
+		observer := NewHistogramBusObserver()
+		observer.OnHandlerComplete(MeterRecorded, time.Hour)
+		for range 1000 {
+			observer.OnHandlerComplete(MeterRecorded, time.Millisecond)
+		}
+
+		stats := observer.Report()[MeterRecorded]
+
+		assert.Equal(t, 1000, stats.Count)
+		assert.Equal(t, time.Millisecond, stats.Max)
+	})
+
+	t.Run("records latency of published events", func(t *testing.T) {
+		observer := NewHistogramBusObserver()
+		bus := NewBus().WithObserver(observer)
+		bus.Subscribe(MeterRecorded, func(Event) { time.Sleep(time.Millisecond) })
+
+		bus.Publish(TestMeterRecordedEvent{MeterID: "meter-1"})
+
+		stats := observer.Report()[MeterRecorded]
+		assert.Equal(t, 1, stats.Count)
+		assert.GreaterOrEqual(t, stats.Min, time.Millisecond)
+	})
+}
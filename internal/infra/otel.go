@@ -0,0 +1,167 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+
+	"github.com/chrisconley/metron/specs"
+)
+
+// readingKey identifies one reported series. Subject identity is scoped to the
+// workspace and universe, so both are part of the key.
+type readingKey struct {
+	workspaceID string
+	universeID  string
+	subject     string
+	unit        string
+	aggregation string
+}
+
+// ReadingObserver reports the latest value of each reading series as an
+// OpenTelemetry observable gauge, so metering data can be scraped (e.g. by
+// Prometheus) without a separate export step.
+//
+// All series are reported by one gauge named "metron.reading". Each
+// observation carries workspace_id, universe_id, subject, unit, and
+// aggregation attributes. Units are attributes rather than part of the
+// instrument name, since arbitrary unit strings are not valid instrument
+// names.
+type ReadingObserver struct {
+	gauge        otelmetric.Float64ObservableGauge
+	registration otelmetric.Registration
+
+	mu     sync.Mutex
+	latest map[readingKey]float64
+	err    error
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// NewReadingObserver starts consuming readings in the background. Consumption
+// ends when readings is closed or Shutdown is called.
+func NewReadingObserver(meter otelmetric.Meter, readings <-chan specs.MeterReadingSpec) (*ReadingObserver, error) {
+	if meter == nil {
+		return nil, fmt.Errorf("meter is required")
+	}
+	if readings == nil {
+		return nil, fmt.Errorf("readings channel is required")
+	}
+
+	o := &ReadingObserver{
+		latest:  make(map[readingKey]float64),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	gauge, err := meter.Float64ObservableGauge(
+		"metron.reading",
+		otelmetric.WithDescription("Latest metron meter reading value"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gauge: %w", err)
+	}
+	// Registered once, without holding o.mu: the SDK holds its own lock while
+	// running the callback, which then takes o.mu
+	registration, err := meter.RegisterCallback(o.observe, gauge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register callback: %w", err)
+	}
+	o.gauge, o.registration = gauge, registration
+
+	go o.run(readings)
+	return o, nil
+}
+
+func (o *ReadingObserver) run(readings <-chan specs.MeterReadingSpec) {
+	defer close(o.stopped)
+	for {
+		select {
+		case <-o.done:
+			return
+		case reading, ok := <-readings:
+			if !ok {
+				return
+			}
+			o.record(reading)
+		}
+	}
+}
+
+// record stores the reading's values. Values that cannot be parsed are
+// skipped; the first such error is returned from Shutdown.
+func (o *ReadingObserver) record(reading specs.MeterReadingSpec) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, cv := range reading.ComputedValues {
+		quantity, err := strconv.ParseFloat(cv.Quantity, 64)
+		if err != nil {
+			o.setErr(fmt.Errorf("reading %s: invalid quantity %q: %w", reading.ID, cv.Quantity, err))
+			continue
+		}
+
+		key := readingKey{
+			workspaceID: reading.WorkspaceID,
+			universeID:  reading.UniverseID,
+			subject:     reading.Subject,
+			unit:        cv.Unit,
+			aggregation: cv.Aggregation,
+		}
+		o.latest[key] = quantity
+	}
+}
+
+// observe reports the latest value of every series.
+func (o *ReadingObserver) observe(_ context.Context, observer otelmetric.Observer) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for key, value := range o.latest {
+		observer.ObserveFloat64(o.gauge, value, otelmetric.WithAttributes(
+			attribute.String("workspace_id", key.workspaceID),
+			attribute.String("universe_id", key.universeID),
+			attribute.String("subject", key.subject),
+			attribute.String("unit", key.unit),
+			attribute.String("aggregation", key.aggregation),
+		))
+	}
+	return nil
+}
+
+func (o *ReadingObserver) setErr(err error) {
+	if o.err == nil {
+		o.err = err
+	}
+}
+
+// Shutdown stops consuming readings and unregisters the gauge callback.
+// Returns the first error encountered while recording readings, or ctx's
+// error if consumption does not stop before ctx is done.
+func (o *ReadingObserver) Shutdown(ctx context.Context) error {
+	select {
+	case <-o.done:
+	default:
+		close(o.done)
+	}
+
+	select {
+	case <-o.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	// Unregister without holding o.mu, which the callback takes
+	unregisterErr := o.registration.Unregister()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if unregisterErr != nil {
+		o.setErr(fmt.Errorf("failed to unregister callback: %w", unregisterErr))
+	}
+	return o.err
+}
@@ -0,0 +1,155 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/chrisconley/metron/specs"
+)
+
+func newTestReading(subject, quantity, unit, aggregation string) specs.MeterReadingSpec {
+	return specs.MeterReadingSpec{
+		ID:          "reading-" + subject,
+		WorkspaceID: "workspace-prod",
+		UniverseID:  "production",
+		Subject:     subject,
+		Window: specs.TimeWindowSpec{
+			Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		ComputedValues: []specs.ComputedValueSpec{{Quantity: quantity, Unit: unit, Aggregation: aggregation}},
+		Aggregation:    aggregation,
+	}
+}
+
+// collectGauges returns gauge values by unit attribute and subject.
+func collectGauges(t *testing.T, reader *sdkmetric.ManualReader) map[string]map[string]float64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	values := map[string]map[string]float64{}
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			gauge, ok := m.Data.(metricdata.Gauge[float64])
+			if !ok {
+				continue
+			}
+			assert.Equal(t, "metron.reading", m.Name)
+			for _, point := range gauge.DataPoints {
+				unit, _ := point.Attributes.Value(attribute.Key("unit"))
+				subject, _ := point.Attributes.Value(attribute.Key("subject"))
+				if values[unit.AsString()] == nil {
+					values[unit.AsString()] = map[string]float64{}
+				}
+				values[unit.AsString()][subject.AsString()] = point.Value
+			}
+		}
+	}
+	return values
+}
+
+func TestReadingObserver(t *testing.T) {
+	t.Run("reports latest reading value per series", func(t *testing.T) {
+		// Arrange
+		reader := sdkmetric.NewManualReader()
+		provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+		readings := make(chan specs.MeterReadingSpec)
+
+		observer, err := NewReadingObserver(provider.Meter("metron-test"), readings)
+		require.NoError(t, err)
+
+		// Act
+		readings <- newTestReading("customer:acme", "100", "api-calls", "sum")
+		readings <- newTestReading("customer:acme", "250", "api-calls", "sum")
+		readings <- newTestReading("customer:globex", "42", "api-calls", "sum")
+		readings <- newTestReading("customer:acme", "12.5", "seats", "time-weighted-avg")
+
+		// Assert
+		assert.Eventually(t, func() bool {
+			values := collectGauges(t, reader)
+			return values["api-calls"]["customer:acme"] == 250 &&
+				values["api-calls"]["customer:globex"] == 42 &&
+				values["seats"]["customer:acme"] == 12.5
+		}, time.Second, 10*time.Millisecond)
+
+		require.NoError(t, observer.Shutdown(context.Background()))
+	})
+
+	t.Run("stops reporting after shutdown", func(t *testing.T) {
+		reader := sdkmetric.NewManualReader()
+		provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+		readings := make(chan specs.MeterReadingSpec)
+
+		observer, err := NewReadingObserver(provider.Meter("metron-test"), readings)
+		require.NoError(t, err)
+		readings <- newTestReading("customer:acme", "100", "api-calls", "sum")
+
+		require.NoError(t, observer.Shutdown(context.Background()))
+
+		assert.Empty(t, collectGauges(t, reader)["api-calls"])
+	})
+
+	t.Run("shutdown returns error for invalid quantity", func(t *testing.T) {
+		reader := sdkmetric.NewManualReader()
+		provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+		readings := make(chan specs.MeterReadingSpec)
+
+		observer, err := NewReadingObserver(provider.Meter("metron-test"), readings)
+		require.NoError(t, err)
+		readings <- newTestReading("customer:acme", "not-a-number", "api-calls", "sum")
+		close(readings)
+
+		err = observer.Shutdown(context.Background())
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid quantity")
+	})
+
+	t.Run("units that are not valid instrument names are reported", func(t *testing.T) {
+		reader := sdkmetric.NewManualReader()
+		provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+		readings := make(chan specs.MeterReadingSpec)
+
+		observer, err := NewReadingObserver(provider.Meter("metron-test"), readings)
+		require.NoError(t, err)
+		readings <- newTestReading("customer:acme", "3", "GB hours (eu/west)", "sum")
+
+		assert.Eventually(t, func() bool {
+			return collectGauges(t, reader)["GB hours (eu/west)"]["customer:acme"] == 3
+		}, time.Second, 10*time.Millisecond)
+		require.NoError(t, observer.Shutdown(context.Background()))
+	})
+
+	t.Run("concurrent readings and collection do not deadlock", func(t *testing.T) {
+		reader := sdkmetric.NewManualReader()
+		provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+		readings := make(chan specs.MeterReadingSpec)
+
+		observer, err := NewReadingObserver(provider.Meter("metron-test"), readings)
+		require.NoError(t, err)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 200; i++ {
+				readings <- newTestReading("customer:acme", "1", fmt.Sprintf("unit-%d", i), "sum")
+			}
+		}()
+		for i := 0; i < 200; i++ {
+			var rm metricdata.ResourceMetrics
+			require.NoError(t, reader.Collect(context.Background(), &rm))
+		}
+		<-done
+
+		require.NoError(t, observer.Shutdown(context.Background()))
+	})
+}
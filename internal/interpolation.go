@@ -0,0 +1,211 @@
+package internal
+
+import (
+	"fmt"
+	specs "github.com/chrisconley/metron/specs"
+	"time"
+)
+
+// InterpolateReadings fills gaps in a sequence of readings so the result has
+// exactly one reading per expected window.
+//
+// readings must belong to a single series (same workspace, universe, subject,
+// units, and aggregation). A reading fills an expected window when its window
+// matches exactly; readings outside expectedWindows are ignored. expectedWindows
+// must be in chronological order.
+//
+// Interpolation strategies for windows with no reading:
+//   - "zero": insert a reading with zero quantities
+//   - "carry-forward": duplicate the previous reading's quantities
+//   - "linear": interpolate each quantity between the surrounding readings,
+//     weighted by window start time
+//
+// Interpolated readings get a deterministic ID for their window, a RecordCount
+// of zero, and the MaxMeteredAt of the reading they were derived from.
+//
+// Returns error if the strategy is unknown, no reading matches any expected
+// window, a gap has no previous reading with "carry-forward", or a gap is at
+// the start or end of the sequence with "linear".
+func InterpolateReadings(
+	readings []specs.MeterReadingSpec,
+	expectedWindows []specs.TimeWindowSpec,
+	interpolation string,
+) ([]specs.MeterReadingSpec, error) {
+	switch interpolation {
+	case "zero", "carry-forward", "linear":
+	default:
+		return nil, fmt.Errorf("invalid interpolation: %q", interpolation)
+	}
+
+	// Match readings to expected windows
+	known := make([]*specs.MeterReadingSpec, len(expectedWindows))
+	var anyKnown bool
+	for i, window := range expectedWindows {
+		for j := range readings {
+			if sameWindow(readings[j].Window, window) {
+				known[i] = &readings[j]
+				anyKnown = true
+				break
+			}
+		}
+	}
+	if !anyKnown {
+		return nil, fmt.Errorf("cannot interpolate: no reading matches any expected window")
+	}
+
+	result := make([]specs.MeterReadingSpec, len(expectedWindows))
+	for i, window := range expectedWindows {
+		if known[i] != nil {
+			result[i] = *known[i]
+			continue
+		}
+
+		prev := previousKnown(known, i)
+		next := nextKnown(known, i)
+
+		var filled specs.MeterReadingSpec
+		var err error
+		switch interpolation {
+		case "zero":
+			template := prev
+			if template == nil {
+				template = next
+			}
+			filled, err = zeroReading(*template, window)
+
+		case "carry-forward":
+			if prev == nil {
+				return nil, fmt.Errorf("cannot carry forward into window %d: no previous reading", i)
+			}
+			filled, err = gapReading(*prev, window, prev.ComputedValues)
+
+		case "linear":
+			if prev == nil || next == nil {
+				return nil, fmt.Errorf("cannot linearly interpolate window %d: gap at start or end of sequence", i)
+			}
+			filled, err = linearReading(*prev, *next, window)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("window %d: %w", i, err)
+		}
+		result[i] = filled
+	}
+
+	return result, nil
+}
+
+func sameWindow(a, b specs.TimeWindowSpec) bool {
+	return a.Start.Equal(b.Start) && a.End.Equal(b.End)
+}
+
+func previousKnown(known []*specs.MeterReadingSpec, i int) *specs.MeterReadingSpec {
+	for j := i - 1; j >= 0; j-- {
+		if known[j] != nil {
+			return known[j]
+		}
+	}
+	return nil
+}
+
+func nextKnown(known []*specs.MeterReadingSpec, i int) *specs.MeterReadingSpec {
+	for j := i + 1; j < len(known); j++ {
+		if known[j] != nil {
+			return known[j]
+		}
+	}
+	return nil
+}
+
+// zeroReading builds a gap reading from template with every quantity set to zero.
+func zeroReading(template specs.MeterReadingSpec, window specs.TimeWindowSpec) (specs.MeterReadingSpec, error) {
+	values := make([]specs.ComputedValueSpec, len(template.ComputedValues))
+	for i, cv := range template.ComputedValues {
+		values[i] = specs.ComputedValueSpec{Quantity: "0", Unit: cv.Unit, Aggregation: cv.Aggregation}
+	}
+	return gapReading(template, window, values)
+}
+
+// linearReading builds a gap reading whose quantities lie on the line between
+// prev and next, positioned by window start time.
+func linearReading(prev, next specs.MeterReadingSpec, window specs.TimeWindowSpec) (specs.MeterReadingSpec, error) {
+	if len(prev.ComputedValues) != len(next.ComputedValues) {
+		return specs.MeterReadingSpec{}, fmt.Errorf("surrounding readings have different computed values")
+	}
+
+	span := NewDecimalFromInt64(int64(next.Window.Start.Sub(prev.Window.Start)))
+	elapsed := NewDecimalFromInt64(int64(window.Start.Sub(prev.Window.Start)))
+	fraction := elapsed.Div(span)
+
+	values := make([]specs.ComputedValueSpec, len(prev.ComputedValues))
+	for i, prevValue := range prev.ComputedValues {
+		nextValue := next.ComputedValues[i]
+		if prevValue.Unit != nextValue.Unit {
+			return specs.MeterReadingSpec{}, fmt.Errorf("unit mismatch: %q vs %q", prevValue.Unit, nextValue.Unit)
+		}
+
+		from, err := NewDecimal(prevValue.Quantity)
+		if err != nil {
+			return specs.MeterReadingSpec{}, fmt.Errorf("invalid previous quantity: %w", err)
+		}
+		to, err := NewDecimal(nextValue.Quantity)
+		if err != nil {
+			return specs.MeterReadingSpec{}, fmt.Errorf("invalid next quantity: %w", err)
+		}
+
+		quantity := from.Add(to.Sub(from).Mul(fraction))
+		values[i] = specs.ComputedValueSpec{Quantity: quantity.String(), Unit: prevValue.Unit, Aggregation: prevValue.Aggregation}
+	}
+
+	return gapReading(prev, window, values)
+}
+
+// gapReading builds a reading for window from template's identity and the given values.
+func gapReading(
+	template specs.MeterReadingSpec,
+	window specs.TimeWindowSpec,
+	values []specs.ComputedValueSpec,
+) (specs.MeterReadingSpec, error) {
+	if len(values) == 0 {
+		return specs.MeterReadingSpec{}, fmt.Errorf("template reading has no computed values")
+	}
+
+	subject, err := NewMeterRecordSubject(template.Subject)
+	if err != nil {
+		return specs.MeterReadingSpec{}, fmt.Errorf("invalid subject: %w", err)
+	}
+	unit, err := NewUnit(values[0].Unit)
+	if err != nil {
+		return specs.MeterReadingSpec{}, fmt.Errorf("invalid unit: %w", err)
+	}
+	timeWindow, err := NewTimeWindow(window)
+	if err != nil {
+		return specs.MeterReadingSpec{}, fmt.Errorf("invalid window: %w", err)
+	}
+	aggregation, err := NewMeterReadingAggregation(template.Aggregation)
+	if err != nil {
+		return specs.MeterReadingSpec{}, fmt.Errorf("invalid aggregation: %w", err)
+	}
+
+	dimensions := NewMeterReadingDimensions()
+	for name, value := range template.Dimensions {
+		dimensions.Set(name, value)
+	}
+
+	id := computeMeterReadingID(subject, unit, timeWindow, aggregation, dimensions)
+
+	return specs.MeterReadingSpec{
+		ID:             id.ToString(),
+		WorkspaceID:    template.WorkspaceID,
+		UniverseID:     template.UniverseID,
+		Subject:        template.Subject,
+		Dimensions:     template.Dimensions,
+		Window:         window,
+		ComputedValues: values,
+		Unit:           unit.ToString(),
+		Aggregation:    template.Aggregation,
+		RecordCount:    0,
+		CreatedAt:      time.Now(),
+		MaxMeteredAt:   template.MaxMeteredAt,
+		Version:        InitialMeterReadingVersion().ToInt64(),
+	}, nil
+}
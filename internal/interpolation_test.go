@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/internal/testutil"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dailyWindows returns n consecutive one-day windows starting Jan 1, 2024.
+func dailyWindows(n int) []specs.TimeWindowSpec {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	windows := make([]specs.TimeWindowSpec, n)
+	for i := range windows {
+		windows[i] = specs.TimeWindowSpec{
+			Start: start.AddDate(0, 0, i),
+			End:   start.AddDate(0, 0, i+1),
+		}
+	}
+	return windows
+}
+
+func newTestDailyReading(window specs.TimeWindowSpec, quantity string) specs.MeterReadingSpec {
+	return testutil.FixtureMeterReading(
+		testutil.WithReadingID("reading-"+window.Start.Format("2006-01-02")),
+		testutil.WithReadingWindow(window),
+		testutil.WithReadingAggregation("time-weighted-avg"),
+		testutil.WithReadingValue(quantity, "seats"),
+		testutil.WithReadingRecordCount(2),
+	)
+}
+
+func TestInterpolateReadings(t *testing.T) {
+	windows := dailyWindows(3)
+	monday := newTestDailyReading(windows[0], "10")
+	wednesday := newTestDailyReading(windows[2], "20")
+
+	t.Run("carry-forward duplicates previous reading into gap", func(t *testing.T) {
+		result, err := InterpolateReadings([]specs.MeterReadingSpec{monday, wednesday}, windows, "carry-forward")
+
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+		assert.Equal(t, monday, result[0])
+		assert.Equal(t, "10", result[1].ComputedValues[0].Quantity)
+		assert.Equal(t, windows[1], result[1].Window)
+		assert.Equal(t, 0, result[1].RecordCount)
+		assert.NotEqual(t, monday.ID, result[1].ID, "gap reading should have its own ID")
+		assert.Equal(t, wednesday, result[2])
+	})
+
+	t.Run("linear interpolates between surrounding readings", func(t *testing.T) {
+		result, err := InterpolateReadings([]specs.MeterReadingSpec{monday, wednesday}, windows, "linear")
+
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+		quantity, err := NewDecimal(result[1].ComputedValues[0].Quantity)
+		require.NoError(t, err)
+		assert.Equal(t, 0, quantity.Cmp(NewDecimalFromInt64(15)), "expected 15, got %s", quantity)
+	})
+
+	t.Run("zero fills gap with zero quantity", func(t *testing.T) {
+		result, err := InterpolateReadings([]specs.MeterReadingSpec{monday, wednesday}, windows, "zero")
+
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+		assert.Equal(t, "0", result[1].ComputedValues[0].Quantity)
+		assert.Equal(t, "seats", result[1].ComputedValues[0].Unit)
+		assert.Equal(t, "customer:test", result[1].Subject)
+	})
+
+	t.Run("linear with gap at start returns error", func(t *testing.T) {
+		_, err := InterpolateReadings([]specs.MeterReadingSpec{wednesday}, windows, "linear")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "gap at start or end")
+	})
+
+	t.Run("with unknown interpolation returns error", func(t *testing.T) {
+		_, err := InterpolateReadings([]specs.MeterReadingSpec{monday}, windows, "cubic")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid interpolation")
+	})
+}
@@ -0,0 +1,129 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PropertyPath locates a value nested inside a JSON-encoded event property.
+//
+// Supports the JSONPath subset needed for event properties: a root "$", the
+// property name, then any number of ".key" and "[index]" steps, for example
+// "$.metadata.model" or "$.metadata.choices[0].tokens". The property's string
+// value is parsed as JSON and the remaining steps are evaluated against it.
+type PropertyPath struct {
+	raw      string
+	property string
+	steps    []pathStep
+}
+
+// pathStep is one ".key" (index < 0) or "[index]" step.
+type pathStep struct {
+	key   string
+	index int
+}
+
+func NewPropertyPath(value string) (PropertyPath, error) {
+	rest, ok := strings.CutPrefix(value, "$.")
+	if !ok {
+		return PropertyPath{}, fmt.Errorf("path %q must start with \"$.\"", value)
+	}
+
+	var steps []pathStep
+	for rest != "" {
+		switch {
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return PropertyPath{}, fmt.Errorf("path %q: unterminated index", value)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return PropertyPath{}, fmt.Errorf("path %q: invalid index %q", value, rest[1:end])
+			}
+			steps = append(steps, pathStep{index: index})
+			rest = rest[end+1:]
+
+		default:
+			rest = strings.TrimPrefix(rest, ".")
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return PropertyPath{}, fmt.Errorf("path %q: empty key", value)
+			}
+			steps = append(steps, pathStep{key: rest[:end], index: -1})
+			rest = rest[end:]
+		}
+	}
+
+	if len(steps) == 0 || steps[0].index >= 0 {
+		return PropertyPath{}, fmt.Errorf("path %q must begin with a property name", value)
+	}
+
+	return PropertyPath{raw: value, property: steps[0].key, steps: steps[1:]}, nil
+}
+
+// Property returns the top-level event property the path reads.
+func (p PropertyPath) Property() string {
+	return p.property
+}
+
+func (p PropertyPath) ToString() string {
+	return p.raw
+}
+
+// Evaluate returns the scalar value at the path. found is false if the
+// property or any step along the path is missing. Returns error if the
+// property is not valid JSON or the path ends at an object or array.
+func (p PropertyPath) Evaluate(properties ImmutableProperties) (value string, found bool, err error) {
+	raw, ok := properties.Get(p.property)
+	if !ok {
+		return "", false, nil
+	}
+	if len(p.steps) == 0 {
+		return raw, true, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(raw)))
+	decoder.UseNumber()
+	var current any
+	if err := decoder.Decode(&current); err != nil {
+		return "", false, fmt.Errorf("property %q is not valid JSON: %w", p.property, err)
+	}
+
+	for _, step := range p.steps {
+		if step.index >= 0 {
+			array, ok := current.([]any)
+			if !ok || step.index >= len(array) {
+				return "", false, nil
+			}
+			current = array[step.index]
+			continue
+		}
+		object, ok := current.(map[string]any)
+		if !ok {
+			return "", false, nil
+		}
+		if current, ok = object[step.key]; !ok {
+			return "", false, nil
+		}
+	}
+
+	switch v := current.(type) {
+	case string:
+		return v, true, nil
+	case json.Number:
+		return v.String(), true, nil
+	case bool:
+		return strconv.FormatBool(v), true, nil
+	case nil:
+		return "", false, nil
+	default:
+		return "", false, fmt.Errorf("path %q does not resolve to a scalar value", p.raw)
+	}
+}
@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPropertyPath(t *testing.T) {
+	t.Run("parses property and steps", func(t *testing.T) {
+		path, err := NewPropertyPath("$.metadata.choices[0].tokens")
+
+		require.NoError(t, err)
+		assert.Equal(t, "metadata", path.Property())
+		assert.Equal(t, "$.metadata.choices[0].tokens", path.ToString())
+	})
+
+	t.Run("rejects invalid paths", func(t *testing.T) {
+		for _, value := range []string{"metadata.model", "$.", "$.[0]", "$.metadata..model", "$.metadata[x]", "$.metadata[0"} {
+			_, err := NewPropertyPath(value)
+			assert.Error(t, err, "path %q should be invalid", value)
+		}
+	})
+}
+
+func TestPropertyPath_Evaluate(t *testing.T) {
+	properties := NewImmutableProperties(map[string]string{
+		"metadata": `{"model": "gpt-4", "cached": true, "usage": {"tokens": 1.5}, "tags": ["a", "b"]}`,
+		"region":   "us-east-1",
+	})
+	evaluate := func(t *testing.T, value string) (string, bool, error) {
+		t.Helper()
+		path, err := NewPropertyPath(value)
+		require.NoError(t, err)
+		return path.Evaluate(properties)
+	}
+
+	t.Run("top-level property returns raw value", func(t *testing.T) {
+		value, found, err := evaluate(t, "$.region")
+
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "us-east-1", value)
+	})
+
+	t.Run("scalars are rendered as strings", func(t *testing.T) {
+		for path, expected := range map[string]string{
+			"$.metadata.model":        "gpt-4",
+			"$.metadata.cached":       "true",
+			"$.metadata.usage.tokens": "1.5",
+			"$.metadata.tags[1]":      "b",
+		} {
+			value, found, err := evaluate(t, path)
+			require.NoError(t, err)
+			assert.True(t, found, path)
+			assert.Equal(t, expected, value, path)
+		}
+	})
+
+	t.Run("missing steps are not found", func(t *testing.T) {
+		for _, path := range []string{"$.missing.model", "$.metadata.region", "$.metadata.tags[2]", "$.metadata.model.name"} {
+			_, found, err := evaluate(t, path)
+			require.NoError(t, err)
+			assert.False(t, found, path)
+		}
+	})
+
+	t.Run("object value returns error", func(t *testing.T) {
+		_, _, err := evaluate(t, "$.metadata.usage")
+
+		assert.ErrorContains(t, err, "scalar")
+	})
+}
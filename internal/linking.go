@@ -0,0 +1,43 @@
+package internal
+
+import (
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// FindLinkedRecords returns the records in all whose IDs appear in
+// record.LinkedRecordIDs, in the order they appear in all.
+//
+// The record itself is never included. Returns an empty slice if the record
+// has no links or none of its linked records are present.
+func FindLinkedRecords(record specs.MeterRecordSpec, all []specs.MeterRecordSpec) []specs.MeterRecordSpec {
+	linked := make(map[string]bool, len(record.LinkedRecordIDs))
+	for _, id := range record.LinkedRecordIDs {
+		linked[id] = true
+	}
+
+	result := make([]specs.MeterRecordSpec, 0, len(record.LinkedRecordIDs))
+	for _, candidate := range all {
+		if candidate.ID != record.ID && linked[candidate.ID] {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+// FindChildRecords returns the records in all whose ParentEventID is parentID,
+// in the order they appear in all.
+//
+// Only direct children are returned; use BuildEventTree to follow the hierarchy
+// further. Returns an empty slice if parentID is empty or has no children.
+func FindChildRecords(parentID string, all []specs.MeterRecordSpec) []specs.MeterRecordSpec {
+	result := make([]specs.MeterRecordSpec, 0)
+	if parentID == "" {
+		return result
+	}
+	for _, candidate := range all {
+		if candidate.ParentEventID == parentID {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
@@ -0,0 +1,100 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindLinkedRecords(t *testing.T) {
+	observedAt := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+
+	input := newTestRecordSpec("evt_1:input-tokens", "100", "input-tokens", observedAt)
+	output := newTestRecordSpec("evt_1:output-tokens", "50", "output-tokens", observedAt)
+	input.LinkedRecordIDs = []string{output.ID}
+	output.LinkedRecordIDs = []string{input.ID}
+	unrelated := newTestRecordSpec("evt_2:input-tokens", "75", "input-tokens", observedAt)
+
+	all := []specs.MeterRecordSpec{input, unrelated, output}
+
+	t.Run("returns linked siblings", func(t *testing.T) {
+		assert.Equal(t, []specs.MeterRecordSpec{output}, FindLinkedRecords(input, all))
+		assert.Equal(t, []specs.MeterRecordSpec{input}, FindLinkedRecords(output, all))
+	})
+
+	t.Run("record without links returns empty list", func(t *testing.T) {
+		assert.Empty(t, FindLinkedRecords(unrelated, all))
+	})
+
+	t.Run("Meter bundles observations instead of linking records", func(t *testing.T) {
+		records, err := Meter(specs.EventPayloadSpec{
+			ID:          "evt_1",
+			WorkspaceID: "workspace-test",
+			UniverseID:  "universe-test",
+			Type:        "llm.completion",
+			Subject:     "customer:test",
+			Time:        observedAt,
+			Properties:  map[string]string{"input_tokens": "100", "output_tokens": "50"},
+		}, specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "input_tokens", Unit: "input-tokens"},
+				{SourceProperty: "output_tokens", Unit: "output-tokens"},
+			},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Len(t, records[0].Observations, 2)
+		assert.Empty(t, records[0].LinkedRecordIDs)
+	})
+}
+
+func TestFindChildRecords(t *testing.T) {
+	observedAt := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+
+	parent := newTestRecordSpec("req_1", "1", "requests", observedAt)
+	child1 := newTestRecordSpec("span_1", "40", "cpu-ms", observedAt)
+	child1.ParentEventID = "req_1"
+	child2 := newTestRecordSpec("span_2", "25", "cpu-ms", observedAt)
+	child2.ParentEventID = "req_1"
+	grandchild := newTestRecordSpec("span_3", "10", "cpu-ms", observedAt)
+	grandchild.ParentEventID = "span_1"
+
+	all := []specs.MeterRecordSpec{parent, child1, grandchild, child2}
+
+	t.Run("returns direct children", func(t *testing.T) {
+		assert.Equal(t, []specs.MeterRecordSpec{child1, child2}, FindChildRecords("req_1", all))
+	})
+
+	t.Run("record without children returns empty list", func(t *testing.T) {
+		assert.Empty(t, FindChildRecords("span_2", all))
+	})
+
+	t.Run("empty parent ID returns empty list", func(t *testing.T) {
+		assert.Empty(t, FindChildRecords("", all))
+	})
+
+	t.Run("Meter propagates parent ID", func(t *testing.T) {
+		records, err := Meter(specs.EventPayloadSpec{
+			ID:          "span_1",
+			WorkspaceID: "workspace-test",
+			UniverseID:  "universe-test",
+			Type:        "trace.span",
+			Subject:     "customer:test",
+			Time:        observedAt,
+			Properties:  map[string]string{"cpu_ms": "40"},
+			ParentID:    "req_1",
+		}, specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "cpu_ms", Unit: "cpu-ms"},
+			},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "req_1", records[0].ParentEventID)
+	})
+}
@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maskedValue replaces dimension values under the "redact" strategy.
+const maskedValue = "***MASKED***"
+
+// PropertyMasking replaces the values of personal-data dimensions before
+// records are stored. The zero value masks nothing.
+type PropertyMasking struct {
+	properties map[string]bool
+	strategy   string
+	truncateTo int
+	key        []byte
+}
+
+// NewPropertyMasking validates the masked property names and strategy. An
+// empty strategy means "redact"; "hash" keys its digests with key. Returns
+// error for an empty property name, an unknown strategy, or "hash" without a
+// key.
+func NewPropertyMasking(properties []string, strategy string, key string) (PropertyMasking, error) {
+	masking := PropertyMasking{strategy: "redact"}
+	switch {
+	case strategy == "" || strategy == "redact" || strategy == "hash":
+		if strategy != "" {
+			masking.strategy = strategy
+		}
+	case strings.HasPrefix(strategy, "truncate:"):
+		n, err := strconv.Atoi(strings.TrimPrefix(strategy, "truncate:"))
+		if err != nil || n < 0 {
+			return PropertyMasking{}, fmt.Errorf("truncate length must be a non-negative integer, got %q", strategy)
+		}
+		masking.strategy = "truncate"
+		masking.truncateTo = n
+	default:
+		return PropertyMasking{}, fmt.Errorf("unknown masking strategy %q", strategy)
+	}
+	if masking.strategy == "hash" {
+		if key == "" {
+			return PropertyMasking{}, fmt.Errorf("hash masking requires a masking key")
+		}
+		masking.key = []byte(key)
+	}
+
+	if len(properties) == 0 {
+		return masking, nil
+	}
+	masking.properties = make(map[string]bool, len(properties))
+	for i, name := range properties {
+		if name == "" {
+			return PropertyMasking{}, fmt.Errorf("masked property %d: name cannot be empty", i)
+		}
+		masking.properties[name] = true
+	}
+	return masking, nil
+}
+
+// Apply masks the values of masked properties present in dimensions, in
+// place.
+func (m PropertyMasking) Apply(dimensions map[string]string) {
+	for name := range m.properties {
+		if value, ok := dimensions[name]; ok {
+			dimensions[name] = m.mask(value)
+		}
+	}
+}
+
+func (m PropertyMasking) mask(value string) string {
+	switch m.strategy {
+	case "hash":
+		mac := hmac.New(sha256.New, m.key)
+		mac.Write([]byte(value))
+		return hex.EncodeToString(mac.Sum(nil))
+	case "truncate":
+		runes := []rune(value)
+		if len(runes) <= m.truncateTo {
+			return value
+		}
+		return string(runes[:m.truncateTo])
+	default:
+		return maskedValue
+	}
+}
@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/internal/testutil"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeter_PropertyMasking(t *testing.T) {
+	payload := testutil.FixtureEventPayload(testutil.WithPayloadProperties(map[string]string{
+		"tokens": "100",
+		"email":  "jane@example.com",
+		"region": "us-east-1",
+	}))
+	newConfig := func(strategy string) specs.MeteringConfigSpec {
+		return specs.MeteringConfigSpec{
+			Observations:     []specs.ObservationExtractionSpec{{SourceProperty: "tokens", Unit: "tokens"}},
+			MaskedProperties: []string{"email"},
+			MaskingStrategy:  strategy,
+			MaskingKey:       "test-secret",
+		}
+	}
+
+	t.Run("masked property is redacted in dimensions", func(t *testing.T) {
+		records, err := Meter(payload, newConfig(""))
+
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "***MASKED***", records[0].Dimensions["email"])
+		assert.Equal(t, "us-east-1", records[0].Dimensions["region"])
+	})
+
+	t.Run("filter matches the original value", func(t *testing.T) {
+		config := newConfig("redact")
+		config.Observations[0].Filter = &specs.FilterSpec{Property: "email", Equals: "jane@example.com"}
+
+		records, err := Meter(payload, config)
+
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "***MASKED***", records[0].Dimensions["email"])
+	})
+
+	t.Run("hash masking is deterministic", func(t *testing.T) {
+		first, err := Meter(payload, newConfig("hash"))
+		require.NoError(t, err)
+		second, err := Meter(payload, newConfig("hash"))
+		require.NoError(t, err)
+
+		assert.Equal(t, "00fed87deff89dfefc52a914a0df4b8a9f172016ebd1027cda45a40aa43d3456", first[0].Dimensions["email"])
+		assert.Equal(t, first[0].Dimensions["email"], second[0].Dimensions["email"])
+	})
+
+	t.Run("hash masking depends on the key", func(t *testing.T) {
+		config := newConfig("hash")
+		config.MaskingKey = "other-secret"
+
+		records, err := Meter(payload, config)
+
+		require.NoError(t, err)
+		assert.NotEqual(t, "00fed87deff89dfefc52a914a0df4b8a9f172016ebd1027cda45a40aa43d3456", records[0].Dimensions["email"])
+	})
+
+	t.Run("truncation preserves prefix", func(t *testing.T) {
+		records, err := Meter(payload, newConfig("truncate:4"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "jane", records[0].Dimensions["email"])
+	})
+}
+
+func TestNewPropertyMasking(t *testing.T) {
+	t.Run("truncation shorter than value keeps value", func(t *testing.T) {
+		masking, err := NewPropertyMasking([]string{"ip"}, "truncate:20", "")
+		require.NoError(t, err)
+		dimensions := map[string]string{"ip": "10.0.0.1"}
+
+		masking.Apply(dimensions)
+
+		assert.Equal(t, "10.0.0.1", dimensions["ip"])
+	})
+
+	t.Run("invalid strategies return error", func(t *testing.T) {
+		for _, strategy := range []string{"encrypt", "truncate:", "truncate:-1", "truncate:abc"} {
+			_, err := NewPropertyMasking([]string{"email"}, strategy, "")
+
+			assert.Error(t, err, strategy)
+		}
+	})
+
+	t.Run("empty property name returns error", func(t *testing.T) {
+		_, err := NewPropertyMasking([]string{""}, "redact", "")
+
+		assert.ErrorContains(t, err, "name cannot be empty")
+	})
+
+	t.Run("hash without a key returns error", func(t *testing.T) {
+		_, err := NewPropertyMasking([]string{"email"}, "hash", "")
+
+		assert.ErrorContains(t, err, "requires a masking key")
+	})
+
+	t.Run("invalid strategy fails metering config", func(t *testing.T) {
+		_, err := NewMeteringConfig(specs.MeteringConfigSpec{
+			Observations:     []specs.ObservationExtractionSpec{{SourceProperty: "tokens", Unit: "tokens"}},
+			MaskedProperties: []string{"email"},
+			MaskingStrategy:  "encrypt",
+		})
+
+		assert.ErrorContains(t, err, "invalid masking")
+	})
+}
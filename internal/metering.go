@@ -1,13 +1,28 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
 	specs "github.com/chrisconley/metron/specs"
+	"strings"
 )
 
 // Meter implements specs.Meter.
 // Converts specs to domain objects, transforms, and converts back to specs.
 func Meter(payloadSpec specs.EventPayloadSpec, configSpec specs.MeteringConfigSpec) ([]specs.MeterRecordSpec, error) {
+	// Reject events missing properties their type requires
+	missing, err := ValidateRequiredProperties(payloadSpec, configSpec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if len(missing) > 0 {
+		errs := make([]error, len(missing))
+		for i, err := range missing {
+			errs[i] = err
+		}
+		return nil, fmt.Errorf("invalid payload: %w", errors.Join(errs...))
+	}
+
 	// Convert specs to domain objects
 	payload, err := NewEventPayload(payloadSpec)
 	if err != nil {
@@ -59,16 +74,29 @@ func Meter(payloadSpec specs.EventPayloadSpec, configSpec specs.MeteringConfigSp
 		}
 
 		recordSpec := specs.MeterRecordSpec{
-			ID:            firstRecord.SourceEventID.ToString(), // Just event ID, no unit suffix
-			WorkspaceID:   firstRecord.WorkspaceID.ToString(),
-			UniverseID:    firstRecord.UniverseID.ToString(),
-			Subject:       firstRecord.Subject.ToString(),
-			ObservedAt:    observedAt,
-			Observations:  observations,
-			Dimensions:    convertDimensionsToMap(firstRecord.Dimensions),
-			SourceEventID: firstRecord.SourceEventID.ToString(),
-			MeteredAt:     firstRecord.MeteredAt.ToTime(),
+			ID:             firstRecord.SourceEventID.ToString(), // Just event ID, no unit suffix
+			WorkspaceID:    firstRecord.WorkspaceID.ToString(),
+			UniverseID:     firstRecord.UniverseID.ToString(),
+			Subject:        firstRecord.Subject.ToString(),
+			ObservedAt:     observedAt,
+			Observations:   observations,
+			Dimensions:     convertDimensionsToMap(firstRecord.Dimensions),
+			SourceEventID:  firstRecord.SourceEventID.ToString(),
+			ParentEventID:  payloadSpec.ParentID,
+			SequenceNumber: payloadSpec.SequenceNumber,
+			MeteredAt:      firstRecord.MeteredAt.ToTime(),
+		}
+
+		if config.DeduplicateObservations() {
+			recordSpec = DeduplicateObservations(recordSpec)
+		}
+
+		// Hash the bundled record so the digest covers all observations
+		bundled, err := NewMeterRecord(recordSpec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create meter record: %w", err)
 		}
+		recordSpec.ContentHash = bundled.ContentHash()
 
 		recordSpecs = append(recordSpecs, recordSpec)
 	}
@@ -90,57 +118,170 @@ func convertDimensionsToMap(dimensions MeterRecordDimensions) map[string]string
 // meter transforms an EventPayload into MeterRecords by applying the metering configuration.
 // This is the private domain-level function that operates on domain objects.
 //
-// For each observation extraction in the config:
+// If the config has a property schema, the payload's properties are checked
+// against it first. Computed properties are then evaluated and added to the
+// payload properties.
+// The properties are then frozen, so extraction cannot mutate them. Then, for
+// each observation extraction in the config:
 //  1. Check if filter matches (if filter exists)
-//  2. Extract the source property value
-//  3. Cast to Decimal
-//  4. Attach the configured unit
-//  5. Pass through all non-extracted properties as dimensions
+//  2. Extract the source property value (or evaluate the source path)
+//  3. Cast to Decimal and check quantity bounds, then subtract any per-event
+//     free quantity
+//  4. Attach the configured unit, resolved to its canonical name through
+//     the config's unit aliases
+//  5. Pass through all non-extracted properties as dimensions, then apply
+//     dimension transforms and coercions, and mask configured dimensions
 //  6. Create a MeterRecord
 //
 // Returns a slice of MeterRecords (one per matched extraction).
-// Returns empty slice if no extractions match (not an error).
+// Returns empty slice if no extractions match or all usage is free (not an error).
+//
+// Every extraction is attempted even after one fails, so a client sees all of
+// an event's problems at once. If any failed, the records of the others are
+// discarded and a *MultiExtractionError listing each failure is returned.
 func meter(payload EventPayload, config MeteringConfig) ([]MeterRecord, error) {
+	if schema := config.PropertySchema(); schema != nil {
+		properties := make(map[string]string, len(payload.Properties.Keys()))
+		for _, key := range payload.Properties.Keys() {
+			properties[key], _ = payload.Properties.Get(key)
+		}
+		if schemaErrors := ValidatePropertiesAgainstSchema(properties, *schema); len(schemaErrors) > 0 {
+			errs := make([]error, len(schemaErrors))
+			for i, e := range schemaErrors {
+				errs[i] = e
+			}
+			return nil, fmt.Errorf("properties do not match schema: %w", errors.Join(errs...))
+		}
+	}
+
+	// Evaluate computed properties first so extraction, filters, and dimensions can use them
+	if computed := config.ComputedProperties(); len(computed) > 0 {
+		properties := make(map[string]string, len(payload.Properties.Keys())+len(computed))
+		for _, key := range payload.Properties.Keys() {
+			properties[key], _ = payload.Properties.Get(key)
+		}
+		payload.Properties = NewEventPayloadProperties(properties)
+		for _, property := range computed {
+			value, err := property.Evaluate(payload.Properties)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compute property %q: %w", property.Key(), err)
+			}
+			payload.Properties.Set(property.Key(), value.String())
+		}
+	}
+
+	// Extraction only reads properties from here on
+	properties := payload.Properties.Freeze()
+
 	observations := config.Observations()
-	// First pass: collect all source properties that will be extracted
+	// First pass: collect all source properties that will be extracted,
+	// including the JSON-encoded properties source paths read from
 	extractedProperties := make(map[string]bool)
 	for _, extraction := range observations {
-		extractedProperties[extraction.SourceProperty().ToString()] = true
+		if path := extraction.SourcePath(); path != nil {
+			extractedProperties[path.Property()] = true
+		} else {
+			extractedProperties[extraction.SourceKey()] = true
+		}
 	}
 
 	records := make([]MeterRecord, 0, len(observations))
+	var extractionErrs []ExtractionError
+	fail := func(index int, extraction ObservationExtraction, err error) {
+		extractionErrs = append(extractionErrs, ExtractionError{
+			ExtractionIndex: index,
+			SourceProperty:  extractionSource(extraction),
+			Err:             err,
+		})
+	}
 
-	for _, extraction := range observations {
+	for i, extraction := range observations {
 		// Check filter first
-		if !extraction.Matches(payload.Properties) {
+		if !extraction.Matches(properties) {
 			continue // Skip this extraction
 		}
 
-		// Extract source property
-		sourceKey := extraction.SourceProperty().ToString()
-		sourceValue, exists := payload.Properties.Get(sourceKey)
-		if !exists {
-			return nil, fmt.Errorf("source property %q not found in payload", sourceKey)
+		// Extract source property, or evaluate source path
+		quantity, ok, err := extractQuantity(properties, extraction)
+		if err != nil {
+			fail(i, extraction, err)
+			continue
+		}
+		if !ok {
+			continue // Path missing and no default
 		}
 
-		// Cast to Decimal
-		quantity, err := NewDecimal(sourceValue)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse property %q value %q as decimal: %w", sourceKey, sourceValue, err)
+		// Bounds apply to the recorded value, before any free tier
+		if err := extraction.ValidateQuantity(quantity); err != nil {
+			fail(i, extraction, err)
+			continue
+		}
+
+		// Apply per-event free tier: only usage above the free quantity is metered
+		if freeTier := extraction.FreeTier(); freeTier != nil && freeTier.IsPerEvent() {
+			quantity = quantity.Sub(freeTier.Quantity())
+			if quantity.Cmp(NewDecimalFromInt64(0)) <= 0 {
+				continue // Entirely free usage
+			}
 		}
 
 		// Build dimensions: all properties except those extracted as observations
-		dimensionsMap := make(map[string]string)
-		for _, key := range payload.Properties.Keys() {
-			if !extractedProperties[key] {
-				if value, ok := payload.Properties.Get(key); ok {
-					dimensionsMap[key] = value
+		dimensionsMap := properties.Filter(func(key, _ string) bool {
+			return !extractedProperties[key]
+		}).ToMap()
+
+		// Namespaced properties become dimensions without their prefix,
+		// winning over unprefixed properties of the same name
+		if namespace := extraction.Namespace(); namespace != "" {
+			namespaced := ExtractNamespacedProperties(payload.Properties, namespace)
+			for _, key := range namespaced.Keys() {
+				delete(dimensionsMap, namespace+"."+key)
+				if extractedProperties[namespace+"."+key] {
+					continue
 				}
+				dimensionsMap[key], _ = namespaced.Get(key)
+			}
+		}
+
+		// Add dimensions nested in JSON-encoded properties
+		var dimensionErr error
+		for _, dimensionPath := range config.DimensionPaths() {
+			value, found, err := dimensionPath.Path().Evaluate(properties)
+			if err != nil {
+				dimensionErr = fmt.Errorf("dimension %q: %w", dimensionPath.Key(), err)
+				break
+			}
+			if found {
+				dimensionsMap[dimensionPath.Key()] = value
+			}
+		}
+		if dimensionErr != nil {
+			fail(i, extraction, dimensionErr)
+			continue
+		}
+
+		// Derive normalized dimensions from the untransformed properties
+		if transforms := config.DimensionTransforms(); len(transforms) > 0 {
+			ApplyDimensionTransforms(transforms, dimensionsMap)
+		}
+
+		// Coerce values through lookup tables, reading the transformed dimensions
+		if coercions := config.DimensionCoercions(); len(coercions) > 0 {
+			transformed := make(map[string]string, len(dimensionsMap))
+			for key, value := range dimensionsMap {
+				transformed[key] = value
+			}
+			for _, coercion := range coercions {
+				coercion.Apply(transformed, dimensionsMap)
 			}
 		}
 
+		// Mask personal data last, so keys derived above are covered too
+		config.Masking().Apply(dimensionsMap)
+
 		// Build MeterRecord
-		recordID := payload.ID.ToString() + ":" + extraction.Unit().ToString()
+		unit := NormalizeUnit(extraction.Unit().ToString(), config.UnitAliases())
+		recordID := payload.ID.ToString() + ":" + unit
 		observedAt := payload.Time.ToTime()
 
 		record, err := NewMeterRecord(specs.MeterRecordSpec{
@@ -152,7 +293,7 @@ func meter(payload EventPayload, config MeteringConfig) ([]MeterRecord, error) {
 			Observations: []specs.ObservationSpec{
 				specs.NewInstantObservation(
 					quantity.String(),
-					extraction.Unit().ToString(),
+					unit,
 					observedAt,
 				),
 			},
@@ -160,11 +301,108 @@ func meter(payload EventPayload, config MeteringConfig) ([]MeterRecord, error) {
 			SourceEventID: payload.ID.ToString(),
 		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to create meter record: %w", err)
+			fail(i, extraction, fmt.Errorf("failed to create meter record: %w", err))
+			continue
 		}
 
 		records = append(records, record)
 	}
 
+	if len(extractionErrs) > 0 {
+		return nil, &MultiExtractionError{Errors: extractionErrs}
+	}
 	return records, nil
 }
+
+// ExtractionError is the failure of one observation extraction while metering
+// an event.
+type ExtractionError struct {
+	ExtractionIndex int    // Index of the extraction in the config's observations
+	SourceProperty  string // Property key or path the extraction reads
+	Err             error
+}
+
+func (e ExtractionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e ExtractionError) Unwrap() error {
+	return e.Err
+}
+
+// MultiExtractionError reports every extraction that failed for an event, in
+// extraction order. errors.As and errors.Is see each underlying error.
+type MultiExtractionError struct {
+	Errors []ExtractionError
+}
+
+// Error returns the single failure's message, or a count followed by each
+// failure's message.
+func (e *MultiExtractionError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = fmt.Sprintf("extraction %d (%s): %s", err.ExtractionIndex, err.SourceProperty, err.Error())
+	}
+	return fmt.Sprintf("%d extractions failed: %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+func (e *MultiExtractionError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// extractionSource returns the property key or path an extraction reads.
+func extractionSource(extraction ObservationExtraction) string {
+	if path := extraction.SourcePath(); path != nil {
+		return path.ToString()
+	}
+	return extraction.SourceKey()
+}
+
+// extractQuantity returns the extraction's quantity from the properties.
+// ok is false when a source path does not resolve and there is no default.
+// Returns error if a source property is missing or a value is not a decimal.
+func extractQuantity(properties ImmutableProperties, extraction ObservationExtraction) (quantity Decimal, ok bool, err error) {
+	if path := extraction.SourcePath(); path != nil {
+		value, found, err := path.Evaluate(properties)
+		if err != nil {
+			return Decimal{}, false, fmt.Errorf("source path %q: %w", path.ToString(), err)
+		}
+		if !found {
+			if def := extraction.SourceDefault(); def != nil {
+				return *def, true, nil
+			}
+			return Decimal{}, false, nil
+		}
+		if err := ValidatePropertyIsNumeric(value); err != nil {
+			return Decimal{}, false, fmt.Errorf("source path %q: %w", path.ToString(), err)
+		}
+		quantity, err := NewDecimal(value)
+		if err != nil {
+			return Decimal{}, false, fmt.Errorf("failed to parse path %q value %q as decimal: %w", path.ToString(), value, err)
+		}
+		return quantity, true, nil
+	}
+
+	sourceKey := extraction.SourceKey()
+	sourceValue, exists := properties.Get(sourceKey)
+	if !exists {
+		return Decimal{}, false, fmt.Errorf("source property %q not found in payload", sourceKey)
+	}
+
+	// Cast to Decimal, rejecting malformed values with a specific message first
+	if err := ValidatePropertyIsNumeric(sourceValue); err != nil {
+		return Decimal{}, false, fmt.Errorf("property %q: %w", sourceKey, err)
+	}
+	quantity, err = NewDecimal(sourceValue)
+	if err != nil {
+		return Decimal{}, false, fmt.Errorf("failed to parse property %q value %q as decimal: %w", sourceKey, sourceValue, err)
+	}
+	return quantity, true, nil
+}
@@ -3,13 +3,44 @@ package internal
 import (
 	"fmt"
 	specs "github.com/chrisconley/metron/specs"
+	"regexp"
+	"strings"
 )
 
 type MeteringConfig struct {
-	observations []ObservationExtraction
+	computedProperties  []ComputedProperty
+	observations        []ObservationExtraction
+	dimensionTransforms []DimensionTransform
+	dimensionCoercions  []DimensionCoercion
+	dimensionPaths      []DimensionPath
+	unitAliases         map[string]string
+	propertySchema      *PropertySchema
+	dedupObservations   bool
+	masking             PropertyMasking
+	inheritedFrom       *MeteringConfig
 }
 
+// NewMeteringConfig validates a metering config. If spec has a BaseConfig,
+// the base is validated on its own and the result is the merge of the base
+// and spec (see MergeConfigs).
 func NewMeteringConfig(spec specs.MeteringConfigSpec) (MeteringConfig, error) {
+	if err := checkBaseConfigChain(spec); err != nil {
+		return MeteringConfig{}, err
+	}
+
+	var inheritedFrom *MeteringConfig
+	if spec.BaseConfig != nil {
+		base, err := NewMeteringConfig(*spec.BaseConfig)
+		if err != nil {
+			return MeteringConfig{}, fmt.Errorf("invalid base config: %w", err)
+		}
+		inheritedFrom = &base
+		spec, err = MergeConfigs(*spec.BaseConfig, spec)
+		if err != nil {
+			return MeteringConfig{}, err
+		}
+	}
+
 	if len(spec.Observations) == 0 {
 		return MeteringConfig{}, fmt.Errorf("at least one observation extraction is required")
 	}
@@ -23,18 +54,569 @@ func NewMeteringConfig(spec specs.MeteringConfigSpec) (MeteringConfig, error) {
 		observations = append(observations, extraction)
 	}
 
+	dimensionTransforms := make([]DimensionTransform, 0, len(spec.DimensionTransforms))
+	for i, t := range spec.DimensionTransforms {
+		transform, err := NewDimensionTransform(t)
+		if err != nil {
+			return MeteringConfig{}, fmt.Errorf("dimension transform %d: %w", i, err)
+		}
+		dimensionTransforms = append(dimensionTransforms, transform)
+	}
+
+	dimensionCoercions := make([]DimensionCoercion, 0, len(spec.DimensionCoercions))
+	for i, c := range spec.DimensionCoercions {
+		coercion, err := NewDimensionCoercion(c)
+		if err != nil {
+			return MeteringConfig{}, fmt.Errorf("dimension coercion %d: %w", i, err)
+		}
+		dimensionCoercions = append(dimensionCoercions, coercion)
+	}
+
+	dimensionPaths := make([]DimensionPath, 0, len(spec.DimensionPaths))
+	for i, p := range spec.DimensionPaths {
+		dimensionPath, err := NewDimensionPath(p)
+		if err != nil {
+			return MeteringConfig{}, fmt.Errorf("dimension path %d: %w", i, err)
+		}
+		dimensionPaths = append(dimensionPaths, dimensionPath)
+	}
+
+	computedProperties, err := newComputedProperties(spec.ComputedProperties)
+	if err != nil {
+		return MeteringConfig{}, err
+	}
+
+	unitAliases, err := newUnitAliases(spec.UnitAliases)
+	if err != nil {
+		return MeteringConfig{}, err
+	}
+	if err := checkUnitAliasCollisions(observations, unitAliases); err != nil {
+		return MeteringConfig{}, err
+	}
+
+	var propertySchema *PropertySchema
+	if spec.PropertySchema != nil {
+		schema, err := NewPropertySchema(spec.PropertySchema.Fields)
+		if err != nil {
+			return MeteringConfig{}, fmt.Errorf("invalid property schema: %w", err)
+		}
+		propertySchema = &schema
+	}
+
+	if err := validateRequiredPropertiesSpec(spec.RequiredProperties); err != nil {
+		return MeteringConfig{}, err
+	}
+
+	masking, err := NewPropertyMasking(spec.MaskedProperties, spec.MaskingStrategy, spec.MaskingKey)
+	if err != nil {
+		return MeteringConfig{}, fmt.Errorf("invalid masking: %w", err)
+	}
+
 	return MeteringConfig{
-		observations: observations,
+		computedProperties:  computedProperties,
+		observations:        observations,
+		dimensionTransforms: dimensionTransforms,
+		dimensionCoercions:  dimensionCoercions,
+		dimensionPaths:      dimensionPaths,
+		unitAliases:         unitAliases,
+		propertySchema:      propertySchema,
+		dedupObservations:   spec.DeduplicateObservations,
+		masking:             masking,
+		inheritedFrom:       inheritedFrom,
+	}, nil
+}
+
+// MergeConfigs returns the effective config of override inheriting from base.
+//
+// Override's observations are appended to base's, except that an override
+// observation replaces every base observation with the same source property
+// (or source path), so a workspace can re-filter a company-wide metric.
+// Observations within override are not deduplicated. Computed properties and
+// dimension paths are merged by key with override winning; dimension
+// transforms and coercions are appended. Unit aliases are merged by alias with override
+// winning, and required properties by event type with override winning.
+// Override's property schema replaces base's if set.
+// Observation deduplication is on if either config turns it on. Masked
+// properties are combined, and override's masking strategy and key win if
+// set.
+//
+// base's own BaseConfig is resolved first; override's BaseConfig is ignored in
+// favor of base. The result has no BaseConfig. Returns ErrBaseConfigCycle if
+// base's chain of BaseConfigs loops back on itself.
+func MergeConfigs(base, override specs.MeteringConfigSpec) (specs.MeteringConfigSpec, error) {
+	if err := checkBaseConfigChain(base); err != nil {
+		return specs.MeteringConfigSpec{}, err
+	}
+	if base.BaseConfig != nil {
+		var err error
+		base, err = MergeConfigs(*base.BaseConfig, base)
+		if err != nil {
+			return specs.MeteringConfigSpec{}, err
+		}
+	}
+
+	overridden := make(map[string]bool, len(override.Observations))
+	for _, o := range override.Observations {
+		overridden[observationSource(o)] = true
+	}
+	var observations []specs.ObservationExtractionSpec
+	for _, o := range base.Observations {
+		if !overridden[observationSource(o)] {
+			observations = append(observations, o)
+		}
+	}
+	observations = append(observations, override.Observations...)
+
+	computedKeys := make(map[string]bool, len(override.ComputedProperties))
+	for _, c := range override.ComputedProperties {
+		computedKeys[c.Key] = true
+	}
+	var computedProperties []specs.ComputedPropertySpec
+	for _, c := range base.ComputedProperties {
+		if !computedKeys[c.Key] {
+			computedProperties = append(computedProperties, c)
+		}
+	}
+	computedProperties = append(computedProperties, override.ComputedProperties...)
+
+	pathKeys := make(map[string]bool, len(override.DimensionPaths))
+	for _, p := range override.DimensionPaths {
+		pathKeys[p.Key] = true
+	}
+	var dimensionPaths []specs.DimensionPathSpec
+	for _, p := range base.DimensionPaths {
+		if !pathKeys[p.Key] {
+			dimensionPaths = append(dimensionPaths, p)
+		}
+	}
+	dimensionPaths = append(dimensionPaths, override.DimensionPaths...)
+
+	var dimensionTransforms []specs.DimensionTransformSpec
+	dimensionTransforms = append(dimensionTransforms, base.DimensionTransforms...)
+	dimensionTransforms = append(dimensionTransforms, override.DimensionTransforms...)
+
+	var dimensionCoercions []specs.DimensionCoercionSpec
+	dimensionCoercions = append(dimensionCoercions, base.DimensionCoercions...)
+	dimensionCoercions = append(dimensionCoercions, override.DimensionCoercions...)
+
+	var unitAliases map[string]string
+	if len(base.UnitAliases)+len(override.UnitAliases) > 0 {
+		unitAliases = make(map[string]string, len(base.UnitAliases)+len(override.UnitAliases))
+		for alias, canonical := range base.UnitAliases {
+			unitAliases[alias] = canonical
+		}
+		for alias, canonical := range override.UnitAliases {
+			unitAliases[alias] = canonical
+		}
+	}
+
+	propertySchema := base.PropertySchema
+	if override.PropertySchema != nil {
+		propertySchema = override.PropertySchema
+	}
+
+	var requiredProperties map[string][]string
+	if len(base.RequiredProperties)+len(override.RequiredProperties) > 0 {
+		requiredProperties = make(map[string][]string, len(base.RequiredProperties)+len(override.RequiredProperties))
+		for eventType, names := range base.RequiredProperties {
+			requiredProperties[eventType] = names
+		}
+		for eventType, names := range override.RequiredProperties {
+			requiredProperties[eventType] = names
+		}
+	}
+
+	var maskedProperties []string
+	masked := make(map[string]bool, len(base.MaskedProperties)+len(override.MaskedProperties))
+	for _, names := range [][]string{base.MaskedProperties, override.MaskedProperties} {
+		for _, name := range names {
+			if !masked[name] {
+				masked[name] = true
+				maskedProperties = append(maskedProperties, name)
+			}
+		}
+	}
+	maskingStrategy := base.MaskingStrategy
+	if override.MaskingStrategy != "" {
+		maskingStrategy = override.MaskingStrategy
+	}
+	maskingKey := base.MaskingKey
+	if override.MaskingKey != "" {
+		maskingKey = override.MaskingKey
+	}
+
+	return specs.MeteringConfigSpec{
+		ComputedProperties:  computedProperties,
+		Observations:        observations,
+		DimensionTransforms: dimensionTransforms,
+		DimensionCoercions:  dimensionCoercions,
+		DimensionPaths:      dimensionPaths,
+		UnitAliases:         unitAliases,
+		RequiredProperties:  requiredProperties,
+		PropertySchema:      propertySchema,
+
+		DeduplicateObservations: base.DeduplicateObservations || override.DeduplicateObservations,
+		MaskedProperties:        maskedProperties,
+		MaskingStrategy:         maskingStrategy,
+		MaskingKey:              maskingKey,
 	}, nil
 }
 
+// checkBaseConfigChain returns ErrBaseConfigCycle if following BaseConfig
+// from spec reaches the same config twice, which would otherwise make
+// resolving the chain recurse forever.
+func checkBaseConfigChain(spec specs.MeteringConfigSpec) error {
+	visited := make(map[*specs.MeteringConfigSpec]bool)
+	for base := spec.BaseConfig; base != nil; base = base.BaseConfig {
+		if visited[base] {
+			return newValidationError(ErrBaseConfigCycle, "base config chain contains a cycle")
+		}
+		visited[base] = true
+	}
+	return nil
+}
+
+// observationSource identifies the value an extraction reads, for matching
+// base and override extractions.
+func observationSource(o specs.ObservationExtractionSpec) string {
+	if o.SourcePath != "" {
+		return "path:" + o.SourcePath
+	}
+	if o.Namespace != "" {
+		return "property:" + o.Namespace + "." + o.SourceProperty
+	}
+	return "property:" + o.SourceProperty
+}
+
+// Masking returns how dimension values are masked.
+func (c MeteringConfig) Masking() PropertyMasking {
+	return c.masking
+}
+
+// DeduplicateObservations reports whether repeated observations are dropped
+// from each meter record.
+func (c MeteringConfig) DeduplicateObservations() bool {
+	return c.dedupObservations
+}
+
+// InheritedFrom returns the validated base config this config was merged
+// from, or nil if it has no BaseConfig.
+func (c MeteringConfig) InheritedFrom() *MeteringConfig {
+	return c.inheritedFrom
+}
+
+// PropertySchema returns the schema event properties are checked against, or
+// nil if the config has none.
+func (c MeteringConfig) PropertySchema() *PropertySchema {
+	return c.propertySchema
+}
+
+// ComputedProperties returns the computed properties in evaluation order:
+// each property comes after the computed properties it references.
+func (c MeteringConfig) ComputedProperties() []ComputedProperty {
+	return c.computedProperties
+}
+
 func (c MeteringConfig) Observations() []ObservationExtraction {
 	return c.observations
 }
 
+func (c MeteringConfig) DimensionTransforms() []DimensionTransform {
+	return c.dimensionTransforms
+}
+
+func (c MeteringConfig) DimensionCoercions() []DimensionCoercion {
+	return c.dimensionCoercions
+}
+
+func (c MeteringConfig) DimensionPaths() []DimensionPath {
+	return c.dimensionPaths
+}
+
+// UnitAliases returns the map from unit aliases to canonical units, or nil if
+// none are configured.
+func (c MeteringConfig) UnitAliases() map[string]string {
+	return c.unitAliases
+}
+
+// DimensionPath extracts a dimension from a JSON-encoded event property.
+type DimensionPath struct {
+	key  string
+	path PropertyPath
+}
+
+func NewDimensionPath(spec specs.DimensionPathSpec) (DimensionPath, error) {
+	if spec.Key == "" {
+		return DimensionPath{}, fmt.Errorf("key is required")
+	}
+	path, err := NewPropertyPath(spec.Path)
+	if err != nil {
+		return DimensionPath{}, fmt.Errorf("invalid path: %w", err)
+	}
+	return DimensionPath{key: spec.Key, path: path}, nil
+}
+
+func (d DimensionPath) Key() string {
+	return d.key
+}
+
+func (d DimensionPath) Path() PropertyPath {
+	return d.path
+}
+
+// ComputedProperty sets a property to the result of an arithmetic expression.
+type ComputedProperty struct {
+	key        string
+	expression formulaNode
+	references []string
+}
+
+func NewComputedProperty(spec specs.ComputedPropertySpec) (ComputedProperty, error) {
+	if spec.Key == "" {
+		return ComputedProperty{}, fmt.Errorf("key is required")
+	}
+	node, err := parseFormula(spec.Expression)
+	if err != nil {
+		return ComputedProperty{}, fmt.Errorf("invalid expression %q: %w", spec.Expression, err)
+	}
+	return ComputedProperty{
+		key:        spec.Key,
+		expression: node,
+		references: formulaReferences(node),
+	}, nil
+}
+
+func (c ComputedProperty) Key() string {
+	return c.key
+}
+
+// Evaluate computes the property's value from properties.
+// Returns error if a referenced property is missing, not a decimal, or the
+// expression divides by zero.
+func (c ComputedProperty) Evaluate(properties EventPayloadProperties) (Decimal, error) {
+	return c.expression.eval(properties)
+}
+
+// newComputedProperties parses computed properties and orders them so every
+// property is evaluated after the computed properties it references.
+// Returns error for duplicate keys or circular references.
+func newComputedProperties(specList []specs.ComputedPropertySpec) ([]ComputedProperty, error) {
+	byKey := make(map[string]ComputedProperty, len(specList))
+	keys := make([]string, 0, len(specList))
+	for i, spec := range specList {
+		property, err := NewComputedProperty(spec)
+		if err != nil {
+			return nil, fmt.Errorf("computed property %d: %w", i, err)
+		}
+		if _, exists := byKey[property.key]; exists {
+			return nil, fmt.Errorf("computed property %d: duplicate key %q", i, property.key)
+		}
+		byKey[property.key] = property
+		keys = append(keys, property.key)
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(byKey))
+	ordered := make([]ComputedProperty, 0, len(byKey))
+	var visit func(key string, path []string) error
+	visit = func(key string, path []string) error {
+		switch state[key] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("circular reference in computed properties: %s", strings.Join(append(path, key), " -> "))
+		}
+		state[key] = visiting
+		property := byKey[key]
+		for _, ref := range property.references {
+			if _, computed := byKey[ref]; computed {
+				if err := visit(ref, append(path, key)); err != nil {
+					return err
+				}
+			}
+		}
+		state[key] = visited
+		ordered = append(ordered, property)
+		return nil
+	}
+
+	for _, key := range keys {
+		if err := visit(key, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// DimensionTransform derives a dimension value from another dimension.
+type DimensionTransform struct {
+	fromProperty string
+	toKey        string
+	dropOriginal bool
+	apply        func(string) string
+}
+
+func NewDimensionTransform(spec specs.DimensionTransformSpec) (DimensionTransform, error) {
+	if spec.FromProperty == "" {
+		return DimensionTransform{}, fmt.Errorf("from property is required")
+	}
+
+	toKey := spec.ToKey
+	if toKey == "" {
+		toKey = spec.FromProperty
+	}
+
+	apply, err := parseDimensionTransform(spec.Transform)
+	if err != nil {
+		return DimensionTransform{}, err
+	}
+
+	return DimensionTransform{
+		fromProperty: spec.FromProperty,
+		toKey:        toKey,
+		dropOriginal: spec.DropOriginal && toKey != spec.FromProperty,
+		apply:        apply,
+	}, nil
+}
+
+// parseDimensionTransform returns the function described by a transform string.
+func parseDimensionTransform(transform string) (func(string) string, error) {
+	kind, arg, _ := strings.Cut(transform, ":")
+	switch kind {
+	case "passthrough":
+		if arg != "" {
+			return nil, fmt.Errorf("passthrough transform takes no argument")
+		}
+		return func(value string) string { return value }, nil
+
+	case "prefix-strip":
+		if arg == "" {
+			return nil, fmt.Errorf("prefix-strip transform requires a prefix")
+		}
+		return func(value string) string { return strings.TrimPrefix(value, arg) }, nil
+
+	case "regex":
+		sep := strings.LastIndex(arg, ":")
+		if sep < 0 {
+			return nil, fmt.Errorf("regex transform must be regex:<pattern>:<replacement>")
+		}
+		pattern, replacement := arg[:sep], arg[sep+1:]
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex pattern %q: %w", pattern, err)
+		}
+		return func(value string) string { return re.ReplaceAllString(value, replacement) }, nil
+
+	default:
+		return nil, fmt.Errorf("unknown transform %q: must be passthrough, prefix-strip, or regex", transform)
+	}
+}
+
+// Apply writes the transformed value into result if the source dimension is
+// present in original, and reports whether it did. It never removes the
+// source; ApplyDimensionTransforms drops originals once all transforms ran.
+func (t DimensionTransform) Apply(original map[string]string, result map[string]string) bool {
+	value, ok := original[t.fromProperty]
+	if !ok {
+		return false
+	}
+	result[t.toKey] = t.apply(value)
+	return true
+}
+
+// ApplyDimensionTransforms applies transforms to dimensions in place. Each
+// transform reads the untransformed dimensions, and sources marked
+// DropOriginal are removed only after every transform has run, unless a
+// transform wrote that key, so the result does not depend on transform order.
+func ApplyDimensionTransforms(transforms []DimensionTransform, dimensions map[string]string) {
+	original := make(map[string]string, len(dimensions))
+	for key, value := range dimensions {
+		original[key] = value
+	}
+
+	written := make(map[string]bool, len(transforms))
+	var dropped []string
+	for _, transform := range transforms {
+		if !transform.Apply(original, dimensions) {
+			continue
+		}
+		written[transform.toKey] = true
+		if transform.dropOriginal {
+			dropped = append(dropped, transform.fromProperty)
+		}
+	}
+	for _, key := range dropped {
+		if !written[key] {
+			delete(dimensions, key)
+		}
+	}
+}
+
+// DimensionCoercion rewrites a dimension's value through a lookup table.
+type DimensionCoercion struct {
+	fromKey      string
+	toKey        string
+	mapping      map[string]string
+	defaultValue string
+}
+
+func NewDimensionCoercion(spec specs.DimensionCoercionSpec) (DimensionCoercion, error) {
+	if spec.FromKey == "" {
+		return DimensionCoercion{}, fmt.Errorf("from key is required")
+	}
+	if len(spec.Mapping) == 0 {
+		return DimensionCoercion{}, fmt.Errorf("mapping is required")
+	}
+
+	toKey := spec.ToKey
+	if toKey == "" {
+		toKey = spec.FromKey
+	}
+
+	mapping := make(map[string]string, len(spec.Mapping))
+	for from, to := range spec.Mapping {
+		mapping[from] = to
+	}
+
+	return DimensionCoercion{
+		fromKey:      spec.FromKey,
+		toKey:        toKey,
+		mapping:      mapping,
+		defaultValue: spec.DefaultValue,
+	}, nil
+}
+
+// Apply writes the coerced value into result if the source dimension is
+// present in original, removing the source from result when renaming.
+func (c DimensionCoercion) Apply(original map[string]string, result map[string]string) {
+	value, ok := original[c.fromKey]
+	if !ok {
+		return
+	}
+
+	coerced, mapped := c.mapping[value]
+	if !mapped {
+		coerced = value
+		if c.defaultValue != "" {
+			coerced = c.defaultValue
+		}
+	}
+
+	if c.toKey != c.fromKey {
+		delete(result, c.fromKey)
+	}
+	result[c.toKey] = coerced
+}
+
 type Filter struct {
-	property FilterProperty
-	equals   FilterValue
+	property    FilterProperty
+	equals      FilterValue
+	matchesAny  *FilterValueSet
+	matchesNone *FilterValueSet
 }
 
 func NewFilter(spec specs.FilterSpec) (Filter, error) {
@@ -43,6 +625,30 @@ func NewFilter(spec specs.FilterSpec) (Filter, error) {
 		return Filter{}, fmt.Errorf("invalid property: %w", err)
 	}
 
+	if spec.MatchesAny != nil && spec.MatchesNone != nil {
+		return Filter{}, fmt.Errorf("matchesAny cannot be combined with matchesNone")
+	}
+
+	if spec.MatchesAny != nil || spec.MatchesNone != nil {
+		if spec.Equals != "" {
+			return Filter{}, fmt.Errorf("equals cannot be combined with matchesAny or matchesNone")
+		}
+
+		if spec.MatchesAny != nil {
+			matchesAny, err := NewFilterValueSet(spec.MatchesAny)
+			if err != nil {
+				return Filter{}, fmt.Errorf("invalid matchesAny: %w", err)
+			}
+			return Filter{property: property, matchesAny: &matchesAny}, nil
+		}
+
+		matchesNone, err := NewFilterValueSet(spec.MatchesNone)
+		if err != nil {
+			return Filter{}, fmt.Errorf("invalid matchesNone: %w", err)
+		}
+		return Filter{property: property, matchesNone: &matchesNone}, nil
+	}
+
 	equals, err := NewFilterValue(spec.Equals)
 	if err != nil {
 		return Filter{}, fmt.Errorf("invalid equals: %w", err)
@@ -62,13 +668,31 @@ func (f Filter) Equals() FilterValue {
 	return f.equals
 }
 
+// MatchesAny returns the allowed values, or nil if this is not a MatchesAny filter.
+func (f Filter) MatchesAny() *FilterValueSet {
+	return f.matchesAny
+}
+
+// MatchesNone returns the excluded values, or nil if this is not a MatchesNone filter.
+func (f Filter) MatchesNone() *FilterValueSet {
+	return f.matchesNone
+}
+
 // Matches returns true if the filter condition is satisfied by the properties.
-func (f Filter) Matches(properties EventPayloadProperties) bool {
+// A missing property never matches, regardless of the filter kind.
+func (f Filter) Matches(properties ImmutableProperties) bool {
 	value, exists := properties.Get(f.property.ToString())
 	if !exists {
 		return false
 	}
-	return value == f.equals.ToString()
+	switch {
+	case f.matchesAny != nil:
+		return f.matchesAny.Contains(value)
+	case f.matchesNone != nil:
+		return !f.matchesNone.Contains(value)
+	default:
+		return value == f.equals.ToString()
+	}
 }
 
 type FilterProperty struct {
@@ -101,18 +725,82 @@ func (v FilterValue) ToString() string {
 	return v.value
 }
 
+// FilterValueSet is a non-empty set of values used for list membership filters.
+type FilterValueSet struct {
+	values map[string]struct{}
+}
+
+func NewFilterValueSet(values []string) (FilterValueSet, error) {
+	if len(values) == 0 {
+		return FilterValueSet{}, fmt.Errorf("at least one value is required")
+	}
+
+	set := make(map[string]struct{}, len(values))
+	for i, value := range values {
+		if value == "" {
+			return FilterValueSet{}, fmt.Errorf("value %d is empty", i)
+		}
+		set[value] = struct{}{}
+	}
+	return FilterValueSet{values: set}, nil
+}
+
+func (s FilterValueSet) Contains(value string) bool {
+	_, ok := s.values[value]
+	return ok
+}
+
+func (s FilterValueSet) Len() int {
+	return len(s.values)
+}
+
 // ObservationExtraction defines how to extract an observation from an event.
 // This is the new naming aligned with domain terminology (Observation for raw extracted values).
 type ObservationExtraction struct {
 	sourceProperty ObservationSourceProperty
+	namespace      string
+	sourcePath     *PropertyPath
+	sourceDefault  *Decimal
 	unit           Unit
 	filter         *Filter
+	freeTier       *FreeTier
+	minQuantity    *Decimal
+	maxQuantity    *Decimal
 }
 
 func NewObservationExtraction(spec specs.ObservationExtractionSpec) (ObservationExtraction, error) {
-	sourceProperty, err := NewObservationSourceProperty(spec.SourceProperty)
-	if err != nil {
-		return ObservationExtraction{}, fmt.Errorf("invalid source property: %w", err)
+	var sourceProperty ObservationSourceProperty
+	var sourcePath *PropertyPath
+	var sourceDefault *Decimal
+	if spec.SourcePath != "" {
+		if spec.SourceProperty != "" {
+			return ObservationExtraction{}, fmt.Errorf("source property and source path are mutually exclusive")
+		}
+		if spec.Namespace != "" {
+			return ObservationExtraction{}, fmt.Errorf("namespace requires a source property")
+		}
+		path, err := NewPropertyPath(spec.SourcePath)
+		if err != nil {
+			return ObservationExtraction{}, fmt.Errorf("invalid source path: %w", err)
+		}
+		sourcePath = &path
+
+		if spec.SourceDefault != "" {
+			d, err := NewDecimal(spec.SourceDefault)
+			if err != nil {
+				return ObservationExtraction{}, fmt.Errorf("invalid source default: %w", err)
+			}
+			sourceDefault = &d
+		}
+	} else {
+		if spec.SourceDefault != "" {
+			return ObservationExtraction{}, fmt.Errorf("source default requires a source path")
+		}
+		p, err := NewObservationSourceProperty(spec.SourceProperty)
+		if err != nil {
+			return ObservationExtraction{}, fmt.Errorf("invalid source property: %w", err)
+		}
+		sourceProperty = p
 	}
 
 	unit, err := NewUnit(spec.Unit)
@@ -129,17 +817,73 @@ func NewObservationExtraction(spec specs.ObservationExtractionSpec) (Observation
 		filter = &f
 	}
 
+	freeTier, err := NewFreeTier(spec.FreeQuantity, spec.FreeTierStrategy)
+	if err != nil {
+		return ObservationExtraction{}, fmt.Errorf("invalid free tier: %w", err)
+	}
+
+	var minQuantity, maxQuantity *Decimal
+	if spec.MinQuantity != "" {
+		min, err := NewDecimal(spec.MinQuantity)
+		if err != nil {
+			return ObservationExtraction{}, fmt.Errorf("invalid min quantity: %w", err)
+		}
+		minQuantity = &min
+	}
+	if spec.MaxQuantity != "" {
+		max, err := NewDecimal(spec.MaxQuantity)
+		if err != nil {
+			return ObservationExtraction{}, fmt.Errorf("invalid max quantity: %w", err)
+		}
+		maxQuantity = &max
+	}
+	if minQuantity != nil && maxQuantity != nil && minQuantity.Cmp(*maxQuantity) > 0 {
+		return ObservationExtraction{}, fmt.Errorf("min quantity %s exceeds max quantity %s", minQuantity, maxQuantity)
+	}
+
 	return ObservationExtraction{
 		sourceProperty: sourceProperty,
+		namespace:      spec.Namespace,
+		sourcePath:     sourcePath,
+		sourceDefault:  sourceDefault,
 		unit:           unit,
 		filter:         filter,
+		freeTier:       freeTier,
+		minQuantity:    minQuantity,
+		maxQuantity:    maxQuantity,
 	}, nil
 }
 
+// SourceProperty returns the property to extract. Empty when SourcePath is set.
 func (o ObservationExtraction) SourceProperty() ObservationSourceProperty {
 	return o.sourceProperty
 }
 
+// Namespace returns the namespace the source property is read from, or empty
+// if the property is not namespaced.
+func (o ObservationExtraction) Namespace() string {
+	return o.namespace
+}
+
+// SourceKey returns the full property key to extract: the source property,
+// prefixed with the namespace if one is set. Empty when SourcePath is set.
+func (o ObservationExtraction) SourceKey() string {
+	if o.namespace == "" {
+		return o.sourceProperty.ToString()
+	}
+	return o.namespace + "." + o.sourceProperty.ToString()
+}
+
+// SourcePath returns the path to extract, or nil if SourceProperty is used.
+func (o ObservationExtraction) SourcePath() *PropertyPath {
+	return o.sourcePath
+}
+
+// SourceDefault returns the value used when SourcePath does not resolve, or nil.
+func (o ObservationExtraction) SourceDefault() *Decimal {
+	return o.sourceDefault
+}
+
 func (o ObservationExtraction) Unit() Unit {
 	return o.unit
 }
@@ -148,8 +892,64 @@ func (o ObservationExtraction) Filter() *Filter {
 	return o.filter
 }
 
+// FreeTier returns the free tier, or nil if the extraction has none.
+func (o ObservationExtraction) FreeTier() *FreeTier {
+	return o.freeTier
+}
+
+// MinQuantity returns the inclusive lower bound on quantities, or nil if unbounded.
+func (o ObservationExtraction) MinQuantity() *Decimal {
+	return o.minQuantity
+}
+
+// MaxQuantity returns the inclusive upper bound on quantities, or nil if unbounded.
+func (o ObservationExtraction) MaxQuantity() *Decimal {
+	return o.maxQuantity
+}
+
+// ValidateQuantity returns a *QuantityValidationError if quantity is outside
+// the extraction's bounds.
+func (o ObservationExtraction) ValidateQuantity(quantity Decimal) error {
+	belowMin := o.minQuantity != nil && quantity.Cmp(*o.minQuantity) < 0
+	aboveMax := o.maxQuantity != nil && quantity.Cmp(*o.maxQuantity) > 0
+	if !belowMin && !aboveMax {
+		return nil
+	}
+	err := &QuantityValidationError{
+		Unit:             o.unit.ToString(),
+		RecordedQuantity: quantity.String(),
+	}
+	if o.minQuantity != nil {
+		err.MinAllowed = o.minQuantity.String()
+	}
+	if o.maxQuantity != nil {
+		err.MaxAllowed = o.maxQuantity.String()
+	}
+	return err
+}
+
+// QuantityValidationError reports an extracted quantity outside its
+// extraction's MinQuantity/MaxQuantity bounds. An empty bound is unlimited.
+type QuantityValidationError struct {
+	Unit             string
+	RecordedQuantity string
+	MinAllowed       string
+	MaxAllowed       string
+}
+
+func (e *QuantityValidationError) Error() string {
+	min, max := e.MinAllowed, e.MaxAllowed
+	if min == "" {
+		min = "-inf"
+	}
+	if max == "" {
+		max = "+inf"
+	}
+	return fmt.Sprintf("%s quantity %s is outside allowed range [%s, %s]", e.Unit, e.RecordedQuantity, min, max)
+}
+
 // Matches returns true if the filter matches the payload properties (or if no filter exists).
-func (o ObservationExtraction) Matches(properties EventPayloadProperties) bool {
+func (o ObservationExtraction) Matches(properties ImmutableProperties) bool {
 	if o.filter == nil {
 		return true
 	}
@@ -171,3 +971,64 @@ func NewObservationSourceProperty(value string) (ObservationSourceProperty, erro
 func (p ObservationSourceProperty) ToString() string {
 	return p.value
 }
+
+// FreeTier is a quantity of free usage subtracted per event or per window.
+type FreeTier struct {
+	quantity Decimal
+	strategy string
+}
+
+// NewFreeTier returns nil if quantity is empty (no free tier).
+// The strategy defaults to "per-event".
+func NewFreeTier(quantity, strategy string) (*FreeTier, error) {
+	if quantity == "" {
+		if strategy != "" {
+			return nil, fmt.Errorf("free tier strategy requires a free quantity")
+		}
+		return nil, nil
+	}
+
+	free, err := NewFreeQuantity(quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strategy {
+	case "":
+		strategy = "per-event"
+	case "per-event", "per-window":
+		// Valid
+	default:
+		return nil, fmt.Errorf("invalid free tier strategy: %q", strategy)
+	}
+
+	return &FreeTier{quantity: free, strategy: strategy}, nil
+}
+
+// NewFreeQuantity parses a non-negative free quantity.
+func NewFreeQuantity(value string) (Decimal, error) {
+	free, err := NewDecimal(value)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("invalid free quantity: %w", err)
+	}
+	if free.Cmp(NewDecimalFromInt64(0)) < 0 {
+		return Decimal{}, fmt.Errorf("free quantity cannot be negative")
+	}
+	return free, nil
+}
+
+func (f FreeTier) Quantity() Decimal {
+	return f.quantity
+}
+
+func (f FreeTier) Strategy() string {
+	return f.strategy
+}
+
+func (f FreeTier) IsPerEvent() bool {
+	return f.strategy == "per-event"
+}
+
+func (f FreeTier) IsPerWindow() bool {
+	return f.strategy == "per-window"
+}
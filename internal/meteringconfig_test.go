@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"github.com/chrisconley/metron/internal/testutil"
 	"github.com/chrisconley/metron/specs"
 	"testing"
 	"time"
@@ -11,44 +12,10 @@ import (
 
 // Test helpers
 
-type eventPayloadOption func(*specs.EventPayloadSpec)
-
-func withPayloadProperties(properties map[string]string) eventPayloadOption {
-	return func(s *specs.EventPayloadSpec) { s.Properties = properties }
-}
-
-func withPayloadID(id string) eventPayloadOption {
-	return func(s *specs.EventPayloadSpec) { s.ID = id }
-}
-
-func withPayloadSubject(subject string) eventPayloadOption {
-	return func(s *specs.EventPayloadSpec) { s.Subject = subject }
-}
-
-// newTestEventPayload creates an EventPayload with the given options.
-// ID defaults to "test-event" if not specified.
-// WorkspaceID defaults to "workspace-test" if not specified.
-// UniverseID defaults to "universe-test" if not specified.
-// Type defaults to "test.event" if not specified.
-// Subject defaults to "customer:test" if not specified.
-// Time defaults to time.Now() if not specified.
-// Properties defaults to empty map if not specified.
-func newTestEventPayload(opts ...eventPayloadOption) (EventPayload, error) {
-	spec := specs.EventPayloadSpec{
-		ID:          "test-event",
-		WorkspaceID: "workspace-test",
-		UniverseID:  "universe-test",
-		Type:        "test.event",
-		Subject:     "customer:test",
-		Time:        time.Now(),
-		Properties:  make(map[string]string),
-	}
-
-	for _, opt := range opts {
-		opt(&spec)
-	}
-
-	return NewEventPayload(spec)
+// newTestEventPayload creates an EventPayload from the fixture payload with
+// the given options.
+func newTestEventPayload(opts ...testutil.EventPayloadOption) (EventPayload, error) {
+	return NewEventPayload(testutil.FixtureEventPayload(opts...))
 }
 
 func TestMeter(t *testing.T) {
@@ -272,13 +239,13 @@ func TestObservationExtraction_Matches(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		payload, err := newTestEventPayload(withPayloadProperties(map[string]string{
+		payload, err := newTestEventPayload(testutil.WithPayloadProperties(map[string]string{
 			"tokens": "1000",
 			"tier":   "basic",
 		}))
 		require.NoError(t, err)
 
-		assert.True(t, extraction.Matches(payload.Properties))
+		assert.True(t, extraction.Matches(payload.Properties.Freeze()))
 	})
 
 	t.Run("matches when filter condition is met", func(t *testing.T) {
@@ -292,13 +259,13 @@ func TestObservationExtraction_Matches(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		payload, err := newTestEventPayload(withPayloadProperties(map[string]string{
+		payload, err := newTestEventPayload(testutil.WithPayloadProperties(map[string]string{
 			"tokens": "1000",
 			"tier":   "premium",
 		}))
 		require.NoError(t, err)
 
-		assert.True(t, extraction.Matches(payload.Properties))
+		assert.True(t, extraction.Matches(payload.Properties.Freeze()))
 	})
 
 	t.Run("does not match when filter condition is not met", func(t *testing.T) {
@@ -312,13 +279,13 @@ func TestObservationExtraction_Matches(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		payload, err := newTestEventPayload(withPayloadProperties(map[string]string{
+		payload, err := newTestEventPayload(testutil.WithPayloadProperties(map[string]string{
 			"tokens": "1000",
 			"tier":   "basic",
 		}))
 		require.NoError(t, err)
 
-		assert.False(t, extraction.Matches(payload.Properties))
+		assert.False(t, extraction.Matches(payload.Properties.Freeze()))
 	})
 
 	t.Run("does not match when filter property is missing", func(t *testing.T) {
@@ -332,13 +299,13 @@ func TestObservationExtraction_Matches(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		payload, err := newTestEventPayload(withPayloadProperties(map[string]string{
+		payload, err := newTestEventPayload(testutil.WithPayloadProperties(map[string]string{
 			"tokens": "1000",
 			// tier property missing
 		}))
 		require.NoError(t, err)
 
-		assert.False(t, extraction.Matches(payload.Properties))
+		assert.False(t, extraction.Matches(payload.Properties.Freeze()))
 	})
 }
 
@@ -357,3 +324,894 @@ func TestNewObservationSourceProperty(t *testing.T) {
 		assert.Contains(t, err.Error(), "required")
 	})
 }
+
+func TestNewFilter(t *testing.T) {
+	t.Run("creates matchesAny filter", func(t *testing.T) {
+		filter, err := NewFilter(specs.FilterSpec{
+			Property:   "model",
+			MatchesAny: []string{"gpt-4", "gpt-4-turbo"},
+		})
+
+		require.NoError(t, err)
+		require.NotNil(t, filter.MatchesAny())
+		assert.Equal(t, 2, filter.MatchesAny().Len())
+		assert.Nil(t, filter.MatchesNone())
+	})
+
+	t.Run("rejects empty matchesAny list", func(t *testing.T) {
+		_, err := NewFilter(specs.FilterSpec{
+			Property:   "model",
+			MatchesAny: []string{},
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid matchesAny")
+	})
+
+	t.Run("rejects empty matchesNone list", func(t *testing.T) {
+		_, err := NewFilter(specs.FilterSpec{
+			Property:    "model",
+			MatchesNone: []string{},
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid matchesNone")
+	})
+
+	t.Run("rejects matchesAny combined with equals", func(t *testing.T) {
+		_, err := NewFilter(specs.FilterSpec{
+			Property:   "model",
+			Equals:     "gpt-4",
+			MatchesAny: []string{"gpt-4-turbo"},
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "equals cannot be combined")
+	})
+
+	t.Run("rejects matchesAny combined with matchesNone", func(t *testing.T) {
+		_, err := NewFilter(specs.FilterSpec{
+			Property:    "model",
+			MatchesAny:  []string{"gpt-4"},
+			MatchesNone: []string{"gpt-3.5"},
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "matchesAny cannot be combined with matchesNone")
+	})
+}
+
+func TestFilter_Matches(t *testing.T) {
+	properties := NewImmutableProperties(map[string]string{
+		"tokens": "1000",
+		"model":  "gpt-4-turbo",
+	})
+
+	t.Run("matchesAny matches value in list", func(t *testing.T) {
+		filter, err := NewFilter(specs.FilterSpec{Property: "model", MatchesAny: []string{"gpt-4", "gpt-4-turbo"}})
+		require.NoError(t, err)
+
+		assert.True(t, filter.Matches(properties))
+	})
+
+	t.Run("matchesAny does not match value outside list", func(t *testing.T) {
+		filter, err := NewFilter(specs.FilterSpec{Property: "model", MatchesAny: []string{"claude", "gemini"}})
+		require.NoError(t, err)
+
+		assert.False(t, filter.Matches(properties))
+	})
+
+	t.Run("matchesNone does not match value in list", func(t *testing.T) {
+		filter, err := NewFilter(specs.FilterSpec{Property: "model", MatchesNone: []string{"gpt-4-turbo"}})
+		require.NoError(t, err)
+
+		assert.False(t, filter.Matches(properties))
+	})
+
+	t.Run("matchesNone matches value outside list", func(t *testing.T) {
+		filter, err := NewFilter(specs.FilterSpec{Property: "model", MatchesNone: []string{"gpt-3.5"}})
+		require.NoError(t, err)
+
+		assert.True(t, filter.Matches(properties))
+	})
+
+	t.Run("matchesNone does not match when property is missing", func(t *testing.T) {
+		filter, err := NewFilter(specs.FilterSpec{Property: "region", MatchesNone: []string{"eu-west-1"}})
+		require.NoError(t, err)
+
+		assert.False(t, filter.Matches(properties))
+	})
+}
+
+func TestMeter_FreeTier(t *testing.T) {
+	meterTokens := func(t *testing.T, tokens string, freeQuantity string) []specs.MeterRecordSpec {
+		t.Helper()
+		payload := specs.EventPayloadSpec{
+			ID:          "event-123",
+			WorkspaceID: "workspace-test",
+			UniverseID:  "universe-test",
+			Type:        "llm.completion",
+			Subject:     "customer:test",
+			Time:        time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC),
+			Properties:  map[string]string{"tokens": tokens},
+		}
+		config := specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "tokens", Unit: "tokens", FreeQuantity: freeQuantity},
+			},
+		}
+		records, err := Meter(payload, config)
+		require.NoError(t, err)
+		return records
+	}
+
+	t.Run("quantity at free threshold produces no record", func(t *testing.T) {
+		assert.Empty(t, meterTokens(t, "1000", "1000"))
+	})
+
+	t.Run("quantity exceeding free tier produces net quantity", func(t *testing.T) {
+		records := meterTokens(t, "1250", "1000")
+
+		require.Len(t, records, 1)
+		assert.Equal(t, "250", records[0].Observations[0].Quantity)
+	})
+
+	t.Run("zero quantity with free tier produces no record", func(t *testing.T) {
+		assert.Empty(t, meterTokens(t, "0", "0"))
+	})
+
+	t.Run("per-window strategy leaves quantity unchanged", func(t *testing.T) {
+		extraction, err := NewObservationExtraction(specs.ObservationExtractionSpec{
+			SourceProperty:   "tokens",
+			Unit:             "tokens",
+			FreeQuantity:     "1000",
+			FreeTierStrategy: "per-window",
+		})
+		require.NoError(t, err)
+		require.NotNil(t, extraction.FreeTier())
+		assert.True(t, extraction.FreeTier().IsPerWindow())
+	})
+}
+
+func TestNewFreeTier(t *testing.T) {
+	t.Run("defaults to per-event strategy", func(t *testing.T) {
+		freeTier, err := NewFreeTier("1000", "")
+
+		require.NoError(t, err)
+		require.NotNil(t, freeTier)
+		assert.True(t, freeTier.IsPerEvent())
+	})
+
+	t.Run("rejects negative free quantity", func(t *testing.T) {
+		_, err := NewFreeTier("-1", "")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "free quantity cannot be negative")
+	})
+
+	t.Run("rejects unknown strategy", func(t *testing.T) {
+		_, err := NewFreeTier("1000", "per-month")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid free tier strategy")
+	})
+
+	t.Run("rejects strategy without quantity", func(t *testing.T) {
+		_, err := NewFreeTier("", "per-window")
+
+		require.Error(t, err)
+	})
+}
+
+func TestMeter_DimensionTransforms(t *testing.T) {
+	meterWithTransforms := func(t *testing.T, transforms ...specs.DimensionTransformSpec) map[string]string {
+		t.Helper()
+		payload := specs.EventPayloadSpec{
+			ID:          "event-123",
+			WorkspaceID: "workspace-test",
+			UniverseID:  "universe-test",
+			Type:        "llm.completion",
+			Subject:     "customer:test",
+			Time:        time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC),
+			Properties:  map[string]string{"tokens": "100", "model_version": "gpt-4-0314"},
+		}
+		config := specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "tokens", Unit: "tokens"},
+			},
+			DimensionTransforms: transforms,
+		}
+		records, err := Meter(payload, config)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		return records[0].Dimensions
+	}
+
+	t.Run("passthrough renames into a new key", func(t *testing.T) {
+		dimensions := meterWithTransforms(t, specs.DimensionTransformSpec{
+			FromProperty: "model_version", ToKey: "model", Transform: "passthrough",
+		})
+
+		assert.Equal(t, map[string]string{"model_version": "gpt-4-0314", "model": "gpt-4-0314"}, dimensions)
+	})
+
+	t.Run("prefix strip", func(t *testing.T) {
+		dimensions := meterWithTransforms(t, specs.DimensionTransformSpec{
+			FromProperty: "model_version", ToKey: "model", Transform: "prefix-strip:gpt-",
+		})
+
+		assert.Equal(t, "4-0314", dimensions["model"])
+	})
+
+	t.Run("regex replacement", func(t *testing.T) {
+		dimensions := meterWithTransforms(t, specs.DimensionTransformSpec{
+			FromProperty: "model_version", ToKey: "model", Transform: `regex:^(gpt-\d+)-\d{4}$:$1`,
+		})
+
+		assert.Equal(t, "gpt-4", dimensions["model"])
+	})
+
+	t.Run("drop original removes the source dimension", func(t *testing.T) {
+		dimensions := meterWithTransforms(t, specs.DimensionTransformSpec{
+			FromProperty: "model_version", ToKey: "model", Transform: "passthrough", DropOriginal: true,
+		})
+
+		assert.Equal(t, map[string]string{"model": "gpt-4-0314"}, dimensions)
+	})
+
+	t.Run("drop original does not depend on transform order", func(t *testing.T) {
+		rename := specs.DimensionTransformSpec{
+			FromProperty: "model_version", ToKey: "model", Transform: "prefix-strip:gpt-", DropOriginal: true,
+		}
+		rewrite := specs.DimensionTransformSpec{
+			FromProperty: "model_version", ToKey: "model_version", Transform: `regex:-\d{4}$:`,
+		}
+
+		forward := meterWithTransforms(t, rename, rewrite)
+		reversed := meterWithTransforms(t, rewrite, rename)
+
+		expected := map[string]string{"model": "4-0314", "model_version": "gpt-4"}
+		assert.Equal(t, expected, forward)
+		assert.Equal(t, expected, reversed)
+	})
+
+	t.Run("missing source property is skipped", func(t *testing.T) {
+		dimensions := meterWithTransforms(t, specs.DimensionTransformSpec{
+			FromProperty: "region", ToKey: "area", Transform: "passthrough",
+		})
+
+		assert.Equal(t, map[string]string{"model_version": "gpt-4-0314"}, dimensions)
+	})
+
+	t.Run("unknown transform type returns error", func(t *testing.T) {
+		_, err := NewMeteringConfig(specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "tokens", Unit: "tokens"},
+			},
+			DimensionTransforms: []specs.DimensionTransformSpec{
+				{FromProperty: "model_version", Transform: "uppercase"},
+			},
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown transform")
+	})
+}
+
+func TestMeter_DimensionCoercions(t *testing.T) {
+	meterWithCoercions := func(t *testing.T, statusCode string, coercions ...specs.DimensionCoercionSpec) map[string]string {
+		t.Helper()
+		payload := specs.EventPayloadSpec{
+			ID:          "event-123",
+			WorkspaceID: "workspace-test",
+			UniverseID:  "universe-test",
+			Type:        "api.request",
+			Subject:     "customer:test",
+			Time:        time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC),
+			Properties:  map[string]string{"calls": "1", "status_code": statusCode},
+		}
+		config := specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "calls", Unit: "api-calls"},
+			},
+			DimensionCoercions: coercions,
+		}
+		records, err := Meter(payload, config)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		return records[0].Dimensions
+	}
+	statusClass := specs.DimensionCoercionSpec{
+		FromKey:      "status_code",
+		ToKey:        "status_class",
+		Mapping:      map[string]string{"200": "2xx", "201": "2xx", "404": "4xx"},
+		DefaultValue: "other",
+	}
+
+	t.Run("exact mapping applied", func(t *testing.T) {
+		dimensions := meterWithCoercions(t, "201", statusClass)
+
+		assert.Equal(t, "2xx", dimensions["status_class"])
+	})
+
+	t.Run("unmapped value uses default", func(t *testing.T) {
+		dimensions := meterWithCoercions(t, "503", statusClass)
+
+		assert.Equal(t, "other", dimensions["status_class"])
+	})
+
+	t.Run("unmapped value with no default preserves original value", func(t *testing.T) {
+		coercion := statusClass
+		coercion.DefaultValue = ""
+
+		dimensions := meterWithCoercions(t, "503", coercion)
+
+		assert.Equal(t, "503", dimensions["status_class"])
+	})
+
+	t.Run("key rename removes original", func(t *testing.T) {
+		dimensions := meterWithCoercions(t, "200", statusClass)
+
+		assert.Equal(t, map[string]string{"status_class": "2xx"}, dimensions)
+	})
+
+	t.Run("same-key mapping replaces value in place", func(t *testing.T) {
+		dimensions := meterWithCoercions(t, "404", specs.DimensionCoercionSpec{
+			FromKey: "status_code",
+			Mapping: map[string]string{"404": "not-found"},
+		})
+
+		assert.Equal(t, map[string]string{"status_code": "not-found"}, dimensions)
+	})
+
+	t.Run("empty mapping returns error", func(t *testing.T) {
+		_, err := NewDimensionCoercion(specs.DimensionCoercionSpec{FromKey: "status_code"})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "mapping is required")
+	})
+}
+
+func TestMeter_SourcePath(t *testing.T) {
+	meterWithConfig := func(t *testing.T, metadata string, config specs.MeteringConfigSpec) ([]specs.MeterRecordSpec, error) {
+		t.Helper()
+		payload := specs.EventPayloadSpec{
+			ID:          "event-123",
+			WorkspaceID: "workspace-test",
+			UniverseID:  "universe-test",
+			Type:        "llm.completion",
+			Subject:     "customer:test",
+			Time:        time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC),
+			Properties:  map[string]string{"metadata": metadata},
+		}
+		return Meter(payload, config)
+	}
+	metadata := `{"model": "gpt-4", "usage": {"tokens": 150}, "choices": [{"tokens": 40}, {"tokens": 60}]}`
+
+	t.Run("simple nested path", func(t *testing.T) {
+		records, err := meterWithConfig(t, metadata, specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{SourcePath: "$.metadata.usage.tokens", Unit: "tokens"},
+			},
+			DimensionPaths: []specs.DimensionPathSpec{
+				{Key: "model", Path: "$.metadata.model"},
+			},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "150", records[0].Observations[0].Quantity)
+		assert.Equal(t, "gpt-4", records[0].Dimensions["model"])
+		assert.NotContains(t, records[0].Dimensions, "metadata", "raw JSON source is not a dimension")
+	})
+
+	t.Run("nested path with array index", func(t *testing.T) {
+		records, err := meterWithConfig(t, metadata, specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{SourcePath: "$.metadata.choices[1].tokens", Unit: "tokens"},
+			},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "60", records[0].Observations[0].Quantity)
+	})
+
+	t.Run("missing path uses default", func(t *testing.T) {
+		records, err := meterWithConfig(t, metadata, specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{SourcePath: "$.metadata.usage.cached_tokens", SourceDefault: "0", Unit: "cached-tokens"},
+				{SourcePath: "$.metadata.choices[5].tokens", Unit: "tokens"},
+			},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		require.Len(t, records[0].Observations, 1)
+		assert.Equal(t, "cached-tokens", records[0].Observations[0].Unit)
+		assert.Equal(t, "0", records[0].Observations[0].Quantity)
+	})
+
+	t.Run("invalid JSON in property returns error", func(t *testing.T) {
+		_, err := meterWithConfig(t, "{not json", specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{SourcePath: "$.metadata.usage.tokens", Unit: "tokens"},
+			},
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not valid JSON")
+	})
+
+	t.Run("source property and source path are mutually exclusive", func(t *testing.T) {
+		_, err := NewObservationExtraction(specs.ObservationExtractionSpec{
+			SourceProperty: "tokens",
+			SourcePath:     "$.metadata.tokens",
+			Unit:           "tokens",
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "mutually exclusive")
+	})
+}
+
+func TestMeter_ComputedProperties(t *testing.T) {
+	payload := specs.EventPayloadSpec{
+		ID:          "event-123",
+		WorkspaceID: "workspace-test",
+		UniverseID:  "universe-test",
+		Type:        "llm.completion",
+		Subject:     "customer:test",
+		Time:        time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC),
+		Properties:  map[string]string{"input_tokens": "100", "output_tokens": "50", "price": "0.5"},
+	}
+
+	t.Run("sum of two properties", func(t *testing.T) {
+		records, err := Meter(payload, specs.MeteringConfigSpec{
+			ComputedProperties: []specs.ComputedPropertySpec{
+				{Key: "total_tokens", Expression: "{input_tokens} + {output_tokens}"},
+			},
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "total_tokens", Unit: "tokens"},
+			},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "150", records[0].Observations[0].Quantity)
+		assert.NotContains(t, payload.Properties, "total_tokens", "input payload must not be modified")
+	})
+
+	t.Run("product referencing another computed property", func(t *testing.T) {
+		records, err := Meter(payload, specs.MeteringConfigSpec{
+			ComputedProperties: []specs.ComputedPropertySpec{
+				{Key: "cost", Expression: "{total_tokens} * {price}"},
+				{Key: "total_tokens", Expression: "{input_tokens} + {output_tokens}"},
+			},
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "cost", Unit: "usd"},
+			},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "75.0", records[0].Observations[0].Quantity)
+	})
+
+	t.Run("computed property referenced in filter", func(t *testing.T) {
+		config := specs.MeteringConfigSpec{
+			ComputedProperties: []specs.ComputedPropertySpec{
+				{Key: "total_tokens", Expression: "{input_tokens} + {output_tokens}"},
+			},
+			Observations: []specs.ObservationExtractionSpec{
+				{
+					SourceProperty: "output_tokens",
+					Unit:           "small-request-output-tokens",
+					Filter:         &specs.FilterSpec{Property: "total_tokens", MatchesAny: []string{"100", "150"}},
+				},
+				{
+					SourceProperty: "output_tokens",
+					Unit:           "large-request-output-tokens",
+					Filter:         &specs.FilterSpec{Property: "total_tokens", MatchesNone: []string{"100", "150"}},
+				},
+			},
+		}
+
+		records, err := Meter(payload, config)
+
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		require.Len(t, records[0].Observations, 1)
+		assert.Equal(t, "small-request-output-tokens", records[0].Observations[0].Unit)
+	})
+
+	t.Run("circular reference returns error", func(t *testing.T) {
+		_, err := NewMeteringConfig(specs.MeteringConfigSpec{
+			ComputedProperties: []specs.ComputedPropertySpec{
+				{Key: "a", Expression: "{b} + 1"},
+				{Key: "b", Expression: "{c} * 2"},
+				{Key: "c", Expression: "{a} - 1"},
+			},
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "a", Unit: "units"},
+			},
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "circular reference")
+		assert.Contains(t, err.Error(), "a -> b -> c -> a")
+	})
+
+	t.Run("invalid expression returns error", func(t *testing.T) {
+		_, err := NewMeteringConfig(specs.MeteringConfigSpec{
+			ComputedProperties: []specs.ComputedPropertySpec{
+				{Key: "total", Expression: "{input_tokens} +"},
+			},
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "total", Unit: "tokens"},
+			},
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid expression")
+	})
+}
+
+func TestMergeConfigs(t *testing.T) {
+	base := specs.MeteringConfigSpec{
+		Observations: []specs.ObservationExtractionSpec{
+			{SourceProperty: "request_count", Unit: "api-calls"},
+			{
+				SourceProperty: "tokens",
+				Unit:           "tokens",
+				Filter:         &specs.FilterSpec{Property: "tier", Equals: "standard"},
+			},
+		},
+	}
+
+	t.Run("base and override observations are combined", func(t *testing.T) {
+		override := specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "bytes", Unit: "bytes"},
+			},
+		}
+
+		merged, err := MergeConfigs(base, override)
+
+		require.NoError(t, err)
+		assert.Equal(t, []specs.ObservationExtractionSpec{
+			base.Observations[0],
+			base.Observations[1],
+			override.Observations[0],
+		}, merged.Observations)
+	})
+
+	t.Run("override replaces base extraction for same property", func(t *testing.T) {
+		override := specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{
+					SourceProperty: "tokens",
+					Unit:           "tokens",
+					Filter:         &specs.FilterSpec{Property: "tier", Equals: "premium"},
+				},
+			},
+		}
+
+		merged, err := MergeConfigs(base, override)
+
+		require.NoError(t, err)
+		assert.Equal(t, []specs.ObservationExtractionSpec{
+			base.Observations[0],
+			override.Observations[0],
+		}, merged.Observations)
+	})
+
+	t.Run("computed properties merge by key", func(t *testing.T) {
+		withComputed := base
+		withComputed.ComputedProperties = []specs.ComputedPropertySpec{
+			{Key: "total", Expression: "{a} + {b}"},
+			{Key: "ratio", Expression: "{a} / {b}"},
+		}
+		override := specs.MeteringConfigSpec{
+			ComputedProperties: []specs.ComputedPropertySpec{{Key: "total", Expression: "{a} + {b} + {c}"}},
+		}
+
+		merged, err := MergeConfigs(withComputed, override)
+
+		require.NoError(t, err)
+		assert.Equal(t, []specs.ComputedPropertySpec{
+			{Key: "ratio", Expression: "{a} / {b}"},
+			{Key: "total", Expression: "{a} + {b} + {c}"},
+		}, merged.ComputedProperties)
+	})
+
+	t.Run("base config cycle returns error", func(t *testing.T) {
+		first := specs.MeteringConfigSpec{Observations: base.Observations}
+		second := specs.MeteringConfigSpec{Observations: base.Observations, BaseConfig: &first}
+		first.BaseConfig = &second
+
+		_, err := NewMeteringConfig(first)
+		assert.ErrorIs(t, err, ErrBaseConfigCycle)
+
+		_, err = MergeConfigs(first, specs.MeteringConfigSpec{})
+		assert.ErrorIs(t, err, ErrBaseConfigCycle)
+
+		_, err = Meter(testutil.FixtureEventPayload(), first)
+		assert.ErrorIs(t, err, ErrBaseConfigCycle)
+	})
+
+	t.Run("nil base uses override only", func(t *testing.T) {
+		override := specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "bytes", Unit: "bytes"},
+			},
+		}
+
+		config, err := NewMeteringConfig(override)
+
+		require.NoError(t, err)
+		assert.Nil(t, config.InheritedFrom())
+		require.Len(t, config.Observations(), 1)
+		assert.Equal(t, "bytes", config.Observations()[0].SourceProperty().ToString())
+	})
+
+	t.Run("config with base config tracks provenance", func(t *testing.T) {
+		override := specs.MeteringConfigSpec{
+			BaseConfig: &base,
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "bytes", Unit: "bytes"},
+			},
+		}
+
+		config, err := NewMeteringConfig(override)
+
+		require.NoError(t, err)
+		assert.Len(t, config.Observations(), 3)
+		require.NotNil(t, config.InheritedFrom())
+		assert.Len(t, config.InheritedFrom().Observations(), 2)
+	})
+
+	t.Run("meter applies inherited observations", func(t *testing.T) {
+		payload := specs.EventPayloadSpec{
+			ID:          "event-123",
+			WorkspaceID: "workspace-test",
+			UniverseID:  "universe-test",
+			Type:        "api.request",
+			Subject:     "customer:test",
+			Time:        time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC),
+			Properties:  map[string]string{"request_count": "1", "bytes": "512"},
+		}
+
+		records, err := Meter(payload, specs.MeteringConfigSpec{
+			BaseConfig: &base,
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "bytes", Unit: "bytes"},
+			},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		var units []string
+		for _, observation := range records[0].Observations {
+			units = append(units, observation.Unit)
+		}
+		assert.ElementsMatch(t, []string{"api-calls", "bytes"}, units)
+	})
+
+	t.Run("invalid base config is rejected", func(t *testing.T) {
+		_, err := NewMeteringConfig(specs.MeteringConfigSpec{
+			BaseConfig: &specs.MeteringConfigSpec{},
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "bytes", Unit: "bytes"},
+			},
+		})
+
+		assert.ErrorContains(t, err, "invalid base config")
+	})
+}
+
+func TestMeter_QuantityBounds(t *testing.T) {
+	meterWithBounds := func(quantity, min, max string) ([]specs.MeterRecordSpec, error) {
+		payload := specs.EventPayloadSpec{
+			ID:          "event-123",
+			WorkspaceID: "workspace-test",
+			UniverseID:  "universe-test",
+			Type:        "host.metrics",
+			Subject:     "customer:test",
+			Time:        time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC),
+			Properties:  map[string]string{"cpu_percent": quantity},
+		}
+		return Meter(payload, specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "cpu_percent", Unit: "percent", MinQuantity: min, MaxQuantity: max},
+			},
+		})
+	}
+
+	t.Run("within bounds succeeds", func(t *testing.T) {
+		records, err := meterWithBounds("42.5", "0", "100")
+
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "42.5", records[0].Observations[0].Quantity)
+	})
+
+	t.Run("exactly min bound succeeds", func(t *testing.T) {
+		_, err := meterWithBounds("0", "0", "100")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("exactly max bound succeeds", func(t *testing.T) {
+		_, err := meterWithBounds("100.0", "0", "100")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("below min returns QuantityValidationError", func(t *testing.T) {
+		_, err := meterWithBounds("-1", "0", "100")
+
+		var validationErr *QuantityValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "-1", validationErr.RecordedQuantity)
+		assert.Equal(t, "0", validationErr.MinAllowed)
+		assert.Equal(t, "100", validationErr.MaxAllowed)
+	})
+
+	t.Run("above max returns QuantityValidationError", func(t *testing.T) {
+		_, err := meterWithBounds("100.1", "0", "100")
+
+		var validationErr *QuantityValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "100.1", validationErr.RecordedQuantity)
+		assert.EqualError(t, err, "percent quantity 100.1 is outside allowed range [0, 100]")
+	})
+
+	t.Run("only min set", func(t *testing.T) {
+		_, err := meterWithBounds("1000000", "0", "")
+		assert.NoError(t, err)
+
+		_, err = meterWithBounds("-5", "0", "")
+		var validationErr *QuantityValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Empty(t, validationErr.MaxAllowed)
+	})
+
+	t.Run("only max set", func(t *testing.T) {
+		_, err := meterWithBounds("-1000000", "", "100")
+		assert.NoError(t, err)
+
+		_, err = meterWithBounds("101", "", "100")
+		var validationErr *QuantityValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.EqualError(t, err, "percent quantity 101 is outside allowed range [-inf, 100]")
+	})
+
+	t.Run("min greater than max is rejected", func(t *testing.T) {
+		_, err := meterWithBounds("50", "100", "0")
+
+		assert.ErrorContains(t, err, "min quantity 100 exceeds max quantity 0")
+	})
+}
+
+func TestMeter_Namespace(t *testing.T) {
+	meterWithProperties := func(t *testing.T, properties map[string]string, extraction specs.ObservationExtractionSpec) specs.MeterRecordSpec {
+		t.Helper()
+		payload := specs.EventPayloadSpec{
+			ID:          "event-123",
+			WorkspaceID: "workspace-test",
+			UniverseID:  "universe-test",
+			Type:        "llm.completion",
+			Subject:     "customer:test",
+			Time:        time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC),
+			Properties:  properties,
+		}
+		config := specs.MeteringConfigSpec{Observations: []specs.ObservationExtractionSpec{extraction}}
+		records, err := Meter(payload, config)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		return records[0]
+	}
+
+	t.Run("namespaced property extracted by its short name", func(t *testing.T) {
+		record := meterWithProperties(t,
+			map[string]string{"llm.tokens": "1500", "llm.model": "gpt-4"},
+			specs.ObservationExtractionSpec{Namespace: "llm", SourceProperty: "tokens", Unit: "tokens"},
+		)
+
+		assert.Equal(t, "1500", record.Observations[0].Quantity)
+		assert.Equal(t, map[string]string{"model": "gpt-4"}, record.Dimensions)
+	})
+
+	t.Run("non-namespaced properties unaffected", func(t *testing.T) {
+		record := meterWithProperties(t,
+			map[string]string{"llm.tokens": "1500", "region": "us-east-1", "storage.bytes": "42"},
+			specs.ObservationExtractionSpec{Namespace: "llm", SourceProperty: "tokens", Unit: "tokens"},
+		)
+
+		assert.Equal(t, map[string]string{"region": "us-east-1", "storage.bytes": "42"}, record.Dimensions)
+	})
+
+	t.Run("namespaced property takes priority over unprefixed one", func(t *testing.T) {
+		record := meterWithProperties(t,
+			map[string]string{"llm.tokens": "1500", "tokens": "7", "llm.model": "gpt-4", "model": "legacy"},
+			specs.ObservationExtractionSpec{Namespace: "llm", SourceProperty: "tokens", Unit: "tokens"},
+		)
+
+		assert.Equal(t, "1500", record.Observations[0].Quantity)
+		assert.Equal(t, "gpt-4", record.Dimensions["model"])
+	})
+
+	t.Run("namespace requires source property", func(t *testing.T) {
+		_, err := NewObservationExtraction(specs.ObservationExtractionSpec{Namespace: "llm", SourcePath: "$.usage.tokens", Unit: "tokens"})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "namespace requires a source property")
+	})
+}
+
+func TestMeter_ExtractionErrors(t *testing.T) {
+	meterWithProperties := func(properties map[string]string, extractions ...specs.ObservationExtractionSpec) ([]specs.MeterRecordSpec, error) {
+		payload := specs.EventPayloadSpec{
+			ID:          "event-123",
+			WorkspaceID: "workspace-test",
+			UniverseID:  "universe-test",
+			Type:        "llm.completion",
+			Subject:     "customer:test",
+			Time:        time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC),
+			Properties:  properties,
+		}
+		return Meter(payload, specs.MeteringConfigSpec{Observations: extractions})
+	}
+	inputTokens := specs.ObservationExtractionSpec{SourceProperty: "input_tokens", Unit: "input-tokens"}
+	outputTokens := specs.ObservationExtractionSpec{SourceProperty: "output_tokens", Unit: "output-tokens"}
+
+	t.Run("single extraction error", func(t *testing.T) {
+		_, err := meterWithProperties(map[string]string{"input_tokens": "100"}, inputTokens, outputTokens)
+
+		var multi *MultiExtractionError
+		require.ErrorAs(t, err, &multi)
+		require.Len(t, multi.Errors, 1)
+		assert.Equal(t, 1, multi.Errors[0].ExtractionIndex)
+		assert.Equal(t, "output_tokens", multi.Errors[0].SourceProperty)
+		assert.EqualError(t, err, `source property "output_tokens" not found in payload`)
+	})
+
+	t.Run("two extraction errors both reported", func(t *testing.T) {
+		_, err := meterWithProperties(map[string]string{"output_tokens": "many"}, inputTokens, outputTokens)
+
+		var multi *MultiExtractionError
+		require.ErrorAs(t, err, &multi)
+		require.Len(t, multi.Errors, 2)
+		assert.Equal(t, "input_tokens", multi.Errors[0].SourceProperty)
+		assert.Equal(t, "output_tokens", multi.Errors[1].SourceProperty)
+		assert.Contains(t, err.Error(), "2 extractions failed")
+		assert.Contains(t, err.Error(), `"input_tokens" not found`)
+		assert.Contains(t, err.Error(), `"many" is not numeric`)
+	})
+
+	t.Run("no errors returns nil", func(t *testing.T) {
+		records, err := meterWithProperties(map[string]string{"input_tokens": "100", "output_tokens": "50"}, inputTokens, outputTokens)
+
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Len(t, records[0].Observations, 2)
+	})
+
+	t.Run("partial success with error returns error, not partial records", func(t *testing.T) {
+		records, err := meterWithProperties(map[string]string{"input_tokens": "100"}, inputTokens, outputTokens)
+
+		require.Error(t, err)
+		assert.Nil(t, records)
+	})
+
+	t.Run("wrapped errors remain inspectable", func(t *testing.T) {
+		bounded := specs.ObservationExtractionSpec{SourceProperty: "percent", Unit: "percent", MaxQuantity: "100"}
+
+		_, err := meterWithProperties(map[string]string{"percent": "101"}, bounded, inputTokens)
+
+		var validationErr *QuantityValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "101", validationErr.RecordedQuantity)
+	})
+}
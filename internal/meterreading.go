@@ -1,8 +1,10 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
 	specs "github.com/chrisconley/metron/specs"
+	"strings"
 	"time"
 )
 
@@ -11,12 +13,27 @@ type MeterReading struct {
 	WorkspaceID    MeterReadingWorkspaceID
 	UniverseID     MeterReadingUniverseID
 	Subject        MeterReadingSubject
+	Dimensions     MeterReadingDimensions
 	Window         TimeWindow
 	ComputedValues []ComputedValue
-	Aggregation    MeterReadingAggregation
-	RecordCount    MeterReadingRecordCount
-	CreatedAt      MeterReadingCreatedAt
-	MaxMeteredAt   MeterReadingMaxMeteredAt
+	// Unit is the unit of the first computed value.
+	Unit         Unit
+	Aggregation  MeterReadingAggregation
+	RecordCount  MeterReadingRecordCount
+	CreatedAt    MeterReadingCreatedAt
+	MaxMeteredAt MeterReadingMaxMeteredAt
+	Version      MeterReadingVersion
+	WasCapped    bool
+	Metadata     MeterReadingMetadata
+	// ConfidenceInterval is nil unless the value was estimated with one.
+	ConfidenceInterval *ConfidenceInterval
+	// SourceRecordIDs is nil unless the config included source IDs.
+	SourceRecordIDs          []string
+	SourceRecordIDsTruncated bool
+	// ResetCount is the number of counter resets detected while aggregating.
+	ResetCount int
+	// Timezone is the IANA timezone name the window is defined in, or empty for UTC.
+	Timezone string
 }
 
 func NewMeterReading(spec specs.MeterReadingSpec) (MeterReading, error) {
@@ -40,10 +57,20 @@ func NewMeterReading(spec specs.MeterReadingSpec) (MeterReading, error) {
 		return MeterReading{}, fmt.Errorf("invalid subject: %w", err)
 	}
 
+	dimensions := NewMeterReadingDimensions()
+	for name, value := range spec.Dimensions {
+		dimensions.Set(name, value)
+	}
+
 	window, err := NewTimeWindow(spec.Window)
 	if err != nil {
 		return MeterReading{}, fmt.Errorf("invalid window: %w", err)
 	}
+	if spec.Timezone != "" {
+		if _, err := time.LoadLocation(spec.Timezone); err != nil {
+			return MeterReading{}, fmt.Errorf("invalid timezone: %w", err)
+		}
+	}
 
 	// Convert ComputedValues from spec to domain objects
 	if len(spec.ComputedValues) == 0 {
@@ -70,6 +97,11 @@ func NewMeterReading(spec specs.MeterReadingSpec) (MeterReading, error) {
 		computedValues[i] = NewComputedValue(quantity, unit, aggregation)
 	}
 
+	unit := computedValues[0].Unit()
+	if spec.Unit != "" && spec.Unit != unit.ToString() {
+		return MeterReading{}, fmt.Errorf("unit %q does not match computed value unit %q", spec.Unit, unit.ToString())
+	}
+
 	aggregation, err := NewMeterReadingAggregation(spec.Aggregation)
 	if err != nil {
 		return MeterReading{}, fmt.Errorf("invalid aggregation: %w", err)
@@ -90,27 +122,373 @@ func NewMeterReading(spec specs.MeterReadingSpec) (MeterReading, error) {
 		return MeterReading{}, fmt.Errorf("invalid max metered at: %w", err)
 	}
 
+	version, err := NewMeterReadingVersion(spec.Version)
+	if err != nil {
+		return MeterReading{}, fmt.Errorf("invalid version: %w", err)
+	}
+
+	confidenceInterval, err := ConfidenceIntervalFromSpec(spec)
+	if err != nil {
+		return MeterReading{}, fmt.Errorf("invalid confidence interval: %w", err)
+	}
+
 	return MeterReading{
-		ID:             id,
-		WorkspaceID:    workspaceID,
-		UniverseID:     universeID,
-		Subject:        subject,
-		Window:         window,
-		ComputedValues: computedValues,
-		Aggregation:    aggregation,
-		RecordCount:    recordCount,
-		CreatedAt:      createdAt,
-		MaxMeteredAt:   maxMeteredAt,
+		ID:                       id,
+		WorkspaceID:              workspaceID,
+		UniverseID:               universeID,
+		Subject:                  subject,
+		Dimensions:               dimensions,
+		Window:                   window,
+		ComputedValues:           computedValues,
+		Unit:                     unit,
+		Aggregation:              aggregation,
+		RecordCount:              recordCount,
+		CreatedAt:                createdAt,
+		MaxMeteredAt:             maxMeteredAt,
+		Version:                  version,
+		WasCapped:                spec.WasCapped,
+		Metadata:                 NewMeterReadingMetadata(spec.Metadata),
+		ConfidenceInterval:       confidenceInterval,
+		SourceRecordIDs:          spec.SourceRecordIDs,
+		SourceRecordIDsTruncated: spec.SourceRecordIDsTruncated,
+		ResetCount:               spec.ResetCount,
+		Timezone:                 spec.Timezone,
 	}, nil
 }
 
+// MeterReadingOption sets one field of a reading built by
+// NewMeterReadingWithOptions, returning error if the value is invalid.
+type MeterReadingOption func(*MeterReading) error
+
+// NewMeterReadingWithOptions builds a reading field by field, without an
+// intermediate spec. See MeterReadingBuilder for a fluent alternative.
+//
+// ID, workspace ID, universe ID, subject, window, max metered at, and at least
+// one computed value are required. The aggregation defaults to that of the
+// first computed value, and values added with WithMeterReadingValue take the
+// reading's aggregation. Created at defaults to now and version to
+// InitialMeterReadingVersion. Unit is set from the first computed value.
+// Returns error if a required field is missing or an option's value is
+// invalid.
+func NewMeterReadingWithOptions(opts ...MeterReadingOption) (MeterReading, error) {
+	createdAt, err := NewMeterReadingCreatedAt(time.Now())
+	if err != nil {
+		return MeterReading{}, fmt.Errorf("invalid created at: %w", err)
+	}
+	reading := MeterReading{
+		Dimensions: NewMeterReadingDimensions(),
+		CreatedAt:  createdAt,
+		Version:    InitialMeterReadingVersion(),
+		Metadata:   NewMeterReadingMetadata(nil),
+	}
+	for _, opt := range opts {
+		if err := opt(&reading); err != nil {
+			return MeterReading{}, err
+		}
+	}
+
+	switch {
+	case reading.ID == MeterReadingID{}:
+		return MeterReading{}, fmt.Errorf("invalid ID: %w", ErrEmptyID)
+	case reading.WorkspaceID == MeterReadingWorkspaceID{}:
+		return MeterReading{}, fmt.Errorf("invalid workspace ID: %w", ErrEmptyWorkspaceID)
+	case reading.UniverseID == MeterReadingUniverseID{}:
+		return MeterReading{}, fmt.Errorf("invalid universe ID: universe ID is required")
+	case reading.Subject == MeterReadingSubject{}:
+		return MeterReading{}, fmt.Errorf("invalid subject: %w", ErrEmptySubject)
+	case reading.Window.Start().ToTime().IsZero():
+		return MeterReading{}, fmt.Errorf("invalid window: window is required")
+	case len(reading.ComputedValues) == 0:
+		return MeterReading{}, fmt.Errorf("at least one computed value is required")
+	case reading.MaxMeteredAt.ToTime().IsZero():
+		return MeterReading{}, fmt.Errorf("invalid max metered at: %w", newValidationError(ErrZeroTime, "max metered at is required"))
+	}
+
+	if reading.Aggregation == (MeterReadingAggregation{}) {
+		reading.Aggregation = reading.ComputedValues[0].Aggregation()
+	}
+	if reading.Aggregation == (MeterReadingAggregation{}) {
+		return MeterReading{}, fmt.Errorf("invalid aggregation: %w", newValidationError(ErrInvalidAggregation, "aggregation is required"))
+	}
+	for i, value := range reading.ComputedValues {
+		if value.Aggregation() == (MeterReadingAggregation{}) {
+			reading.ComputedValues[i] = NewComputedValue(value.Quantity(), value.Unit(), reading.Aggregation)
+		}
+	}
+	reading.Unit = reading.ComputedValues[0].Unit()
+	return reading, nil
+}
+
+func WithMeterReadingID(id string) MeterReadingOption {
+	return func(r *MeterReading) error {
+		value, err := NewMeterReadingID(id)
+		if err != nil {
+			return fmt.Errorf("invalid ID: %w", err)
+		}
+		r.ID = value
+		return nil
+	}
+}
+
+func WithMeterReadingWorkspaceID(workspaceID string) MeterReadingOption {
+	return func(r *MeterReading) error {
+		value, err := NewMeterReadingWorkspaceID(workspaceID)
+		if err != nil {
+			return fmt.Errorf("invalid workspace ID: %w", err)
+		}
+		r.WorkspaceID = value
+		return nil
+	}
+}
+
+func WithMeterReadingUniverseID(universeID string) MeterReadingOption {
+	return func(r *MeterReading) error {
+		value, err := NewMeterReadingUniverseID(universeID)
+		if err != nil {
+			return fmt.Errorf("invalid universe ID: %w", err)
+		}
+		r.UniverseID = value
+		return nil
+	}
+}
+
+func WithMeterReadingSubject(subject string) MeterReadingOption {
+	return func(r *MeterReading) error {
+		value, err := NewMeterReadingSubject(subject)
+		if err != nil {
+			return fmt.Errorf("invalid subject: %w", err)
+		}
+		r.Subject = value
+		return nil
+	}
+}
+
+// WithMeterReadingDimension sets one group-by dimension, replacing any earlier
+// value for key.
+func WithMeterReadingDimension(key, value string) MeterReadingOption {
+	return func(r *MeterReading) error {
+		if key == "" {
+			return fmt.Errorf("invalid dimension: key cannot be empty")
+		}
+		r.Dimensions.Set(key, value)
+		return nil
+	}
+}
+
+func WithMeterReadingWindow(window specs.TimeWindowSpec) MeterReadingOption {
+	return func(r *MeterReading) error {
+		value, err := NewTimeWindow(window)
+		if err != nil {
+			return fmt.Errorf("invalid window: %w", err)
+		}
+		r.Window = value
+		return nil
+	}
+}
+
+// WithMeterReadingValue replaces the reading's computed values with a single
+// value of quantity and unit, under the reading's aggregation.
+func WithMeterReadingValue(quantity, unit string) MeterReadingOption {
+	return func(r *MeterReading) error {
+		value, err := newComputedValueFromStrings(0, quantity, unit, MeterReadingAggregation{})
+		if err != nil {
+			return err
+		}
+		r.ComputedValues = []ComputedValue{value}
+		return nil
+	}
+}
+
+// WithComputedValue appends a computed value.
+func WithComputedValue(quantity, unit, aggregation string) MeterReadingOption {
+	return func(r *MeterReading) error {
+		i := len(r.ComputedValues)
+		agg, err := NewMeterReadingAggregation(aggregation)
+		if err != nil {
+			return fmt.Errorf("invalid computed value %d aggregation: %w", i, err)
+		}
+		value, err := newComputedValueFromStrings(i, quantity, unit, agg)
+		if err != nil {
+			return err
+		}
+		r.ComputedValues = append(r.ComputedValues, value)
+		return nil
+	}
+}
+
+func newComputedValueFromStrings(i int, quantity, unit string, aggregation MeterReadingAggregation) (ComputedValue, error) {
+	q, err := NewDecimal(quantity)
+	if err != nil {
+		return ComputedValue{}, fmt.Errorf("invalid computed value %d quantity: %w", i, err)
+	}
+	u, err := NewUnit(unit)
+	if err != nil {
+		return ComputedValue{}, fmt.Errorf("invalid computed value %d unit: %w", i, err)
+	}
+	return NewComputedValue(q, u, aggregation), nil
+}
+
+func WithMeterReadingAggregation(aggregation string) MeterReadingOption {
+	return func(r *MeterReading) error {
+		value, err := NewMeterReadingAggregation(aggregation)
+		if err != nil {
+			return fmt.Errorf("invalid aggregation: %w", err)
+		}
+		r.Aggregation = value
+		return nil
+	}
+}
+
+func WithMeterReadingRecordCount(count int) MeterReadingOption {
+	return func(r *MeterReading) error {
+		value, err := NewMeterReadingRecordCount(count)
+		if err != nil {
+			return fmt.Errorf("invalid record count: %w", err)
+		}
+		r.RecordCount = value
+		return nil
+	}
+}
+
+func WithMeterReadingCreatedAt(t time.Time) MeterReadingOption {
+	return func(r *MeterReading) error {
+		value, err := NewMeterReadingCreatedAt(t)
+		if err != nil {
+			return fmt.Errorf("invalid created at: %w", err)
+		}
+		r.CreatedAt = value
+		return nil
+	}
+}
+
+func WithMeterReadingMaxMeteredAt(t time.Time) MeterReadingOption {
+	return func(r *MeterReading) error {
+		value, err := NewMeterReadingMaxMeteredAt(t)
+		if err != nil {
+			return fmt.Errorf("invalid max metered at: %w", err)
+		}
+		r.MaxMeteredAt = value
+		return nil
+	}
+}
+
+func WithMeterReadingVersion(version int64) MeterReadingOption {
+	return func(r *MeterReading) error {
+		value, err := NewMeterReadingVersion(version)
+		if err != nil {
+			return fmt.Errorf("invalid version: %w", err)
+		}
+		r.Version = value
+		return nil
+	}
+}
+
+func WithMeterReadingMetadata(metadata map[string]string) MeterReadingOption {
+	return func(r *MeterReading) error {
+		r.Metadata = NewMeterReadingMetadata(metadata)
+		return nil
+	}
+}
+
+func WithMeterReadingTimezone(timezone string) MeterReadingOption {
+	return func(r *MeterReading) error {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return fmt.Errorf("invalid timezone: %w", err)
+		}
+		r.Timezone = timezone
+		return nil
+	}
+}
+
+// MeterReadingBuilder builds a reading with chained setters, collecting
+// MeterReadingOptions for NewMeterReadingWithOptions. Invalid values are
+// reported by Build.
+type MeterReadingBuilder struct {
+	opts []MeterReadingOption
+}
+
+func NewMeterReadingBuilder() *MeterReadingBuilder {
+	return &MeterReadingBuilder{}
+}
+
+func (b *MeterReadingBuilder) with(opt MeterReadingOption) *MeterReadingBuilder {
+	b.opts = append(b.opts, opt)
+	return b
+}
+
+func (b *MeterReadingBuilder) ID(id string) *MeterReadingBuilder {
+	return b.with(WithMeterReadingID(id))
+}
+
+func (b *MeterReadingBuilder) WorkspaceID(workspaceID string) *MeterReadingBuilder {
+	return b.with(WithMeterReadingWorkspaceID(workspaceID))
+}
+
+func (b *MeterReadingBuilder) UniverseID(universeID string) *MeterReadingBuilder {
+	return b.with(WithMeterReadingUniverseID(universeID))
+}
+
+func (b *MeterReadingBuilder) Subject(subject string) *MeterReadingBuilder {
+	return b.with(WithMeterReadingSubject(subject))
+}
+
+func (b *MeterReadingBuilder) Dimension(key, value string) *MeterReadingBuilder {
+	return b.with(WithMeterReadingDimension(key, value))
+}
+
+func (b *MeterReadingBuilder) Window(window specs.TimeWindowSpec) *MeterReadingBuilder {
+	return b.with(WithMeterReadingWindow(window))
+}
+
+func (b *MeterReadingBuilder) Value(quantity, unit string) *MeterReadingBuilder {
+	return b.with(WithMeterReadingValue(quantity, unit))
+}
+
+func (b *MeterReadingBuilder) ComputedValue(quantity, unit, aggregation string) *MeterReadingBuilder {
+	return b.with(WithComputedValue(quantity, unit, aggregation))
+}
+
+func (b *MeterReadingBuilder) Aggregation(aggregation string) *MeterReadingBuilder {
+	return b.with(WithMeterReadingAggregation(aggregation))
+}
+
+func (b *MeterReadingBuilder) RecordCount(count int) *MeterReadingBuilder {
+	return b.with(WithMeterReadingRecordCount(count))
+}
+
+func (b *MeterReadingBuilder) CreatedAt(t time.Time) *MeterReadingBuilder {
+	return b.with(WithMeterReadingCreatedAt(t))
+}
+
+func (b *MeterReadingBuilder) MaxMeteredAt(t time.Time) *MeterReadingBuilder {
+	return b.with(WithMeterReadingMaxMeteredAt(t))
+}
+
+func (b *MeterReadingBuilder) Version(version int64) *MeterReadingBuilder {
+	return b.with(WithMeterReadingVersion(version))
+}
+
+func (b *MeterReadingBuilder) Metadata(metadata map[string]string) *MeterReadingBuilder {
+	return b.with(WithMeterReadingMetadata(metadata))
+}
+
+func (b *MeterReadingBuilder) Timezone(timezone string) *MeterReadingBuilder {
+	return b.with(WithMeterReadingTimezone(timezone))
+}
+
+// Build returns the reading, or the first error from a setter's value or a
+// missing required field.
+func (b *MeterReadingBuilder) Build() (MeterReading, error) {
+	return NewMeterReadingWithOptions(b.opts...)
+}
+
 type MeterReadingID struct {
 	value string
 }
 
 func NewMeterReadingID(value string) (MeterReadingID, error) {
 	if value == "" {
-		return MeterReadingID{}, fmt.Errorf("ID is required")
+		return MeterReadingID{}, ErrEmptyID
 	}
 	return MeterReadingID{value: value}, nil
 }
@@ -125,7 +503,7 @@ type MeterReadingWorkspaceID struct {
 
 func NewMeterReadingWorkspaceID(value string) (MeterReadingWorkspaceID, error) {
 	if value == "" {
-		return MeterReadingWorkspaceID{}, fmt.Errorf("workspace ID is required")
+		return MeterReadingWorkspaceID{}, ErrEmptyWorkspaceID
 	}
 	return MeterReadingWorkspaceID{value: value}, nil
 }
@@ -155,7 +533,7 @@ type MeterReadingSubject struct {
 
 func NewMeterReadingSubject(value string) (MeterReadingSubject, error) {
 	if value == "" {
-		return MeterReadingSubject{}, fmt.Errorf("subject is required")
+		return MeterReadingSubject{}, ErrEmptySubject
 	}
 	return MeterReadingSubject{value: value}, nil
 }
@@ -164,6 +542,80 @@ func (s MeterReadingSubject) ToString() string {
 	return s.value
 }
 
+// MeterReadingDimensions holds the group-by dimension values shared by all
+// records aggregated into a reading. Empty for ungrouped readings.
+type MeterReadingDimensions struct {
+	values map[string]string
+}
+
+func NewMeterReadingDimensions() MeterReadingDimensions {
+	return MeterReadingDimensions{
+		values: make(map[string]string),
+	}
+}
+
+func (d *MeterReadingDimensions) Set(name string, value string) {
+	d.values[name] = value
+}
+
+func (d MeterReadingDimensions) Get(name string) (string, bool) {
+	val, ok := d.values[name]
+	return val, ok
+}
+
+func (d MeterReadingDimensions) Names() []string {
+	names := make([]string, 0, len(d.values))
+	for name := range d.values {
+		names = append(names, name)
+	}
+	return names
+}
+
+// ToMap returns the dimensions as a map, or nil if there are none.
+func (d MeterReadingDimensions) ToMap() map[string]string {
+	if len(d.values) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(d.values))
+	for name, value := range d.values {
+		result[name] = value
+	}
+	return result
+}
+
+// MeterReadingMetadata holds informational annotations on a reading. It never
+// affects aggregation or the reading ID.
+type MeterReadingMetadata struct {
+	values map[string]string
+}
+
+// NewMeterReadingMetadata copies values, so later changes to the map do not
+// affect the metadata.
+func NewMeterReadingMetadata(values map[string]string) MeterReadingMetadata {
+	copied := make(map[string]string, len(values))
+	for key, value := range values {
+		copied[key] = value
+	}
+	return MeterReadingMetadata{values: copied}
+}
+
+func (m MeterReadingMetadata) Get(key string) (string, bool) {
+	val, ok := m.values[key]
+	return val, ok
+}
+
+// ToMap returns the metadata as a map, or nil if there is none.
+func (m MeterReadingMetadata) ToMap() map[string]string {
+	if len(m.values) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(m.values))
+	for key, value := range m.values {
+		result[key] = value
+	}
+	return result
+}
+
 type TimeWindow struct {
 	start TimeWindowStart
 	end   TimeWindowEnd
@@ -224,13 +676,61 @@ func (w TimeWindow) ToSpec() specs.TimeWindowSpec {
 	}
 }
 
+// Align returns the window snapped outward to multiples of grid: Start is
+// truncated down with time.Truncate and End rounded up, so the aligned window
+// contains w. Multiples are counted from the zero time, so grids that divide
+// a day align to UTC clock times. Returns error if grid is not positive.
+func (w TimeWindow) Align(grid time.Duration) (TimeWindow, error) {
+	if grid <= 0 {
+		return TimeWindow{}, fmt.Errorf("grid must be positive, got %s", grid)
+	}
+	return TimeWindow{
+		start: TimeWindowStart{value: w.start.value.Truncate(grid)},
+		end:   TimeWindowEnd{value: ceilTime(w.end.value, grid)},
+	}, nil
+}
+
+// AlignToHour returns the window snapped outward to whole UTC hours.
+func AlignToHour(w TimeWindow) TimeWindow {
+	aligned, _ := w.Align(time.Hour)
+	return aligned
+}
+
+// AlignToDay returns the window snapped outward to local midnights in loc.
+// Days follow the local calendar, so a day spanning a daylight saving change
+// is 23 or 25 hours long. Returns error if loc is nil.
+func AlignToDay(w TimeWindow, loc *time.Location) (TimeWindow, error) {
+	if loc == nil {
+		return TimeWindow{}, fmt.Errorf("location is required")
+	}
+	midnight := func(t time.Time) time.Time {
+		year, month, day := t.In(loc).Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, loc)
+	}
+	start := midnight(w.start.value)
+	end := midnight(w.end.value)
+	if end.Before(w.end.value) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return TimeWindow{start: TimeWindowStart{value: start}, end: TimeWindowEnd{value: end}}, nil
+}
+
+// ceilTime returns t rounded up to a multiple of d since the zero time.
+func ceilTime(t time.Time, d time.Duration) time.Time {
+	floor := t.Truncate(d)
+	if floor.Equal(t) {
+		return t
+	}
+	return floor.Add(d)
+}
+
 type TimeWindowStart struct {
 	value time.Time
 }
 
 func NewTimeWindowStart(value time.Time) (TimeWindowStart, error) {
 	if value.IsZero() {
-		return TimeWindowStart{}, fmt.Errorf("start is required")
+		return TimeWindowStart{}, newValidationError(ErrZeroTime, "start is required")
 	}
 	return TimeWindowStart{value: value}, nil
 }
@@ -245,7 +745,7 @@ type TimeWindowEnd struct {
 
 func NewTimeWindowEnd(value time.Time) (TimeWindowEnd, error) {
 	if value.IsZero() {
-		return TimeWindowEnd{}, fmt.Errorf("end is required")
+		return TimeWindowEnd{}, newValidationError(ErrZeroTime, "end is required")
 	}
 	return TimeWindowEnd{value: value}, nil
 }
@@ -288,6 +788,19 @@ func (c ComputedValue) Aggregation() MeterReadingAggregation {
 	return c.aggregation
 }
 
+// Equal reports whether c and other have numerically equal quantities and the
+// same unit and aggregation. Unlike ==, quantities that differ only in
+// representation, such as "15" and "15.00", are equal.
+func (c ComputedValue) Equal(other ComputedValue) bool {
+	return c.EqualQuantity(other) && c.unit == other.unit && c.aggregation == other.aggregation
+}
+
+// EqualQuantity reports whether c and other have numerically equal
+// quantities, ignoring unit and aggregation.
+func (c ComputedValue) EqualQuantity(other ComputedValue) bool {
+	return c.quantity.Cmp(other.quantity) == 0
+}
+
 // ToSpec converts ComputedValue to specs.ComputedValueSpec
 func (c ComputedValue) ToSpec() specs.ComputedValueSpec {
 	return specs.ComputedValueSpec{
@@ -303,15 +816,15 @@ type MeterReadingAggregation struct {
 
 func NewMeterReadingAggregation(value string) (MeterReadingAggregation, error) {
 	if value == "" {
-		return MeterReadingAggregation{}, fmt.Errorf("aggregation is required")
+		return MeterReadingAggregation{}, newValidationError(ErrInvalidAggregation, "aggregation is required")
 	}
 
 	// Validate aggregation type
 	switch value {
-	case "sum", "max", "time-weighted-avg", "latest", "min":
+	case "sum", "max", "time-weighted-avg", "latest", "min", "first-non-zero", "mode", "count", "average":
 		// Valid
 	default:
-		return MeterReadingAggregation{}, fmt.Errorf("invalid aggregation type: %q", value)
+		return MeterReadingAggregation{}, newValidationError(ErrInvalidAggregation, "invalid aggregation type: %q", value)
 	}
 
 	return MeterReadingAggregation{value: value}, nil
@@ -321,10 +834,61 @@ func (a MeterReadingAggregation) ToString() string {
 	return a.value
 }
 
+// String returns the aggregation's name as configured ("time-weighted-avg"),
+// like ToString, so aggregations format readably with fmt.
+func (a MeterReadingAggregation) String() string {
+	return a.value
+}
+
+// aggregationNames holds each aggregation's display and short names.
+var aggregationNames = map[string]struct{ display, short string }{
+	"sum":               {"Sum", "SUM"},
+	"max":               {"Maximum", "MAX"},
+	"min":               {"Minimum", "MIN"},
+	"latest":            {"Latest", "LAT"},
+	"time-weighted-avg": {"Time-Weighted Average", "TWA"},
+	"first-non-zero":    {"First Non-Zero", "FNZ"},
+	"mode":              {"Mode", "MOD"},
+	"count":             {"Count", "CNT"},
+	"average":           {"Average", "AVG"},
+}
+
+// DisplayName returns the aggregation's name for billing UIs and reports,
+// such as "Time-Weighted Average". Empty for the zero value.
+func (a MeterReadingAggregation) DisplayName() string {
+	return aggregationNames[a.value].display
+}
+
+// ShortName returns a three-letter abbreviation for chart labels, such as
+// "TWA". Empty for the zero value.
+func (a MeterReadingAggregation) ShortName() string {
+	return aggregationNames[a.value].short
+}
+
+// ParseAggregationDisplayName returns the aggregation whose DisplayName is
+// name, ignoring case and surrounding whitespace. Returns error wrapping
+// ErrInvalidAggregation for an unknown name.
+func ParseAggregationDisplayName(name string) (MeterReadingAggregation, error) {
+	trimmed := strings.TrimSpace(name)
+	for value, names := range aggregationNames {
+		if strings.EqualFold(names.display, trimmed) {
+			return MeterReadingAggregation{value: value}, nil
+		}
+	}
+	return MeterReadingAggregation{}, newValidationError(ErrInvalidAggregation, "unknown aggregation display name: %q", name)
+}
+
 func (a MeterReadingAggregation) IsSum() bool {
 	return a.value == "sum"
 }
 
+// isAdditiveAggregation reports whether values of aggregation add up across
+// windows: a sum or count over two windows is the total of the two, while
+// levels such as max or average are not.
+func isAdditiveAggregation(aggregation string) bool {
+	return aggregation == "sum" || aggregation == "count"
+}
+
 func (a MeterReadingAggregation) IsMax() bool {
 	return a.value == "max"
 }
@@ -341,9 +905,56 @@ func (a MeterReadingAggregation) IsMin() bool {
 	return a.value == "min"
 }
 
+func (a MeterReadingAggregation) IsFirstNonZero() bool {
+	return a.value == "first-non-zero"
+}
+
+// IsMode reports whether this is the mode aggregation: the most frequently
+// observed quantity in the window. Mode suits homogeneous, discrete data
+// such as plan tiers or instance sizes, not continuous measurements where
+// every quantity is likely distinct.
+func (a MeterReadingAggregation) IsMode() bool {
+	return a.value == "mode"
+}
+
+// IsCount reports whether this is the count aggregation: the number of
+// records in the window, regardless of their quantities, such as billing per
+// API call whatever its payload size.
+func (a MeterReadingAggregation) IsCount() bool {
+	return a.value == "count"
+}
+
+// IsAverage reports whether this is the arithmetic mean aggregation. Unlike
+// time-weighted-avg, every record counts equally however long its value held.
+func (a MeterReadingAggregation) IsAverage() bool {
+	return a.value == "average"
+}
+
+// RequiresLastBeforeWindow reports whether the aggregation uses the last record
+// before the window, so callers know whether to query for it. Only
+// time-weighted-avg does: the value in effect at window start carries forward.
+func (a MeterReadingAggregation) RequiresLastBeforeWindow() bool {
+	return a.IsTimeWeightedAvg()
+}
+
+// RequiresOrdering reports whether the result depends on when records were
+// observed (latest, first-non-zero, time-weighted-avg), as opposed to only
+// their quantities.
+func (a MeterReadingAggregation) RequiresOrdering() bool {
+	return a.IsLatest() || a.IsFirstNonZero() || a.IsTimeWeightedAvg()
+}
+
+// SupportsConcurrentEvaluation reports whether records can be aggregated in
+// any order or in independent partitions whose results are then combined
+// (sum, max, min). Such aggregations can also fold records one at a time
+// without buffering them.
+func (a MeterReadingAggregation) SupportsConcurrentEvaluation() bool {
+	return a.IsSum() || a.IsMax() || a.IsMin()
+}
+
 // Aggregate applies this aggregation type to the given records.
 // Each aggregation type uses the parameters it needs:
-//   - sum/max/min/latest: use recordsInWindow only
+//   - sum/max/min/latest/first-non-zero/mode/count/average: use recordsInWindow only
 //   - time-weighted-avg: uses all parameters
 //
 // Returns the aggregated quantity, unit, record count, and any error.
@@ -369,6 +980,22 @@ func (a MeterReadingAggregation) Aggregate(
 		quantity, unit, err := latestRecord(recordsInWindow)
 		return quantity, unit, len(recordsInWindow), err
 
+	case "first-non-zero":
+		quantity, unit, err := firstNonZeroRecord(recordsInWindow)
+		return quantity, unit, len(recordsInWindow), err
+
+	case "mode":
+		quantity, unit, err := modeRecords(recordsInWindow)
+		return quantity, unit, len(recordsInWindow), err
+
+	case "count":
+		quantity, unit, err := countRecords(recordsInWindow)
+		return quantity, unit, len(recordsInWindow), err
+
+	case "average":
+		quantity, unit, err := avgRecords(recordsInWindow)
+		return quantity, unit, len(recordsInWindow), err
+
 	case "time-weighted-avg":
 		quantity, unit, err := timeWeightedAvgRecords(recordsInWindow, lastBeforeWindow, window)
 		recordCount := len(recordsInWindow)
@@ -384,13 +1011,80 @@ func (a MeterReadingAggregation) Aggregate(
 	}
 }
 
+// AggregationWarning describes a data problem found before aggregation.
+// Warnings are non-fatal: aggregation may still succeed, but the result may
+// not mean what the caller expects.
+type AggregationWarning struct {
+	// RecordID identifies the record the warning is about, or is empty for
+	// warnings about the input as a whole.
+	RecordID string
+	Message  string
+}
+
+func (w AggregationWarning) String() string {
+	if w.RecordID == "" {
+		return w.Message
+	}
+	return fmt.Sprintf("record %s: %s", w.RecordID, w.Message)
+}
+
+// ValidateRecords checks records for problems before Aggregate runs:
+//   - no in-window records (and, for time-weighted-avg, no lastBefore either)
+//   - in-window records observed outside the window
+//   - a lastBefore record that is not before the window
+//   - a zero-duration window for time-weighted-avg, which divides by duration
+//
+// Returns an empty slice when nothing looks wrong.
+func (a MeterReadingAggregation) ValidateRecords(
+	records []MeterRecord,
+	lastBefore *MeterRecord,
+	window TimeWindow,
+) []AggregationWarning {
+	warnings := []AggregationWarning{}
+	windowStart := window.Start().ToTime()
+	windowEnd := window.End().ToTime()
+
+	if len(records) == 0 {
+		if !a.IsTimeWeightedAvg() {
+			warnings = append(warnings, AggregationWarning{Message: "no records in window"})
+		} else if lastBefore == nil {
+			warnings = append(warnings, AggregationWarning{Message: "no records in window and no prior record: no data to average"})
+		}
+	}
+
+	for _, record := range records {
+		observedAt := record.ObservedAt.ToTime()
+		if observedAt.Before(windowStart) || !observedAt.Before(windowEnd) {
+			warnings = append(warnings, AggregationWarning{
+				RecordID: record.ID.ToString(),
+				Message: fmt.Sprintf("observed at %s is outside window [%s, %s)",
+					observedAt.Format(time.RFC3339), windowStart.Format(time.RFC3339), windowEnd.Format(time.RFC3339)),
+			})
+		}
+	}
+
+	if lastBefore != nil && !lastBefore.ObservedAt.ToTime().Before(windowStart) {
+		warnings = append(warnings, AggregationWarning{
+			RecordID: lastBefore.ID.ToString(),
+			Message: fmt.Sprintf("prior record observed at %s is not before window start %s",
+				lastBefore.ObservedAt.ToTime().Format(time.RFC3339), windowStart.Format(time.RFC3339)),
+		})
+	}
+
+	if a.IsTimeWeightedAvg() && !windowEnd.After(windowStart) {
+		warnings = append(warnings, AggregationWarning{Message: "window has zero duration: cannot compute time-weighted average"})
+	}
+
+	return warnings
+}
+
 type MeterReadingRecordCount struct {
 	value int
 }
 
 func NewMeterReadingRecordCount(value int) (MeterReadingRecordCount, error) {
 	if value < 0 {
-		return MeterReadingRecordCount{}, fmt.Errorf("record count cannot be negative")
+		return MeterReadingRecordCount{}, ErrNegativeRecordCount
 	}
 	return MeterReadingRecordCount{value: value}, nil
 }
@@ -403,9 +1097,21 @@ type MeterReadingCreatedAt struct {
 	value time.Time
 }
 
+// NewMeterReadingCreatedAt returns error if value is zero or more than
+// DefaultMaxFutureSkew ahead of now.
 func NewMeterReadingCreatedAt(value time.Time) (MeterReadingCreatedAt, error) {
+	return NewMeterReadingCreatedAtWithSkew(value, DefaultMaxFutureSkew)
+}
+
+// NewMeterReadingCreatedAtWithSkew is NewMeterReadingCreatedAt with an explicit
+// future-skew limit. Zero accepts only timestamps at or before now; negative
+// disables the check.
+func NewMeterReadingCreatedAtWithSkew(value time.Time, maxSkew time.Duration) (MeterReadingCreatedAt, error) {
 	if value.IsZero() {
-		return MeterReadingCreatedAt{}, fmt.Errorf("created at is required")
+		return MeterReadingCreatedAt{}, newValidationError(ErrZeroTime, "created at is required")
+	}
+	if err := checkFutureSkew(value, maxSkew); err != nil {
+		return MeterReadingCreatedAt{}, err
 	}
 	return MeterReadingCreatedAt{value: value}, nil
 }
@@ -420,7 +1126,7 @@ type MeterReadingMaxMeteredAt struct {
 
 func NewMeterReadingMaxMeteredAt(value time.Time) (MeterReadingMaxMeteredAt, error) {
 	if value.IsZero() {
-		return MeterReadingMaxMeteredAt{}, fmt.Errorf("max metered at is required")
+		return MeterReadingMaxMeteredAt{}, newValidationError(ErrZeroTime, "max metered at is required")
 	}
 	return MeterReadingMaxMeteredAt{value: value}, nil
 }
@@ -429,6 +1135,50 @@ func (m MeterReadingMaxMeteredAt) ToTime() time.Time {
 	return m.value
 }
 
+type MeterReadingVersion struct {
+	value int64
+}
+
+func NewMeterReadingVersion(value int64) (MeterReadingVersion, error) {
+	if value < 0 {
+		return MeterReadingVersion{}, fmt.Errorf("version cannot be negative")
+	}
+	return MeterReadingVersion{value: value}, nil
+}
+
+// InitialMeterReadingVersion returns the version assigned to newly aggregated readings.
+func InitialMeterReadingVersion() MeterReadingVersion {
+	return MeterReadingVersion{value: 1}
+}
+
+func (v MeterReadingVersion) ToInt64() int64 {
+	return v.value
+}
+
+// ErrMixedUnits is returned when records to be aggregated together do not all
+// share one unit; combining them would produce a meaningless value.
+var ErrMixedUnits = errors.New("mixed units")
+
+// ValidateUnitHomogeneity returns an error wrapping ErrMixedUnits, listing the
+// units found, if the records' observations do not all share one unit.
+// Each record's first observation is checked, as aggregation expects records
+// with bundled observations to be unbundled first. Empty records pass.
+func ValidateUnitHomogeneity(records []MeterRecord) error {
+	var units []string
+	seen := make(map[string]bool)
+	for _, r := range records {
+		unit := r.Observations[0].Unit().ToString()
+		if !seen[unit] {
+			seen[unit] = true
+			units = append(units, unit)
+		}
+	}
+	if len(units) > 1 {
+		return fmt.Errorf("%w: found %s", ErrMixedUnits, strings.Join(units, ", "))
+	}
+	return nil
+}
+
 // sumRecords returns the sum of all record observations.
 // Returns error if records is empty or observations are incompatible.
 func sumRecords(records []MeterRecord) (Decimal, Unit, error) {
@@ -438,6 +1188,9 @@ func sumRecords(records []MeterRecord) (Decimal, Unit, error) {
 	if len(records) == 0 {
 		return zeroDecimal, zeroUnit, fmt.Errorf("cannot sum empty records")
 	}
+	if err := ValidateUnitHomogeneity(records); err != nil {
+		return zeroDecimal, zeroUnit, err
+	}
 
 	// Use first observation from first record
 	sum := records[0].Observations[0].Quantity()
@@ -450,6 +1203,68 @@ func sumRecords(records []MeterRecord) (Decimal, Unit, error) {
 	return sum, unit, nil
 }
 
+// avgRecords returns the mean of all record observations per event, without
+// trailing fractional zeros. Unsampled records are weighted equally. A
+// sampled record stands for 1/SampleRate events, so it is weighted by that
+// many: its quantity is scaled up unless NormalizeForSampling already did
+// (Confidence below 1), and the total is divided by the events represented.
+// Ten records of 1 at rate 0.1 therefore average 1 whether or not they were
+// normalized.
+// Returns error if records is empty or observations are incompatible.
+func avgRecords(records []MeterRecord) (Decimal, Unit, error) {
+	var zeroDecimal Decimal
+	var zeroUnit Unit
+
+	if len(records) == 0 {
+		return zeroDecimal, zeroUnit, fmt.Errorf("cannot average empty records")
+	}
+	if err := ValidateUnitHomogeneity(records); err != nil {
+		return zeroDecimal, zeroUnit, err
+	}
+
+	total := NewDecimalFromInt64(0)
+	events := NewDecimalFromInt64(0)
+	for _, r := range records {
+		weight := r.SampleRate.EventsRepresented()
+		quantity := r.Observations[0].Quantity()
+		if r.Confidence.IsExact() {
+			quantity = quantity.Mul(weight)
+		}
+		total = total.Add(quantity)
+		events = events.Add(weight)
+	}
+
+	return total.Div(events).Normalize(), records[0].Observations[0].Unit(), nil
+}
+
+// countRecords returns the number of events the records represent, ignoring
+// their quantities, in the unit of the first record's observation. A sampled
+// record counts as 1/SampleRate events, and the total is rounded half-up as
+// estimateEventCount rounds the reading's RecordCount, so the two agree.
+// Returns error if records is empty or observations are incompatible.
+func countRecords(records []MeterRecord) (Decimal, Unit, error) {
+	var zeroDecimal Decimal
+	var zeroUnit Unit
+
+	if len(records) == 0 {
+		return zeroDecimal, zeroUnit, fmt.Errorf("cannot count empty records")
+	}
+	if err := ValidateUnitHomogeneity(records); err != nil {
+		return zeroDecimal, zeroUnit, err
+	}
+
+	events := NewDecimalFromInt64(0)
+	for _, r := range records {
+		events = events.Add(r.SampleRate.EventsRepresented())
+	}
+	count, err := events.RoundToInt64()
+	if err != nil {
+		return zeroDecimal, zeroUnit, err
+	}
+
+	return NewDecimalFromInt64(count), records[0].Observations[0].Unit(), nil
+}
+
 // maxRecords returns the maximum observation from all records.
 // Returns error if records is empty.
 func maxRecords(records []MeterRecord) (Decimal, Unit, error) {
@@ -459,6 +1274,9 @@ func maxRecords(records []MeterRecord) (Decimal, Unit, error) {
 	if len(records) == 0 {
 		return zeroDecimal, zeroUnit, fmt.Errorf("cannot find max of empty records")
 	}
+	if err := ValidateUnitHomogeneity(records); err != nil {
+		return zeroDecimal, zeroUnit, err
+	}
 
 	maxQuantity := records[0].Observations[0].Quantity()
 	unit := records[0].Observations[0].Unit()
@@ -481,6 +1299,9 @@ func minRecords(records []MeterRecord) (Decimal, Unit, error) {
 	if len(records) == 0 {
 		return zeroDecimal, zeroUnit, fmt.Errorf("cannot find min of empty records")
 	}
+	if err := ValidateUnitHomogeneity(records); err != nil {
+		return zeroDecimal, zeroUnit, err
+	}
 
 	minQuantity := records[0].Observations[0].Quantity()
 	unit := records[0].Observations[0].Unit()
@@ -503,6 +1324,9 @@ func latestRecord(records []MeterRecord) (Decimal, Unit, error) {
 	if len(records) == 0 {
 		return zeroDecimal, zeroUnit, fmt.Errorf("cannot find latest of empty records")
 	}
+	if err := ValidateUnitHomogeneity(records); err != nil {
+		return zeroDecimal, zeroUnit, err
+	}
 
 	latest := records[0]
 	for _, r := range records[1:] {
@@ -514,6 +1338,78 @@ func latestRecord(records []MeterRecord) (Decimal, Unit, error) {
 	return latest.Observations[0].Quantity(), latest.Observations[0].Unit(), nil
 }
 
+// firstNonZeroRecord returns the observation from the earliest record by ObservedAt
+// timestamp whose quantity is positive. Used to initialize a gauge from its first
+// real reading when the carried-forward state is zero; negative quantities (refunds,
+// corrections) are not readings of the gauge and are skipped like zeros.
+// If no record has a positive quantity, returns zero in the records' unit.
+// Returns error if records is empty.
+func firstNonZeroRecord(records []MeterRecord) (Decimal, Unit, error) {
+	var zeroDecimal Decimal
+	var zeroUnit Unit
+
+	if len(records) == 0 {
+		return zeroDecimal, zeroUnit, fmt.Errorf("cannot find first non-zero of empty records")
+	}
+	if err := ValidateUnitHomogeneity(records); err != nil {
+		return zeroDecimal, zeroUnit, err
+	}
+
+	zero := NewDecimalFromInt64(0)
+	var earliestPositive *MeterRecord
+	for i := range records {
+		r := &records[i]
+		if r.Observations[0].Quantity().Cmp(zero) <= 0 {
+			continue
+		}
+		if earliestPositive == nil || r.ObservedAt.ToTime().Before(earliestPositive.ObservedAt.ToTime()) {
+			earliestPositive = r
+		}
+	}
+
+	if earliestPositive == nil {
+		return zero, records[0].Observations[0].Unit(), nil
+	}
+	return earliestPositive.Observations[0].Quantity(), earliestPositive.Observations[0].Unit(), nil
+}
+
+// modeRecords returns the most frequent quantity across records.
+// Quantities are compared by their string form, so "1" and "1.0" count as
+// different values. Ties are broken by returning the lexicographically
+// smallest quantity string, which keeps the result independent of record order.
+// Returns error if records is empty.
+func modeRecords(records []MeterRecord) (Decimal, Unit, error) {
+	var zeroDecimal Decimal
+	var zeroUnit Unit
+
+	if len(records) == 0 {
+		return zeroDecimal, zeroUnit, fmt.Errorf("cannot find mode of empty records")
+	}
+	if err := ValidateUnitHomogeneity(records); err != nil {
+		return zeroDecimal, zeroUnit, err
+	}
+
+	counts := make(map[string]int)
+	quantities := make(map[string]Decimal)
+	for _, r := range records {
+		quantity := r.Observations[0].Quantity()
+		key := quantity.String()
+		counts[key]++
+		quantities[key] = quantity
+	}
+
+	var modeKey string
+	maxCount := 0
+	for key, count := range counts {
+		if count > maxCount || (count == maxCount && key < modeKey) {
+			modeKey = key
+			maxCount = count
+		}
+	}
+
+	return quantities[modeKey], records[0].Observations[0].Unit(), nil
+}
+
 // timeWeightedAvgRecords computes the time-weighted average of gauge readings.
 // Uses step interpolation: each value holds until the next reading (or window end).
 //
@@ -531,6 +1427,17 @@ func timeWeightedAvgRecords(
 	recordsInWindow []MeterRecord,
 	lastBeforeWindow *MeterRecord,
 	window TimeWindow,
+) (Decimal, Unit, error) {
+	return timeWeightedAvgRecordsWithLogger(recordsInWindow, lastBeforeWindow, window, NoopAggregationLogger())
+}
+
+// timeWeightedAvgRecordsWithLogger is timeWeightedAvgRecords, reporting each
+// step of the computation to logger.
+func timeWeightedAvgRecordsWithLogger(
+	recordsInWindow []MeterRecord,
+	lastBeforeWindow *MeterRecord,
+	window TimeWindow,
+	logger AggregationLogger,
 ) (Decimal, Unit, error) {
 	var zeroDecimal Decimal
 	var zeroUnit Unit
@@ -545,30 +1452,67 @@ func timeWeightedAvgRecords(
 	if len(allRecords) == 0 {
 		return zeroDecimal, zeroUnit, fmt.Errorf("cannot compute time-weighted average: no records")
 	}
+	if err := ValidateUnitHomogeneity(allRecords); err != nil {
+		return zeroDecimal, zeroUnit, err
+	}
 
-	// Sort by ObservedAt timestamp
-	sortedRecords := make([]MeterRecord, len(allRecords))
-	copy(sortedRecords, allRecords)
-	for i := 0; i < len(sortedRecords); i++ {
-		for j := i + 1; j < len(sortedRecords); j++ {
-			if sortedRecords[j].ObservedAt.ToTime().Before(sortedRecords[i].ObservedAt.ToTime()) {
-				sortedRecords[i], sortedRecords[j] = sortedRecords[j], sortedRecords[i]
-			}
-		}
+	// Sort by ObservedAt timestamp. allRecords is our own copy, so sorting in
+	// place is safe, and chronological input skips the sort entirely.
+	sortedRecords := EnsureSortedByObservedAt(allRecords)
+
+	for i, record := range sortedRecords {
+		logger.LogStep(AggregationStep{StepType: "sort", RecordIndex: i, Value: record.Observations[0].Quantity().String()})
 	}
 
 	// Compute weighted sum: Σ(value × duration)
 	unit := sortedRecords[0].Observations[0].Unit()
 	weightedSum, _ := NewDecimal("0")
+	for _, segment := range timeWeightedSegmentsWithLogger(sortedRecords, window, logger) {
+		weightedSum = weightedSum.Add(segment.quantity.Mul(segment.duration))
+	}
+	logger.LogStep(AggregationStep{StepType: "sum", RecordIndex: -1, Value: weightedSum.String()})
+
+	windowStart := window.Start().ToTime()
+	windowEnd := window.End().ToTime()
+
+	// Divide by total window duration to get average
+	totalSeconds := windowEnd.Sub(windowStart).Seconds()
+	totalDuration, _ := NewDecimal(fmt.Sprintf("%.15f", totalSeconds))
+
+	avg := weightedSum.Div(totalDuration)
+	logger.LogStep(AggregationStep{StepType: "divide", RecordIndex: -1, Value: avg.String(), Duration: totalDuration.String()})
+
+	return avg, unit, nil
+}
+
+// timeWeightedSegment is one record's value and how long, in seconds, it was
+// active within the window.
+type timeWeightedSegment struct {
+	quantity Decimal
+	duration Decimal
+}
 
+// timeWeightedSegments returns the active duration of each record within the
+// window. sortedRecords must be ordered by ObservedAt. A value is active from
+// its timestamp (clamped to window start) until the next record or window end;
+// records with no active time are omitted.
+func timeWeightedSegments(sortedRecords []MeterRecord, window TimeWindow) []timeWeightedSegment {
+	return timeWeightedSegmentsWithLogger(sortedRecords, window, NoopAggregationLogger())
+}
+
+// timeWeightedSegmentsWithLogger is timeWeightedSegments, reporting clamped
+// start times and each segment's weight to logger.
+func timeWeightedSegmentsWithLogger(sortedRecords []MeterRecord, window TimeWindow, logger AggregationLogger) []timeWeightedSegment {
 	windowStart := window.Start().ToTime()
 	windowEnd := window.End().ToTime()
 
+	segments := make([]timeWeightedSegment, 0, len(sortedRecords))
 	for i, record := range sortedRecords {
 		// Determine when this value is valid (from this timestamp until next, or window end)
 		validFrom := record.ObservedAt.ToTime()
 		if validFrom.Before(windowStart) {
 			validFrom = windowStart // Clamp to window start
+			logger.LogStep(AggregationStep{StepType: "clamp", RecordIndex: i, Value: validFrom.Format(time.RFC3339)})
 		}
 
 		validUntil := windowEnd
@@ -584,16 +1528,18 @@ func timeWeightedAvgRecords(
 			durationSeconds := validUntil.Sub(validFrom).Seconds()
 			duration, _ := NewDecimal(fmt.Sprintf("%.15f", durationSeconds))
 
-			contribution := record.Observations[0].Quantity().Mul(duration)
-			weightedSum = weightedSum.Add(contribution)
+			segment := timeWeightedSegment{
+				quantity: record.Observations[0].Quantity(),
+				duration: duration,
+			}
+			logger.LogStep(AggregationStep{
+				StepType:    "weight",
+				RecordIndex: i,
+				Value:       segment.quantity.Mul(segment.duration).String(),
+				Duration:    duration.String(),
+			})
+			segments = append(segments, segment)
 		}
 	}
-
-	// Divide by total window duration to get average
-	totalSeconds := windowEnd.Sub(windowStart).Seconds()
-	totalDuration, _ := NewDecimal(fmt.Sprintf("%.15f", totalSeconds))
-
-	avg := weightedSum.Div(totalDuration)
-
-	return avg, unit, nil
+	return segments
 }
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"github.com/chrisconley/metron/internal/testutil"
 	"github.com/chrisconley/metron/specs"
 	"testing"
 	"time"
@@ -126,6 +127,33 @@ func TestNewMeterReading(t *testing.T) {
 		assert.Contains(t, err.Error(), "record count cannot be negative")
 	})
 
+	t.Run("with negative version returns error", func(t *testing.T) {
+		now := time.Now()
+		spec := specs.MeterReadingSpec{
+			ID:          "reading-123",
+			WorkspaceID: "workspace-prod",
+			UniverseID:  "production",
+			Subject:     "customer:acme",
+			Window: specs.TimeWindowSpec{
+				Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+			},
+			ComputedValues: []specs.ComputedValueSpec{
+				{Quantity: "100", Unit: "tokens", Aggregation: "sum"},
+			},
+			Aggregation:  "sum",
+			RecordCount:  1,
+			CreatedAt:    now,
+			MaxMeteredAt: now,
+			Version:      -1,
+		}
+
+		_, err := NewMeterReading(spec)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "version cannot be negative")
+	})
+
 	t.Run("creates meter reading with ComputedValues array", func(t *testing.T) {
 		now := time.Now()
 		windowStart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
@@ -219,10 +247,47 @@ func TestMeterReadingAggregation(t *testing.T) {
 		assert.False(t, agg.IsTimeWeightedAvg())
 		assert.False(t, agg.IsLatest())
 		assert.False(t, agg.IsMin())
+		assert.False(t, agg.IsFirstNonZero())
+	})
+
+	t.Run("first-non-zero aggregation type checks", func(t *testing.T) {
+		agg, err := NewMeterReadingAggregation("first-non-zero")
+		require.NoError(t, err)
+
+		assert.True(t, agg.IsFirstNonZero())
+		assert.False(t, agg.IsLatest())
+		assert.False(t, agg.IsSum())
+	})
+
+	t.Run("mode aggregation type checks", func(t *testing.T) {
+		agg, err := NewMeterReadingAggregation("mode")
+		require.NoError(t, err)
+
+		assert.True(t, agg.IsMode())
+		assert.False(t, agg.IsMax())
+		assert.False(t, agg.IsLatest())
+	})
+
+	t.Run("count aggregation type checks", func(t *testing.T) {
+		agg, err := NewMeterReadingAggregation("count")
+		require.NoError(t, err)
+
+		assert.True(t, agg.IsCount())
+		assert.False(t, agg.IsSum())
+		assert.False(t, agg.RequiresLastBeforeWindow())
+	})
+
+	t.Run("average aggregation type checks", func(t *testing.T) {
+		agg, err := NewMeterReadingAggregation("average")
+		require.NoError(t, err)
+
+		assert.True(t, agg.IsAverage())
+		assert.False(t, agg.IsTimeWeightedAvg())
+		assert.False(t, agg.RequiresOrdering())
 	})
 
 	t.Run("validates aggregation types", func(t *testing.T) {
-		validTypes := []string{"sum", "max", "time-weighted-avg", "latest", "min"}
+		validTypes := []string{"sum", "max", "time-weighted-avg", "latest", "min", "first-non-zero", "mode", "count", "average"}
 
 		for _, aggType := range validTypes {
 			_, err := NewMeterReadingAggregation(aggType)
@@ -235,6 +300,84 @@ func TestMeterReadingAggregation(t *testing.T) {
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid aggregation type")
 	})
+
+	t.Run("capability predicates", func(t *testing.T) {
+		tests := []struct {
+			aggregation        string
+			requiresLastBefore bool
+			requiresOrdering   bool
+			supportsConcurrent bool
+		}{
+			{"sum", false, false, true},
+			{"max", false, false, true},
+			{"min", false, false, true},
+			{"latest", false, true, false},
+			{"first-non-zero", false, true, false},
+			{"mode", false, false, false},
+			{"count", false, false, false},
+			{"average", false, false, false},
+			{"time-weighted-avg", true, true, false},
+		}
+
+		for _, tt := range tests {
+			agg, err := NewMeterReadingAggregation(tt.aggregation)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.requiresLastBefore, agg.RequiresLastBeforeWindow(), "%s RequiresLastBeforeWindow", tt.aggregation)
+			assert.Equal(t, tt.requiresOrdering, agg.RequiresOrdering(), "%s RequiresOrdering", tt.aggregation)
+			assert.Equal(t, tt.supportsConcurrent, agg.SupportsConcurrentEvaluation(), "%s SupportsConcurrentEvaluation", tt.aggregation)
+		}
+	})
+}
+
+func TestMeterReadingAggregation_ValidateRecords(t *testing.T) {
+	jan := func(d int) time.Time {
+		return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	window, err := NewTimeWindow(specs.TimeWindowSpec{Start: jan(1), End: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)})
+	require.NoError(t, err)
+	twa, err := NewMeterReadingAggregation("time-weighted-avg")
+	require.NoError(t, err)
+	newRecord := func(id string, observedAt time.Time) MeterRecord {
+		record, err := NewMeterRecord(newTestRecordSpec(id, "10", "seats", observedAt))
+		require.NoError(t, err)
+		return record
+	}
+
+	t.Run("empty records with time-weighted-avg warns about no data", func(t *testing.T) {
+		warnings := twa.ValidateRecords(nil, nil, window)
+
+		require.Len(t, warnings, 1)
+		assert.Contains(t, warnings[0].Message, "no data")
+	})
+
+	t.Run("records outside window warn per record", func(t *testing.T) {
+		records := []MeterRecord{newRecord("rec-1", jan(10)), newRecord("rec-2", jan(1).AddDate(0, 2, 0))}
+
+		warnings := twa.ValidateRecords(records, nil, window)
+
+		require.Len(t, warnings, 1)
+		assert.Equal(t, "rec-2", warnings[0].RecordID)
+		assert.Contains(t, warnings[0].String(), "outside window")
+	})
+
+	t.Run("lastBefore outside window warns", func(t *testing.T) {
+		lastBefore := newRecord("rec-0", jan(20))
+
+		warnings := twa.ValidateRecords([]MeterRecord{newRecord("rec-1", jan(10))}, &lastBefore, window)
+
+		require.Len(t, warnings, 1)
+		assert.Equal(t, "rec-0", warnings[0].RecordID)
+		assert.Contains(t, warnings[0].Message, "not before window start")
+	})
+
+	t.Run("valid records produce no warnings", func(t *testing.T) {
+		lastBefore := newRecord("rec-0", jan(1).AddDate(0, -1, 0))
+
+		warnings := twa.ValidateRecords([]MeterRecord{newRecord("rec-1", jan(10))}, &lastBefore, window)
+
+		assert.Empty(t, warnings)
+	})
 }
 
 func TestTimeWindow(t *testing.T) {
@@ -280,6 +423,77 @@ func TestTimeWindow(t *testing.T) {
 	})
 }
 
+func TestTimeWindow_Align(t *testing.T) {
+	newWindow := func(t *testing.T, start, end time.Time) TimeWindow {
+		t.Helper()
+		window, err := NewTimeWindow(specs.TimeWindowSpec{Start: start, End: end})
+		require.NoError(t, err)
+		return window
+	}
+
+	t.Run("already aligned window is unchanged", func(t *testing.T) {
+		window := newWindow(t, time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC))
+
+		aligned, err := window.Align(time.Hour)
+
+		require.NoError(t, err)
+		assert.Equal(t, window, aligned)
+	})
+
+	t.Run("snaps start down and end up", func(t *testing.T) {
+		window := newWindow(t, time.Date(2024, 1, 15, 10, 7, 3, 0, time.UTC), time.Date(2024, 1, 15, 10, 41, 0, 0, time.UTC))
+
+		aligned, err := window.Align(15 * time.Minute)
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), aligned.Start().ToTime())
+		assert.Equal(t, time.Date(2024, 1, 15, 10, 45, 0, 0, time.UTC), aligned.End().ToTime())
+	})
+
+	t.Run("sub-millisecond grid", func(t *testing.T) {
+		window := newWindow(t, time.Date(2024, 1, 15, 10, 0, 0, 1_250, time.UTC), time.Date(2024, 1, 15, 10, 0, 0, 3_001, time.UTC))
+
+		aligned, err := window.Align(time.Microsecond)
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 1, 15, 10, 0, 0, 1_000, time.UTC), aligned.Start().ToTime())
+		assert.Equal(t, time.Date(2024, 1, 15, 10, 0, 0, 4_000, time.UTC), aligned.End().ToTime())
+	})
+
+	t.Run("rejects non-positive grid", func(t *testing.T) {
+		window := newWindow(t, time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC))
+
+		_, err := window.Align(0)
+		assert.ErrorContains(t, err, "grid must be positive")
+
+		_, err = window.Align(-time.Hour)
+		assert.Error(t, err)
+	})
+
+	t.Run("AlignToHour snaps to whole hours", func(t *testing.T) {
+		window := newWindow(t, time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC), time.Date(2024, 1, 15, 11, 30, 0, 0, time.UTC))
+
+		aligned := AlignToHour(window)
+
+		assert.Equal(t, time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), aligned.Start().ToTime())
+		assert.Equal(t, time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC), aligned.End().ToTime())
+	})
+
+	t.Run("AlignToDay follows local days across DST", func(t *testing.T) {
+		newYork, err := time.LoadLocation("America/New_York")
+		require.NoError(t, err)
+		// 2024-03-10 is the spring-forward day in New York: 23 hours long.
+		window := newWindow(t, time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC), time.Date(2024, 3, 10, 20, 0, 0, 0, time.UTC))
+
+		aligned, err := AlignToDay(window, newYork)
+
+		require.NoError(t, err)
+		assert.True(t, aligned.Start().ToTime().Equal(time.Date(2024, 3, 10, 5, 0, 0, 0, time.UTC)))
+		assert.True(t, aligned.End().ToTime().Equal(time.Date(2024, 3, 11, 4, 0, 0, 0, time.UTC)))
+		assert.Equal(t, 23*time.Hour, aligned.End().ToTime().Sub(aligned.Start().ToTime()))
+	})
+}
+
 func TestNewComputedValue(t *testing.T) {
 	t.Run("creates computed value with all fields", func(t *testing.T) {
 		quantity, err := NewDecimal("1250.50")
@@ -316,6 +530,54 @@ func TestNewComputedValue(t *testing.T) {
 	})
 }
 
+// assertComputedValueEqual asserts that actual equals expected by value, so
+// quantities that differ only in representation compare equal.
+func assertComputedValueEqual(t *testing.T, expected, actual ComputedValue) bool {
+	t.Helper()
+	return assert.True(t, expected.Equal(actual), "expected %s %s (%s), got %s %s (%s)",
+		expected.Quantity(), expected.Unit().ToString(), expected.Aggregation().ToString(),
+		actual.Quantity(), actual.Unit().ToString(), actual.Aggregation().ToString())
+}
+
+func TestComputedValue_Equal(t *testing.T) {
+	sum, err := NewMeterReadingAggregation("sum")
+	require.NoError(t, err)
+	tokens, err := NewUnit("tokens")
+	require.NoError(t, err)
+	newValue := func(quantity string, unit Unit) ComputedValue {
+		q, err := NewDecimal(quantity)
+		require.NoError(t, err)
+		return NewComputedValue(q, unit, sum)
+	}
+
+	t.Run("numerically equal but differently represented values are equal", func(t *testing.T) {
+		a := newValue("15", tokens)
+		b := newValue("15.000", tokens)
+
+		assert.NotEqual(t, a, b, "struct comparison sees the representation")
+		assertComputedValueEqual(t, a, b)
+		assert.True(t, a.EqualQuantity(b))
+	})
+
+	t.Run("different values are unequal", func(t *testing.T) {
+		a := newValue("15", tokens)
+		b := newValue("15.001", tokens)
+
+		assert.False(t, a.Equal(b))
+		assert.False(t, a.EqualQuantity(b))
+	})
+
+	t.Run("EqualQuantity ignores unit", func(t *testing.T) {
+		requests, err := NewUnit("requests")
+		require.NoError(t, err)
+		a := newValue("15", tokens)
+		b := newValue("15.0", requests)
+
+		assert.False(t, a.Equal(b))
+		assert.True(t, a.EqualQuantity(b))
+	})
+}
+
 func TestComputedValue_ToSpec(t *testing.T) {
 	t.Run("converts to spec correctly", func(t *testing.T) {
 		quantity, _ := NewDecimal("1250.50")
@@ -343,3 +605,232 @@ func TestComputedValue_ToSpec(t *testing.T) {
 		assert.Equal(t, "time-weighted-avg", spec.Aggregation)
 	})
 }
+
+func TestNewMeterReadingCreatedAt(t *testing.T) {
+	t.Run("accepts timestamp 1 minute in future by default", func(t *testing.T) {
+		_, err := NewMeterReadingCreatedAt(time.Now().Add(time.Minute))
+
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects timestamp 10 minutes in future with 5 minute skew", func(t *testing.T) {
+		_, err := NewMeterReadingCreatedAtWithSkew(time.Now().Add(10*time.Minute), 5*time.Minute)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "in the future")
+	})
+
+	t.Run("with zero time returns error", func(t *testing.T) {
+		_, err := NewMeterReadingCreatedAt(time.Time{})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "created at is required")
+	})
+}
+
+func TestValidateUnitHomogeneity(t *testing.T) {
+	observedAt := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	newRecord := func(id, unit string) MeterRecord {
+		record, err := NewMeterRecord(newTestRecordSpec(id, "10", unit, observedAt))
+		require.NoError(t, err)
+		return record
+	}
+
+	t.Run("homogeneous units pass", func(t *testing.T) {
+		records := []MeterRecord{newRecord("rec-1", "tokens"), newRecord("rec-2", "tokens")}
+
+		assert.NoError(t, ValidateUnitHomogeneity(records))
+	})
+
+	t.Run("two different units return ErrMixedUnits", func(t *testing.T) {
+		records := []MeterRecord{newRecord("rec-1", "tokens"), newRecord("rec-2", "api-calls"), newRecord("rec-3", "tokens")}
+
+		err := ValidateUnitHomogeneity(records)
+
+		assert.ErrorIs(t, err, ErrMixedUnits)
+		assert.EqualError(t, err, "mixed units: found tokens, api-calls")
+	})
+
+	t.Run("empty records pass", func(t *testing.T) {
+		assert.NoError(t, ValidateUnitHomogeneity(nil))
+	})
+
+	t.Run("every aggregation rejects mixed units", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newTestRecordSpec("rec-1", "10", "tokens", observedAt),
+			newTestRecordSpec("rec-2", "10", "api-calls", observedAt.Add(time.Hour)),
+		}
+
+		for _, aggregation := range []string{"sum", "max", "min", "latest", "first-non-zero", "mode", "time-weighted-avg"} {
+			_, err := Aggregate(records, nil, newTestAggregateConfig(aggregation))
+			assert.ErrorIs(t, err, ErrMixedUnits, aggregation)
+
+			_, err = AggregateFromReader(SliceRecordReader(records), nil, newTestAggregateConfig(aggregation))
+			assert.ErrorIs(t, err, ErrMixedUnits, aggregation)
+		}
+	})
+}
+
+func TestNewMeterReadingWithOptions(t *testing.T) {
+	spec := testutil.FixtureMeterReading()
+	required := func(extra ...MeterReadingOption) []MeterReadingOption {
+		return append([]MeterReadingOption{
+			WithMeterReadingID(spec.ID),
+			WithMeterReadingWorkspaceID(spec.WorkspaceID),
+			WithMeterReadingUniverseID(spec.UniverseID),
+			WithMeterReadingSubject(spec.Subject),
+			WithMeterReadingWindow(spec.Window),
+			WithMeterReadingMaxMeteredAt(spec.MaxMeteredAt),
+			WithComputedValue("100", "tokens", "sum"),
+		}, extra...)
+	}
+
+	t.Run("builds reading equal to spec constructor", func(t *testing.T) {
+		expected, err := NewMeterReading(spec)
+		require.NoError(t, err)
+
+		reading, err := NewMeterReadingWithOptions(required(
+			WithMeterReadingRecordCount(spec.RecordCount),
+			WithMeterReadingCreatedAt(spec.CreatedAt),
+		)...)
+
+		require.NoError(t, err)
+		assert.Equal(t, meterReadingToSpec(expected), meterReadingToSpec(reading))
+	})
+
+	t.Run("applies defaults", func(t *testing.T) {
+		reading, err := NewMeterReadingWithOptions(required()...)
+
+		require.NoError(t, err)
+		assert.Equal(t, "sum", reading.Aggregation.ToString())
+		assert.Equal(t, "tokens", reading.Unit.ToString())
+		assert.Equal(t, InitialMeterReadingVersion(), reading.Version)
+		assert.False(t, reading.CreatedAt.ToTime().IsZero())
+	})
+
+	t.Run("value takes the reading's aggregation", func(t *testing.T) {
+		reading, err := NewMeterReadingWithOptions(required(
+			WithMeterReadingValue("7", "seats"),
+			WithMeterReadingAggregation("max"),
+		)...)
+
+		require.NoError(t, err)
+		require.Len(t, reading.ComputedValues, 1)
+		assert.Equal(t, "max", reading.ComputedValues[0].Aggregation().ToString())
+		assert.Equal(t, "seats", reading.Unit.ToString())
+	})
+
+	t.Run("value without aggregation returns error", func(t *testing.T) {
+		_, err := NewMeterReadingWithOptions(required(WithMeterReadingValue("7", "seats"))...)
+
+		assert.ErrorIs(t, err, ErrInvalidAggregation)
+	})
+
+	t.Run("missing required fields return errors", func(t *testing.T) {
+		_, err := NewMeterReadingWithOptions(required()[1:]...)
+		assert.ErrorIs(t, err, ErrEmptyID)
+
+		_, err = NewMeterReadingWithOptions(required()[:6]...)
+		assert.ErrorContains(t, err, "at least one computed value is required")
+
+		_, err = NewMeterReadingWithOptions(append(required()[:5:5], required()[6])...)
+		assert.ErrorIs(t, err, ErrZeroTime)
+	})
+
+	t.Run("invalid option value returns error", func(t *testing.T) {
+		_, err := NewMeterReadingWithOptions(required(WithComputedValue("1", "tokens", "median"))...)
+
+		assert.ErrorIs(t, err, ErrInvalidAggregation)
+		assert.ErrorContains(t, err, "invalid computed value 1 aggregation")
+	})
+}
+
+func TestMeterReadingBuilder(t *testing.T) {
+	window := specs.TimeWindowSpec{
+		Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("builds reading from chained setters", func(t *testing.T) {
+		reading, err := NewMeterReadingBuilder().
+			ID("reading-1").
+			WorkspaceID("workspace-1").
+			UniverseID("production").
+			Subject("customer:1").
+			Dimension("model", "gpt-4").
+			Window(window).
+			Value("42", "tokens").
+			Aggregation("sum").
+			RecordCount(3).
+			MaxMeteredAt(window.End).
+			Timezone("America/New_York").
+			Build()
+
+		require.NoError(t, err)
+		assert.Equal(t, "reading-1", reading.ID.ToString())
+		assert.Equal(t, map[string]string{"model": "gpt-4"}, reading.Dimensions.ToMap())
+		assert.Equal(t, "42", reading.ComputedValues[0].Quantity().String())
+		assert.Equal(t, 3, reading.RecordCount.ToInt())
+		assert.Equal(t, "America/New_York", reading.Timezone)
+	})
+
+	t.Run("build reports invalid setter value", func(t *testing.T) {
+		_, err := NewMeterReadingBuilder().ID("reading-1").Timezone("Mars/Olympus").Build()
+
+		assert.ErrorContains(t, err, "invalid timezone")
+	})
+}
+
+func TestMeterReadingAggregation_DisplayName(t *testing.T) {
+	tests := []struct {
+		aggregation string
+		display     string
+		short       string
+	}{
+		{"sum", "Sum", "SUM"},
+		{"max", "Maximum", "MAX"},
+		{"min", "Minimum", "MIN"},
+		{"latest", "Latest", "LAT"},
+		{"time-weighted-avg", "Time-Weighted Average", "TWA"},
+		{"first-non-zero", "First Non-Zero", "FNZ"},
+		{"mode", "Mode", "MOD"},
+		{"count", "Count", "CNT"},
+		{"average", "Average", "AVG"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.aggregation, func(t *testing.T) {
+			agg, err := NewMeterReadingAggregation(tt.aggregation)
+			require.NoError(t, err)
+
+			parsed, err := ParseAggregationDisplayName(agg.DisplayName())
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.display, agg.DisplayName())
+			assert.Equal(t, tt.short, agg.ShortName())
+			assert.Equal(t, agg, parsed)
+			assert.Equal(t, tt.aggregation, agg.String())
+		})
+	}
+
+	t.Run("parsing ignores case and whitespace", func(t *testing.T) {
+		agg, err := ParseAggregationDisplayName("  time-weighted average ")
+
+		require.NoError(t, err)
+		assert.True(t, agg.IsTimeWeightedAvg())
+	})
+
+	t.Run("unknown display name returns error", func(t *testing.T) {
+		_, err := ParseAggregationDisplayName("Median")
+
+		assert.ErrorIs(t, err, ErrInvalidAggregation)
+		assert.EqualError(t, err, `unknown aggregation display name: "Median"`)
+	})
+
+	t.Run("every valid aggregation has names", func(t *testing.T) {
+		for value := range aggregationNames {
+			_, err := NewMeterReadingAggregation(value)
+			assert.NoError(t, err, value)
+		}
+		assert.Len(t, aggregationNames, len(tests))
+	})
+}
@@ -1,8 +1,12 @@
 package internal
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	specs "github.com/chrisconley/metron/specs"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -16,9 +20,18 @@ type MeterRecord struct {
 	Dimensions    MeterRecordDimensions
 	SourceEventID MeterRecordSourceEventID
 	MeteredAt     MeterRecordMeteredAt
+	SampleRate    MeterRecordSampleRate
+	Confidence    MeterRecordConfidence
 }
 
+// NewMeterRecord builds a record from spec using DefaultMeterRecordChecks.
 func NewMeterRecord(spec specs.MeterRecordSpec) (MeterRecord, error) {
+	return NewMeterRecordWithChecks(spec, DefaultMeterRecordChecks())
+}
+
+// NewMeterRecordWithChecks builds a record from spec, applying checks in place
+// of the defaults. Use it to load records written before a check existed.
+func NewMeterRecordWithChecks(spec specs.MeterRecordSpec, checks MeterRecordChecks) (MeterRecord, error) {
 	id, err := NewMeterRecordID(spec.ID)
 	if err != nil {
 		return MeterRecord{}, fmt.Errorf("invalid ID: %w", err)
@@ -41,7 +54,11 @@ func NewMeterRecord(spec specs.MeterRecordSpec) (MeterRecord, error) {
 
 	// Build observations from spec.Observations array
 	if len(spec.Observations) == 0 {
-		return MeterRecord{}, fmt.Errorf("observations array is empty")
+		return MeterRecord{}, ErrEmptyObservations
+	}
+
+	if errs := specs.ValidateObservations(spec.Observations); len(errs) > 0 {
+		return MeterRecord{}, fmt.Errorf("invalid %w", errs[0])
 	}
 
 	observations := make([]Observation, len(spec.Observations))
@@ -69,6 +86,10 @@ func NewMeterRecord(spec specs.MeterRecordSpec) (MeterRecord, error) {
 		return MeterRecord{}, fmt.Errorf("invalid observed at: %w", err)
 	}
 
+	if err := checks.checkObservations(observedAt, observations); err != nil {
+		return MeterRecord{}, err
+	}
+
 	dimensions := NewMeterRecordDimensions()
 	for name, value := range spec.Dimensions {
 		dimensions.Set(name, value)
@@ -79,11 +100,21 @@ func NewMeterRecord(spec specs.MeterRecordSpec) (MeterRecord, error) {
 		return MeterRecord{}, fmt.Errorf("invalid source event ID: %w", err)
 	}
 
-	meteredAt, err := NewMeterRecordMeteredAt(spec.MeteredAt)
+	meteredAt, err := NewMeterRecordMeteredAtWithSkew(spec.MeteredAt, checks.MaxFutureSkew)
 	if err != nil {
 		return MeterRecord{}, fmt.Errorf("invalid metered at: %w", err)
 	}
 
+	sampleRate, err := NewMeterRecordSampleRate(spec.SampleRate)
+	if err != nil {
+		return MeterRecord{}, fmt.Errorf("invalid sample rate: %w", err)
+	}
+
+	confidence, err := NewMeterRecordConfidence(spec.Confidence)
+	if err != nil {
+		return MeterRecord{}, fmt.Errorf("invalid confidence: %w", err)
+	}
+
 	return MeterRecord{
 		ID:            id,
 		WorkspaceID:   workspaceID,
@@ -94,16 +125,379 @@ func NewMeterRecord(spec specs.MeterRecordSpec) (MeterRecord, error) {
 		Dimensions:    dimensions,
 		SourceEventID: sourceEventID,
 		MeteredAt:     meteredAt,
+		SampleRate:    sampleRate,
+		Confidence:    confidence,
 	}, nil
 }
 
+// MeterRecordOption sets one field of a record built by
+// NewMeterRecordWithOptions, returning error if the value is invalid.
+type MeterRecordOption func(*meterRecordBuilder) error
+
+// meterRecordBuilder is the record under construction by
+// NewMeterRecordWithOptions, plus the checks to apply once all options ran.
+type meterRecordBuilder struct {
+	MeterRecord
+	checks MeterRecordChecks
+}
+
+// NewMeterRecordWithOptions builds a record field by field, without an
+// intermediate spec.
+//
+// ID, workspace ID, universe ID, subject, observed at, and at least one
+// observation are required. The source event ID defaults to the ID, metered
+// at to now, and sample rate and confidence to 1. Returns error if a required
+// field is missing, an option's value is invalid, or an observation's window
+// disagrees with observed at (see MeterRecordChecks and WithMeterRecordChecks).
+func NewMeterRecordWithOptions(opts ...MeterRecordOption) (MeterRecord, error) {
+	meteredAt, err := NewMeterRecordMeteredAt(time.Time{})
+	if err != nil {
+		return MeterRecord{}, fmt.Errorf("invalid metered at: %w", err)
+	}
+	sampleRate, _ := NewMeterRecordSampleRate("")
+	confidence, _ := NewMeterRecordConfidence("")
+	builder := meterRecordBuilder{
+		MeterRecord: MeterRecord{
+			Dimensions: NewMeterRecordDimensions(),
+			MeteredAt:  meteredAt,
+			SampleRate: sampleRate,
+			Confidence: confidence,
+		},
+		checks: DefaultMeterRecordChecks(),
+	}
+	for _, opt := range opts {
+		if err := opt(&builder); err != nil {
+			return MeterRecord{}, err
+		}
+	}
+	record := builder.MeterRecord
+	if err := checkFutureSkew(record.MeteredAt.ToTime(), builder.checks.MaxFutureSkew); err != nil {
+		return MeterRecord{}, fmt.Errorf("invalid metered at: %w", err)
+	}
+
+	switch {
+	case record.ID == MeterRecordID{}:
+		return MeterRecord{}, fmt.Errorf("invalid ID: %w", ErrEmptyID)
+	case record.WorkspaceID == MeterRecordWorkspaceID{}:
+		return MeterRecord{}, fmt.Errorf("invalid workspace ID: %w", ErrEmptyWorkspaceID)
+	case record.UniverseID == MeterRecordUniverseID{}:
+		return MeterRecord{}, fmt.Errorf("invalid universe ID: universe ID is required")
+	case record.Subject == MeterRecordSubject{}:
+		return MeterRecord{}, fmt.Errorf("invalid subject: %w", ErrEmptySubject)
+	case len(record.Observations) == 0:
+		return MeterRecord{}, ErrEmptyObservations
+	case record.ObservedAt.ToTime().IsZero():
+		return MeterRecord{}, fmt.Errorf("invalid observed at: %w", newValidationError(ErrZeroTime, "observed at is required"))
+	}
+
+	if err := builder.checks.checkObservations(record.ObservedAt, record.Observations); err != nil {
+		return MeterRecord{}, err
+	}
+
+	if record.SourceEventID == (MeterRecordSourceEventID{}) {
+		record.SourceEventID = MeterRecordSourceEventID{value: record.ID.ToString()}
+	}
+	return record, nil
+}
+
+func WithMeterRecordID(id string) MeterRecordOption {
+	return func(r *meterRecordBuilder) error {
+		value, err := NewMeterRecordID(id)
+		if err != nil {
+			return fmt.Errorf("invalid ID: %w", err)
+		}
+		r.ID = value
+		return nil
+	}
+}
+
+func WithMeterRecordWorkspaceID(workspaceID string) MeterRecordOption {
+	return func(r *meterRecordBuilder) error {
+		value, err := NewMeterRecordWorkspaceID(workspaceID)
+		if err != nil {
+			return fmt.Errorf("invalid workspace ID: %w", err)
+		}
+		r.WorkspaceID = value
+		return nil
+	}
+}
+
+func WithMeterRecordUniverseID(universeID string) MeterRecordOption {
+	return func(r *meterRecordBuilder) error {
+		value, err := NewMeterRecordUniverseID(universeID)
+		if err != nil {
+			return fmt.Errorf("invalid universe ID: %w", err)
+		}
+		r.UniverseID = value
+		return nil
+	}
+}
+
+func WithMeterRecordSubject(subject string) MeterRecordOption {
+	return func(r *meterRecordBuilder) error {
+		value, err := NewMeterRecordSubject(subject)
+		if err != nil {
+			return fmt.Errorf("invalid subject: %w", err)
+		}
+		r.Subject = value
+		return nil
+	}
+}
+
+func WithMeterRecordObservedAt(t time.Time) MeterRecordOption {
+	return func(r *meterRecordBuilder) error {
+		value, err := NewMeterRecordObservedAt(t)
+		if err != nil {
+			return fmt.Errorf("invalid observed at: %w", err)
+		}
+		r.ObservedAt = value
+		return nil
+	}
+}
+
+// WithMeterRecordObservation appends an observation. Its window must be set
+// (see specs.NewInstantObservation).
+func WithMeterRecordObservation(obs specs.ObservationSpec) MeterRecordOption {
+	return func(r *meterRecordBuilder) error {
+		i := len(r.Observations)
+		if err := obs.Validate(); err != nil {
+			return fmt.Errorf("invalid observation[%d]: %w", i, err)
+		}
+		quantity, err := NewDecimal(obs.Quantity)
+		if err != nil {
+			return fmt.Errorf("invalid observation[%d] quantity: %w", i, err)
+		}
+		unit, err := NewUnit(obs.Unit)
+		if err != nil {
+			return fmt.Errorf("invalid observation[%d] unit: %w", i, err)
+		}
+		window, err := TimeWindowFromSpec(obs.Window)
+		if err != nil {
+			return fmt.Errorf("invalid observation[%d] window: %w", i, err)
+		}
+		r.Observations = append(r.Observations, NewObservation(quantity, unit, window))
+		return nil
+	}
+}
+
+// WithMeterRecordDimension sets one dimension, replacing any earlier value for
+// key.
+func WithMeterRecordDimension(key, value string) MeterRecordOption {
+	return func(r *meterRecordBuilder) error {
+		if key == "" {
+			return fmt.Errorf("invalid dimension: key cannot be empty")
+		}
+		r.Dimensions.Set(key, value)
+		return nil
+	}
+}
+
+func WithMeterRecordSourceEventID(sourceEventID string) MeterRecordOption {
+	return func(r *meterRecordBuilder) error {
+		value, err := NewMeterRecordSourceEventID(sourceEventID)
+		if err != nil {
+			return fmt.Errorf("invalid source event ID: %w", err)
+		}
+		r.SourceEventID = value
+		return nil
+	}
+}
+
+// WithMeterRecordChecks replaces DefaultMeterRecordChecks for this record.
+func WithMeterRecordChecks(checks MeterRecordChecks) MeterRecordOption {
+	return func(r *meterRecordBuilder) error {
+		r.checks = checks
+		return nil
+	}
+}
+
+// WithMeterRecordMeteredAt sets metered at; zero means now. The future-skew
+// check runs after all options, so WithMeterRecordChecks applies regardless of
+// option order.
+func WithMeterRecordMeteredAt(t time.Time) MeterRecordOption {
+	return func(r *meterRecordBuilder) error {
+		value, err := NewMeterRecordMeteredAtWithSkew(t, -1)
+		if err != nil {
+			return fmt.Errorf("invalid metered at: %w", err)
+		}
+		r.MeteredAt = value
+		return nil
+	}
+}
+
+// ContentHash returns the SHA-256 hex digest of the record's usage content:
+// workspace, universe, subject, observed at, observations sorted by unit, and
+// dimensions sorted by key. IDs, source event, and system timestamps are
+// excluded so records from different events with identical usage hash equally.
+// A sampled or estimated record also covers its SampleRate and Confidence, so
+// a record standing for 100 events never hashes like an unsampled one; exact,
+// unsampled records hash as before.
+func (r MeterRecord) ContentHash() string {
+	observations := make([]Observation, len(r.Observations))
+	copy(observations, r.Observations)
+	sort.SliceStable(observations, func(i, j int) bool {
+		return observations[i].Unit().ToString() < observations[j].Unit().ToString()
+	})
+
+	names := r.Dimensions.Names()
+	sort.Strings(names)
+
+	// Quote every component so separators inside values cannot collide
+	var b strings.Builder
+	fmt.Fprintf(&b, "%q|%q|%q|%q", r.WorkspaceID.ToString(), r.UniverseID.ToString(), r.Subject.ToString(),
+		r.ObservedAt.ToTime().UTC().Format(time.RFC3339Nano))
+	for _, o := range observations {
+		fmt.Fprintf(&b, "|obs:%q,%q,%q,%q", o.Quantity().String(), o.Unit().ToString(),
+			o.Window().Start().ToTime().UTC().Format(time.RFC3339Nano),
+			o.Window().End().ToTime().UTC().Format(time.RFC3339Nano))
+	}
+	for _, name := range names {
+		value, _ := r.Dimensions.Get(name)
+		fmt.Fprintf(&b, "|dim:%q=%q", name, value)
+	}
+	if r.SampleRate.IsSampled() || !r.Confidence.IsExact() {
+		fmt.Fprintf(&b, "|sample:%q,%q", r.SampleRate.ToDecimal().String(), r.Confidence.ToDecimal().String())
+	}
+
+	hash := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(hash[:])
+}
+
+// HasDuplicateObservations reports whether two of the record's observations
+// have the same quantity, unit, and window, as removed by
+// DeduplicateObservations.
+func (r MeterRecord) HasDuplicateObservations() bool {
+	seen := make(map[observationKey]bool, len(r.Observations))
+	for _, o := range r.Observations {
+		key := newObservationKey(o.Quantity().String(), o.Unit().ToString(),
+			o.Window().Start().ToTime(), o.Window().End().ToTime())
+		if seen[key] {
+			return true
+		}
+		seen[key] = true
+	}
+	return false
+}
+
+// ObservationTimeRange returns the earliest window start and latest window
+// end across the record's observations. For a record of only instant
+// observations both are the instant. A record without observations returns
+// zero times.
+func (r MeterRecord) ObservationTimeRange() (earliest time.Time, latest time.Time) {
+	for i, o := range r.Observations {
+		start, end := o.Window().Start().ToTime(), o.Window().End().ToTime()
+		if i == 0 || start.Before(earliest) {
+			earliest = start
+		}
+		if i == 0 || end.After(latest) {
+			latest = end
+		}
+	}
+	return earliest, latest
+}
+
+// ObservationDuration returns the total time covered by the record's span
+// observations. Overlapping spans are counted once, so two observations of
+// 10:00–11:00 and 10:30–11:30 cover 90 minutes. Instant observations cover no
+// time.
+func (r MeterRecord) ObservationDuration() time.Duration {
+	spans := make([]TimeWindow, 0, len(r.Observations))
+	for _, o := range r.Observations {
+		if !o.Window().IsInstant() {
+			spans = append(spans, o.Window())
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].Start().ToTime().Before(spans[j].Start().ToTime())
+	})
+
+	var total time.Duration
+	var coveredUntil time.Time
+	for _, w := range spans {
+		start, end := w.Start().ToTime(), w.End().ToTime()
+		if start.Before(coveredUntil) {
+			start = coveredUntil
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+			coveredUntil = end
+		}
+	}
+	return total
+}
+
+// SplitByObservation returns one record per observation, for systems that
+// expect unbundled records. Each split keeps every other field of r, with its
+// own copy of the dimensions, and the observation's index appended to the ID
+// ("<id>:0", "<id>:1", ...).
+func (r MeterRecord) SplitByObservation() []MeterRecord {
+	result := make([]MeterRecord, 0, len(r.Observations))
+	for i, observation := range r.Observations {
+		split := r
+		split.ID = MeterRecordID{value: fmt.Sprintf("%s:%d", r.ID.ToString(), i)}
+		split.Observations = []Observation{observation}
+		split.Dimensions = NewMeterRecordDimensions()
+		for name, value := range r.Dimensions.values {
+			split.Dimensions.Set(name, value)
+		}
+		result = append(result, split)
+	}
+	return result
+}
+
+// SplitMeterRecord returns one record spec per observation in record, as
+// MeterRecord.SplitByObservation does. ContentHash is cleared on each split,
+// since it no longer describes the split record's content.
+func SplitMeterRecord(record specs.MeterRecordSpec) []specs.MeterRecordSpec {
+	result := make([]specs.MeterRecordSpec, 0, len(record.Observations))
+	for i, observation := range record.Observations {
+		split := record
+		split.ID = fmt.Sprintf("%s:%d", record.ID, i)
+		split.Observations = []specs.ObservationSpec{observation}
+		split.ContentHash = ""
+		if record.Dimensions != nil {
+			split.Dimensions = make(map[string]string, len(record.Dimensions))
+			for name, value := range record.Dimensions {
+				split.Dimensions[name] = value
+			}
+		}
+		result = append(result, split)
+	}
+	return result
+}
+
+// IsSortedByObservedAt reports whether records are in non-decreasing ObservedAt
+// order. It runs in linear time, so callers can skip an O(n log n) sort for
+// input that is already chronological.
+func IsSortedByObservedAt(records []MeterRecord) bool {
+	for i := 1; i < len(records); i++ {
+		if records[i].ObservedAt.ToTime().Before(records[i-1].ObservedAt.ToTime()) {
+			return false
+		}
+	}
+	return true
+}
+
+// EnsureSortedByObservedAt sorts records by ObservedAt in place and returns
+// the same slice. Records with equal timestamps keep their relative order.
+// Already-sorted input is left untouched.
+func EnsureSortedByObservedAt(records []MeterRecord) []MeterRecord {
+	if IsSortedByObservedAt(records) {
+		return records
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].ObservedAt.ToTime().Before(records[j].ObservedAt.ToTime())
+	})
+	return records
+}
+
 type MeterRecordID struct {
 	value string
 }
 
 func NewMeterRecordID(value string) (MeterRecordID, error) {
 	if value == "" {
-		return MeterRecordID{}, fmt.Errorf("ID is required")
+		return MeterRecordID{}, ErrEmptyID
 	}
 	return MeterRecordID{value: value}, nil
 }
@@ -118,7 +512,7 @@ type MeterRecordSubject struct {
 
 func NewMeterRecordSubject(value string) (MeterRecordSubject, error) {
 	if value == "" {
-		return MeterRecordSubject{}, fmt.Errorf("subject is required")
+		return MeterRecordSubject{}, ErrEmptySubject
 	}
 	return MeterRecordSubject{value: value}, nil
 }
@@ -133,7 +527,7 @@ type MeterRecordObservedAt struct {
 
 func NewMeterRecordObservedAt(value time.Time) (MeterRecordObservedAt, error) {
 	if value.IsZero() {
-		return MeterRecordObservedAt{}, fmt.Errorf("observed at is required")
+		return MeterRecordObservedAt{}, newValidationError(ErrZeroTime, "observed at is required")
 	}
 	return MeterRecordObservedAt{value: value}, nil
 }
@@ -180,7 +574,7 @@ type MeterRecordSourceEventID struct {
 
 func NewMeterRecordSourceEventID(value string) (MeterRecordSourceEventID, error) {
 	if value == "" {
-		return MeterRecordSourceEventID{}, fmt.Errorf("source event ID is required")
+		return MeterRecordSourceEventID{}, newValidationError(ErrEmptyID, "source event ID is required")
 	}
 	return MeterRecordSourceEventID{value: value}, nil
 }
@@ -195,7 +589,7 @@ type MeterRecordWorkspaceID struct {
 
 func NewMeterRecordWorkspaceID(value string) (MeterRecordWorkspaceID, error) {
 	if value == "" {
-		return MeterRecordWorkspaceID{}, fmt.Errorf("workspace ID is required")
+		return MeterRecordWorkspaceID{}, ErrEmptyWorkspaceID
 	}
 	return MeterRecordWorkspaceID{value: value}, nil
 }
@@ -225,7 +619,7 @@ type Unit struct {
 
 func NewUnit(value string) (Unit, error) {
 	if value == "" {
-		return Unit{}, fmt.Errorf("unit is required")
+		return Unit{}, newValidationError(ErrInvalidUnit, "unit is required")
 	}
 	return Unit{value: value}, nil
 }
@@ -262,17 +656,175 @@ func (o Observation) Window() TimeWindow {
 	return o.window
 }
 
+// DefaultMaxFutureSkew bounds how far ahead of time.Now() system timestamps
+// (MeterRecord.MeteredAt, MeterReading.CreatedAt) may be unless a caller
+// passes its own limit.
+//
+// Future system timestamps would advance watermarks past records that have not
+// been processed yet. A small allowance absorbs clock drift between hosts.
+const DefaultMaxFutureSkew = 5 * time.Minute
+
+// checkFutureSkew returns an error if value is more than maxSkew ahead of now.
+// Zero accepts only timestamps at or before now; a negative maxSkew disables
+// the check.
+func checkFutureSkew(value time.Time, maxSkew time.Duration) error {
+	if maxSkew < 0 {
+		return nil
+	}
+	if limit := time.Now().Add(maxSkew); value.After(limit) {
+		return fmt.Errorf("%s is more than %s in the future", value.Format(time.RFC3339), maxSkew)
+	}
+	return nil
+}
+
+// MeterRecordChecks are the cross-field checks applied when building a
+// MeterRecord. They are passed per call rather than set process-wide so one
+// caller loading legacy data cannot loosen validation for every other caller.
+type MeterRecordChecks struct {
+	// WindowConsistency requires each observation's window to agree with the
+	// record's ObservedAt: instant observations must occur at ObservedAt
+	// (within WindowConsistencyTolerance) and span observations must contain
+	// it. Disable to load records written before the check existed.
+	WindowConsistency bool
+
+	// MaxFutureSkew bounds how far ahead of now MeteredAt may be. Zero
+	// accepts only timestamps at or before now; negative disables the check.
+	MaxFutureSkew time.Duration
+}
+
+// DefaultMeterRecordChecks returns the checks NewMeterRecord applies.
+func DefaultMeterRecordChecks() MeterRecordChecks {
+	return MeterRecordChecks{WindowConsistency: true, MaxFutureSkew: DefaultMaxFutureSkew}
+}
+
+// checkObservations returns an error for the first observation whose window
+// disagrees with observedAt, if WindowConsistency is enabled.
+func (c MeterRecordChecks) checkObservations(observedAt MeterRecordObservedAt, observations []Observation) error {
+	if !c.WindowConsistency {
+		return nil
+	}
+	for i, observation := range observations {
+		if err := checkWindowConsistency(observedAt, observation.Window()); err != nil {
+			return fmt.Errorf("invalid observation[%d] window: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// WindowConsistencyTolerance is how far an instant observation may be from
+// ObservedAt, absorbing sub-second precision lost by some serializers.
+const WindowConsistencyTolerance = time.Second
+
+// checkWindowConsistency returns an error if window disagrees with observedAt.
+func checkWindowConsistency(observedAt MeterRecordObservedAt, window TimeWindow) error {
+	at := observedAt.ToTime()
+	start := window.Start().ToTime()
+	end := window.End().ToTime()
+
+	if window.IsInstant() {
+		diff := at.Sub(start)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > WindowConsistencyTolerance {
+			return fmt.Errorf("instant observation at %s does not match observed at %s",
+				start.Format(time.RFC3339Nano), at.Format(time.RFC3339Nano))
+		}
+		return nil
+	}
+
+	if at.Before(start) || at.After(end) {
+		return fmt.Errorf("observed at %s is outside span [%s, %s]",
+			at.Format(time.RFC3339Nano), start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano))
+	}
+	return nil
+}
+
 type MeterRecordMeteredAt struct {
 	value time.Time
 }
 
+// NewMeterRecordMeteredAt returns value, or now if value is zero. Returns
+// error if value is more than DefaultMaxFutureSkew ahead of now.
 func NewMeterRecordMeteredAt(value time.Time) (MeterRecordMeteredAt, error) {
+	return NewMeterRecordMeteredAtWithSkew(value, DefaultMaxFutureSkew)
+}
+
+// NewMeterRecordMeteredAtWithSkew is NewMeterRecordMeteredAt with an explicit
+// future-skew limit (see MeterRecordChecks.MaxFutureSkew).
+func NewMeterRecordMeteredAtWithSkew(value time.Time, maxSkew time.Duration) (MeterRecordMeteredAt, error) {
 	if value.IsZero() {
 		value = time.Now()
 	}
+	if err := checkFutureSkew(value, maxSkew); err != nil {
+		return MeterRecordMeteredAt{}, err
+	}
 	return MeterRecordMeteredAt{value: value}, nil
 }
 
 func (m MeterRecordMeteredAt) ToTime() time.Time {
 	return m.value
 }
+
+// MeterRecordSampleRate is the fraction of source events a record was sampled from.
+// An empty value means the record was not sampled (rate 1).
+type MeterRecordSampleRate struct {
+	value Decimal
+}
+
+func NewMeterRecordSampleRate(value string) (MeterRecordSampleRate, error) {
+	if value == "" {
+		return MeterRecordSampleRate{value: NewDecimalFromInt64(1)}, nil
+	}
+	rate, err := NewDecimal(value)
+	if err != nil {
+		return MeterRecordSampleRate{}, err
+	}
+	if rate.Cmp(NewDecimalFromInt64(0)) <= 0 || rate.Cmp(NewDecimalFromInt64(1)) > 0 {
+		return MeterRecordSampleRate{}, fmt.Errorf("sample rate must be greater than 0 and at most 1, got %s", value)
+	}
+	return MeterRecordSampleRate{value: rate}, nil
+}
+
+func (s MeterRecordSampleRate) ToDecimal() Decimal {
+	return s.value
+}
+
+// IsSampled returns true if the rate is below 1.
+func (s MeterRecordSampleRate) IsSampled() bool {
+	return s.value.Cmp(NewDecimalFromInt64(1)) < 0
+}
+
+// EventsRepresented returns the number of source events a record stands for (1/rate).
+func (s MeterRecordSampleRate) EventsRepresented() Decimal {
+	return NewDecimalFromInt64(1).Div(s.value)
+}
+
+// MeterRecordConfidence is the confidence in a record's quantities.
+// An empty value means exact quantities (confidence 1).
+type MeterRecordConfidence struct {
+	value Decimal
+}
+
+func NewMeterRecordConfidence(value string) (MeterRecordConfidence, error) {
+	if value == "" {
+		return MeterRecordConfidence{value: NewDecimalFromInt64(1)}, nil
+	}
+	confidence, err := NewDecimal(value)
+	if err != nil {
+		return MeterRecordConfidence{}, err
+	}
+	if confidence.Cmp(NewDecimalFromInt64(0)) < 0 || confidence.Cmp(NewDecimalFromInt64(1)) > 0 {
+		return MeterRecordConfidence{}, fmt.Errorf("confidence must be between 0 and 1, got %s", value)
+	}
+	return MeterRecordConfidence{value: confidence}, nil
+}
+
+func (c MeterRecordConfidence) ToDecimal() Decimal {
+	return c.value
+}
+
+// IsExact returns true if the confidence is 1.
+func (c MeterRecordConfidence) IsExact() bool {
+	return c.value.Cmp(NewDecimalFromInt64(1)) == 0
+}
@@ -0,0 +1,521 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/internal/testutil"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMeterRecord_WindowConsistency(t *testing.T) {
+	observedAt := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	withSpan := func(spec specs.MeterRecordSpec, start, end time.Time) specs.MeterRecordSpec {
+		observation, err := specs.NewSpanObservation("8", "compute-hours", start, end)
+		require.NoError(t, err)
+		spec.Observations = []specs.ObservationSpec{observation}
+		return spec
+	}
+
+	t.Run("accepts instant observation at observed at", func(t *testing.T) {
+		_, err := NewMeterRecord(newTestRecordSpec("rec-1", "1", "api-calls", observedAt))
+
+		require.NoError(t, err)
+	})
+
+	t.Run("accepts instant observation within tolerance", func(t *testing.T) {
+		spec := newTestRecordSpec("rec-1", "1", "api-calls", observedAt)
+		spec.ObservedAt = observedAt.Add(500 * time.Millisecond)
+
+		_, err := NewMeterRecord(spec)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("accepts span containing observed at", func(t *testing.T) {
+		spec := withSpan(newTestRecordSpec("rec-1", "8", "compute-hours", observedAt), observedAt.Add(-8*time.Hour), observedAt)
+
+		_, err := NewMeterRecord(spec)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects instant observation away from observed at", func(t *testing.T) {
+		spec := newTestRecordSpec("rec-1", "1", "api-calls", observedAt)
+		spec.ObservedAt = time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+		_, err := NewMeterRecord(spec)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not match observed at")
+	})
+
+	t.Run("rejects span not containing observed at", func(t *testing.T) {
+		start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		spec := withSpan(newTestRecordSpec("rec-1", "8", "compute-hours", observedAt), start, start.Add(8*time.Hour))
+
+		_, err := NewMeterRecord(spec)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "outside span")
+	})
+
+	t.Run("disabled check accepts inconsistent windows", func(t *testing.T) {
+		spec := newTestRecordSpec("rec-1", "1", "api-calls", observedAt)
+		spec.ObservedAt = time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+		checks := DefaultMeterRecordChecks()
+		checks.WindowConsistency = false
+
+		_, err := NewMeterRecordWithChecks(spec, checks)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("disabling the check for one call leaves NewMeterRecord strict", func(t *testing.T) {
+		spec := newTestRecordSpec("rec-1", "1", "api-calls", observedAt)
+		spec.ObservedAt = time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+		_, err := NewMeterRecordWithChecks(spec, MeterRecordChecks{WindowConsistency: false})
+		require.NoError(t, err)
+
+		_, err = NewMeterRecord(spec)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not match observed at")
+	})
+}
+
+func TestNewMeterRecordMeteredAt(t *testing.T) {
+	t.Run("accepts timestamp 1 minute in future by default", func(t *testing.T) {
+		value := time.Now().Add(time.Minute)
+
+		meteredAt, err := NewMeterRecordMeteredAt(value)
+
+		require.NoError(t, err)
+		assert.Equal(t, value, meteredAt.ToTime())
+	})
+
+	t.Run("rejects timestamp 10 minutes in future with 5 minute skew", func(t *testing.T) {
+		_, err := NewMeterRecordMeteredAtWithSkew(time.Now().Add(10*time.Minute), 5*time.Minute)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "in the future")
+	})
+
+	t.Run("with zero skew accepts only past timestamps", func(t *testing.T) {
+		_, err := NewMeterRecordMeteredAtWithSkew(time.Now().Add(-time.Second), 0)
+		require.NoError(t, err)
+
+		_, err = NewMeterRecordMeteredAtWithSkew(time.Now().Add(time.Second), 0)
+		require.Error(t, err)
+	})
+
+	t.Run("with negative skew disables the check", func(t *testing.T) {
+		_, err := NewMeterRecordMeteredAtWithSkew(time.Now().Add(24*time.Hour), -1)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("with zero time uses now", func(t *testing.T) {
+		before := time.Now()
+
+		meteredAt, err := NewMeterRecordMeteredAtWithSkew(time.Time{}, 0)
+
+		require.NoError(t, err)
+		assert.False(t, meteredAt.ToTime().Before(before))
+		assert.False(t, meteredAt.ToTime().After(time.Now()))
+	})
+
+	t.Run("record checks carry the skew limit", func(t *testing.T) {
+		observedAt := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+		spec := newTestRecordSpec("rec-1", "1", "api-calls", observedAt)
+		spec.MeteredAt = time.Now().Add(time.Minute)
+		checks := DefaultMeterRecordChecks()
+		checks.MaxFutureSkew = 0
+
+		_, defaultErr := NewMeterRecord(spec)
+		_, strictErr := NewMeterRecordWithChecks(spec, checks)
+		_, optionsErr := NewMeterRecordWithOptions(
+			WithMeterRecordMeteredAt(spec.MeteredAt),
+			WithMeterRecordID("rec-1"),
+			WithMeterRecordWorkspaceID(spec.WorkspaceID),
+			WithMeterRecordUniverseID(spec.UniverseID),
+			WithMeterRecordSubject(spec.Subject),
+			WithMeterRecordObservedAt(observedAt),
+			WithMeterRecordObservation(spec.Observations[0]),
+			WithMeterRecordChecks(checks),
+		)
+
+		assert.NoError(t, defaultErr)
+		assert.ErrorContains(t, strictErr, "in the future")
+		assert.ErrorContains(t, optionsErr, "invalid metered at")
+	})
+}
+
+func TestMeterRecord_ContentHash(t *testing.T) {
+	observedAt := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+
+	newRecord := func(t *testing.T, id string, dimensions map[string]string) MeterRecord {
+		t.Helper()
+		spec := newTestRecordSpec(id, "1250", "tokens", observedAt)
+		spec.Dimensions = dimensions
+		record, err := NewMeterRecord(spec)
+		require.NoError(t, err)
+		return record
+	}
+
+	t.Run("records with identical content have same hash", func(t *testing.T) {
+		first := newRecord(t, "event-1", map[string]string{"model": "gpt-4", "region": "us-east-1"})
+		second := newRecord(t, "event-2", map[string]string{"region": "us-east-1", "model": "gpt-4"})
+
+		assert.Equal(t, first.ContentHash(), second.ContentHash())
+		assert.Len(t, first.ContentHash(), 64)
+	})
+
+	t.Run("records differing only in dimensions have different hash", func(t *testing.T) {
+		first := newRecord(t, "event-1", map[string]string{"model": "gpt-4"})
+		second := newRecord(t, "event-1", map[string]string{"model": "gpt-4o"})
+
+		assert.NotEqual(t, first.ContentHash(), second.ContentHash())
+	})
+
+	t.Run("observation order does not affect hash", func(t *testing.T) {
+		input := specs.NewInstantObservation("450", "input-tokens", observedAt)
+		output := specs.NewInstantObservation("890", "output-tokens", observedAt)
+
+		spec := newTestRecordSpec("event-1", "0", "unused", observedAt)
+		spec.Observations = []specs.ObservationSpec{input, output}
+		first, err := NewMeterRecord(spec)
+		require.NoError(t, err)
+
+		spec.Observations = []specs.ObservationSpec{output, input}
+		second, err := NewMeterRecord(spec)
+		require.NoError(t, err)
+
+		assert.Equal(t, first.ContentHash(), second.ContentHash())
+	})
+}
+
+func TestMeterRecord_SortedByObservedAt(t *testing.T) {
+	base := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	newRecord := func(t *testing.T, id string, offset time.Duration) MeterRecord {
+		record, err := NewMeterRecord(newTestRecordSpec(id, "1", "seats", base.Add(offset)))
+		require.NoError(t, err)
+		return record
+	}
+	ids := func(records []MeterRecord) []string {
+		result := make([]string, len(records))
+		for i, r := range records {
+			result[i] = r.ID.ToString()
+		}
+		return result
+	}
+
+	t.Run("detects sorted and unsorted input", func(t *testing.T) {
+		a, b, c := newRecord(t, "a", 0), newRecord(t, "b", time.Hour), newRecord(t, "c", time.Hour)
+
+		assert.True(t, IsSortedByObservedAt(nil))
+		assert.True(t, IsSortedByObservedAt([]MeterRecord{a, b, c}))
+		assert.False(t, IsSortedByObservedAt([]MeterRecord{b, a}))
+	})
+
+	t.Run("sorts in place keeping ties in input order", func(t *testing.T) {
+		records := []MeterRecord{
+			newRecord(t, "late", 2*time.Hour),
+			newRecord(t, "tie-1", time.Hour),
+			newRecord(t, "early", 0),
+			newRecord(t, "tie-2", time.Hour),
+		}
+
+		sorted := EnsureSortedByObservedAt(records)
+
+		assert.Equal(t, []string{"early", "tie-1", "tie-2", "late"}, ids(sorted))
+		assert.Equal(t, ids(sorted), ids(records))
+	})
+}
+
+func TestMeterRecord_ObservationTimeRange(t *testing.T) {
+	observedAt := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 15, hour, minute, 0, 0, time.UTC)
+	}
+	span := func(t *testing.T, start, end time.Time) specs.ObservationSpec {
+		t.Helper()
+		observation, err := specs.NewSpanObservation("1", "seats", start, end)
+		require.NoError(t, err)
+		return observation
+	}
+	newRecord := func(t *testing.T, observations ...specs.ObservationSpec) MeterRecord {
+		t.Helper()
+		spec := newTestRecordSpec("event-1", "1", "seats", observedAt)
+		spec.Observations = observations
+		record, err := NewMeterRecord(spec)
+		require.NoError(t, err)
+		return record
+	}
+
+	t.Run("multiple spans", func(t *testing.T) {
+		record := newRecord(t,
+			span(t, at(11, 0), at(13, 0)),
+			span(t, at(10, 0), at(12, 30)),
+			span(t, at(12, 0), at(14, 0)),
+		)
+
+		earliest, latest := record.ObservationTimeRange()
+		duration := record.ObservationDuration()
+
+		assert.Equal(t, at(10, 0), earliest)
+		assert.Equal(t, at(14, 0), latest)
+		assert.Equal(t, 4*time.Hour, duration, "overlapping spans count once")
+	})
+
+	t.Run("mix of instant and span", func(t *testing.T) {
+		record := newRecord(t,
+			specs.NewInstantObservation("1", "requests", observedAt),
+			span(t, at(11, 30), at(12, 15)),
+		)
+
+		earliest, latest := record.ObservationTimeRange()
+		duration := record.ObservationDuration()
+
+		assert.Equal(t, at(11, 30), earliest)
+		assert.Equal(t, at(12, 15), latest)
+		assert.Equal(t, 45*time.Minute, duration)
+	})
+
+	t.Run("all instant", func(t *testing.T) {
+		record := newRecord(t,
+			specs.NewInstantObservation("1", "requests", observedAt),
+			specs.NewInstantObservation("2", "tokens", observedAt),
+		)
+
+		earliest, latest := record.ObservationTimeRange()
+		duration := record.ObservationDuration()
+
+		assert.Equal(t, observedAt, earliest)
+		assert.Equal(t, observedAt, latest)
+		assert.Zero(t, duration)
+	})
+
+	t.Run("single observation", func(t *testing.T) {
+		record := newRecord(t, span(t, at(11, 0), at(12, 0)))
+
+		earliest, latest := record.ObservationTimeRange()
+		duration := record.ObservationDuration()
+
+		assert.Equal(t, at(11, 0), earliest)
+		assert.Equal(t, at(12, 0), latest)
+		assert.Equal(t, time.Hour, duration)
+	})
+}
+
+func TestNewMeterRecordWithOptions(t *testing.T) {
+	observedAt := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	required := func(extra ...MeterRecordOption) []MeterRecordOption {
+		return append([]MeterRecordOption{
+			WithMeterRecordID("rec-1"),
+			WithMeterRecordWorkspaceID("workspace-1"),
+			WithMeterRecordUniverseID("production"),
+			WithMeterRecordSubject("customer:1"),
+			WithMeterRecordObservedAt(observedAt),
+			WithMeterRecordObservation(specs.NewInstantObservation("100", "tokens", observedAt)),
+		}, extra...)
+	}
+
+	t.Run("builds record equal to spec constructor", func(t *testing.T) {
+		spec := newTestRecordSpec("rec-1", "100", "tokens", observedAt)
+		spec.WorkspaceID, spec.UniverseID, spec.Subject = "workspace-1", "production", "customer:1"
+		spec.Dimensions = map[string]string{"model": "gpt-4"}
+		spec.SourceEventID = "rec-1"
+		expected, err := NewMeterRecord(spec)
+		require.NoError(t, err)
+
+		record, err := NewMeterRecordWithOptions(required(
+			WithMeterRecordDimension("model", "gpt-4"),
+			WithMeterRecordMeteredAt(spec.MeteredAt),
+		)...)
+
+		require.NoError(t, err)
+		assert.Equal(t, expected.ContentHash(), record.ContentHash())
+		assert.Equal(t, expected.ID, record.ID)
+		assert.Equal(t, expected.SourceEventID, record.SourceEventID)
+		assert.Equal(t, expected.MeteredAt, record.MeteredAt)
+	})
+
+	t.Run("applies defaults", func(t *testing.T) {
+		record, err := NewMeterRecordWithOptions(required()...)
+
+		require.NoError(t, err)
+		assert.Equal(t, "rec-1", record.SourceEventID.ToString())
+		assert.False(t, record.MeteredAt.ToTime().IsZero())
+		assert.False(t, record.SampleRate.IsSampled())
+		assert.True(t, record.Confidence.IsExact())
+	})
+
+	t.Run("appends observations in order", func(t *testing.T) {
+		record, err := NewMeterRecordWithOptions(required(
+			WithMeterRecordObservation(specs.NewInstantObservation("40", "output-tokens", observedAt)),
+		)...)
+
+		require.NoError(t, err)
+		require.Len(t, record.Observations, 2)
+		assert.Equal(t, "output-tokens", record.Observations[1].Unit().ToString())
+	})
+
+	t.Run("missing required fields return errors", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			opts     []MeterRecordOption
+			expected error
+		}{
+			{"ID", required()[1:], ErrEmptyID},
+			{"subject", append(required()[:3:3], required()[4:]...), ErrEmptySubject},
+			{"observations", required()[:5], ErrEmptyObservations},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := NewMeterRecordWithOptions(tt.opts...)
+
+				assert.ErrorIs(t, err, tt.expected)
+			})
+		}
+	})
+
+	t.Run("missing observed at returns error", func(t *testing.T) {
+		opts := append(required()[:4:4], required()[5])
+
+		_, err := NewMeterRecordWithOptions(opts...)
+
+		assert.ErrorIs(t, err, ErrZeroTime)
+		assert.ErrorContains(t, err, "invalid observed at")
+	})
+
+	t.Run("invalid option value returns error", func(t *testing.T) {
+		_, err := NewMeterRecordWithOptions(required(
+			WithMeterRecordObservation(specs.NewInstantObservation("lots", "tokens", observedAt)),
+		)...)
+
+		var invalid *specs.ObservationValidationError
+		assert.ErrorAs(t, err, &invalid)
+		assert.ErrorContains(t, err, `invalid observation[1]: quantity "lots" is not a valid decimal`)
+	})
+
+	t.Run("observation window must agree with observed at", func(t *testing.T) {
+		_, err := NewMeterRecordWithOptions(required(
+			WithMeterRecordObservation(specs.NewInstantObservation("1", "tokens", observedAt.Add(time.Hour))),
+		)...)
+
+		assert.ErrorContains(t, err, "invalid observation[1] window")
+	})
+
+	t.Run("checks option can disable window consistency", func(t *testing.T) {
+		_, err := NewMeterRecordWithOptions(required(
+			WithMeterRecordObservation(specs.NewInstantObservation("1", "tokens", observedAt.Add(time.Hour))),
+			WithMeterRecordChecks(MeterRecordChecks{WindowConsistency: false}),
+		)...)
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestNewMeterRecord_InvalidObservation(t *testing.T) {
+	observedAt := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+
+	t.Run("reports every problem with the observation", func(t *testing.T) {
+		spec := newTestRecordSpec("rec-1", "1,000", "tokens", observedAt)
+		spec.Observations[0].Unit = ""
+
+		_, err := NewMeterRecord(spec)
+
+		var invalid *specs.ObservationValidationError
+		require.ErrorAs(t, err, &invalid)
+		assert.Equal(t, `invalid observation[0]: quantity "1,000" is not a valid decimal; unit is required`, err.Error())
+	})
+
+	t.Run("names the invalid observation", func(t *testing.T) {
+		spec := newTestRecordSpec("rec-1", "1", "tokens", observedAt)
+		spec.Observations = append(spec.Observations, specs.ObservationSpec{Quantity: "2", Unit: "tokens"})
+
+		_, err := NewMeterRecord(spec)
+
+		assert.ErrorContains(t, err, "invalid observation[1]: window start is required; window end is required")
+	})
+}
+
+func TestMeterRecord_SplitByObservation(t *testing.T) {
+	newRecord := func(t *testing.T, observations ...specs.ObservationSpec) MeterRecord {
+		t.Helper()
+		spec := testutil.FixtureMeterRecord(
+			testutil.WithRecordID("event-1"),
+			testutil.WithRecordObservations(observations...),
+			testutil.WithRecordDimensions(map[string]string{"model": "gpt-4"}),
+		)
+		record, err := NewMeterRecord(spec)
+		require.NoError(t, err)
+		return record
+	}
+
+	t.Run("single observation returns single record", func(t *testing.T) {
+		record := newRecord(t, testutil.FixtureObservation("100", "tokens"))
+
+		splits := record.SplitByObservation()
+
+		require.Len(t, splits, 1)
+		assert.Equal(t, "event-1:0", splits[0].ID.ToString())
+		assert.Equal(t, "100", splits[0].Observations[0].Quantity().String())
+	})
+
+	t.Run("two observations return records with distinct IDs", func(t *testing.T) {
+		record := newRecord(t,
+			testutil.FixtureObservation("450", "input-tokens"),
+			testutil.FixtureObservation("890", "output-tokens"))
+
+		splits := record.SplitByObservation()
+
+		require.Len(t, splits, 2)
+		assert.Equal(t, "event-1:0", splits[0].ID.ToString())
+		assert.Equal(t, "event-1:1", splits[1].ID.ToString())
+		assert.Equal(t, "input-tokens", splits[0].Observations[0].Unit().ToString())
+		assert.Equal(t, "output-tokens", splits[1].Observations[0].Unit().ToString())
+		assert.Equal(t, record.ObservedAt, splits[0].ObservedAt)
+		assert.Equal(t, record.ObservedAt, splits[1].ObservedAt)
+	})
+
+	t.Run("dimensions are copied, not shared", func(t *testing.T) {
+		record := newRecord(t,
+			testutil.FixtureObservation("450", "input-tokens"),
+			testutil.FixtureObservation("890", "output-tokens"))
+
+		splits := record.SplitByObservation()
+		splits[0].Dimensions.Set("model", "gpt-4o")
+
+		model, _ := splits[1].Dimensions.Get("model")
+		assert.Equal(t, "gpt-4", model)
+		model, _ = record.Dimensions.Get("model")
+		assert.Equal(t, "gpt-4", model)
+	})
+}
+
+func TestSplitMeterRecord(t *testing.T) {
+	record := testutil.FixtureMeterRecord(
+		testutil.WithRecordID("event-1"),
+		testutil.WithRecordObservations(
+			testutil.FixtureObservation("450", "input-tokens"),
+			testutil.FixtureObservation("890", "output-tokens")),
+		testutil.WithRecordDimensions(map[string]string{"model": "gpt-4"}),
+	)
+	record.ContentHash = "stale"
+
+	splits := SplitMeterRecord(record)
+	splits[0].Dimensions["model"] = "gpt-4o"
+
+	require.Len(t, splits, 2)
+	assert.Equal(t, "event-1:0", splits[0].ID)
+	assert.Equal(t, "event-1:1", splits[1].ID)
+	assert.Equal(t, []specs.ObservationSpec{record.Observations[1]}, splits[1].Observations)
+	assert.Equal(t, record.ObservedAt, splits[1].ObservedAt)
+	assert.Equal(t, "gpt-4", splits[1].Dimensions["model"])
+	assert.Equal(t, "gpt-4", record.Dimensions["model"])
+	assert.Empty(t, splits[0].ContentHash)
+}
@@ -0,0 +1,148 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// ValidatePropertyIsNumeric returns nil if value is a plain decimal string
+// such as "42", "-1.5", or "2.5e3", as specs.IsPlainDecimal checks.
+//
+// Stricter than NewDecimal, which also accepts forms like ".5", "+5", and
+// "Infinity" that usually indicate a producer bug. The error wraps
+// ErrInvalidDecimal and, for common mistakes (thousands separators, "k" or
+// "M" suffixes, percentages, surrounding whitespace), says what is wrong.
+func ValidatePropertyIsNumeric(value string) error {
+	if specs.IsPlainDecimal(value) {
+		return nil
+	}
+	return newValidationError(ErrInvalidDecimal, "%q is not numeric%s", value, nonNumericHint(value))
+}
+
+// ValidatePropertyIsPositiveNumeric returns nil if value is numeric, as
+// ValidatePropertyIsNumeric checks, and greater than zero.
+func ValidatePropertyIsPositiveNumeric(value string) error {
+	if err := ValidatePropertyIsNumeric(value); err != nil {
+		return err
+	}
+	quantity, err := NewDecimal(value)
+	if err != nil {
+		return err
+	}
+	if quantity.Cmp(NewDecimalFromInt64(0)) <= 0 {
+		return newValidationError(ErrInvalidDecimal, "%q is not positive", value)
+	}
+	return nil
+}
+
+// nonNumericHint explains why value is not numeric, or returns "" if no
+// common mistake applies.
+func nonNumericHint(value string) string {
+	trimmed := strings.TrimSpace(value)
+	switch {
+	case value == "":
+		return ": value is empty"
+	case trimmed != value:
+		return ": remove surrounding whitespace"
+	case strings.HasSuffix(value, "%"):
+		return ": percentages must be converted with ParseNumericWithFormat"
+	case strings.Contains(value, ","):
+		return ": remove thousands separators"
+	case strings.ContainsAny(value[len(value)-1:], "kKmMbB"):
+		return ": magnitude suffixes are not supported, send the full number"
+	case strings.HasPrefix(value, "+"):
+		return ": remove the leading plus sign"
+	case strings.HasPrefix(value, ".") || strings.HasSuffix(value, "."):
+		return ": add a digit on both sides of the decimal point"
+	}
+	return ""
+}
+
+// NumericFormat is how a numeric property value is written.
+type NumericFormat struct {
+	value string
+}
+
+var (
+	// NumericFormatInteger is a whole number, e.g. "42".
+	NumericFormatInteger = NumericFormat{value: "integer"}
+	// NumericFormatDecimal is a number with a fraction, e.g. "1.5".
+	NumericFormatDecimal = NumericFormat{value: "decimal"}
+	// NumericFormatScientific is a number with an exponent, e.g. "1.5e3".
+	NumericFormatScientific = NumericFormat{value: "scientific"}
+	// NumericFormatPercentage is a number followed by "%", e.g. "12.5%".
+	NumericFormatPercentage = NumericFormat{value: "percentage"}
+)
+
+func NewNumericFormat(value string) (NumericFormat, error) {
+	switch value {
+	case "integer", "decimal", "scientific", "percentage":
+		return NumericFormat{value: value}, nil
+	case "":
+		return NumericFormat{}, fmt.Errorf("numeric format is required")
+	default:
+		return NumericFormat{}, fmt.Errorf("invalid numeric format: %q", value)
+	}
+}
+
+func (f NumericFormat) ToString() string {
+	return f.value
+}
+
+// InferNumericFormat returns the format value is written in. A trailing "%"
+// makes it a percentage, an exponent makes it scientific, and a fraction a
+// decimal. Returns error if value (without any "%") is not numeric.
+func InferNumericFormat(value string) (NumericFormat, error) {
+	if number, ok := strings.CutSuffix(value, "%"); ok {
+		if err := ValidatePropertyIsNumeric(number); err != nil {
+			return NumericFormat{}, err
+		}
+		return NumericFormatPercentage, nil
+	}
+	if err := ValidatePropertyIsNumeric(value); err != nil {
+		return NumericFormat{}, err
+	}
+	switch {
+	case strings.ContainsAny(value, "eE"):
+		return NumericFormatScientific, nil
+	case strings.Contains(value, "."):
+		return NumericFormatDecimal, nil
+	default:
+		return NumericFormatInteger, nil
+	}
+}
+
+// ParseNumericWithFormat converts value, written in format, to a plain
+// decimal string NewDecimal accepts. Scientific notation is expanded ("1.5e3"
+// becomes "1500") and percentages become fractions ("12.5%" becomes "0.125").
+//
+// Returns error if value is not written in format.
+func ParseNumericWithFormat(value string, format NumericFormat) (string, error) {
+	inferred, err := InferNumericFormat(value)
+	if err != nil {
+		return "", err
+	}
+	if inferred != format && !(format == NumericFormatDecimal && inferred == NumericFormatInteger) {
+		return "", newValidationError(ErrInvalidDecimal, "%q is not in %s format", value, format.ToString())
+	}
+
+	switch format {
+	case NumericFormatPercentage:
+		quantity, err := NewDecimal(strings.TrimSuffix(value, "%"))
+		if err != nil {
+			return "", err
+		}
+		fraction := quantity.Div(NewDecimalFromInt64(100)).Normalize()
+		return fraction.value.Text('f'), nil
+	case NumericFormatScientific:
+		quantity, err := NewDecimal(value)
+		if err != nil {
+			return "", err
+		}
+		return quantity.value.Text('f'), nil
+	default:
+		return value, nil
+	}
+}
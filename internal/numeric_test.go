@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/internal/testutil"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePropertyIsNumeric(t *testing.T) {
+	t.Run("valid formats", func(t *testing.T) {
+		for _, value := range []string{"0", "42", "-7", "1.5", "-0.001", "1e3", "2.5E-4", "1.5e+10"} {
+			assert.NoError(t, ValidatePropertyIsNumeric(value), value)
+		}
+	})
+
+	t.Run("invalid formats explain the problem", func(t *testing.T) {
+		tests := []struct {
+			value string
+			hint  string
+		}{
+			{"", "value is empty"},
+			{" 42", "surrounding whitespace"},
+			{"1.5k", "magnitude suffixes"},
+			{"2M", "magnitude suffixes"},
+			{"1,000", "thousands separators"},
+			{"50%", "percentages"},
+			{"+5", "leading plus sign"},
+			{".5", "both sides of the decimal point"},
+			{"abc", `"abc" is not numeric`},
+			{"NaN", `"NaN" is not numeric`},
+			{"Infinity", `"Infinity" is not numeric`},
+		}
+		for _, tt := range tests {
+			err := ValidatePropertyIsNumeric(tt.value)
+
+			assert.ErrorIs(t, err, ErrInvalidDecimal, tt.value)
+			assert.ErrorContains(t, err, tt.hint, tt.value)
+		}
+	})
+}
+
+func TestValidatePropertyIsPositiveNumeric(t *testing.T) {
+	assert.NoError(t, ValidatePropertyIsPositiveNumeric("0.5"))
+	assert.ErrorContains(t, ValidatePropertyIsPositiveNumeric("0"), `"0" is not positive`)
+	assert.ErrorContains(t, ValidatePropertyIsPositiveNumeric("-3"), `"-3" is not positive`)
+	assert.ErrorContains(t, ValidatePropertyIsPositiveNumeric("1.5k"), "is not numeric")
+}
+
+func TestInferNumericFormat(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected NumericFormat
+	}{
+		{"42", NumericFormatInteger},
+		{"-42", NumericFormatInteger},
+		{"1.5", NumericFormatDecimal},
+		{"1.5e3", NumericFormatScientific},
+		{"1E-2", NumericFormatScientific},
+		{"12.5%", NumericFormatPercentage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			format, err := InferNumericFormat(tt.value)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, format)
+		})
+	}
+
+	t.Run("invalid value returns error", func(t *testing.T) {
+		_, err := InferNumericFormat("ten%")
+
+		assert.ErrorIs(t, err, ErrInvalidDecimal)
+	})
+}
+
+func TestParseNumericWithFormat(t *testing.T) {
+	t.Run("scientific notation is expanded", func(t *testing.T) {
+		tests := map[string]string{"1.5e3": "1500", "2E-3": "0.002", "-4e0": "-4"}
+		for value, expected := range tests {
+			parsed, err := ParseNumericWithFormat(value, NumericFormatScientific)
+
+			require.NoError(t, err, value)
+			assert.Equal(t, expected, parsed, value)
+		}
+	})
+
+	t.Run("percentage becomes a fraction", func(t *testing.T) {
+		tests := map[string]string{"12.5%": "0.125", "100%": "1", "-50%": "-0.5", "0.1%": "0.001"}
+		for value, expected := range tests {
+			parsed, err := ParseNumericWithFormat(value, NumericFormatPercentage)
+
+			require.NoError(t, err, value)
+			assert.Equal(t, expected, parsed, value)
+		}
+	})
+
+	t.Run("integer and decimal are unchanged", func(t *testing.T) {
+		parsed, err := ParseNumericWithFormat("42", NumericFormatDecimal)
+		require.NoError(t, err)
+		assert.Equal(t, "42", parsed)
+
+		parsed, err = ParseNumericWithFormat("1.25", NumericFormatDecimal)
+		require.NoError(t, err)
+		assert.Equal(t, "1.25", parsed)
+	})
+
+	t.Run("value in another format returns error", func(t *testing.T) {
+		_, err := ParseNumericWithFormat("12.5%", NumericFormatDecimal)
+
+		assert.ErrorContains(t, err, `"12.5%" is not in decimal format`)
+	})
+}
+
+func TestMeter_NonNumericProperty(t *testing.T) {
+	config := specs.MeteringConfigSpec{
+		Observations: []specs.ObservationExtractionSpec{{SourceProperty: "tokens", Unit: "tokens"}},
+	}
+	payload := testutil.FixtureEventPayload(testutil.WithPayloadProperties(map[string]string{"tokens": "1.5k"}))
+
+	_, err := Meter(payload, config)
+
+	assert.ErrorIs(t, err, ErrInvalidDecimal)
+	assert.ErrorContains(t, err, `property "tokens": "1.5k" is not numeric: magnitude suffixes are not supported`)
+}
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// newOutputIDStrategy validates an AggregateConfigSpec's OutputIDStrategy and
+// OutputID, returning the strategy with the "sha256" default applied.
+func newOutputIDStrategy(strategy, outputID string, groupBy []string) (string, error) {
+	switch strategy {
+	case "", "sha256", "ulid":
+		if outputID != "" {
+			return "", fmt.Errorf("output ID requires passthrough strategy")
+		}
+		if strategy == "" {
+			return "sha256", nil
+		}
+		return strategy, nil
+	case "passthrough":
+		if outputID == "" {
+			return "", fmt.Errorf("passthrough requires output ID")
+		}
+		if len(groupBy) > 0 {
+			return "", fmt.Errorf("passthrough cannot be used with group by")
+		}
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unknown strategy %q", strategy)
+	}
+}
+
+// applyIDStrategy returns the ID for a reading of subject, unit, and group-by
+// dimensions under the config's OutputIDStrategy.
+func applyIDStrategy(
+	config AggregationConfig,
+	subject MeterRecordSubject,
+	unit Unit,
+	dimensions MeterReadingDimensions,
+) (MeterReadingID, error) {
+	switch config.OutputIDStrategy() {
+	case "ulid":
+		return NewMeterReadingID(ulid.Make().String())
+	case "passthrough":
+		return NewMeterReadingID(config.OutputID())
+	default:
+		return computeMeterReadingID(subject, unit, config.Window(), config.Aggregation(), dimensions), nil
+	}
+}
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregate_OutputIDStrategy(t *testing.T) {
+	records := []specs.MeterRecordSpec{
+		newTestRecordSpec("event-1", "100", "tokens", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)),
+	}
+
+	t.Run("sha256 is idempotent for same inputs", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.OutputIDStrategy = "sha256"
+
+		first, err := Aggregate(records, nil, config)
+		require.NoError(t, err)
+		second, err := Aggregate(records, nil, config)
+		require.NoError(t, err)
+		unset, err := Aggregate(records, nil, newTestAggregateConfig("sum"))
+		require.NoError(t, err)
+
+		assert.Equal(t, first.ID, second.ID)
+		assert.Equal(t, first.ID, unset.ID, "sha256 is the default")
+	})
+
+	t.Run("ulid changes each call", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.OutputIDStrategy = "ulid"
+
+		first, err := Aggregate(records, nil, config)
+		require.NoError(t, err)
+		second, err := Aggregate(records, nil, config)
+		require.NoError(t, err)
+
+		assert.NotEqual(t, first.ID, second.ID)
+		assert.True(t, IsGeneratedID(first.ID))
+	})
+
+	t.Run("passthrough uses provided ID", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.OutputIDStrategy = "passthrough"
+		config.OutputID = "reading-2024-01"
+
+		reading, err := Aggregate(records, nil, config)
+
+		require.NoError(t, err)
+		assert.Equal(t, "reading-2024-01", reading.ID)
+	})
+
+	t.Run("passthrough with empty output ID returns error", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.OutputIDStrategy = "passthrough"
+
+		_, err := Aggregate(records, nil, config)
+
+		assert.ErrorContains(t, err, "passthrough requires output ID")
+	})
+
+	t.Run("rejects invalid combinations", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.OutputIDStrategy = "uuid"
+		_, err := NewAggregationConfig(config)
+		assert.ErrorContains(t, err, `unknown strategy "uuid"`)
+
+		config.OutputIDStrategy = ""
+		config.OutputID = "reading-2024-01"
+		_, err = NewAggregationConfig(config)
+		assert.ErrorContains(t, err, "output ID requires passthrough strategy")
+
+		config.OutputIDStrategy = "passthrough"
+		config.GroupBy = []string{"model"}
+		_, err = NewAggregationConfig(config)
+		assert.ErrorContains(t, err, "passthrough cannot be used with group by")
+	})
+}
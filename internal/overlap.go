@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// OverlapPair identifies two overlapping observations by their indexes.
+type OverlapPair struct {
+	Index1 int
+	Index2 int
+}
+
+// OverlapError describes two span observations for the same subject and unit
+// whose windows overlap. Such observations double-count time in
+// time-weighted-avg aggregation.
+type OverlapError struct {
+	Subject   string
+	Unit      string
+	RecordID1 string
+	RecordID2 string
+	Window1   specs.TimeWindowSpec
+	Window2   specs.TimeWindowSpec
+}
+
+func (e OverlapError) Error() string {
+	return fmt.Sprintf("overlapping %s observations for %s: record %s [%s, %s) overlaps record %s [%s, %s)",
+		e.Unit, e.Subject,
+		e.RecordID1, e.Window1.Start.Format(time.RFC3339), e.Window1.End.Format(time.RFC3339),
+		e.RecordID2, e.Window2.Start.Format(time.RFC3339), e.Window2.End.Format(time.RFC3339),
+	)
+}
+
+// ObservationsOverlap reports whether two observations share a unit and have
+// overlapping span windows.
+//
+// Windows are half-open, so spans that only touch at a boundary do not
+// overlap. Instant observations never overlap: they measure a single moment
+// rather than claiming a period of time.
+func ObservationsOverlap(a, b specs.ObservationSpec) bool {
+	if a.Unit != b.Unit {
+		return false
+	}
+	if !isSpan(a) || !isSpan(b) {
+		return false
+	}
+	return a.Window.Start.Before(b.Window.End) && b.Window.Start.Before(a.Window.End)
+}
+
+// FindOverlappingObservations returns every pair of overlapping observations,
+// ordered by first index then second index.
+func FindOverlappingObservations(observations []specs.ObservationSpec) []OverlapPair {
+	var pairs []OverlapPair
+	for i := 0; i < len(observations); i++ {
+		for j := i + 1; j < len(observations); j++ {
+			if ObservationsOverlap(observations[i], observations[j]) {
+				pairs = append(pairs, OverlapPair{Index1: i, Index2: j})
+			}
+		}
+	}
+	return pairs
+}
+
+// ValidateNoOverlap finds overlapping observations of the given unit across
+// records for the same subject. Run before time-weighted-avg aggregation,
+// which assumes each moment is covered by at most one observation.
+//
+// Returns nil if no observations overlap.
+func ValidateNoOverlap(records []specs.MeterRecordSpec, unit string) []OverlapError {
+	type located struct {
+		record      specs.MeterRecordSpec
+		observation specs.ObservationSpec
+	}
+
+	var candidates []located
+	var observations []specs.ObservationSpec
+	for _, record := range records {
+		for _, observation := range record.Observations {
+			if observation.Unit != unit {
+				continue
+			}
+			candidates = append(candidates, located{record: record, observation: observation})
+			observations = append(observations, observation)
+		}
+	}
+
+	var errs []OverlapError
+	for _, pair := range FindOverlappingObservations(observations) {
+		a, b := candidates[pair.Index1], candidates[pair.Index2]
+		if a.record.Subject != b.record.Subject {
+			continue
+		}
+		errs = append(errs, OverlapError{
+			Subject:   a.record.Subject,
+			Unit:      unit,
+			RecordID1: a.record.ID,
+			RecordID2: b.record.ID,
+			Window1:   a.observation.Window,
+			Window2:   b.observation.Window,
+		})
+	}
+	return errs
+}
+
+func isSpan(observation specs.ObservationSpec) bool {
+	return observation.Window.Start.Before(observation.Window.End)
+}
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindOverlappingObservations(t *testing.T) {
+	hour := func(h int) time.Time {
+		return time.Date(2024, 1, 15, h, 0, 0, 0, time.UTC)
+	}
+	span := func(start, end int) specs.ObservationSpec {
+		observation, err := specs.NewSpanObservation("1", "gpu-hours", hour(start), hour(end))
+		require.NoError(t, err)
+		return observation
+	}
+
+	t.Run("non-overlapping spans", func(t *testing.T) {
+		observations := []specs.ObservationSpec{span(0, 2), span(2, 4)}
+
+		assert.False(t, ObservationsOverlap(observations[0], observations[1]))
+		assert.Empty(t, FindOverlappingObservations(observations))
+	})
+
+	t.Run("overlapping spans", func(t *testing.T) {
+		observations := []specs.ObservationSpec{span(0, 3), span(2, 4)}
+
+		assert.True(t, ObservationsOverlap(observations[0], observations[1]))
+		assert.Equal(t, []OverlapPair{{Index1: 0, Index2: 1}}, FindOverlappingObservations(observations))
+	})
+
+	t.Run("only second and third overlap", func(t *testing.T) {
+		observations := []specs.ObservationSpec{span(0, 2), span(3, 6), span(5, 8)}
+
+		assert.Equal(t, []OverlapPair{{Index1: 1, Index2: 2}}, FindOverlappingObservations(observations))
+	})
+
+	t.Run("instant observations never overlap", func(t *testing.T) {
+		observations := []specs.ObservationSpec{
+			specs.NewInstantObservation("1", "gpu-hours", hour(1)),
+			specs.NewInstantObservation("2", "gpu-hours", hour(1)),
+			span(0, 2),
+		}
+
+		assert.Empty(t, FindOverlappingObservations(observations))
+	})
+
+	t.Run("different units do not overlap", func(t *testing.T) {
+		other, err := specs.NewSpanObservation("1", "cpu-hours", hour(0), hour(3))
+		require.NoError(t, err)
+
+		assert.False(t, ObservationsOverlap(span(0, 3), other))
+	})
+}
+
+func TestValidateNoOverlap(t *testing.T) {
+	hour := func(h int) time.Time {
+		return time.Date(2024, 1, 15, h, 0, 0, 0, time.UTC)
+	}
+	spanRecord := func(id, subject string, start, end int) specs.MeterRecordSpec {
+		observation, err := specs.NewSpanObservation("1", "gpu-hours", hour(start), hour(end))
+		require.NoError(t, err)
+		record := newTestRecordSpec(id, "1", "gpu-hours", hour(start))
+		record.Subject = subject
+		record.Observations = []specs.ObservationSpec{observation}
+		return record
+	}
+
+	t.Run("reports overlaps for the same subject", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			spanRecord("rec-1", "customer:a", 0, 3),
+			spanRecord("rec-2", "customer:a", 2, 4),
+			spanRecord("rec-3", "customer:b", 1, 2),
+		}
+
+		errs := ValidateNoOverlap(records, "gpu-hours")
+
+		require.Len(t, errs, 1)
+		assert.Equal(t, "customer:a", errs[0].Subject)
+		assert.Equal(t, "rec-1", errs[0].RecordID1)
+		assert.Equal(t, "rec-2", errs[0].RecordID2)
+		assert.Contains(t, errs[0].Error(), "overlapping gpu-hours observations")
+	})
+
+	t.Run("ignores other units", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			spanRecord("rec-1", "customer:a", 0, 3),
+			spanRecord("rec-2", "customer:a", 2, 4),
+		}
+
+		assert.Empty(t, ValidateNoOverlap(records, "cpu-hours"))
+	})
+}
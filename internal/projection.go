@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"fmt"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// projectedFields maps each field name ProjectRecord accepts to the record
+// value it selects. Names match the record's JSON keys.
+var projectedFields = map[string]func(specs.MeterRecordSpec) interface{}{
+	"id":          func(r specs.MeterRecordSpec) interface{} { return r.ID },
+	"subject":     func(r specs.MeterRecordSpec) interface{} { return r.Subject },
+	"workspaceID": func(r specs.MeterRecordSpec) interface{} { return r.WorkspaceID },
+	"observedAt":  func(r specs.MeterRecordSpec) interface{} { return r.ObservedAt },
+	"observations": func(r specs.MeterRecordSpec) interface{} {
+		return append([]specs.ObservationSpec(nil), r.Observations...)
+	},
+	"dimensions": func(r specs.MeterRecordSpec) interface{} {
+		dimensions := make(map[string]string, len(r.Dimensions))
+		for k, v := range r.Dimensions {
+			dimensions[k] = v
+		}
+		return dimensions
+	},
+}
+
+// ProjectRecord returns only the requested fields of record, keyed by name,
+// for handlers that need a subset such as subject and observations for
+// pricing. fields may contain "id", "subject", "workspaceID", "observations",
+// "dimensions", and "observedAt". Values have the record's field types (a
+// time.Time for "observedAt"); observations and dimensions are copies.
+//
+// Returns error if fields is empty or names an unknown field.
+func ProjectRecord(record specs.MeterRecordSpec, fields []string) (map[string]interface{}, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("at least one field is required")
+	}
+	projection := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		value, ok := projectedFields[field]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		projection[field] = value(record)
+	}
+	return projection, nil
+}
+
+// ProjectRecords projects each record as ProjectRecord does, in order.
+//
+// Returns error if fields is empty or names an unknown field.
+func ProjectRecords(records []specs.MeterRecordSpec, fields []string) ([]map[string]interface{}, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("at least one field is required")
+	}
+	for _, field := range fields {
+		if _, ok := projectedFields[field]; !ok {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+	}
+	projections := make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		projection, err := ProjectRecord(record, fields)
+		if err != nil {
+			return nil, err
+		}
+		projections = append(projections, projection)
+	}
+	return projections, nil
+}
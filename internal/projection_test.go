@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectRecord(t *testing.T) {
+	observedAt := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	record := newTestRecordSpec("event-1", "1250", "tokens", observedAt)
+	record.Dimensions = map[string]string{"model": "gpt-4"}
+
+	t.Run("all fields requested returns all", func(t *testing.T) {
+		projection, err := ProjectRecord(record,
+			[]string{"id", "subject", "workspaceID", "observations", "dimensions", "observedAt"})
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"id":           "event-1",
+			"subject":      record.Subject,
+			"workspaceID":  record.WorkspaceID,
+			"observations": record.Observations,
+			"dimensions":   map[string]string{"model": "gpt-4"},
+			"observedAt":   observedAt,
+		}, projection)
+	})
+
+	t.Run("subset of fields returns subset", func(t *testing.T) {
+		projection, err := ProjectRecord(record, []string{"subject", "observations"})
+
+		require.NoError(t, err)
+		assert.Len(t, projection, 2)
+		assert.Equal(t, record.Subject, projection["subject"])
+		assert.Equal(t, record.Observations, projection["observations"])
+	})
+
+	t.Run("dimensions are copied", func(t *testing.T) {
+		projection, err := ProjectRecord(record, []string{"dimensions"})
+		require.NoError(t, err)
+
+		projection["dimensions"].(map[string]string)["model"] = "gpt-4o"
+
+		assert.Equal(t, "gpt-4", record.Dimensions["model"])
+	})
+
+	t.Run("unknown field name returns error", func(t *testing.T) {
+		_, err := ProjectRecord(record, []string{"subject", "price"})
+
+		assert.EqualError(t, err, `unknown field "price"`)
+	})
+
+	t.Run("empty fields list returns error", func(t *testing.T) {
+		_, err := ProjectRecord(record, nil)
+
+		assert.EqualError(t, err, "at least one field is required")
+	})
+}
+
+func TestProjectRecords(t *testing.T) {
+	observedAt := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	records := []specs.MeterRecordSpec{
+		newTestRecordSpec("event-1", "100", "tokens", observedAt),
+		newTestRecordSpec("event-2", "200", "tokens", observedAt),
+	}
+
+	t.Run("projects each record in order", func(t *testing.T) {
+		projections, err := ProjectRecords(records, []string{"id"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]interface{}{{"id": "event-1"}, {"id": "event-2"}}, projections)
+	})
+
+	t.Run("unknown field returns error even without records", func(t *testing.T) {
+		_, err := ProjectRecords(nil, []string{"price"})
+
+		assert.EqualError(t, err, `unknown field "price"`)
+	})
+
+	t.Run("empty fields list returns error", func(t *testing.T) {
+		_, err := ProjectRecords(records, []string{})
+
+		assert.EqualError(t, err, "at least one field is required")
+	})
+}
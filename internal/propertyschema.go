@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+
+	specs "github.com/chrisconley/metron/specs"
+	"github.com/cockroachdb/apd/v3"
+)
+
+// PropertySchema is a validated set of declared event properties.
+type PropertySchema struct {
+	fields []specs.PropertyFieldSpec
+}
+
+// NewPropertySchema validates fields. Returns error if a field has no name,
+// two fields share a name, or a type is not "string", "decimal", "integer",
+// or "boolean".
+func NewPropertySchema(fields []specs.PropertyFieldSpec) (PropertySchema, error) {
+	seen := make(map[string]bool, len(fields))
+	for i, field := range fields {
+		if field.Name == "" {
+			return PropertySchema{}, fmt.Errorf("field %d: name is required", i)
+		}
+		if seen[field.Name] {
+			return PropertySchema{}, fmt.Errorf("field %q is declared more than once", field.Name)
+		}
+		seen[field.Name] = true
+		switch field.Type {
+		case "string", "decimal", "integer", "boolean":
+		default:
+			return PropertySchema{}, fmt.Errorf("field %q: invalid type %q", field.Name, field.Type)
+		}
+	}
+	return PropertySchema{fields: append([]specs.PropertyFieldSpec(nil), fields...)}, nil
+}
+
+// Fields returns the declared properties in declaration order.
+func (s PropertySchema) Fields() []specs.PropertyFieldSpec {
+	return s.fields
+}
+
+// SchemaError describes a property that does not match its declaration.
+type SchemaError struct {
+	Property string
+	Message  string
+}
+
+func (e SchemaError) Error() string {
+	return fmt.Sprintf("property %q: %s", e.Property, e.Message)
+}
+
+// ValidatePropertiesAgainstSchema checks properties against schema and
+// returns one error per missing required property or value that does not
+// parse as its declared type, in declaration order. Properties not in the
+// schema are ignored. Returns nil if properties match.
+func ValidatePropertiesAgainstSchema(properties map[string]string, schema PropertySchema) []SchemaError {
+	var errs []SchemaError
+	for _, field := range schema.fields {
+		if _, err := TypedValue(properties, field); err != nil {
+			errs = append(errs, SchemaError{Property: field.Name, Message: err.Error()})
+		}
+	}
+	return errs
+}
+
+// TypedValue returns field's value in properties parsed as its declared type:
+// a string, a Decimal, an int64, or a bool. Returns nil if the property is
+// absent and not required.
+//
+// Returns error if a required property is absent or the value does not parse.
+func TypedValue(properties map[string]string, field specs.PropertyFieldSpec) (interface{}, error) {
+	value, ok := properties[field.Name]
+	if !ok {
+		if field.Required {
+			return nil, fmt.Errorf("required property is missing")
+		}
+		return nil, nil
+	}
+
+	switch field.Type {
+	case "string":
+		return value, nil
+	case "decimal":
+		d, err := NewDecimal(value)
+		if err != nil || d.value.Form != apd.Finite {
+			return nil, fmt.Errorf("value %q is not a decimal", value)
+		}
+		return d, nil
+	case "integer":
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not an integer", value)
+		}
+		return i, nil
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not a boolean", value)
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("invalid type %q", field.Type)
+	}
+}
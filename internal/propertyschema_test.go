@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePropertiesAgainstSchema(t *testing.T) {
+	schema, err := NewPropertySchema([]specs.PropertyFieldSpec{
+		{Name: "tokens", Type: "decimal", Required: true},
+		{Name: "model", Type: "string", Required: true},
+		{Name: "retries", Type: "integer"},
+		{Name: "cached", Type: "boolean"},
+	})
+	require.NoError(t, err)
+
+	t.Run("valid properties pass", func(t *testing.T) {
+		properties := map[string]string{"tokens": "1250.5", "model": "gpt-4", "retries": "2", "cached": "true", "extra": "x"}
+
+		errs := ValidatePropertiesAgainstSchema(properties, schema)
+
+		assert.Empty(t, errs)
+	})
+
+	t.Run("missing required field returns error", func(t *testing.T) {
+		errs := ValidatePropertiesAgainstSchema(map[string]string{"tokens": "100"}, schema)
+
+		require.Len(t, errs, 1)
+		assert.Equal(t, "model", errs[0].Property)
+		assert.EqualError(t, errs[0], `property "model": required property is missing`)
+	})
+
+	t.Run("decimal property with non-numeric value returns error", func(t *testing.T) {
+		for _, value := range []string{"many", "NaN", "Infinity"} {
+			errs := ValidatePropertiesAgainstSchema(map[string]string{"tokens": value, "model": "gpt-4"}, schema)
+
+			require.Len(t, errs, 1, value)
+			assert.Equal(t, "tokens", errs[0].Property)
+			assert.Contains(t, errs[0].Message, "is not a decimal")
+		}
+	})
+
+	t.Run("every mismatch is reported", func(t *testing.T) {
+		errs := ValidatePropertiesAgainstSchema(map[string]string{"tokens": "100", "retries": "1.5", "cached": "maybe"}, schema)
+
+		require.Len(t, errs, 3)
+		assert.Equal(t, []string{"model", "retries", "cached"}, []string{errs[0].Property, errs[1].Property, errs[2].Property})
+	})
+}
+
+func TestTypedValue(t *testing.T) {
+	properties := map[string]string{"tokens": "1250.5", "retries": "3", "cached": "false", "model": "gpt-4"}
+
+	decimal, err := TypedValue(properties, specs.PropertyFieldSpec{Name: "tokens", Type: "decimal"})
+	require.NoError(t, err)
+	assert.Equal(t, "1250.5", decimal.(Decimal).String())
+
+	integer, err := TypedValue(properties, specs.PropertyFieldSpec{Name: "retries", Type: "integer"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), integer)
+
+	boolean, err := TypedValue(properties, specs.PropertyFieldSpec{Name: "cached", Type: "boolean"})
+	require.NoError(t, err)
+	assert.Equal(t, false, boolean)
+
+	str, err := TypedValue(properties, specs.PropertyFieldSpec{Name: "model", Type: "string"})
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-4", str)
+
+	absent, err := TypedValue(properties, specs.PropertyFieldSpec{Name: "region", Type: "string"})
+	require.NoError(t, err)
+	assert.Nil(t, absent)
+}
+
+func TestNewPropertySchema(t *testing.T) {
+	t.Run("rejects unknown type", func(t *testing.T) {
+		_, err := NewPropertySchema([]specs.PropertyFieldSpec{{Name: "tokens", Type: "float"}})
+
+		assert.ErrorContains(t, err, `field "tokens": invalid type "float"`)
+	})
+
+	t.Run("rejects duplicate names", func(t *testing.T) {
+		_, err := NewPropertySchema([]specs.PropertyFieldSpec{
+			{Name: "tokens", Type: "decimal"},
+			{Name: "tokens", Type: "integer"},
+		})
+
+		assert.ErrorContains(t, err, "declared more than once")
+	})
+}
+
+func TestMeter_PropertySchema(t *testing.T) {
+	payload := specs.EventPayloadSpec{
+		ID:          "event-123",
+		WorkspaceID: "workspace-test",
+		UniverseID:  "universe-test",
+		Type:        "api.completion",
+		Subject:     "customer:test",
+		Time:        time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC),
+		Properties:  map[string]string{"tokens": "1250", "model": "gpt-4"},
+	}
+	config := specs.MeteringConfigSpec{
+		Observations: []specs.ObservationExtractionSpec{{SourceProperty: "tokens", Unit: "tokens"}},
+		PropertySchema: &specs.PropertySchemaSpec{Fields: []specs.PropertyFieldSpec{
+			{Name: "tokens", Type: "integer", Required: true},
+			{Name: "model", Type: "string", Required: true},
+		}},
+	}
+
+	t.Run("valid properties are metered", func(t *testing.T) {
+		records, err := Meter(payload, config)
+
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "1250", records[0].Observations[0].Quantity)
+	})
+
+	t.Run("missing required property rejects event", func(t *testing.T) {
+		missing := payload
+		missing.Properties = map[string]string{"tokens": "1250"}
+
+		_, err := Meter(missing, config)
+
+		assert.ErrorContains(t, err, `properties do not match schema: property "model": required property is missing`)
+	})
+
+	t.Run("invalid schema fails config", func(t *testing.T) {
+		invalid := config
+		invalid.PropertySchema = &specs.PropertySchemaSpec{Fields: []specs.PropertyFieldSpec{{Name: "tokens", Type: "number"}}}
+
+		_, err := Meter(payload, invalid)
+
+		assert.ErrorContains(t, err, "invalid property schema")
+	})
+}
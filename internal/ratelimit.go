@@ -0,0 +1,77 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	specs "github.com/chrisconley/metron/specs"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// ErrRateLimitExceeded is returned by RateLimitedMeter when the payload's
+// workspace has exhausted its ingest allowance.
+var ErrRateLimitExceeded = errors.New("ingest rate limit exceeded")
+
+// IngestRateLimiter decides whether a workspace may ingest another event.
+//
+// Limits are tracked per workspace so a single misbehaving client cannot
+// starve other workspaces sharing the metering pipeline.
+type IngestRateLimiter interface {
+	// Allow consumes one unit of the workspace's allowance, returning false if none remains.
+	Allow(workspaceID string) bool
+	// Remaining returns how many events the workspace may ingest right now.
+	Remaining(workspaceID string) int
+}
+
+// RateLimitedMeter checks the limiter for the payload's workspace before metering.
+// Returns an error wrapping ErrRateLimitExceeded when the limiter denies the payload.
+func RateLimitedMeter(
+	limiter IngestRateLimiter,
+	payload specs.EventPayloadSpec,
+	config specs.MeteringConfigSpec,
+) ([]specs.MeterRecordSpec, error) {
+	if !limiter.Allow(payload.WorkspaceID) {
+		return nil, fmt.Errorf("workspace %q: %w", payload.WorkspaceID, ErrRateLimitExceeded)
+	}
+	return Meter(payload, config)
+}
+
+type tokenBucketRateLimiter struct {
+	mu       sync.Mutex
+	limit    rate.Limit
+	burst    int
+	limiters map[string]*rate.Limiter
+}
+
+// NewTokenBucketRateLimiter returns an IngestRateLimiter that gives each
+// workspace its own token bucket refilling at ratePerSecond, holding at most
+// burst tokens. Buckets start full.
+func NewTokenBucketRateLimiter(ratePerSecond int, burst int) IngestRateLimiter {
+	return &tokenBucketRateLimiter{
+		limit:    rate.Limit(ratePerSecond),
+		burst:    burst,
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+func (l *tokenBucketRateLimiter) Allow(workspaceID string) bool {
+	return l.limiterFor(workspaceID).Allow()
+}
+
+func (l *tokenBucketRateLimiter) Remaining(workspaceID string) int {
+	return int(l.limiterFor(workspaceID).Tokens())
+}
+
+// limiterFor returns the workspace's bucket, creating it on first use.
+func (l *tokenBucketRateLimiter) limiterFor(workspaceID string) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limiter, ok := l.limiters[workspaceID]
+	if !ok {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		l.limiters[workspaceID] = limiter
+	}
+	return limiter
+}
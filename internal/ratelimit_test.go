@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/internal/testutil"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRateLimitedPayload(workspaceID string) specs.EventPayloadSpec {
+	return testutil.FixtureEventPayload(
+		testutil.WithPayloadWorkspaceID(workspaceID),
+		testutil.WithPayloadProperties(map[string]string{"requests": "1"}),
+	)
+}
+
+func TestRateLimitedMeter(t *testing.T) {
+	config := specs.MeteringConfigSpec{
+		Observations: []specs.ObservationExtractionSpec{
+			{SourceProperty: "requests", Unit: "api-calls"},
+		},
+	}
+
+	t.Run("first N requests succeed and N+1th fails", func(t *testing.T) {
+		// A rate of 1/s cannot refill a token within the test
+		limiter := NewTokenBucketRateLimiter(1, 3)
+
+		for i := 0; i < 3; i++ {
+			records, err := RateLimitedMeter(limiter, newTestRateLimitedPayload("workspace-a"), config)
+			require.NoError(t, err, "request %d should be allowed", i+1)
+			assert.Len(t, records, 1)
+		}
+
+		records, err := RateLimitedMeter(limiter, newTestRateLimitedPayload("workspace-a"), config)
+
+		require.ErrorIs(t, err, ErrRateLimitExceeded)
+		assert.Nil(t, records)
+	})
+
+	t.Run("workspaces have independent limits", func(t *testing.T) {
+		limiter := NewTokenBucketRateLimiter(1, 1)
+
+		_, err := RateLimitedMeter(limiter, newTestRateLimitedPayload("workspace-a"), config)
+		require.NoError(t, err)
+		_, err = RateLimitedMeter(limiter, newTestRateLimitedPayload("workspace-a"), config)
+		require.ErrorIs(t, err, ErrRateLimitExceeded)
+
+		_, err = RateLimitedMeter(limiter, newTestRateLimitedPayload("workspace-b"), config)
+		require.NoError(t, err)
+	})
+}
+
+func TestTokenBucketRateLimiter_Remaining(t *testing.T) {
+	t.Run("decrements as requests are allowed", func(t *testing.T) {
+		limiter := NewTokenBucketRateLimiter(1, 5)
+
+		assert.Equal(t, 5, limiter.Remaining("workspace-a"))
+		require.True(t, limiter.Allow("workspace-a"))
+		assert.Equal(t, 4, limiter.Remaining("workspace-a"))
+		require.True(t, limiter.Allow("workspace-a"))
+		assert.Equal(t, 3, limiter.Remaining("workspace-a"))
+		assert.Equal(t, 5, limiter.Remaining("workspace-b"))
+	})
+}
@@ -0,0 +1,38 @@
+package internal
+
+import (
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// FilterReadingsByUnit returns the readings of unit, preserving order.
+//
+// A reading's unit is its Unit field, or the unit of its first computed value
+// when Unit is empty (readings stored before Unit was populated).
+func FilterReadingsByUnit(readings []specs.MeterReadingSpec, unit string) []specs.MeterReadingSpec {
+	result := make([]specs.MeterReadingSpec, 0, len(readings))
+	for _, reading := range readings {
+		if readingUnit(reading) == unit {
+			result = append(result, reading)
+		}
+	}
+	return result
+}
+
+// GroupReadingsByUnit buckets readings by unit, as FilterReadingsByUnit
+// determines it. Readings within a bucket keep their input order. Readings
+// with no unit are keyed by the empty string.
+func GroupReadingsByUnit(readings []specs.MeterReadingSpec) map[string][]specs.MeterReadingSpec {
+	groups := make(map[string][]specs.MeterReadingSpec)
+	for _, reading := range readings {
+		unit := readingUnit(reading)
+		groups[unit] = append(groups[unit], reading)
+	}
+	return groups
+}
+
+func readingUnit(reading specs.MeterReadingSpec) string {
+	if reading.Unit != "" || len(reading.ComputedValues) == 0 {
+		return reading.Unit
+	}
+	return reading.ComputedValues[0].Unit
+}
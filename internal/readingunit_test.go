@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/internal/testutil"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeterReading_Unit(t *testing.T) {
+	t.Run("aggregate populates unit from computed value", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newTestRecordSpec("rec-1", "100", "tokens", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+		}
+
+		reading, err := Aggregate(records, nil, newTestAggregateConfig("sum"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "tokens", reading.Unit)
+		assert.Equal(t, reading.ComputedValues[0].Unit, reading.Unit)
+	})
+
+	t.Run("NewMeterReading populates unit from computed value", func(t *testing.T) {
+		spec := testutil.FixtureMeterReading()
+		spec.Unit = ""
+
+		reading, err := NewMeterReading(spec)
+
+		require.NoError(t, err)
+		assert.Equal(t, "tokens", reading.Unit.ToString())
+	})
+
+	t.Run("NewMeterReading rejects unit not matching computed value", func(t *testing.T) {
+		spec := testutil.FixtureMeterReading()
+		spec.Unit = "requests"
+
+		_, err := NewMeterReading(spec)
+
+		assert.ErrorContains(t, err, `unit "requests" does not match computed value unit "tokens"`)
+	})
+}
+
+func TestFilterReadingsByUnit(t *testing.T) {
+	tokens := testutil.FixtureMeterReading(testutil.WithReadingID("tokens"))
+	requests := testutil.FixtureMeterReading(testutil.WithReadingID("requests"), testutil.WithReadingValue("5", "requests"))
+	legacy := testutil.FixtureMeterReading(testutil.WithReadingID("legacy"))
+	legacy.Unit = ""
+	readings := []specs.MeterReadingSpec{tokens, requests, legacy}
+
+	t.Run("returns readings of unit in order", func(t *testing.T) {
+		filtered := FilterReadingsByUnit(readings, "tokens")
+
+		assert.Equal(t, []specs.MeterReadingSpec{tokens, legacy}, filtered)
+	})
+
+	t.Run("no matches returns empty", func(t *testing.T) {
+		assert.Empty(t, FilterReadingsByUnit(readings, "seats"))
+	})
+}
+
+func TestGroupReadingsByUnit(t *testing.T) {
+	first := testutil.FixtureMeterReading(testutil.WithReadingID("first"))
+	second := testutil.FixtureMeterReading(testutil.WithReadingID("second"), testutil.WithReadingValue("5", "requests"))
+	third := testutil.FixtureMeterReading(testutil.WithReadingID("third"))
+
+	groups := GroupReadingsByUnit([]specs.MeterReadingSpec{first, second, third})
+
+	assert.Equal(t, map[string][]specs.MeterReadingSpec{
+		"tokens":   {first, third},
+		"requests": {second},
+	}, groups)
+}
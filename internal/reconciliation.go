@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// ReconciliationResult compares a stored reading with the reading recomputed
+// from its raw records, for billing audits. Quantities are decimal strings.
+type ReconciliationResult struct {
+	ReadingID string
+
+	// True when the recomputed quantity equals the stored one and both were
+	// built from the same number of records.
+	Matches bool
+
+	StoredValue   string
+	ComputedValue string
+
+	// ComputedValue minus StoredValue.
+	Discrepancy string
+
+	// Discrepancy as a percentage of StoredValue (e.g., "2.5" for 2.5%).
+	// Empty when StoredValue is zero and ComputedValue is not, since the
+	// percentage is then undefined.
+	DiscrepancyPct string
+
+	// Recomputed record count minus stored record count.
+	RecordCountDiscrepancy int
+}
+
+// ReconcileReading recomputes stored from records and last, as Aggregate
+// would with config, and reports how the two differ.
+//
+// A discrepancy is reported in the result, not as an error. Returns error if
+// stored does not have exactly one computed value, its quantity is not a
+// valid decimal, or aggregation fails. A reading below the config's
+// MinRecordCount is still reconciled.
+func ReconcileReading(
+	stored specs.MeterReadingSpec,
+	records []specs.MeterRecordSpec,
+	last *specs.MeterRecordSpec,
+	config specs.AggregateConfigSpec,
+) (ReconciliationResult, error) {
+	if len(stored.ComputedValues) != 1 {
+		return ReconciliationResult{}, fmt.Errorf("stored reading must have one computed value, got %d", len(stored.ComputedValues))
+	}
+	storedQuantity, err := NewDecimal(stored.ComputedValues[0].Quantity)
+	if err != nil {
+		return ReconciliationResult{}, fmt.Errorf("invalid stored quantity: %w", err)
+	}
+
+	computed, err := Aggregate(records, last, config)
+	if err != nil && !IsInsufficientDataError(err) {
+		return ReconciliationResult{}, fmt.Errorf("failed to recompute reading: %w", err)
+	}
+	computedQuantity, err := NewDecimal(computed.ComputedValues[0].Quantity)
+	if err != nil {
+		return ReconciliationResult{}, fmt.Errorf("invalid computed quantity: %w", err)
+	}
+
+	discrepancy := computedQuantity.Sub(storedQuantity)
+
+	var percentage string
+	switch {
+	case !storedQuantity.IsZero():
+		percentage = discrepancy.Mul(NewDecimalFromInt64(100)).Div(storedQuantity).Normalize().String()
+	case discrepancy.IsZero():
+		percentage = "0"
+	}
+
+	recordCountDiscrepancy := computed.RecordCount - stored.RecordCount
+
+	return ReconciliationResult{
+		ReadingID:              stored.ID,
+		Matches:                discrepancy.IsZero() && recordCountDiscrepancy == 0,
+		StoredValue:            stored.ComputedValues[0].Quantity,
+		ComputedValue:          computed.ComputedValues[0].Quantity,
+		Discrepancy:            discrepancy.String(),
+		DiscrepancyPct:         percentage,
+		RecordCountDiscrepancy: recordCountDiscrepancy,
+	}, nil
+}
+
+// ReconcileReadings reconciles each stored reading against the records
+// fetcher returns for its subject, unit, and window, as AggregateFromStore
+// would fetch them. config supplies every setting except the window, which is
+// taken from each stored reading. Results are in the order of stored.
+//
+// Returns error naming the reading if fetching or reconciling any reading
+// fails.
+func ReconcileReadings(
+	stored []specs.MeterReadingSpec,
+	fetcher RecordFetcher,
+	config specs.AggregateConfigSpec,
+) ([]ReconciliationResult, error) {
+	results := make([]ReconciliationResult, 0, len(stored))
+	for _, reading := range stored {
+		if len(reading.ComputedValues) != 1 {
+			return nil, fmt.Errorf("reading %s: stored reading must have one computed value, got %d", reading.ID, len(reading.ComputedValues))
+		}
+		readingConfig := config
+		readingConfig.Window = reading.Window
+
+		records, last, err := fetchForAggregation(context.Background(), fetcher,
+			reading.Subject, reading.ComputedValues[0].Unit, readingConfig)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", reading.ID, err)
+		}
+		result, err := ReconcileReading(reading, records, last, readingConfig)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", reading.ID, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileReading(t *testing.T) {
+	records := []specs.MeterRecordSpec{
+		newTestRecordSpec("rec-1", "100", "tokens", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+		newTestRecordSpec("rec-2", "300", "tokens", time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)),
+	}
+	config := newTestAggregateConfig("sum")
+	stored, err := Aggregate(records, nil, config)
+	require.NoError(t, err)
+
+	t.Run("matching reading", func(t *testing.T) {
+		result, err := ReconcileReading(stored, records, nil, config)
+
+		require.NoError(t, err)
+		assert.Equal(t, ReconciliationResult{
+			ReadingID:              stored.ID,
+			Matches:                true,
+			StoredValue:            "400",
+			ComputedValue:          "400",
+			Discrepancy:            "0",
+			DiscrepancyPct:         "0",
+			RecordCountDiscrepancy: 0,
+		}, result)
+	})
+
+	t.Run("quantity discrepancy", func(t *testing.T) {
+		tampered := stored
+		tampered.ComputedValues = []specs.ComputedValueSpec{{Quantity: "500", Unit: "tokens", Aggregation: "sum"}}
+
+		result, err := ReconcileReading(tampered, records, nil, config)
+
+		require.NoError(t, err)
+		assert.False(t, result.Matches)
+		assert.Equal(t, "500", result.StoredValue)
+		assert.Equal(t, "400", result.ComputedValue)
+		assert.Equal(t, "-100", result.Discrepancy)
+		assert.Equal(t, "-20", result.DiscrepancyPct)
+		assert.Zero(t, result.RecordCountDiscrepancy)
+	})
+
+	t.Run("record count discrepancy", func(t *testing.T) {
+		late := newTestRecordSpec("rec-late", "0", "tokens", time.Date(2024, 1, 25, 0, 0, 0, 0, time.UTC))
+
+		result, err := ReconcileReading(stored, append(records, late), nil, config)
+
+		require.NoError(t, err)
+		assert.False(t, result.Matches)
+		assert.Equal(t, "0", result.Discrepancy)
+		assert.Equal(t, 1, result.RecordCountDiscrepancy)
+	})
+
+	t.Run("zero stored value has no percentage", func(t *testing.T) {
+		zero := stored
+		zero.ComputedValues = []specs.ComputedValueSpec{{Quantity: "0", Unit: "tokens", Aggregation: "sum"}}
+
+		result, err := ReconcileReading(zero, records, nil, config)
+
+		require.NoError(t, err)
+		assert.Equal(t, "400", result.Discrepancy)
+		assert.Empty(t, result.DiscrepancyPct)
+	})
+
+	t.Run("aggregation failure is an error", func(t *testing.T) {
+		_, err := ReconcileReading(stored, records, nil, newTestAggregateConfig("median-ish"))
+
+		assert.ErrorContains(t, err, "failed to recompute reading")
+	})
+}
+
+func TestReconcileReadings(t *testing.T) {
+	january := newTestAggregateConfig("sum")
+	february := january
+	february.Window = specs.TimeWindowSpec{
+		Start: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	janRecords := []specs.MeterRecordSpec{
+		newTestRecordSpec("jan-1", "100", "tokens", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+	}
+	febRecords := []specs.MeterRecordSpec{
+		newTestRecordSpec("feb-1", "200", "tokens", time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC)),
+	}
+	janReading, err := Aggregate(janRecords, nil, january)
+	require.NoError(t, err)
+	febReading, err := Aggregate(febRecords, nil, february)
+	require.NoError(t, err)
+	febReading.ComputedValues = []specs.ComputedValueSpec{{Quantity: "250", Unit: "tokens", Aggregation: "sum"}}
+	fetcher := SliceRecordFetcher(append(janRecords, febRecords...))
+
+	t.Run("reconciles each reading over its own window", func(t *testing.T) {
+		results, err := ReconcileReadings([]specs.MeterReadingSpec{janReading, febReading}, fetcher, january)
+
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.True(t, results[0].Matches)
+		assert.Equal(t, janReading.ID, results[0].ReadingID)
+		assert.False(t, results[1].Matches)
+		assert.Equal(t, "200", results[1].ComputedValue)
+		assert.Equal(t, "-50", results[1].Discrepancy)
+	})
+
+	t.Run("fetch error names the reading", func(t *testing.T) {
+		failing := &recordingFetcher{RecordFetcher: fetcher, err: assert.AnError}
+
+		_, err := ReconcileReadings([]specs.MeterReadingSpec{janReading}, failing, january)
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.ErrorContains(t, err, "reading "+janReading.ID)
+	})
+}
@@ -0,0 +1,152 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// RecordFetcher loads the records for one subject and unit from storage, such
+// as a database queried with "observed_at >= start AND observed_at < end".
+type RecordFetcher interface {
+	// FetchInWindow returns the subject's records with an observation of
+	// unit and ObservedAt in [window.Start, window.End).
+	FetchInWindow(ctx context.Context, subject, unit string, window specs.TimeWindowSpec) ([]specs.MeterRecordSpec, error)
+	// FetchLastBefore returns the subject's latest record with an
+	// observation of unit and ObservedAt before before, or nil if none.
+	FetchLastBefore(ctx context.Context, subject, unit string, before time.Time) (*specs.MeterRecordSpec, error)
+}
+
+// AggregateFromStore aggregates subject's records of unit fetched from
+// fetcher, producing the reading Aggregate would for the same records.
+//
+// The fetched window is widened by the config's ClockSkewTolerance so
+// tolerated records are included, and the last record before the window is
+// fetched only for aggregations that use it. Fetched records are narrowed to
+// their observations of unit, after applying the config's UnitAliases, so
+// records bundling several units aggregate as unit alone. Returns error if the
+// config is invalid, fetching fails, or aggregation fails.
+func AggregateFromStore(
+	ctx context.Context,
+	fetcher RecordFetcher,
+	subject, unit string,
+	configSpec specs.AggregateConfigSpec,
+) (specs.MeterReadingSpec, error) {
+	records, lastBefore, err := fetchForAggregation(ctx, fetcher, subject, unit, configSpec)
+	if err != nil {
+		return specs.MeterReadingSpec{}, err
+	}
+	return Aggregate(records, lastBefore, configSpec)
+}
+
+// fetchForAggregation fetches and narrows the records AggregateFromStore
+// aggregates: subject's records of unit in the config's window, and the last
+// record before it when the aggregation uses one.
+func fetchForAggregation(
+	ctx context.Context,
+	fetcher RecordFetcher,
+	subject, unit string,
+	configSpec specs.AggregateConfigSpec,
+) ([]specs.MeterRecordSpec, *specs.MeterRecordSpec, error) {
+	config, err := NewAggregationConfig(configSpec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	tolerance := config.ClockSkewTolerance()
+	fetchWindow := specs.TimeWindowSpec{
+		Start: configSpec.Window.Start.Add(-tolerance),
+		End:   configSpec.Window.End.Add(tolerance),
+	}
+	fetched, err := fetcher.FetchInWindow(ctx, subject, unit, fetchWindow)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch records: %w", err)
+	}
+	records := make([]specs.MeterRecordSpec, 0, len(fetched))
+	for _, record := range fetched {
+		if narrowed, ok := recordForUnit(record, unit, config.UnitAliases()); ok {
+			records = append(records, narrowed)
+		}
+	}
+
+	var lastBefore *specs.MeterRecordSpec
+	if config.Aggregation().RequiresLastBeforeWindow() {
+		last, err := fetcher.FetchLastBefore(ctx, subject, unit, fetchWindow.Start)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to fetch last record before window: %w", err)
+		}
+		if last != nil {
+			if narrowed, ok := recordForUnit(*last, unit, config.UnitAliases()); ok {
+				lastBefore = &narrowed
+			}
+		}
+	}
+	return records, lastBefore, nil
+}
+
+// recordForUnit returns record with only its observations of unit, after
+// resolving aliases, and false if it has none.
+func recordForUnit(record specs.MeterRecordSpec, unit string, aliases map[string]string) (specs.MeterRecordSpec, bool) {
+	record = normalizeObservationUnits(record, aliases)
+	observations := make([]specs.ObservationSpec, 0, len(record.Observations))
+	for _, observation := range record.Observations {
+		if observation.Unit == unit {
+			observations = append(observations, observation)
+		}
+	}
+	record.Observations = observations
+	return record, len(observations) > 0
+}
+
+type sliceRecordFetcher struct {
+	records []specs.MeterRecordSpec
+}
+
+// SliceRecordFetcher returns a RecordFetcher over an in-memory slice, for
+// tests and small datasets. Records are returned in slice order.
+func SliceRecordFetcher(records []specs.MeterRecordSpec) RecordFetcher {
+	return &sliceRecordFetcher{records: records}
+}
+
+func (f *sliceRecordFetcher) FetchInWindow(ctx context.Context, subject, unit string, window specs.TimeWindowSpec) ([]specs.MeterRecordSpec, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var records []specs.MeterRecordSpec
+	for _, record := range f.records {
+		if f.matches(record, subject, unit) && !record.ObservedAt.Before(window.Start) && record.ObservedAt.Before(window.End) {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+func (f *sliceRecordFetcher) FetchLastBefore(ctx context.Context, subject, unit string, before time.Time) (*specs.MeterRecordSpec, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var last *specs.MeterRecordSpec
+	for i, record := range f.records {
+		if !f.matches(record, subject, unit) || !record.ObservedAt.Before(before) {
+			continue
+		}
+		if last == nil || record.ObservedAt.After(last.ObservedAt) {
+			last = &f.records[i]
+		}
+	}
+	if last == nil {
+		return nil, nil
+	}
+	found := *last
+	return &found, nil
+}
+
+func (f *sliceRecordFetcher) matches(record specs.MeterRecordSpec, subject, unit string) bool {
+	if record.Subject != subject {
+		return false
+	}
+	_, ok := record.ObservationByUnit(unit)
+	return ok
+}
@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingFetcher delegates to a slice fetcher and records each call.
+type recordingFetcher struct {
+	RecordFetcher
+	windows []specs.TimeWindowSpec
+	befores []time.Time
+	err     error
+}
+
+func (f *recordingFetcher) FetchInWindow(ctx context.Context, subject, unit string, window specs.TimeWindowSpec) ([]specs.MeterRecordSpec, error) {
+	f.windows = append(f.windows, window)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.RecordFetcher.FetchInWindow(ctx, subject, unit, window)
+}
+
+func (f *recordingFetcher) FetchLastBefore(ctx context.Context, subject, unit string, before time.Time) (*specs.MeterRecordSpec, error) {
+	f.befores = append(f.befores, before)
+	return f.RecordFetcher.FetchLastBefore(ctx, subject, unit, before)
+}
+
+func TestSliceRecordFetcher(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	other := newTestRecordSpec("other-subject", "5", "tokens", day(10))
+	other.Subject = "customer:other"
+	fetcher := SliceRecordFetcher([]specs.MeterRecordSpec{
+		newTestRecordSpec("before", "1", "tokens", day(1)),
+		newTestRecordSpec("in-window", "2", "tokens", day(10)),
+		newTestRecordSpec("other-unit", "3", "requests", day(10)),
+		newTestRecordSpec("at-end", "4", "tokens", day(20)),
+		other,
+	})
+	window := specs.TimeWindowSpec{Start: day(5), End: day(20)}
+
+	t.Run("fetches subject's records of unit within window", func(t *testing.T) {
+		records, err := fetcher.FetchInWindow(context.Background(), "customer:test", "tokens", window)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"in-window"}, recordIDs(records))
+	})
+
+	t.Run("fetches latest record before time", func(t *testing.T) {
+		last, err := fetcher.FetchLastBefore(context.Background(), "customer:test", "tokens", day(5))
+
+		require.NoError(t, err)
+		require.NotNil(t, last)
+		assert.Equal(t, "before", last.ID)
+	})
+
+	t.Run("no record before time returns nil", func(t *testing.T) {
+		last, err := fetcher.FetchLastBefore(context.Background(), "customer:test", "tokens", day(1))
+
+		require.NoError(t, err)
+		assert.Nil(t, last)
+	})
+
+	t.Run("cancelled context returns error", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := fetcher.FetchInWindow(ctx, "customer:test", "tokens", window)
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestAggregateFromStore(t *testing.T) {
+	records := []specs.MeterRecordSpec{
+		newTestRecordSpec("dec", "10", "seats", time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)),
+		newTestRecordSpec("jan-10", "100", "seats", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+		newTestRecordSpec("jan-20", "50", "seats", time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)),
+	}
+
+	t.Run("aggregates fetched records like Aggregate", func(t *testing.T) {
+		fetcher := &recordingFetcher{RecordFetcher: SliceRecordFetcher(records)}
+		config := newTestAggregateConfig("sum")
+
+		reading, err := AggregateFromStore(context.Background(), fetcher, "customer:test", "seats", config)
+		expected, expectedErr := Aggregate(records[1:], nil, config)
+
+		require.NoError(t, err)
+		require.NoError(t, expectedErr)
+		assert.Equal(t, expected.ComputedValues, reading.ComputedValues)
+		assert.Equal(t, expected.ID, reading.ID)
+		assert.Equal(t, []specs.TimeWindowSpec{config.Window}, fetcher.windows)
+		assert.Empty(t, fetcher.befores, "sum does not need the last record before the window")
+	})
+
+	t.Run("fetches last record before window when aggregation needs it", func(t *testing.T) {
+		fetcher := &recordingFetcher{RecordFetcher: SliceRecordFetcher(records)}
+		config := newTestAggregateConfig("time-weighted-avg")
+
+		reading, err := AggregateFromStore(context.Background(), fetcher, "customer:test", "seats", config)
+		expected, expectedErr := Aggregate(records[1:], &records[0], config)
+
+		require.NoError(t, err)
+		require.NoError(t, expectedErr)
+		assert.Equal(t, []time.Time{config.Window.Start}, fetcher.befores)
+		assert.Equal(t, expected.ComputedValues, reading.ComputedValues)
+	})
+
+	t.Run("widens fetched window by clock skew tolerance", func(t *testing.T) {
+		fetcher := &recordingFetcher{RecordFetcher: SliceRecordFetcher(records)}
+		config := newTestAggregateConfig("sum")
+		config.ClockSkewTolerance = time.Hour
+
+		_, err := AggregateFromStore(context.Background(), fetcher, "customer:test", "seats", config)
+
+		require.NoError(t, err)
+		assert.Equal(t, config.Window.Start.Add(-time.Hour), fetcher.windows[0].Start)
+		assert.Equal(t, config.Window.End.Add(time.Hour), fetcher.windows[0].End)
+	})
+
+	t.Run("narrows bundled records to the requested unit", func(t *testing.T) {
+		bundled := newTestRecordSpec("bundled", "100", "input-tokens", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC))
+		bundled.Observations = append(bundled.Observations,
+			specs.NewInstantObservation("40", "output-tokens", bundled.ObservedAt))
+
+		reading, err := AggregateFromStore(context.Background(), SliceRecordFetcher([]specs.MeterRecordSpec{bundled}),
+			"customer:test", "output-tokens", newTestAggregateConfig("sum"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "40", reading.ComputedValues[0].Quantity)
+		assert.Equal(t, "output-tokens", reading.ComputedValues[0].Unit)
+	})
+
+	t.Run("fetch error is returned", func(t *testing.T) {
+		fetcher := &recordingFetcher{RecordFetcher: SliceRecordFetcher(records), err: fmt.Errorf("connection refused")}
+
+		_, err := AggregateFromStore(context.Background(), fetcher, "customer:test", "seats", newTestAggregateConfig("sum"))
+
+		assert.ErrorContains(t, err, "failed to fetch records: connection refused")
+	})
+}
@@ -0,0 +1,273 @@
+package internal
+
+import (
+	"fmt"
+	specs "github.com/chrisconley/metron/specs"
+	"time"
+)
+
+// RecordReader yields meter records one at a time, typically from storage.
+//
+// Next returns false when there are no more records or reading failed; Err
+// distinguishes the two, returning nil at a clean end of input.
+type RecordReader interface {
+	Next() (specs.MeterRecordSpec, bool)
+	Err() error
+}
+
+type sliceRecordReader struct {
+	records []specs.MeterRecordSpec
+	pos     int
+}
+
+// SliceRecordReader returns a RecordReader over an in-memory slice.
+func SliceRecordReader(records []specs.MeterRecordSpec) RecordReader {
+	return &sliceRecordReader{records: records}
+}
+
+func (r *sliceRecordReader) Next() (specs.MeterRecordSpec, bool) {
+	if r.pos >= len(r.records) {
+		return specs.MeterRecordSpec{}, false
+	}
+	record := r.records[r.pos]
+	r.pos++
+	return record, true
+}
+
+func (r *sliceRecordReader) Err() error {
+	return nil
+}
+
+// AggregateFromReader aggregates records read one at a time, producing the
+// same reading Aggregate would for the same records.
+//
+// sum, max, and min fold each record into a running value, so memory stays
+// constant however many records the reader yields (except that
+// IncludeSourceIDs retains each record's ID). Other aggregations need
+// every record at once (time-weighted-avg sorts by time), so their records are
+// buffered in memory; use them only with windows that fit in memory.
+//
+// GroupBy is not supported; use AggregateGrouped. Returns error if the reader
+// fails, a record is invalid, or the config is invalid. Like Aggregate, a
+// reading below MinRecordCount is returned with an *InsufficientDataError.
+func AggregateFromReader(
+	reader RecordReader,
+	lastBeforeWindowSpec *specs.MeterRecordSpec,
+	configSpec specs.AggregateConfigSpec,
+) (specs.MeterReadingSpec, error) {
+	config, err := NewAggregationConfig(configSpec)
+	if err != nil {
+		return specs.MeterReadingSpec{}, fmt.Errorf("invalid config: %w", err)
+	}
+	if len(config.GroupBy()) > 0 {
+		return specs.MeterReadingSpec{}, fmt.Errorf("group by is not supported when reading records; use AggregateGrouped")
+	}
+
+	aggregation := config.Aggregation()
+	if !aggregation.SupportsConcurrentEvaluation() {
+		var records []specs.MeterRecordSpec
+		for {
+			record, ok := reader.Next()
+			if !ok {
+				break
+			}
+			records = append(records, record)
+		}
+		if err := reader.Err(); err != nil {
+			return specs.MeterReadingSpec{}, fmt.Errorf("failed to read records: %w", err)
+		}
+		return Aggregate(records, lastBeforeWindowSpec, configSpec)
+	}
+
+	acc := newRecordAccumulator(config)
+	if err := acc.addLastBefore(lastBeforeWindowSpec); err != nil {
+		return specs.MeterReadingSpec{}, err
+	}
+
+	index := 0
+	for {
+		recordSpec, ok := reader.Next()
+		if !ok {
+			break
+		}
+		if index, err = acc.addSpec(recordSpec, config, index); err != nil {
+			return specs.MeterReadingSpec{}, err
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return specs.MeterReadingSpec{}, fmt.Errorf("failed to read records: %w", err)
+	}
+
+	return acc.reading(config)
+}
+
+// recordAccumulator folds records into a running sum, max, or min along with
+// the metadata a reading needs, without retaining the records.
+type recordAccumulator struct {
+	aggregation  MeterReadingAggregation
+	first        MeterRecord
+	quantity     Decimal
+	unit         Unit
+	count        int
+	events       Decimal
+	sampled      bool
+	maxMeteredAt time.Time
+	includeIDs   bool
+	ids          []string // Only collected when includeIDs is set
+	warnings     []OutOfWindowWarning
+}
+
+func newRecordAccumulator(config AggregationConfig) *recordAccumulator {
+	return &recordAccumulator{aggregation: config.Aggregation(), includeIDs: config.IncludeSourceIDs()}
+}
+
+// addLastBefore counts lastBeforeWindowSpec, if any, toward the reading's
+// MaxMeteredAt watermark. It does not contribute to sum, max, or min.
+func (a *recordAccumulator) addLastBefore(lastBeforeWindowSpec *specs.MeterRecordSpec) error {
+	if lastBeforeWindowSpec == nil {
+		return nil
+	}
+	unbundledLast := unbundleObservations([]specs.MeterRecordSpec{*lastBeforeWindowSpec})
+	if len(unbundledLast) == 0 {
+		return nil
+	}
+	record, err := NewMeterRecord(unbundledLast[0])
+	if err != nil {
+		return fmt.Errorf("invalid lastBeforeWindow: %w", err)
+	}
+	if record.MeteredAt.ToTime().After(a.maxMeteredAt) {
+		a.maxMeteredAt = record.MeteredAt.ToTime()
+	}
+	return nil
+}
+
+// addSpec unbundles recordSpec and folds in each observation within the
+// window, as Aggregate would, keeping a warning for each one outside it. index is the position of the first unbundled
+// observation, used in error messages; returns the position after the last.
+func (a *recordAccumulator) addSpec(recordSpec specs.MeterRecordSpec, config AggregationConfig, index int) (int, error) {
+	for _, spec := range unbundleObservations([]specs.MeterRecordSpec{recordSpec}) {
+		record, err := NewMeterRecord(normalizeObservationUnits(spec, config.UnitAliases()))
+		if err != nil {
+			return index, fmt.Errorf("invalid record at index %d: %w", index, err)
+		}
+		index++
+		if warning, ok := checkInWindow(record, config.Window(), config.ClockSkewTolerance()); !ok {
+			if config.StrictMode() {
+				return index, fmt.Errorf("%w: %s", ErrRecordOutOfWindow, warning)
+			}
+			a.warnings = append(a.warnings, warning)
+			continue
+		}
+		if unit := record.Observations[0].Unit(); a.count > 0 && unit.ToString() != a.unit.ToString() {
+			return index, a.mixedUnitsError(unit)
+		}
+		a.add(record)
+	}
+	return index, nil
+}
+
+func (a *recordAccumulator) mixedUnitsError(unit Unit) error {
+	return fmt.Errorf("failed to aggregate with %s: %w: found %s, %s",
+		a.aggregation.ToString(), ErrMixedUnits, a.unit.ToString(), unit.ToString())
+}
+
+// merge folds the records accumulated in other into a, as if they had been
+// added to a after its own.
+func (a *recordAccumulator) merge(other *recordAccumulator) error {
+	if other.maxMeteredAt.After(a.maxMeteredAt) {
+		a.maxMeteredAt = other.maxMeteredAt
+	}
+	a.warnings = append(a.warnings, other.warnings...)
+	if other.count == 0 {
+		return nil
+	}
+	if a.count == 0 {
+		a.first, a.quantity, a.unit = other.first, other.quantity, other.unit
+		a.count, a.events, a.sampled = other.count, other.events, other.sampled
+		a.ids = append(a.ids, other.ids...)
+		return nil
+	}
+	if other.unit.ToString() != a.unit.ToString() {
+		return a.mixedUnitsError(other.unit)
+	}
+
+	switch {
+	case a.aggregation.IsSum():
+		a.quantity = a.quantity.Add(other.quantity)
+	case a.aggregation.IsMax():
+		if other.quantity.Cmp(a.quantity) > 0 {
+			a.quantity = other.quantity
+		}
+	case a.aggregation.IsMin():
+		if other.quantity.Cmp(a.quantity) < 0 {
+			a.quantity = other.quantity
+		}
+	}
+	a.count += other.count
+	a.events = a.events.Add(other.events)
+	a.sampled = a.sampled || other.sampled
+	a.ids = append(a.ids, other.ids...)
+	return nil
+}
+
+// reading builds the reading for the accumulated records. Like Aggregate, a
+// reading below MinRecordCount is returned with an *InsufficientDataError.
+func (a *recordAccumulator) reading(config AggregationConfig) (specs.MeterReadingSpec, error) {
+	if a.count == 0 {
+		return specs.MeterReadingSpec{}, fmt.Errorf("failed to aggregate with %s: no records", a.aggregation.ToString())
+	}
+
+	recordCount := a.count
+	if a.sampled {
+		estimate, err := a.events.RoundToInt64()
+		if err != nil {
+			return specs.MeterReadingSpec{}, fmt.Errorf("failed to estimate record count: %w", err)
+		}
+		recordCount = int(estimate)
+	}
+
+	reading, err := buildMeterReading(a.first, a.quantity, a.unit, recordCount, a.maxMeteredAt, config)
+	if err != nil {
+		return specs.MeterReadingSpec{}, err
+	}
+	if a.includeIDs {
+		reading.SourceRecordIDs, reading.SourceRecordIDsTruncated = selectSourceRecordIDs(a.ids, config.MaxSourceIDs())
+	}
+	return meterReadingToSpec(reading), checkMinRecordCount(reading, config)
+}
+
+func (a *recordAccumulator) add(record MeterRecord) {
+	quantity := record.Observations[0].Quantity()
+
+	if a.count == 0 {
+		a.first = record
+		a.quantity = quantity
+		a.unit = record.Observations[0].Unit()
+		a.events = NewDecimalFromInt64(0)
+	} else {
+		switch {
+		case a.aggregation.IsSum():
+			a.quantity = a.quantity.Add(quantity)
+		case a.aggregation.IsMax():
+			if quantity.Cmp(a.quantity) > 0 {
+				a.quantity = quantity
+			}
+		case a.aggregation.IsMin():
+			if quantity.Cmp(a.quantity) < 0 {
+				a.quantity = quantity
+			}
+		}
+	}
+
+	a.count++
+	a.events = a.events.Add(record.SampleRate.EventsRepresented())
+	if record.SampleRate.IsSampled() {
+		a.sampled = true
+	}
+	if record.MeteredAt.ToTime().After(a.maxMeteredAt) {
+		a.maxMeteredAt = record.MeteredAt.ToTime()
+	}
+	if a.includeIDs {
+		a.ids = append(a.ids, record.ID.ToString())
+	}
+}
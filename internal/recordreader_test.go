@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"github.com/chrisconley/metron/specs"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generatedRecordReader yields n records built on demand, retaining none.
+type generatedRecordReader struct {
+	n, pos int
+	err    error
+	onNext func(pos int)
+}
+
+func (r *generatedRecordReader) Next() (specs.MeterRecordSpec, bool) {
+	if r.pos >= r.n {
+		return specs.MeterRecordSpec{}, false
+	}
+	if r.onNext != nil {
+		r.onNext(r.pos)
+	}
+	observedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(r.pos) * time.Second)
+	record := newTestRecordSpec(fmt.Sprintf("event-%d", r.pos), "1", "api-calls", observedAt)
+	r.pos++
+	return record, true
+}
+
+func (r *generatedRecordReader) Err() error {
+	return r.err
+}
+
+func TestAggregateFromReader(t *testing.T) {
+	jan := func(d int) time.Time {
+		return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	records := []specs.MeterRecordSpec{
+		newTestRecordSpec("event-1", "10", "seats", jan(3)),
+		newTestRecordSpec("event-2", "25", "seats", jan(10)),
+		newTestRecordSpec("event-3", "5", "seats", jan(20)),
+	}
+	records[1].SampleRate = "0.5"
+
+	t.Run("matches Aggregate for every aggregation", func(t *testing.T) {
+		for _, aggregation := range []string{"sum", "max", "min", "latest", "first-non-zero", "mode", "time-weighted-avg"} {
+			expected, err := Aggregate(records, nil, newTestAggregateConfig(aggregation))
+			require.NoError(t, err)
+
+			actual, err := AggregateFromReader(SliceRecordReader(records), nil, newTestAggregateConfig(aggregation))
+
+			require.NoError(t, err, aggregation)
+			assert.Equal(t, expected.ID, actual.ID, aggregation)
+			assert.Equal(t, expected.ComputedValues, actual.ComputedValues, aggregation)
+			assert.Equal(t, expected.RecordCount, actual.RecordCount, aggregation)
+			assert.Equal(t, expected.MaxMeteredAt, actual.MaxMeteredAt, aggregation)
+		}
+	})
+
+	t.Run("end of input with no records is an error", func(t *testing.T) {
+		_, err := AggregateFromReader(SliceRecordReader(nil), nil, newTestAggregateConfig("sum"))
+
+		assert.ErrorContains(t, err, "no records")
+	})
+
+	t.Run("read error is propagated", func(t *testing.T) {
+		readErr := errors.New("connection reset")
+		reader := &generatedRecordReader{n: 10, err: readErr}
+
+		_, err := AggregateFromReader(reader, nil, newTestAggregateConfig("sum"))
+
+		assert.ErrorIs(t, err, readErr)
+	})
+
+	t.Run("sum of 1M records runs in constant memory", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("skipping 1M record aggregation in short mode")
+		}
+		const n = 1_000_000
+		var baseline, peak uint64
+		reader := &generatedRecordReader{n: n, onNext: func(pos int) {
+			if pos%250_000 != 0 {
+				return
+			}
+			runtime.GC()
+			var stats runtime.MemStats
+			runtime.ReadMemStats(&stats)
+			if pos == 0 {
+				baseline = stats.HeapAlloc
+			}
+			peak = max(peak, stats.HeapAlloc)
+		}}
+		config := newTestAggregateConfig("sum")
+		config.Window.End = jan(31)
+
+		reading, err := AggregateFromReader(reader, nil, config)
+
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprint(n), reading.ComputedValues[0].Quantity)
+		assert.Equal(t, n, reading.RecordCount)
+		assert.Less(t, peak-baseline, uint64(16<<20), "heap grew while reading records")
+	})
+
+	t.Run("group by is rejected", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.GroupBy = []string{"model"}
+
+		_, err := AggregateFromReader(SliceRecordReader(records), nil, config)
+
+		assert.ErrorContains(t, err, "AggregateGrouped")
+	})
+}
@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"sync"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// IDStore remembers which records have been processed, so records redelivered
+// under at-least-once delivery are not processed twice.
+type IDStore interface {
+	// MarkProcessed records that record has been processed and reports
+	// whether it had already been.
+	MarkProcessed(record specs.MeterRecordSpec) bool
+}
+
+// ValidateNoReplays splits records into those not yet processed and those
+// processedIDs has already seen, marking every record as processed. Records
+// are checked in input order, so a record repeated within the batch is new
+// the first time and a replay after. Both slices preserve input order.
+func ValidateNoReplays(records []specs.MeterRecordSpec, processedIDs IDStore) (fresh, alreadySeen []specs.MeterRecordSpec) {
+	fresh = make([]specs.MeterRecordSpec, 0, len(records))
+	alreadySeen = make([]specs.MeterRecordSpec, 0)
+	for _, record := range records {
+		if processedIDs.MarkProcessed(record) {
+			alreadySeen = append(alreadySeen, record)
+		} else {
+			fresh = append(fresh, record)
+		}
+	}
+	return fresh, alreadySeen
+}
+
+// MemoryIDStore is an IDStore that remembers every record ID it has seen.
+// It is safe for concurrent use.
+type MemoryIDStore struct {
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+// NewMemoryIDStore returns an empty MemoryIDStore.
+func NewMemoryIDStore() *MemoryIDStore {
+	return &MemoryIDStore{seen: make(map[string]bool)}
+}
+
+// MarkProcessed records the record's ID and reports whether it was seen before.
+func (s *MemoryIDStore) MarkProcessed(record specs.MeterRecordSpec) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen[record.ID] {
+		return true
+	}
+	s.seen[record.ID] = true
+	return false
+}
+
+// MonotonicSequenceStore is an IDStore that tracks the highest SequenceNumber
+// seen per workspace and subject. A record whose sequence number is not above
+// that maximum is a replay.
+//
+// This is stricter than deduplicating by ID: it also rejects a record that
+// arrives after its sequence successor, and needs constant memory per
+// subject rather than per record. Gaps are not replays; a record that skips
+// ahead simply raises the maximum. Records without a sequence number (zero
+// or negative) cannot be judged and are never replays.
+//
+// A producer whose counter wraps around or restarts numbers from low values
+// again. When the store is created with a positive rolloverGap, a sequence
+// number more than rolloverGap below the maximum is taken as a rollover: the
+// maximum restarts from it and the record is new. It is safe for concurrent
+// use.
+type MonotonicSequenceStore struct {
+	mu          sync.Mutex
+	rolloverGap int64
+	maxSeen     map[sequenceStreamKey]int64
+}
+
+type sequenceStreamKey struct {
+	workspaceID string
+	subject     string
+}
+
+// NewMonotonicSequenceStore returns an empty MonotonicSequenceStore. A
+// non-positive rolloverGap disables rollover detection.
+func NewMonotonicSequenceStore(rolloverGap int64) *MonotonicSequenceStore {
+	return &MonotonicSequenceStore{
+		rolloverGap: rolloverGap,
+		maxSeen:     make(map[sequenceStreamKey]int64),
+	}
+}
+
+// MarkProcessed records the record's sequence number for its workspace and
+// subject and reports whether the record is a replay.
+func (s *MonotonicSequenceStore) MarkProcessed(record specs.MeterRecordSpec) bool {
+	sequence := record.SequenceNumber
+	if sequence <= 0 {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := sequenceStreamKey{workspaceID: record.WorkspaceID, subject: record.Subject}
+	max, ok := s.maxSeen[key]
+	switch {
+	case !ok || sequence > max:
+		s.maxSeen[key] = sequence
+		return false
+	case s.rolloverGap > 0 && max-sequence > s.rolloverGap:
+		s.maxSeen[key] = sequence
+		return false
+	default:
+		return true
+	}
+}
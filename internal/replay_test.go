@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newSequencedRecordSpec(id, subject string, sequence int64) specs.MeterRecordSpec {
+	record := newTestRecordSpec(id, "1", "requests", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	record.Subject = subject
+	record.SequenceNumber = sequence
+	return record
+}
+
+func recordIDs(records []specs.MeterRecordSpec) []string {
+	ids := make([]string, len(records))
+	for i, record := range records {
+		ids[i] = record.ID
+	}
+	return ids
+}
+
+func TestValidateNoReplays(t *testing.T) {
+	t.Run("sequential records are all new", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newSequencedRecordSpec("event-1", "customer:a", 1),
+			newSequencedRecordSpec("event-2", "customer:a", 2),
+			newSequencedRecordSpec("event-3", "customer:a", 3),
+		}
+
+		fresh, alreadySeen := ValidateNoReplays(records, NewMonotonicSequenceStore(0))
+
+		assert.Equal(t, []string{"event-1", "event-2", "event-3"}, recordIDs(fresh))
+		assert.Empty(t, alreadySeen)
+	})
+
+	t.Run("replay is detected across batches", func(t *testing.T) {
+		store := NewMonotonicSequenceStore(0)
+		ValidateNoReplays([]specs.MeterRecordSpec{
+			newSequencedRecordSpec("event-1", "customer:a", 1),
+			newSequencedRecordSpec("event-2", "customer:a", 2),
+		}, store)
+
+		fresh, alreadySeen := ValidateNoReplays([]specs.MeterRecordSpec{
+			newSequencedRecordSpec("event-2", "customer:a", 2),
+			newSequencedRecordSpec("event-3", "customer:a", 3),
+		}, store)
+
+		assert.Equal(t, []string{"event-3"}, recordIDs(fresh))
+		assert.Equal(t, []string{"event-2"}, recordIDs(alreadySeen))
+	})
+
+	t.Run("gap in sequence is not a replay", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newSequencedRecordSpec("event-1", "customer:a", 1),
+			newSequencedRecordSpec("event-5", "customer:a", 5),
+			newSequencedRecordSpec("event-9", "customer:a", 9),
+		}
+
+		fresh, alreadySeen := ValidateNoReplays(records, NewMonotonicSequenceStore(0))
+
+		assert.Len(t, fresh, 3)
+		assert.Empty(t, alreadySeen)
+	})
+
+	t.Run("record arriving after its successor is a replay", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newSequencedRecordSpec("event-1", "customer:a", 1),
+			newSequencedRecordSpec("event-3", "customer:a", 3),
+			newSequencedRecordSpec("event-2", "customer:a", 2),
+		}
+
+		_, alreadySeen := ValidateNoReplays(records, NewMonotonicSequenceStore(0))
+
+		assert.Equal(t, []string{"event-2"}, recordIDs(alreadySeen))
+	})
+
+	t.Run("sequences are tracked per subject", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newSequencedRecordSpec("event-a5", "customer:a", 5),
+			newSequencedRecordSpec("event-b1", "customer:b", 1),
+		}
+
+		fresh, _ := ValidateNoReplays(records, NewMonotonicSequenceStore(0))
+
+		assert.Len(t, fresh, 2)
+	})
+
+	t.Run("sequence rollover restarts the maximum", func(t *testing.T) {
+		store := NewMonotonicSequenceStore(1000)
+		records := []specs.MeterRecordSpec{
+			newSequencedRecordSpec("event-before", "customer:a", 1_000_000),
+			newSequencedRecordSpec("event-late", "customer:a", 999_999),
+			newSequencedRecordSpec("event-after", "customer:a", 1),
+			newSequencedRecordSpec("event-next", "customer:a", 2),
+			newSequencedRecordSpec("event-replayed", "customer:a", 2),
+		}
+
+		fresh, alreadySeen := ValidateNoReplays(records, store)
+
+		assert.Equal(t, []string{"event-before", "event-after", "event-next"}, recordIDs(fresh))
+		assert.Equal(t, []string{"event-late", "event-replayed"}, recordIDs(alreadySeen))
+	})
+
+	t.Run("unnumbered records are never replays", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newSequencedRecordSpec("event-1", "customer:a", 0),
+			newSequencedRecordSpec("event-1", "customer:a", 0),
+		}
+
+		fresh, _ := ValidateNoReplays(records, NewMonotonicSequenceStore(0))
+
+		assert.Len(t, fresh, 2)
+	})
+
+	t.Run("memory store detects repeated IDs", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newSequencedRecordSpec("event-1", "customer:a", 0),
+			newSequencedRecordSpec("event-2", "customer:a", 0),
+			newSequencedRecordSpec("event-1", "customer:a", 0),
+		}
+
+		fresh, alreadySeen := ValidateNoReplays(records, NewMemoryIDStore())
+
+		assert.Equal(t, []string{"event-1", "event-2"}, recordIDs(fresh))
+		assert.Equal(t, []string{"event-1"}, recordIDs(alreadySeen))
+	})
+}
@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"fmt"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// RequiredPropertyError reports an event missing a property its event type
+// requires.
+type RequiredPropertyError struct {
+	EventType       string
+	MissingProperty string
+}
+
+func (e RequiredPropertyError) Error() string {
+	return fmt.Sprintf("event type %q requires property %q", e.EventType, e.MissingProperty)
+}
+
+// ValidateRequiredProperties returns an error for each property that the
+// config's RequiredProperties lists for the payload's event type but the
+// payload lacks, in the order the config lists them. Returns nil when the
+// event type has no required properties. A BaseConfig is merged first;
+// returns error if the BaseConfig chain has a cycle.
+func ValidateRequiredProperties(payload specs.EventPayloadSpec, config specs.MeteringConfigSpec) ([]RequiredPropertyError, error) {
+	if err := checkBaseConfigChain(config); err != nil {
+		return nil, err
+	}
+	if config.BaseConfig != nil {
+		var err error
+		config, err = MergeConfigs(*config.BaseConfig, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var missing []RequiredPropertyError
+	for _, name := range config.RequiredProperties[payload.Type] {
+		if _, ok := payload.Properties[name]; !ok {
+			missing = append(missing, RequiredPropertyError{EventType: payload.Type, MissingProperty: name})
+		}
+	}
+	return missing, nil
+}
+
+// validateRequiredPropertiesSpec returns error if an event type or property
+// name is empty.
+func validateRequiredPropertiesSpec(required map[string][]string) error {
+	for eventType, names := range required {
+		if eventType == "" {
+			return fmt.Errorf("required properties: event type is required")
+		}
+		for i, name := range names {
+			if name == "" {
+				return fmt.Errorf("required properties for %q: property %d: name is required", eventType, i)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRequiredProperties(t *testing.T) {
+	newPayload := func(eventType string, properties map[string]string) specs.EventPayloadSpec {
+		return specs.EventPayloadSpec{
+			ID:          "event-123",
+			WorkspaceID: "workspace-test",
+			UniverseID:  "universe-test",
+			Type:        eventType,
+			Subject:     "customer:test",
+			Time:        time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC),
+			Properties:  properties,
+		}
+	}
+	config := specs.MeteringConfigSpec{
+		Observations: []specs.ObservationExtractionSpec{{SourceProperty: "tokens", Unit: "tokens"}},
+		RequiredProperties: map[string][]string{
+			"api.request":    {"tokens", "model"},
+			"storage.upload": {"bytes"},
+		},
+	}
+
+	t.Run("event with all required properties passes", func(t *testing.T) {
+		payload := newPayload("api.request", map[string]string{"tokens": "100", "model": "gpt-4"})
+
+		missing, validateErr := ValidateRequiredProperties(payload, config)
+		records, err := Meter(payload, config)
+
+		require.NoError(t, validateErr)
+		assert.Empty(t, missing)
+		require.NoError(t, err)
+		assert.Len(t, records, 1)
+	})
+
+	t.Run("event missing one required property returns error for that property", func(t *testing.T) {
+		payload := newPayload("api.request", map[string]string{"tokens": "100"})
+
+		missing, validateErr := ValidateRequiredProperties(payload, config)
+		_, err := Meter(payload, config)
+
+		require.NoError(t, validateErr)
+		assert.Equal(t, []RequiredPropertyError{{EventType: "api.request", MissingProperty: "model"}}, missing)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `event type "api.request" requires property "model"`)
+	})
+
+	t.Run("event type not in required properties skips validation", func(t *testing.T) {
+		payload := newPayload("api.batch", map[string]string{"tokens": "100"})
+
+		missing, err := ValidateRequiredProperties(payload, config)
+
+		require.NoError(t, err)
+		assert.Empty(t, missing)
+	})
+
+	t.Run("empty required properties is permissive", func(t *testing.T) {
+		payload := newPayload("api.request", map[string]string{"tokens": "100"})
+
+		missing, err := ValidateRequiredProperties(payload, specs.MeteringConfigSpec{})
+
+		require.NoError(t, err)
+		assert.Empty(t, missing)
+	})
+
+	t.Run("base config requirements apply", func(t *testing.T) {
+		payload := newPayload("storage.upload", map[string]string{"tokens": "100"})
+		derived := specs.MeteringConfigSpec{BaseConfig: &config}
+
+		missing, err := ValidateRequiredProperties(payload, derived)
+
+		require.NoError(t, err)
+		assert.Equal(t, []RequiredPropertyError{{EventType: "storage.upload", MissingProperty: "bytes"}}, missing)
+	})
+
+	t.Run("rejects empty property name", func(t *testing.T) {
+		invalid := config
+		invalid.RequiredProperties = map[string][]string{"api.request": {""}}
+
+		_, err := NewMeteringConfig(invalid)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "name is required")
+	})
+}
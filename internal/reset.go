@@ -0,0 +1,59 @@
+package internal
+
+import "sort"
+
+// adjustForResets detects counter resets across lastBeforeWindow and
+// recordsInWindow in ObservedAt order, and returns copies of the records with
+// resets undone, along with the number of resets found.
+//
+// A reset is a drop of more than threshold from one record to the next. Each
+// reset adds the pre-reset value to a running offset that is applied to every
+// later record, so a counter restarting from zero continues from where it
+// left off. A leading zero is not a reset; there is nothing to drop from.
+func adjustForResets(
+	recordsInWindow []MeterRecord,
+	lastBeforeWindow *MeterRecord,
+	threshold Decimal,
+) ([]MeterRecord, *MeterRecord, int) {
+	// Combine records (last-before first) so the adjusted copies can be split back
+	all := make([]MeterRecord, 0, len(recordsInWindow)+1)
+	if lastBeforeWindow != nil {
+		all = append(all, *lastBeforeWindow)
+	}
+	all = append(all, recordsInWindow...)
+
+	order := make([]int, len(all))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return all[order[i]].ObservedAt.ToTime().Before(all[order[j]].ObservedAt.ToTime())
+	})
+
+	resets := 0
+	offset := NewDecimalFromInt64(0)
+	var previous Decimal
+	for n, i := range order {
+		observation := all[i].Observations[0]
+		raw := observation.Quantity()
+		if n > 0 && previous.Sub(raw).Cmp(threshold) > 0 {
+			resets++
+			offset = offset.Add(previous)
+		}
+		previous = raw
+
+		if resets > 0 {
+			adjusted := all[i]
+			adjusted.Observations = []Observation{
+				NewObservation(raw.Add(offset), observation.Unit(), observation.Window()),
+			}
+			all[i] = adjusted
+		}
+	}
+
+	if lastBeforeWindow == nil {
+		return all, nil, resets
+	}
+	last := all[0]
+	return all[1:], &last, resets
+}
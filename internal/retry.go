@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// BackoffStrategy decides how long to wait before retrying a failed attempt.
+type BackoffStrategy interface {
+	// Wait returns the delay after the given failed attempt, numbered from 1.
+	Wait(attempt int) time.Duration
+}
+
+type exponentialBackoff struct {
+	base       time.Duration
+	multiplier float64
+	max        time.Duration
+}
+
+// ExponentialBackoff returns a BackoffStrategy that waits base after the
+// first attempt and multiplier times longer after each one after that, never
+// more than max. A non-positive max means no cap.
+func ExponentialBackoff(base time.Duration, multiplier float64, max time.Duration) BackoffStrategy {
+	return exponentialBackoff{base: base, multiplier: multiplier, max: max}
+}
+
+func (b exponentialBackoff) Wait(attempt int) time.Duration {
+	wait := float64(b.base) * math.Pow(b.multiplier, float64(attempt-1))
+	if b.max > 0 && wait > float64(b.max) {
+		return b.max
+	}
+	return time.Duration(wait)
+}
+
+type constantBackoff struct {
+	wait time.Duration
+}
+
+// ConstantBackoff returns a BackoffStrategy that always waits d.
+func ConstantBackoff(d time.Duration) BackoffStrategy {
+	return constantBackoff{wait: d}
+}
+
+func (b constantBackoff) Wait(int) time.Duration {
+	return b.wait
+}
+
+// transientError marks an error as worth retrying.
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transientError) Unwrap() error {
+	return e.err
+}
+
+// TransientError marks err as transient, such as a timeout calling a schema
+// registry or enricher, so RetryableMeter retries it. The result still
+// matches err with errors.Is and errors.As. Returns nil if err is nil.
+func TransientError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &transientError{err: err}
+}
+
+// IsTransientError reports whether err is or wraps an error marked by
+// TransientError.
+func IsTransientError(err error) bool {
+	var transient *transientError
+	return errors.As(err, &transient)
+}
+
+// RetryableMeter wraps meter to retry transient failures up to maxAttempts
+// attempts in total, waiting between attempts as backoff decides.
+//
+// Only errors marked with TransientError are retried; any other error, such
+// as unparseable event data, is returned immediately since retrying cannot
+// fix it. When every attempt fails, returns the last error. A maxAttempts
+// below 1 is treated as 1.
+func RetryableMeter(meter specs.Meter, maxAttempts int, backoff BackoffStrategy) specs.Meter {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return func(payload specs.EventPayloadSpec, config specs.MeteringConfigSpec) ([]specs.MeterRecordSpec, error) {
+		var err error
+		for attempt := 1; attempt <= maxAttempts; attempt++ {
+			var records []specs.MeterRecordSpec
+			records, err = meter(payload, config)
+			if err == nil {
+				return records, nil
+			}
+			if !IsTransientError(err) {
+				return nil, err
+			}
+			if attempt < maxAttempts {
+				time.Sleep(backoff.Wait(attempt))
+			}
+		}
+		return nil, fmt.Errorf("meter failed after %d attempts: %w", maxAttempts, err)
+	}
+}
@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"errors"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryableMeter(t *testing.T) {
+	errUnavailable := errors.New("schema registry unavailable")
+	payload := specs.EventPayloadSpec{
+		ID:          "event-123",
+		WorkspaceID: "workspace-test",
+		UniverseID:  "universe-test",
+		Type:        "api.request",
+		Subject:     "customer:test",
+		Time:        time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC),
+		Properties:  map[string]string{"calls": "1"},
+	}
+	config := specs.MeteringConfigSpec{
+		Observations: []specs.ObservationExtractionSpec{{SourceProperty: "calls", Unit: "api-calls"}},
+	}
+	// failingMeter fails with err on its first failures calls, then meters normally
+	failingMeter := func(failures int, err error) (specs.Meter, *int) {
+		calls := 0
+		return func(p specs.EventPayloadSpec, c specs.MeteringConfigSpec) ([]specs.MeterRecordSpec, error) {
+			calls++
+			if calls <= failures {
+				return nil, err
+			}
+			return Meter(p, c)
+		}, &calls
+	}
+
+	t.Run("success on first attempt", func(t *testing.T) {
+		meter, calls := failingMeter(0, nil)
+
+		records, err := RetryableMeter(meter, 3, ConstantBackoff(0))(payload, config)
+
+		require.NoError(t, err)
+		assert.Len(t, records, 1)
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("success on third attempt", func(t *testing.T) {
+		meter, calls := failingMeter(2, TransientError(errUnavailable))
+
+		records, err := RetryableMeter(meter, 3, ConstantBackoff(time.Millisecond))(payload, config)
+
+		require.NoError(t, err)
+		assert.Len(t, records, 1)
+		assert.Equal(t, 3, *calls)
+	})
+
+	t.Run("permanent error not retried", func(t *testing.T) {
+		meter, calls := failingMeter(5, errUnavailable)
+
+		_, err := RetryableMeter(meter, 3, ConstantBackoff(0))(payload, config)
+
+		assert.ErrorIs(t, err, errUnavailable)
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("max attempts exceeded returns last error", func(t *testing.T) {
+		meter, calls := failingMeter(5, TransientError(errUnavailable))
+
+		_, err := RetryableMeter(meter, 3, ConstantBackoff(0))(payload, config)
+
+		assert.ErrorIs(t, err, errUnavailable)
+		assert.True(t, IsTransientError(err))
+		assert.Contains(t, err.Error(), "after 3 attempts")
+		assert.Equal(t, 3, *calls)
+	})
+}
+
+func TestBackoffStrategy(t *testing.T) {
+	t.Run("exponential backoff grows to max", func(t *testing.T) {
+		backoff := ExponentialBackoff(100*time.Millisecond, 2, time.Second)
+
+		assert.Equal(t, 100*time.Millisecond, backoff.Wait(1))
+		assert.Equal(t, 200*time.Millisecond, backoff.Wait(2))
+		assert.Equal(t, 800*time.Millisecond, backoff.Wait(4))
+		assert.Equal(t, time.Second, backoff.Wait(5))
+	})
+
+	t.Run("constant backoff", func(t *testing.T) {
+		backoff := ConstantBackoff(50 * time.Millisecond)
+
+		assert.Equal(t, 50*time.Millisecond, backoff.Wait(1))
+		assert.Equal(t, 50*time.Millisecond, backoff.Wait(10))
+	})
+
+	t.Run("transient marker", func(t *testing.T) {
+		assert.Nil(t, TransientError(nil))
+		assert.False(t, IsTransientError(errors.New("bad data")))
+		assert.True(t, IsTransientError(TransientError(errors.New("timeout"))))
+	})
+}
@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"fmt"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// RollupMetering meters each payload as Meter does, then adds a rollup record
+// for each ancestor of the record's subject in hierarchy.
+//
+// A rollup record copies the metered record's observations and dimensions
+// under the ancestor's subject, with RecordType "rollup" and ID
+// "<record ID>:rollup:<ancestor>". Its ContentHash is recomputed for the new
+// subject. Records are returned in payload order, each metered record followed
+// by its rollups from the nearest ancestor outward. Subjects not in hierarchy
+// produce no rollups.
+//
+// Returns error if hierarchy is invalid (an empty subject, a subject with more
+// than one parent, or the root subject below itself), or metering any payload
+// fails.
+func RollupMetering(
+	payloads []specs.EventPayloadSpec,
+	hierarchy specs.SubjectHierarchySpec,
+	config specs.MeteringConfigSpec,
+) ([]specs.MeterRecordSpec, error) {
+	parents := make(map[string]string)
+	if err := collectParents(hierarchy, parents); err != nil {
+		return nil, fmt.Errorf("invalid hierarchy: %w", err)
+	}
+	if _, ok := parents[hierarchy.Subject]; ok {
+		return nil, fmt.Errorf("invalid hierarchy: subject %s cannot be its own descendant", hierarchy.Subject)
+	}
+
+	var result []specs.MeterRecordSpec
+	for i, payload := range payloads {
+		records, err := Meter(payload, config)
+		if err != nil {
+			return nil, fmt.Errorf("payload %d: %w", i, err)
+		}
+		for _, record := range records {
+			result = append(result, record)
+			for ancestor, ok := parents[record.Subject]; ok; ancestor, ok = parents[ancestor] {
+				rollup, err := newRollupRecord(record, ancestor)
+				if err != nil {
+					return nil, fmt.Errorf("payload %d: %w", i, err)
+				}
+				result = append(result, rollup)
+			}
+		}
+	}
+	return result, nil
+}
+
+// collectParents records the parent of every subject below hierarchy's
+// subject in parents.
+func collectParents(hierarchy specs.SubjectHierarchySpec, parents map[string]string) error {
+	if hierarchy.Subject == "" {
+		return fmt.Errorf("subject cannot be empty")
+	}
+	addChild := func(child string) error {
+		if child == "" {
+			return fmt.Errorf("child of %s cannot be empty", hierarchy.Subject)
+		}
+		if parent, ok := parents[child]; ok {
+			return fmt.Errorf("subject %s has more than one parent: %s and %s", child, parent, hierarchy.Subject)
+		}
+		parents[child] = hierarchy.Subject
+		return nil
+	}
+
+	for _, child := range hierarchy.Children {
+		if err := addChild(child); err != nil {
+			return err
+		}
+	}
+	for _, subgroup := range hierarchy.Subgroups {
+		if err := addChild(subgroup.Subject); err != nil {
+			return err
+		}
+		if err := collectParents(subgroup, parents); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newRollupRecord returns a copy of record attributed to ancestor.
+func newRollupRecord(record specs.MeterRecordSpec, ancestor string) (specs.MeterRecordSpec, error) {
+	rollup := record
+	rollup.ID = record.ID + ":rollup:" + ancestor
+	rollup.Subject = ancestor
+	rollup.RecordType = "rollup"
+	rollup.Observations = append([]specs.ObservationSpec(nil), record.Observations...)
+	if record.Dimensions != nil {
+		rollup.Dimensions = make(map[string]string, len(record.Dimensions))
+		for k, v := range record.Dimensions {
+			rollup.Dimensions[k] = v
+		}
+	}
+
+	domain, err := NewMeterRecord(rollup)
+	if err != nil {
+		return specs.MeterRecordSpec{}, fmt.Errorf("failed to create rollup record for %s: %w", ancestor, err)
+	}
+	rollup.ContentHash = domain.ContentHash()
+	return rollup, nil
+}
@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/internal/testutil"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollupMetering(t *testing.T) {
+	config := specs.MeteringConfigSpec{
+		Observations: []specs.ObservationExtractionSpec{
+			{SourceProperty: "tokens", Unit: "tokens"},
+		},
+	}
+	payload := func(id, subject string) specs.EventPayloadSpec {
+		return testutil.FixtureEventPayload(
+			testutil.WithPayloadID(id),
+			testutil.WithPayloadSubject(subject),
+			testutil.WithPayloadProperties(map[string]string{"tokens": "100", "model": "gpt-4"}),
+		)
+	}
+
+	t.Run("customer records produce org-level rollups", func(t *testing.T) {
+		hierarchy := specs.SubjectHierarchySpec{
+			Subject:  "org:acme",
+			Children: []string{"customer:1", "customer:2"},
+		}
+
+		records, err := RollupMetering([]specs.EventPayloadSpec{payload("event-1", "customer:1"), payload("event-2", "customer:2")}, hierarchy, config)
+
+		require.NoError(t, err)
+		require.Len(t, records, 4)
+		assert.Equal(t, "customer:1", records[0].Subject)
+		assert.Empty(t, records[0].RecordType)
+
+		rollup := records[1]
+		assert.Equal(t, "event-1:rollup:org:acme", rollup.ID)
+		assert.Equal(t, "org:acme", rollup.Subject)
+		assert.Equal(t, "rollup", rollup.RecordType)
+		assert.Equal(t, records[0].Observations, rollup.Observations)
+		assert.Equal(t, records[0].Dimensions, rollup.Dimensions)
+		assert.Equal(t, records[0].SourceEventID, rollup.SourceEventID)
+		assert.NotEqual(t, records[0].ContentHash, rollup.ContentHash)
+
+		assert.Equal(t, "customer:2", records[2].Subject)
+		assert.Equal(t, "org:acme", records[3].Subject)
+	})
+
+	t.Run("three-level hierarchy produces two levels of rollup records", func(t *testing.T) {
+		hierarchy := specs.SubjectHierarchySpec{
+			Subject: "org:acme",
+			Subgroups: []specs.SubjectHierarchySpec{
+				{Subject: "team:platform", Children: []string{"customer:1"}},
+			},
+		}
+
+		records, err := RollupMetering([]specs.EventPayloadSpec{payload("event-1", "customer:1")}, hierarchy, config)
+
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, []string{"customer:1", "team:platform", "org:acme"},
+			[]string{records[0].Subject, records[1].Subject, records[2].Subject})
+		assert.Equal(t, "rollup", records[1].RecordType)
+		assert.Equal(t, "rollup", records[2].RecordType)
+	})
+
+	t.Run("subjects not in hierarchy produce no rollups", func(t *testing.T) {
+		hierarchy := specs.SubjectHierarchySpec{Subject: "org:acme", Children: []string{"customer:1"}}
+
+		records, err := RollupMetering([]specs.EventPayloadSpec{payload("event-1", "customer:other")}, hierarchy, config)
+
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "customer:other", records[0].Subject)
+	})
+
+	t.Run("subject with two parents is rejected", func(t *testing.T) {
+		hierarchy := specs.SubjectHierarchySpec{
+			Subject:  "org:acme",
+			Children: []string{"customer:1"},
+			Subgroups: []specs.SubjectHierarchySpec{
+				{Subject: "team:platform", Children: []string{"customer:1"}},
+			},
+		}
+
+		_, err := RollupMetering(nil, hierarchy, config)
+
+		assert.ErrorContains(t, err, "customer:1 has more than one parent")
+	})
+
+	t.Run("root below itself is rejected", func(t *testing.T) {
+		hierarchy := specs.SubjectHierarchySpec{
+			Subject: "org:acme",
+			Subgroups: []specs.SubjectHierarchySpec{
+				{Subject: "team:platform", Children: []string{"org:acme"}},
+			},
+		}
+
+		_, err := RollupMetering(nil, hierarchy, config)
+
+		assert.ErrorContains(t, err, "org:acme cannot be its own descendant")
+	})
+}
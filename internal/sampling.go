@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"fmt"
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// NormalizeForSampling scales the quantities of sampled records up to estimates
+// of the full population.
+//
+// For each record with SampleRate below 1 and exact Confidence, every
+// observation quantity is multiplied by 1/SampleRate and Confidence is set to
+// the sample rate, marking the quantities as estimates. Records that are not
+// sampled, or whose Confidence is already below 1 (already scaled), pass
+// through unchanged, so normalizing twice is safe. SampleRate is kept so
+// aggregation can report the estimated event count as RecordCount. A
+// ContentHash is recomputed for the new quantities, as ScaleRecords does.
+//
+// Returns error if a record has an invalid SampleRate, Confidence, or quantity.
+func NormalizeForSampling(records []specs.MeterRecordSpec) ([]specs.MeterRecordSpec, error) {
+	result := make([]specs.MeterRecordSpec, len(records))
+	for i, record := range records {
+		sampleRate, err := NewMeterRecordSampleRate(record.SampleRate)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: invalid sample rate: %w", i, err)
+		}
+		confidence, err := NewMeterRecordConfidence(record.Confidence)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: invalid confidence: %w", i, err)
+		}
+
+		if !sampleRate.IsSampled() || !confidence.IsExact() {
+			result[i] = record
+			continue
+		}
+
+		scale := sampleRate.EventsRepresented()
+		observations := make([]specs.ObservationSpec, len(record.Observations))
+		for j, observation := range record.Observations {
+			quantity, err := NewDecimal(observation.Quantity)
+			if err != nil {
+				return nil, fmt.Errorf("record %d: invalid observation[%d] quantity: %w", i, j, err)
+			}
+			observation.Quantity = quantity.Mul(scale).Normalize().String()
+			observations[j] = observation
+		}
+
+		normalized := record
+		normalized.Observations = observations
+		normalized.Confidence = sampleRate.ToDecimal().String()
+		if normalized.ContentHash != "" {
+			domain, err := NewMeterRecord(normalized)
+			if err != nil {
+				return nil, fmt.Errorf("record %d: %w", i, err)
+			}
+			normalized.ContentHash = domain.ContentHash()
+		}
+		result[i] = normalized
+	}
+	return result, nil
+}
+
+// estimateEventCount adjusts an aggregation's record count for sampled records.
+// Each record contributes 1/SampleRate events instead of one. The lastBeforeWindow
+// record is included only when the aggregation counted it (count exceeds the
+// number of in-window records). Returns count unchanged if no record is sampled.
+func estimateEventCount(recordsInWindow []MeterRecord, lastBeforeWindow *MeterRecord, count int) (int, error) {
+	counted := recordsInWindow
+	if lastBeforeWindow != nil && count > len(recordsInWindow) {
+		counted = append(append([]MeterRecord{}, recordsInWindow...), *lastBeforeWindow)
+	}
+
+	sampled := false
+	total := NewDecimalFromInt64(0)
+	for _, record := range counted {
+		if record.SampleRate.IsSampled() {
+			sampled = true
+		}
+		total = total.Add(record.SampleRate.EventsRepresented())
+	}
+	if !sampled {
+		return count, nil
+	}
+
+	estimate, err := total.RoundToInt64()
+	if err != nil {
+		return 0, err
+	}
+	return int(estimate), nil
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeForSampling(t *testing.T) {
+	t.Run("1% sample of 10 records represents 1000 events", func(t *testing.T) {
+		records := make([]specs.MeterRecordSpec, 10)
+		for i := range records {
+			records[i] = newTestRecordSpec(fmt.Sprintf("event-%d", i), "1", "api-calls", time.Date(2024, 1, 10+i, 0, 0, 0, 0, time.UTC))
+			records[i].SampleRate = "0.01"
+		}
+
+		normalized, err := NormalizeForSampling(records)
+		require.NoError(t, err)
+
+		reading, err := Aggregate(normalized, nil, newTestAggregateConfig("sum"))
+
+		require.NoError(t, err)
+		assert.Equal(t, 1000, reading.RecordCount)
+		quantity, err := NewDecimal(reading.ComputedValues[0].Quantity)
+		require.NoError(t, err)
+		assert.Equal(t, 0, quantity.Cmp(NewDecimalFromInt64(1000)), "expected 1000, got %s", quantity)
+		assert.Equal(t, "0.01", normalized[0].Confidence)
+	})
+
+	t.Run("scaled quantities are written without trailing zeros", func(t *testing.T) {
+		record := newTestRecordSpec("event-1", "1", "api-calls", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC))
+		record.SampleRate = "0.5"
+		fractional := newTestRecordSpec("event-2", "0.25", "api-calls", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC))
+		fractional.SampleRate = "0.5"
+
+		normalized, err := NormalizeForSampling([]specs.MeterRecordSpec{record, fractional})
+
+		require.NoError(t, err)
+		assert.Equal(t, "2", normalized[0].Observations[0].Quantity)
+		assert.Equal(t, "0.5", normalized[1].Observations[0].Quantity)
+	})
+
+	t.Run("normalizing twice does not scale again", func(t *testing.T) {
+		record := newTestRecordSpec("event-1", "2", "api-calls", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC))
+		record.SampleRate = "0.5"
+
+		once, err := NormalizeForSampling([]specs.MeterRecordSpec{record})
+		require.NoError(t, err)
+		twice, err := NormalizeForSampling(once)
+		require.NoError(t, err)
+
+		assert.Equal(t, once, twice)
+	})
+
+	t.Run("confidence 1.0 passes through unchanged", func(t *testing.T) {
+		record := newTestRecordSpec("event-1", "7", "api-calls", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC))
+		record.Confidence = "1.0"
+
+		normalized, err := NormalizeForSampling([]specs.MeterRecordSpec{record})
+
+		require.NoError(t, err)
+		assert.Equal(t, []specs.MeterRecordSpec{record}, normalized)
+	})
+
+	t.Run("with invalid confidence returns validation error", func(t *testing.T) {
+		record := newTestRecordSpec("event-1", "7", "api-calls", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC))
+		record.Confidence = "1.5"
+
+		_, err := NormalizeForSampling([]specs.MeterRecordSpec{record})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "confidence must be between 0 and 1")
+
+		_, err = NewMeterRecord(record)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid confidence")
+	})
+
+	t.Run("with zero sample rate returns validation error", func(t *testing.T) {
+		record := newTestRecordSpec("event-1", "7", "api-calls", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC))
+		record.SampleRate = "0"
+
+		_, err := NewMeterRecord(record)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid sample rate")
+	})
+}
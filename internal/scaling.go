@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"fmt"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// ScaleRecords returns copies of records with every observation quantity
+// multiplied by factor, for batch billing corrections after metering (e.g., a
+// factor of "0.9" when quantities were overstated by 10%).
+//
+// Each scaled observation records its Scaling audit trail. A ContentHash, if
+// present, is recomputed for the new quantities. The input records are not
+// modified. Returns error if factor is not a non-zero decimal, or a record
+// has an invalid quantity.
+func ScaleRecords(records []specs.MeterRecordSpec, factor string) ([]specs.MeterRecordSpec, error) {
+	scale, err := newScaleFactor(factor)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]specs.MeterRecordSpec, len(records))
+	for i, record := range records {
+		observations := make([]specs.ObservationSpec, len(record.Observations))
+		for j, observation := range record.Observations {
+			observation.Quantity, observation.Scaling, err = scaleQuantity(observation.Quantity, observation.Scaling, scale)
+			if err != nil {
+				return nil, fmt.Errorf("record %d: invalid observation[%d] quantity: %w", i, j, err)
+			}
+			observations[j] = observation
+		}
+
+		scaled := record
+		scaled.Observations = observations
+		if scaled.ContentHash != "" {
+			domain, err := NewMeterRecord(scaled)
+			if err != nil {
+				return nil, fmt.Errorf("record %d: %w", i, err)
+			}
+			scaled.ContentHash = domain.ContentHash()
+		}
+		result[i] = scaled
+	}
+	return result, nil
+}
+
+// ScaleReadings returns copies of readings with every computed value
+// multiplied by factor. Confidence interval bounds are scaled too, and swapped
+// when factor is negative so the lower bound stays lower. RecordCount and
+// PreviousValues are unchanged.
+//
+// Each scaled computed value records its Scaling audit trail. The input
+// readings are not modified. Returns error if factor is not a non-zero
+// decimal, or a reading has an invalid quantity.
+func ScaleReadings(readings []specs.MeterReadingSpec, factor string) ([]specs.MeterReadingSpec, error) {
+	scale, err := newScaleFactor(factor)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]specs.MeterReadingSpec, len(readings))
+	for i, reading := range readings {
+		values := make([]specs.ComputedValueSpec, len(reading.ComputedValues))
+		for j, value := range reading.ComputedValues {
+			value.Quantity, value.Scaling, err = scaleQuantity(value.Quantity, value.Scaling, scale)
+			if err != nil {
+				return nil, fmt.Errorf("reading %d: invalid computed value %d quantity: %w", i, j, err)
+			}
+			values[j] = value
+		}
+
+		scaled := reading
+		scaled.ComputedValues = values
+		if reading.ConfidenceIntervalLower != "" || reading.ConfidenceIntervalUpper != "" {
+			lower, _, err := scaleQuantity(reading.ConfidenceIntervalLower, nil, scale)
+			if err != nil {
+				return nil, fmt.Errorf("reading %d: invalid confidence interval lower bound: %w", i, err)
+			}
+			upper, _, err := scaleQuantity(reading.ConfidenceIntervalUpper, nil, scale)
+			if err != nil {
+				return nil, fmt.Errorf("reading %d: invalid confidence interval upper bound: %w", i, err)
+			}
+			if scale.Cmp(NewDecimalFromInt64(0)) < 0 {
+				lower, upper = upper, lower
+			}
+			scaled.ConfidenceIntervalLower, scaled.ConfidenceIntervalUpper = lower, upper
+		}
+		result[i] = scaled
+	}
+	return result, nil
+}
+
+// newScaleFactor parses a scaling factor. Zero is rejected since it would
+// erase usage rather than correct it; negative factors are allowed.
+func newScaleFactor(factor string) (Decimal, error) {
+	scale, err := NewDecimal(factor)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("invalid scale factor: %w", err)
+	}
+	if scale.IsZero() {
+		return Decimal{}, fmt.Errorf("invalid scale factor: must be non-zero")
+	}
+	return scale, nil
+}
+
+// scaleQuantity multiplies quantity by scale and returns it with its updated
+// audit trail. A previous scaling keeps its original quantity and has its
+// factor multiplied.
+func scaleQuantity(quantity string, previous *specs.ScalingSpec, scale Decimal) (string, *specs.ScalingSpec, error) {
+	value, err := NewDecimal(quantity)
+	if err != nil {
+		return "", nil, err
+	}
+	adjusted := value.Mul(scale).String()
+
+	audit := &specs.ScalingSpec{
+		OriginalQuantity: quantity,
+		Factor:           scale.String(),
+		AdjustedQuantity: adjusted,
+	}
+	if previous != nil {
+		previousFactor, err := NewDecimal(previous.Factor)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid scaling factor: %w", err)
+		}
+		audit.OriginalQuantity = previous.OriginalQuantity
+		audit.Factor = previousFactor.Mul(scale).String()
+	}
+	return adjusted, audit, nil
+}
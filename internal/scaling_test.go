@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScaleRecords(t *testing.T) {
+	observedAt := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	newRecords := func() []specs.MeterRecordSpec {
+		return []specs.MeterRecordSpec{
+			newTestRecordSpec("event-1", "100", "tokens", observedAt),
+			newTestRecordSpec("event-2", "250", "tokens", observedAt),
+		}
+	}
+
+	t.Run("factor 0.9", func(t *testing.T) {
+		records := newRecords()
+
+		scaled, err := ScaleRecords(records, "0.9")
+
+		require.NoError(t, err)
+		require.Len(t, scaled, 2)
+		assert.Equal(t, "90.0", scaled[0].Observations[0].Quantity)
+		assert.Equal(t, "225.0", scaled[1].Observations[0].Quantity)
+		assert.Equal(t, &specs.ScalingSpec{OriginalQuantity: "100", Factor: "0.9", AdjustedQuantity: "90.0"}, scaled[0].Observations[0].Scaling)
+		assert.Equal(t, "100", records[0].Observations[0].Quantity, "input records are not modified")
+		assert.Nil(t, records[0].Observations[0].Scaling)
+	})
+
+	t.Run("factor 2.0", func(t *testing.T) {
+		scaled, err := ScaleRecords(newRecords(), "2.0")
+
+		require.NoError(t, err)
+		assert.Equal(t, "200.0", scaled[0].Observations[0].Quantity)
+	})
+
+	t.Run("negative factor allowed", func(t *testing.T) {
+		scaled, err := ScaleRecords(newRecords(), "-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "-100", scaled[0].Observations[0].Quantity)
+	})
+
+	t.Run("zero factor returns error", func(t *testing.T) {
+		_, err := ScaleRecords(newRecords(), "0")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must be non-zero")
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		scaled, err := ScaleRecords(nil, "0.9")
+
+		require.NoError(t, err)
+		assert.Empty(t, scaled)
+	})
+
+	t.Run("scaling twice keeps original quantity", func(t *testing.T) {
+		once, err := ScaleRecords(newRecords(), "0.5")
+		require.NoError(t, err)
+
+		twice, err := ScaleRecords(once, "4")
+
+		require.NoError(t, err)
+		assert.Equal(t, &specs.ScalingSpec{OriginalQuantity: "100", Factor: "2.0", AdjustedQuantity: "200.0"}, twice[0].Observations[0].Scaling)
+	})
+
+	t.Run("recomputes content hash", func(t *testing.T) {
+		records := newRecords()
+		original, err := NewMeterRecord(records[0])
+		require.NoError(t, err)
+		records[0].ContentHash = original.ContentHash()
+
+		scaled, err := ScaleRecords(records, "0.9")
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, scaled[0].ContentHash)
+		assert.NotEqual(t, records[0].ContentHash, scaled[0].ContentHash)
+	})
+}
+
+func TestScaleReadings(t *testing.T) {
+	readings := []specs.MeterReadingSpec{{
+		ID:                      "reading-1",
+		ComputedValues:          []specs.ComputedValueSpec{{Quantity: "1000", Unit: "tokens", Aggregation: "sum"}},
+		RecordCount:             42,
+		ConfidenceIntervalLower: "900",
+		ConfidenceIntervalUpper: "1100",
+		ConfidenceLevel:         "0.95",
+	}}
+
+	t.Run("factor 0.9", func(t *testing.T) {
+		scaled, err := ScaleReadings(readings, "0.9")
+
+		require.NoError(t, err)
+		require.Len(t, scaled, 1)
+		assert.Equal(t, "900.0", scaled[0].ComputedValues[0].Quantity)
+		assert.Equal(t, &specs.ScalingSpec{OriginalQuantity: "1000", Factor: "0.9", AdjustedQuantity: "900.0"}, scaled[0].ComputedValues[0].Scaling)
+		assert.Equal(t, 42, scaled[0].RecordCount)
+		assert.Equal(t, "810.0", scaled[0].ConfidenceIntervalLower)
+		assert.Equal(t, "990.0", scaled[0].ConfidenceIntervalUpper)
+		assert.Equal(t, "1000", readings[0].ComputedValues[0].Quantity, "input readings are not modified")
+	})
+
+	t.Run("factor 2.0", func(t *testing.T) {
+		scaled, err := ScaleReadings(readings, "2.0")
+
+		require.NoError(t, err)
+		assert.Equal(t, "2000.0", scaled[0].ComputedValues[0].Quantity)
+	})
+
+	t.Run("negative factor swaps confidence bounds", func(t *testing.T) {
+		scaled, err := ScaleReadings(readings, "-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "-1000", scaled[0].ComputedValues[0].Quantity)
+		assert.Equal(t, "-1100", scaled[0].ConfidenceIntervalLower)
+		assert.Equal(t, "-900", scaled[0].ConfidenceIntervalUpper)
+	})
+
+	t.Run("zero factor returns error", func(t *testing.T) {
+		_, err := ScaleReadings(readings, "0.0")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must be non-zero")
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		scaled, err := ScaleReadings([]specs.MeterReadingSpec{}, "0.9")
+
+		require.NoError(t, err)
+		assert.Empty(t, scaled)
+	})
+}
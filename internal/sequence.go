@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// SequenceGap is a run of missing sequence numbers between two received events.
+type SequenceGap struct {
+	From        int64 // Last sequence number received before the gap
+	To          int64 // First sequence number received after the gap
+	MissedCount int
+}
+
+// SortBySequenceNumber returns the payloads ordered by SequenceNumber. The sort
+// is stable, so duplicates keep their relative order. The input is not modified.
+func SortBySequenceNumber(payloads []specs.EventPayloadSpec) []specs.EventPayloadSpec {
+	sorted := append([]specs.EventPayloadSpec(nil), payloads...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].SequenceNumber < sorted[j].SequenceNumber
+	})
+	return sorted
+}
+
+// DetectSequenceGaps returns the gaps in the payloads' sequence numbers, in
+// order. Payloads may arrive in any order, and duplicates are not gaps. All
+// payloads should come from the same producer.
+func DetectSequenceGaps(payloads []specs.EventPayloadSpec) []SequenceGap {
+	sorted := SortBySequenceNumber(payloads)
+	var gaps []SequenceGap
+	for i := 1; i < len(sorted); i++ {
+		from, to := sorted[i-1].SequenceNumber, sorted[i].SequenceNumber
+		if to-from > 1 {
+			gaps = append(gaps, SequenceGap{From: from, To: to, MissedCount: int(to - from - 1)})
+		}
+	}
+	return gaps
+}
+
+// ValidateSequence returns error if the payloads' sequence numbers have a gap
+// (lost events) or a duplicate (redelivered events).
+func ValidateSequence(payloads []specs.EventPayloadSpec) error {
+	sorted := SortBySequenceNumber(payloads)
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].SequenceNumber == sorted[i-1].SequenceNumber {
+			return fmt.Errorf("duplicate sequence number %d: events %s and %s",
+				sorted[i].SequenceNumber, sorted[i-1].ID, sorted[i].ID)
+		}
+	}
+	if gaps := DetectSequenceGaps(payloads); len(gaps) > 0 {
+		missed := 0
+		for _, gap := range gaps {
+			missed += gap.MissedCount
+		}
+		return fmt.Errorf("sequence has %d gaps with %d missing events, first between %d and %d",
+			len(gaps), missed, gaps[0].From, gaps[0].To)
+	}
+	return nil
+}
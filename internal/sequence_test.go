@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequence(t *testing.T) {
+	payloadsWithSequence := func(sequenceNumbers ...int64) []specs.EventPayloadSpec {
+		payloads := make([]specs.EventPayloadSpec, len(sequenceNumbers))
+		for i, n := range sequenceNumbers {
+			payloads[i] = specs.EventPayloadSpec{ID: fmt.Sprintf("event-%d", i), SequenceNumber: n}
+		}
+		return payloads
+	}
+
+	t.Run("contiguous sequence has no gaps", func(t *testing.T) {
+		payloads := payloadsWithSequence(1, 2, 3, 4)
+
+		assert.Empty(t, DetectSequenceGaps(payloads))
+		assert.NoError(t, ValidateSequence(payloads))
+	})
+
+	t.Run("gap detected between 5 and 7", func(t *testing.T) {
+		payloads := payloadsWithSequence(4, 5, 7, 8)
+
+		gaps := DetectSequenceGaps(payloads)
+
+		assert.Equal(t, []SequenceGap{{From: 5, To: 7, MissedCount: 1}}, gaps)
+		assert.ErrorContains(t, ValidateSequence(payloads), "between 5 and 7")
+	})
+
+	t.Run("out-of-order sequence is sorted with no gaps", func(t *testing.T) {
+		payloads := payloadsWithSequence(3, 1, 4, 2)
+
+		sorted := SortBySequenceNumber(payloads)
+
+		var order []int64
+		for _, p := range sorted {
+			order = append(order, p.SequenceNumber)
+		}
+		assert.Equal(t, []int64{1, 2, 3, 4}, order)
+		assert.Equal(t, int64(3), payloads[0].SequenceNumber, "input must not be modified")
+		assert.Empty(t, DetectSequenceGaps(payloads))
+		assert.NoError(t, ValidateSequence(payloads))
+	})
+
+	t.Run("duplicate sequence numbers are flagged", func(t *testing.T) {
+		payloads := payloadsWithSequence(1, 2, 2, 3)
+
+		assert.Empty(t, DetectSequenceGaps(payloads))
+		assert.ErrorContains(t, ValidateSequence(payloads), "duplicate sequence number 2")
+	})
+
+	t.Run("sort is stable for duplicates", func(t *testing.T) {
+		payloads := payloadsWithSequence(2, 1, 2)
+
+		sorted := SortBySequenceNumber(payloads)
+
+		assert.Equal(t, "event-0", sorted[1].ID)
+		assert.Equal(t, "event-2", sorted[2].ID)
+	})
+}
@@ -0,0 +1,164 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	specs "github.com/chrisconley/metron/specs"
+	"time"
+)
+
+// StreamAggregate aggregates records as they arrive, emitting a reading each
+// time a window closes.
+//
+// config.Window is the first window of the stream; subsequent windows tile
+// forward (and backward) with the same duration. A window closes when a record
+// observed in a later window arrives, or when recordsIn is closed. Only windows
+// that received records produce readings.
+//
+// Records must arrive in window order: a record for an already-closed window
+// is reported on the error channel and stops the stream. Within a window,
+// records may arrive in any order.
+//
+// Every aggregation type Aggregate supports is accepted. The open window's
+// records are held until it closes, so memory is bounded by the records in one
+// window. For time-weighted-avg, the latest record of each closed window is
+// carried into the next window as its lastBeforeWindow. Readings below the
+// config's MinRecordCount are emitted like any other; callers can compare
+// RecordCount to MinRecordCount to flag them.
+//
+// Both channels are closed when the stream ends: after recordsIn is closed and
+// the final window is emitted, after an error, or when ctx is cancelled. The
+// open window is discarded on cancellation.
+func StreamAggregate(
+	ctx context.Context,
+	recordsIn <-chan specs.MeterRecordSpec,
+	config specs.AggregateConfigSpec,
+) (<-chan specs.MeterReadingSpec, <-chan error) {
+	readingsOut := make(chan specs.MeterReadingSpec)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(readingsOut)
+		defer close(errs)
+
+		if err := streamAggregate(ctx, recordsIn, config, readingsOut); err != nil && ctx.Err() == nil {
+			errs <- err
+		}
+	}()
+
+	return readingsOut, errs
+}
+
+func streamAggregate(
+	ctx context.Context,
+	recordsIn <-chan specs.MeterRecordSpec,
+	config specs.AggregateConfigSpec,
+	readingsOut chan<- specs.MeterReadingSpec,
+) error {
+	// Validate config up front so a bad config fails before any records are read
+	validated, err := NewAggregationConfig(config)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	duration := config.Window.End.Sub(config.Window.Start)
+	if duration <= 0 {
+		return fmt.Errorf("invalid config: window must have a positive duration")
+	}
+
+	var (
+		current          int64
+		open             bool
+		buffered         []specs.MeterRecordSpec
+		lastBeforeWindow *specs.MeterRecordSpec
+	)
+
+	emit := func() error {
+		windowConfig := config
+		windowConfig.Window = streamWindow(config.Window.Start, duration, current)
+
+		// A window below MinRecordCount is still emitted, as Aggregate returns it,
+		// so one sparse window does not end the stream
+		reading, err := Aggregate(buffered, lastBeforeWindow, windowConfig)
+		if err != nil && !IsInsufficientDataError(err) {
+			return fmt.Errorf("window %s: %w", windowConfig.Window.Start.Format(time.RFC3339), err)
+		}
+
+		if validated.Aggregation().RequiresLastBeforeWindow() {
+			lastBeforeWindow = latestRecordSpec(buffered)
+		}
+		buffered = nil
+
+		select {
+		case readingsOut <- reading:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case record, ok := <-recordsIn:
+			if !ok {
+				if !open {
+					return nil
+				}
+				return emit()
+			}
+
+			index := streamWindowIndex(config.Window.Start, duration, record.ObservedAt)
+			switch {
+			case !open:
+				current, open = index, true
+			case index < current:
+				return fmt.Errorf("record %s observed at %s belongs to a closed window",
+					record.ID, record.ObservedAt.Format(time.RFC3339))
+			case index > current:
+				if err := emit(); err != nil {
+					return err
+				}
+				// Readings are not carried across gaps of empty windows
+				if index > current+1 {
+					lastBeforeWindow = nil
+				}
+				current = index
+			}
+			buffered = append(buffered, record)
+		}
+	}
+}
+
+// streamWindowIndex returns which window, counting from the window starting at
+// start, contains t. Times before start have negative indexes.
+func streamWindowIndex(start time.Time, duration time.Duration, t time.Time) int64 {
+	offset := t.Sub(start)
+	index := int64(offset / duration)
+	if offset < 0 && offset%duration != 0 {
+		index--
+	}
+	return index
+}
+
+func streamWindow(start time.Time, duration time.Duration, index int64) specs.TimeWindowSpec {
+	windowStart := start.Add(time.Duration(index) * duration)
+	return specs.TimeWindowSpec{
+		Start: windowStart,
+		End:   windowStart.Add(duration),
+	}
+}
+
+func latestRecordSpec(records []specs.MeterRecordSpec) *specs.MeterRecordSpec {
+	if len(records) == 0 {
+		return nil
+	}
+	latest := records[0]
+	for _, record := range records[1:] {
+		if record.ObservedAt.After(latest.ObservedAt) {
+			latest = record
+		}
+	}
+	return &latest
+}
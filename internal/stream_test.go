@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"context"
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamAggregate(t *testing.T) {
+	day := func(d, h int) time.Time {
+		return time.Date(2024, 1, d, h, 0, 0, 0, time.UTC)
+	}
+	dailyConfig := func(aggregation string) specs.AggregateConfigSpec {
+		return specs.AggregateConfigSpec{
+			Aggregation: aggregation,
+			Window:      specs.TimeWindowSpec{Start: day(1, 0), End: day(2, 0)},
+		}
+	}
+	collect := func(readings <-chan specs.MeterReadingSpec, errs <-chan error) ([]specs.MeterReadingSpec, error) {
+		var result []specs.MeterReadingSpec
+		for reading := range readings {
+			result = append(result, reading)
+		}
+		return result, <-errs
+	}
+
+	t.Run("emits one reading per closed window", func(t *testing.T) {
+		in := make(chan specs.MeterRecordSpec, 5)
+		in <- newTestRecordSpec("event-1", "10", "api-calls", day(1, 3))
+		in <- newTestRecordSpec("event-2", "20", "api-calls", day(1, 9))
+		in <- newTestRecordSpec("event-3", "5", "api-calls", day(2, 0))
+		in <- newTestRecordSpec("event-4", "7", "api-calls", day(4, 12))
+		in <- newTestRecordSpec("event-5", "1", "api-calls", day(4, 13))
+		close(in)
+
+		readings, err := collect(StreamAggregate(context.Background(), in, dailyConfig("sum")))
+
+		require.NoError(t, err)
+		require.Len(t, readings, 3)
+		assert.Equal(t, day(1, 0), readings[0].Window.Start)
+		assert.Equal(t, "30", readings[0].ComputedValues[0].Quantity)
+		assert.Equal(t, day(2, 0), readings[1].Window.Start)
+		assert.Equal(t, "5", readings[1].ComputedValues[0].Quantity)
+		assert.Equal(t, day(4, 0), readings[2].Window.Start)
+		assert.Equal(t, day(5, 0), readings[2].Window.End)
+		assert.Equal(t, "8", readings[2].ComputedValues[0].Quantity)
+	})
+
+	t.Run("emits when the next window starts, before input closes", func(t *testing.T) {
+		in := make(chan specs.MeterRecordSpec, 2)
+		readings, _ := StreamAggregate(context.Background(), in, dailyConfig("max"))
+
+		in <- newTestRecordSpec("event-1", "10", "seats", day(1, 3))
+		in <- newTestRecordSpec("event-2", "4", "seats", day(2, 3))
+
+		select {
+		case reading := <-readings:
+			assert.Equal(t, day(1, 0), reading.Window.Start)
+			assert.Equal(t, "10", reading.ComputedValues[0].Quantity)
+		case <-time.After(time.Second):
+			t.Fatal("expected reading for first window")
+		}
+		close(in)
+	})
+
+	t.Run("time-weighted-avg carries the last record into the next window", func(t *testing.T) {
+		in := make(chan specs.MeterRecordSpec, 2)
+		in <- newTestRecordSpec("event-1", "10", "seats", day(1, 0))
+		in <- newTestRecordSpec("event-2", "20", "seats", day(2, 12))
+		close(in)
+
+		readings, err := collect(StreamAggregate(context.Background(), in, dailyConfig("time-weighted-avg")))
+
+		require.NoError(t, err)
+		require.Len(t, readings, 2)
+		first, err := NewDecimal(readings[0].ComputedValues[0].Quantity)
+		require.NoError(t, err)
+		assert.Zero(t, first.Cmp(NewDecimalFromInt64(10)))
+		second, err := NewDecimal(readings[1].ComputedValues[0].Quantity)
+		require.NoError(t, err)
+		assert.Zero(t, second.Cmp(NewDecimalFromInt64(15)))
+	})
+
+	t.Run("window below MinRecordCount is emitted and the stream continues", func(t *testing.T) {
+		in := make(chan specs.MeterRecordSpec, 4)
+		in <- newTestRecordSpec("event-1", "10", "api-calls", day(1, 3))
+		in <- newTestRecordSpec("event-2", "20", "api-calls", day(2, 3))
+		in <- newTestRecordSpec("event-3", "30", "api-calls", day(2, 9))
+		in <- newTestRecordSpec("event-4", "40", "api-calls", day(3, 3))
+		close(in)
+		config := dailyConfig("sum")
+		config.MinRecordCount = 2
+
+		readings, err := collect(StreamAggregate(context.Background(), in, config))
+
+		require.NoError(t, err)
+		require.Len(t, readings, 3)
+		assert.Equal(t, "10", readings[0].ComputedValues[0].Quantity)
+		assert.Equal(t, 1, readings[0].RecordCount)
+		assert.Equal(t, "50", readings[1].ComputedValues[0].Quantity)
+		assert.Equal(t, "40", readings[2].ComputedValues[0].Quantity)
+	})
+
+	t.Run("record for a closed window is an error", func(t *testing.T) {
+		in := make(chan specs.MeterRecordSpec, 3)
+		in <- newTestRecordSpec("event-1", "10", "api-calls", day(1, 3))
+		in <- newTestRecordSpec("event-2", "10", "api-calls", day(2, 3))
+		in <- newTestRecordSpec("event-3", "10", "api-calls", day(1, 4))
+		close(in)
+
+		readings, err := collect(StreamAggregate(context.Background(), in, dailyConfig("sum")))
+
+		assert.ErrorContains(t, err, "closed window")
+		assert.Len(t, readings, 1)
+	})
+
+	t.Run("context cancellation closes the output channels", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		in := make(chan specs.MeterRecordSpec, 1)
+		in <- newTestRecordSpec("event-1", "10", "api-calls", day(1, 3))
+
+		readings, errs := StreamAggregate(ctx, in, dailyConfig("sum"))
+		cancel()
+
+		select {
+		case _, ok := <-readings:
+			assert.False(t, ok)
+		case <-time.After(time.Second):
+			t.Fatal("expected readings channel to close")
+		}
+		_, ok := <-errs
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,215 @@
+// Package testutil provides valid spec fixtures for tests. Each fixture fills
+// every field with a fixed test value and accepts options to override them.
+//
+// It imports only the specs package, so any package's tests can use it.
+package testutil
+
+import (
+	"time"
+
+	"github.com/chrisconley/metron/specs"
+)
+
+// FixtureTime is the default event and observation time: 2024-01-15 14:30 UTC.
+var FixtureTime = time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+
+// Default identity shared by every fixture.
+const (
+	FixtureWorkspaceID = "workspace-test"
+	FixtureUniverseID  = "universe-test"
+	FixtureSubject     = "customer:test"
+)
+
+// EventPayloadOption customizes a FixtureEventPayload.
+type EventPayloadOption func(*specs.EventPayloadSpec)
+
+// FixtureEventPayload returns a valid "api.request" event payload with ID
+// "event-123" at FixtureTime and no properties.
+func FixtureEventPayload(opts ...EventPayloadOption) specs.EventPayloadSpec {
+	spec := specs.EventPayloadSpec{
+		ID:          "event-123",
+		WorkspaceID: FixtureWorkspaceID,
+		UniverseID:  FixtureUniverseID,
+		Type:        "api.request",
+		Subject:     FixtureSubject,
+		Time:        FixtureTime,
+		Properties:  map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(&spec)
+	}
+	return spec
+}
+
+func WithPayloadID(id string) EventPayloadOption {
+	return func(s *specs.EventPayloadSpec) { s.ID = id }
+}
+
+func WithPayloadWorkspaceID(workspaceID string) EventPayloadOption {
+	return func(s *specs.EventPayloadSpec) { s.WorkspaceID = workspaceID }
+}
+
+func WithPayloadType(eventType string) EventPayloadOption {
+	return func(s *specs.EventPayloadSpec) { s.Type = eventType }
+}
+
+func WithPayloadSubject(subject string) EventPayloadOption {
+	return func(s *specs.EventPayloadSpec) { s.Subject = subject }
+}
+
+func WithPayloadTime(t time.Time) EventPayloadOption {
+	return func(s *specs.EventPayloadSpec) { s.Time = t }
+}
+
+func WithPayloadProperties(properties map[string]string) EventPayloadOption {
+	return func(s *specs.EventPayloadSpec) { s.Properties = properties }
+}
+
+// MeterRecordOption customizes a FixtureMeterRecord.
+type MeterRecordOption func(*specs.MeterRecordSpec)
+
+// FixtureObservation returns an instant observation without a time. Passed to
+// WithRecordObservations, it is placed at the record's ObservedAt.
+func FixtureObservation(quantity, unit string) specs.ObservationSpec {
+	return specs.ObservationSpec{Quantity: quantity, Unit: unit}
+}
+
+// FixtureMeterRecord returns a valid meter record with ID and SourceEventID
+// "record-1", observed and metered at FixtureTime, with a single instant
+// observation of 100 "tokens". Observations without a window are placed at
+// the record's ObservedAt.
+func FixtureMeterRecord(opts ...MeterRecordOption) specs.MeterRecordSpec {
+	spec := specs.MeterRecordSpec{
+		ID:            "record-1",
+		WorkspaceID:   FixtureWorkspaceID,
+		UniverseID:    FixtureUniverseID,
+		Subject:       FixtureSubject,
+		ObservedAt:    FixtureTime,
+		Observations:  []specs.ObservationSpec{FixtureObservation("100", "tokens")},
+		SourceEventID: "record-1",
+		MeteredAt:     FixtureTime,
+	}
+	for _, opt := range opts {
+		opt(&spec)
+	}
+
+	observations := make([]specs.ObservationSpec, len(spec.Observations))
+	for i, o := range spec.Observations {
+		if o.Window == (specs.TimeWindowSpec{}) {
+			o.Window = specs.TimeWindowSpec{Start: spec.ObservedAt, End: spec.ObservedAt}
+		}
+		observations[i] = o
+	}
+	spec.Observations = observations
+	return spec
+}
+
+// WithRecordID sets the record's ID and SourceEventID.
+func WithRecordID(id string) MeterRecordOption {
+	return func(s *specs.MeterRecordSpec) {
+		s.ID = id
+		s.SourceEventID = id
+	}
+}
+
+func WithRecordSubject(subject string) MeterRecordOption {
+	return func(s *specs.MeterRecordSpec) { s.Subject = subject }
+}
+
+// WithRecordObservedAt sets the record's ObservedAt and MeteredAt.
+func WithRecordObservedAt(t time.Time) MeterRecordOption {
+	return func(s *specs.MeterRecordSpec) {
+		s.ObservedAt = t
+		s.MeteredAt = t
+	}
+}
+
+func WithRecordObservations(observations ...specs.ObservationSpec) MeterRecordOption {
+	return func(s *specs.MeterRecordSpec) { s.Observations = observations }
+}
+
+func WithRecordDimensions(dimensions map[string]string) MeterRecordOption {
+	return func(s *specs.MeterRecordSpec) { s.Dimensions = dimensions }
+}
+
+// MeterReadingOption customizes a FixtureMeterReading.
+type MeterReadingOption func(*specs.MeterReadingSpec)
+
+// FixtureTimeWindow returns the window [start, end).
+func FixtureTimeWindow(start, end time.Time) specs.TimeWindowSpec {
+	return specs.TimeWindowSpec{Start: start, End: end}
+}
+
+// FixtureMeterReading returns a valid version 1 "sum" reading with ID
+// "reading-1" of 100 "tokens" from one record over January 2024, created at
+// the end of the window.
+func FixtureMeterReading(opts ...MeterReadingOption) specs.MeterReadingSpec {
+	window := FixtureTimeWindow(
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	)
+	spec := specs.MeterReadingSpec{
+		ID:             "reading-1",
+		WorkspaceID:    FixtureWorkspaceID,
+		UniverseID:     FixtureUniverseID,
+		Subject:        FixtureSubject,
+		Window:         window,
+		ComputedValues: []specs.ComputedValueSpec{{Quantity: "100", Unit: "tokens", Aggregation: "sum"}},
+		Unit:           "tokens",
+		Aggregation:    "sum",
+		RecordCount:    1,
+		CreatedAt:      window.End,
+		MaxMeteredAt:   window.End,
+		Version:        1,
+	}
+	for _, opt := range opts {
+		opt(&spec)
+	}
+	return spec
+}
+
+func WithReadingID(id string) MeterReadingOption {
+	return func(s *specs.MeterReadingSpec) { s.ID = id }
+}
+
+func WithReadingSubject(subject string) MeterReadingOption {
+	return func(s *specs.MeterReadingSpec) { s.Subject = subject }
+}
+
+// WithReadingWindow sets the reading's window, and its CreatedAt and
+// MaxMeteredAt to the window's end.
+func WithReadingWindow(window specs.TimeWindowSpec) MeterReadingOption {
+	return func(s *specs.MeterReadingSpec) {
+		s.Window = window
+		s.CreatedAt = window.End
+		s.MaxMeteredAt = window.End
+	}
+}
+
+// WithReadingAggregation sets the aggregation of the reading and each of its
+// computed values.
+func WithReadingAggregation(aggregation string) MeterReadingOption {
+	return func(s *specs.MeterReadingSpec) {
+		s.Aggregation = aggregation
+		values := make([]specs.ComputedValueSpec, len(s.ComputedValues))
+		for i, v := range s.ComputedValues {
+			v.Aggregation = aggregation
+			values[i] = v
+		}
+		s.ComputedValues = values
+	}
+}
+
+// WithReadingValue replaces the reading's computed values with a single value
+// of quantity and unit, under the reading's aggregation, and sets the
+// reading's Unit to unit.
+func WithReadingValue(quantity, unit string) MeterReadingOption {
+	return func(s *specs.MeterReadingSpec) {
+		s.ComputedValues = []specs.ComputedValueSpec{{Quantity: quantity, Unit: unit, Aggregation: s.Aggregation}}
+		s.Unit = unit
+	}
+}
+
+func WithReadingRecordCount(count int) MeterReadingOption {
+	return func(s *specs.MeterReadingSpec) { s.RecordCount = count }
+}
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"fmt"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// NormalizeUnit returns the canonical unit for unit under aliases, or unit
+// itself if it is not an alias. A nil or empty aliases map is a no-op.
+func NormalizeUnit(unit string, aliases map[string]string) string {
+	if canonical, ok := aliases[unit]; ok {
+		return canonical
+	}
+	return unit
+}
+
+// newUnitAliases validates an alias-to-canonical-unit map and returns a copy.
+// Aliases resolve in a single step, so a canonical unit cannot itself be an
+// alias for another unit.
+func newUnitAliases(aliases map[string]string) (map[string]string, error) {
+	if len(aliases) == 0 {
+		return nil, nil
+	}
+
+	result := make(map[string]string, len(aliases))
+	for alias, canonical := range aliases {
+		if alias == "" {
+			return nil, fmt.Errorf("unit alias is required")
+		}
+		if canonical == "" {
+			return nil, fmt.Errorf("unit alias %q: canonical unit is required", alias)
+		}
+		if next, ok := aliases[canonical]; ok && next != canonical {
+			return nil, fmt.Errorf("unit alias %q: canonical unit %q is itself an alias for %q", alias, canonical, next)
+		}
+		result[alias] = canonical
+	}
+	return result, nil
+}
+
+// checkUnitAliasCollisions returns error if two extractions declare
+// different units that resolve to the same canonical unit. Metering names a
+// record by event ID and canonical unit, so both extractions would produce
+// the same record ID. Extractions declaring the same unit are allowed; see
+// MeteringConfigSpec.DeduplicateObservations.
+func checkUnitAliasCollisions(observations []ObservationExtraction, aliases map[string]string) error {
+	if len(aliases) == 0 {
+		return nil
+	}
+	declared := make(map[string]string, len(observations))
+	for i, o := range observations {
+		unit := o.Unit().ToString()
+		canonical := NormalizeUnit(unit, aliases)
+		other, ok := declared[canonical]
+		if !ok {
+			declared[canonical] = unit
+			continue
+		}
+		if other != unit {
+			return fmt.Errorf("observation %d: units %q and %q both resolve to %q", i, other, unit, canonical)
+		}
+	}
+	return nil
+}
+
+// normalizeObservationUnits returns spec with every observation unit replaced
+// by its canonical unit. The observations are copied, so spec's slice is not
+// modified.
+func normalizeObservationUnits(spec specs.MeterRecordSpec, aliases map[string]string) specs.MeterRecordSpec {
+	if len(aliases) == 0 {
+		return spec
+	}
+	observations := make([]specs.ObservationSpec, len(spec.Observations))
+	for i, observation := range spec.Observations {
+		observation.Unit = NormalizeUnit(observation.Unit, aliases)
+		observations[i] = observation
+	}
+	spec.Observations = observations
+	return spec
+}
@@ -0,0 +1,145 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeUnit(t *testing.T) {
+	aliases := map[string]string{"tk": "tokens", "api-tokens": "tokens", "token": "tokens"}
+
+	t.Run("alias resolves to canonical unit", func(t *testing.T) {
+		assert.Equal(t, "tokens", NormalizeUnit("tk", aliases))
+		assert.Equal(t, "tokens", NormalizeUnit("api-tokens", aliases))
+	})
+
+	t.Run("canonical unit is preserved", func(t *testing.T) {
+		assert.Equal(t, "tokens", NormalizeUnit("tokens", aliases))
+	})
+
+	t.Run("unknown unit is preserved", func(t *testing.T) {
+		assert.Equal(t, "api-calls", NormalizeUnit("api-calls", aliases))
+	})
+
+	t.Run("empty aliases map is a no-op", func(t *testing.T) {
+		assert.Equal(t, "tk", NormalizeUnit("tk", map[string]string{}))
+		assert.Equal(t, "tk", NormalizeUnit("tk", nil))
+	})
+}
+
+func TestUnitAliases(t *testing.T) {
+	observedAt := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	aliases := map[string]string{"tk": "tokens", "token": "tokens"}
+
+	t.Run("Meter records canonical unit", func(t *testing.T) {
+		records, err := Meter(specs.EventPayloadSpec{
+			ID:          "event-123",
+			WorkspaceID: "workspace-test",
+			UniverseID:  "universe-test",
+			Type:        "llm.completion",
+			Subject:     "customer:test",
+			Time:        observedAt,
+			Properties:  map[string]string{"tokens": "450", "calls": "1"},
+		}, specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "tokens", Unit: "tk"},
+				{SourceProperty: "calls", Unit: "api-calls"},
+			},
+			UnitAliases: aliases,
+		})
+
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		units := []string{records[0].Observations[0].Unit, records[0].Observations[1].Unit}
+		assert.ElementsMatch(t, []string{"tokens", "api-calls"}, units)
+	})
+
+	t.Run("Aggregate treats aliased records as one unit", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newTestRecordSpec("rec-1", "100", "tokens", observedAt),
+			newTestRecordSpec("rec-2", "50", "tk", observedAt.Add(time.Hour)),
+			newTestRecordSpec("rec-3", "25", "token", observedAt.Add(2*time.Hour)),
+		}
+		config := newTestAggregateConfig("sum")
+		config.UnitAliases = aliases
+
+		reading, err := Aggregate(records, nil, config)
+		require.NoError(t, err)
+		assert.Equal(t, "175", reading.ComputedValues[0].Quantity)
+		assert.Equal(t, "tokens", reading.ComputedValues[0].Unit)
+
+		streamed, err := AggregateFromReader(SliceRecordReader(records), nil, config)
+		require.NoError(t, err)
+		assert.Equal(t, reading.ComputedValues, streamed.ComputedValues)
+		assert.Equal(t, "tk", records[1].Observations[0].Unit, "input records are not modified")
+	})
+
+	t.Run("Aggregate without aliases rejects mixed aliases", func(t *testing.T) {
+		records := []specs.MeterRecordSpec{
+			newTestRecordSpec("rec-1", "100", "tokens", observedAt),
+			newTestRecordSpec("rec-2", "50", "tk", observedAt.Add(time.Hour)),
+		}
+
+		_, err := Aggregate(records, nil, newTestAggregateConfig("sum"))
+
+		assert.ErrorIs(t, err, ErrMixedUnits)
+	})
+
+	t.Run("rejects chained aliases", func(t *testing.T) {
+		_, err := NewMeteringConfig(specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{{SourceProperty: "tokens", Unit: "tk"}},
+			UnitAliases:  map[string]string{"tk": "token", "token": "tokens"},
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is itself an alias")
+	})
+
+	t.Run("rejects extractions whose units resolve to the same canonical unit", func(t *testing.T) {
+		_, err := NewMeteringConfig(specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "input_tokens", Unit: "tk"},
+				{SourceProperty: "output_tokens", Unit: "api-tokens"},
+			},
+			UnitAliases: map[string]string{"tk": "tokens", "api-tokens": "tokens"},
+		})
+
+		assert.ErrorContains(t, err, `units "tk" and "api-tokens" both resolve to "tokens"`)
+	})
+
+	t.Run("allows extractions declaring the same aliased unit", func(t *testing.T) {
+		_, err := NewMeteringConfig(specs.MeteringConfigSpec{
+			Observations: []specs.ObservationExtractionSpec{
+				{SourceProperty: "input_tokens", Unit: "tk"},
+				{SourceProperty: "output_tokens", Unit: "tk"},
+			},
+			UnitAliases: map[string]string{"tk": "tokens"},
+		})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects empty canonical unit", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.UnitAliases = map[string]string{"tk": ""}
+
+		_, err := NewAggregationConfig(config)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "canonical unit is required")
+	})
+
+	t.Run("merged configs combine aliases with override winning", func(t *testing.T) {
+		merged, err := MergeConfigs(
+			specs.MeteringConfigSpec{UnitAliases: map[string]string{"tk": "tokens", "req": "requests"}},
+			specs.MeteringConfigSpec{UnitAliases: map[string]string{"req": "api-calls"}},
+		)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"tk": "tokens", "req": "api-calls"}, merged.UnitAliases)
+	})
+}
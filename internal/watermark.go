@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// IsSortedByMeteredAt reports whether records are in non-decreasing MeteredAt
+// order, as an incremental processor expects when it advances its watermark
+// record by record.
+func IsSortedByMeteredAt(records []specs.MeterRecordSpec) bool {
+	for i := 1; i < len(records); i++ {
+		if records[i].MeteredAt.Before(records[i-1].MeteredAt) {
+			return false
+		}
+	}
+	return true
+}
+
+// GroupByMeteredAtWindow buckets records by MeteredAt into consecutive windows
+// of windowSize, so an incremental processor can checkpoint one bucket at a
+// time.
+//
+// Windows are aligned to the Unix epoch and keyed in UTC, so the same record
+// always lands in the same window. Records within a bucket keep their input
+// order. Returns an empty map if windowSize is not positive.
+func GroupByMeteredAtWindow(records []specs.MeterRecordSpec, windowSize time.Duration) map[specs.TimeWindowSpec][]specs.MeterRecordSpec {
+	groups := make(map[specs.TimeWindowSpec][]specs.MeterRecordSpec)
+	if windowSize <= 0 {
+		return groups
+	}
+
+	for _, record := range records {
+		start := record.MeteredAt.UTC().Truncate(windowSize)
+		window := specs.TimeWindowSpec{Start: start, End: start.Add(windowSize)}
+		groups[window] = append(groups[window], record)
+	}
+	return groups
+}
+
+// MaxMeteredAt returns the latest MeteredAt among records, the watermark to
+// checkpoint after processing them. Returns error if records is empty.
+func MaxMeteredAt(records []specs.MeterRecordSpec) (time.Time, error) {
+	if len(records) == 0 {
+		return time.Time{}, fmt.Errorf("cannot find max metered at of empty records")
+	}
+
+	maxMeteredAt := records[0].MeteredAt
+	for _, record := range records[1:] {
+		if record.MeteredAt.After(maxMeteredAt) {
+			maxMeteredAt = record.MeteredAt
+		}
+	}
+	return maxMeteredAt, nil
+}
+
+// FilterByMeteredAtRange returns the records metered in [from, to), preserving
+// order. from is inclusive and to exclusive, so consecutive ranges sharing a
+// boundary never return the same record twice.
+func FilterByMeteredAtRange(records []specs.MeterRecordSpec, from, to time.Time) []specs.MeterRecordSpec {
+	result := make([]specs.MeterRecordSpec, 0, len(records))
+	for _, record := range records {
+		if !record.MeteredAt.Before(from) && record.MeteredAt.Before(to) {
+			result = append(result, record)
+		}
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeteredAtWatermarks(t *testing.T) {
+	base := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	meteredAt := func(id string, offset time.Duration) specs.MeterRecordSpec {
+		record := newTestRecordSpec(id, "1", "api-calls", base)
+		record.MeteredAt = base.Add(offset)
+		return record
+	}
+	records := []specs.MeterRecordSpec{
+		meteredAt("rec-1", 5*time.Second),
+		meteredAt("rec-2", 59*time.Second),
+		meteredAt("rec-3", 60*time.Second),
+		meteredAt("rec-4", 150*time.Second),
+	}
+
+	t.Run("groups into 60-second buckets", func(t *testing.T) {
+		groups := GroupByMeteredAtWindow(records, time.Minute)
+
+		require.Len(t, groups, 3)
+		first := specs.TimeWindowSpec{Start: base, End: base.Add(time.Minute)}
+		second := specs.TimeWindowSpec{Start: base.Add(time.Minute), End: base.Add(2 * time.Minute)}
+		third := specs.TimeWindowSpec{Start: base.Add(2 * time.Minute), End: base.Add(3 * time.Minute)}
+		assert.Equal(t, []specs.MeterRecordSpec{records[0], records[1]}, groups[first])
+		assert.Equal(t, []specs.MeterRecordSpec{records[2]}, groups[second])
+		assert.Equal(t, []specs.MeterRecordSpec{records[3]}, groups[third])
+	})
+
+	t.Run("sorted by metered at", func(t *testing.T) {
+		assert.True(t, IsSortedByMeteredAt(records))
+		assert.True(t, IsSortedByMeteredAt(nil))
+		assert.False(t, IsSortedByMeteredAt([]specs.MeterRecordSpec{records[2], records[0]}))
+	})
+
+	t.Run("max metered at", func(t *testing.T) {
+		max, err := MaxMeteredAt(records)
+
+		require.NoError(t, err)
+		assert.Equal(t, base.Add(150*time.Second), max)
+	})
+
+	t.Run("max of empty returns error", func(t *testing.T) {
+		_, err := MaxMeteredAt(nil)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("range includes from and excludes to", func(t *testing.T) {
+		filtered := FilterByMeteredAtRange(records, base.Add(59*time.Second), base.Add(150*time.Second))
+
+		assert.Equal(t, []specs.MeterRecordSpec{records[1], records[2]}, filtered)
+	})
+}
@@ -0,0 +1,195 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	specs "github.com/chrisconley/metron/specs"
+)
+
+// WindowSequence returns consecutive, non-overlapping windows of step size
+// covering [start, end), for batch pipelines that compute one reading per
+// window (e.g. 24*time.Hour over a billing month yields 28-31 daily windows).
+//
+// If step does not evenly divide the range, the final window is shortened to
+// end at end, so the sequence never extends past the requested range.
+//
+// Returns error if step is not positive or start is not before end.
+func WindowSequence(start, end time.Time, step time.Duration) ([]specs.TimeWindowSpec, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be positive, got %s", step)
+	}
+	if !start.Before(end) {
+		return nil, fmt.Errorf("start must be before end")
+	}
+
+	return windowSequence(start, end, func(t time.Time) time.Time {
+		return t.Add(step)
+	}), nil
+}
+
+// CalendarWindowSequence returns consecutive windows covering [start, end)
+// whose boundaries fall on calendar hours, days, or months in start's
+// location. Unlike WindowSequence, daily and monthly windows follow the
+// calendar, so months have their real length (28-31 days) and days spanning
+// a DST change are 23 or 25 hours.
+//
+// If start is not on a boundary, the first window runs from start to the next
+// boundary. If end is not on a boundary, the final window is shortened to end.
+//
+// Returns error if start is not before end.
+func CalendarWindowSequence(start, end time.Time, unit CalendarUnit) ([]specs.TimeWindowSpec, error) {
+	if unit.value == "" {
+		return nil, fmt.Errorf("calendar unit is required")
+	}
+	if !start.Before(end) {
+		return nil, fmt.Errorf("start must be before end")
+	}
+
+	return windowSequence(start, end, unit.nextBoundary), nil
+}
+
+// CalendarWindowSequenceWithOrigin is CalendarWindowSequence for billing
+// periods that begin origin after local midnight rather than at it: with a
+// 9*time.Hour origin, daily windows run from 09:00 to 09:00 the next day and
+// monthly windows from 09:00 on the first. Hourly boundaries are unaffected.
+//
+// A negative origin is taken modulo 24 hours, so -3*time.Hour is 21:00.
+// Returns error if origin is 24 hours or more in either direction, or start is
+// not before end.
+func CalendarWindowSequenceWithOrigin(start, end time.Time, unit CalendarUnit, origin time.Duration) ([]specs.TimeWindowSpec, error) {
+	origin, err := NewWindowOrigin(origin)
+	if err != nil {
+		return nil, err
+	}
+	if unit.value == "" {
+		return nil, fmt.Errorf("calendar unit is required")
+	}
+	if !start.Before(end) {
+		return nil, fmt.Errorf("start must be before end")
+	}
+
+	return windowSequence(start, end, func(t time.Time) time.Time {
+		return unit.nextBoundaryFrom(t, origin)
+	}), nil
+}
+
+// DailyWindowWithOrigin returns the daily window for date's calendar day in
+// loc, running from origin after local midnight to the same wall clock time
+// the next day. A nil loc means UTC. The origin is a wall clock offset, so a
+// window spanning a daylight saving change is 23 or 25 hours long.
+//
+// A negative origin is taken modulo 24 hours. Returns error if origin is 24
+// hours or more in either direction.
+func DailyWindowWithOrigin(date time.Time, origin time.Duration, loc *time.Location) (TimeWindow, error) {
+	origin, err := NewWindowOrigin(origin)
+	if err != nil {
+		return TimeWindow{}, err
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	year, month, day := date.In(loc).Date()
+	return NewTimeWindow(specs.TimeWindowSpec{
+		Start: originAfterMidnight(year, month, day, origin, loc),
+		End:   originAfterMidnight(year, month, day+1, origin, loc),
+	})
+}
+
+// NewWindowOrigin validates an offset from midnight at which billing periods
+// begin, returning it in [0, 24h). Negative origins are taken modulo 24 hours.
+// Returns error if origin is 24 hours or more in either direction.
+func NewWindowOrigin(origin time.Duration) (time.Duration, error) {
+	if origin >= 24*time.Hour || origin <= -24*time.Hour {
+		return 0, fmt.Errorf("window origin must be less than 24h from midnight, got %s", origin)
+	}
+	if origin < 0 {
+		origin += 24 * time.Hour
+	}
+	return origin, nil
+}
+
+// originAfterMidnight returns the wall clock time origin after midnight on the
+// given day in loc.
+func originAfterMidnight(year int, month time.Month, day int, origin time.Duration, loc *time.Location) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, int(origin), loc)
+}
+
+// LocalMonthlyWindow returns the window covering the given calendar month in
+// the IANA timezone tz, from local midnight on the first to local midnight on
+// the first of the next month. For "America/Los_Angeles", January 2024 runs
+// from 08:00 UTC on January 1 to 08:00 UTC on February 1; months that cross a
+// daylight saving change are an hour shorter or longer than in UTC.
+//
+// Returns error if tz is not a known timezone name.
+func LocalMonthlyWindow(year int, month time.Month, tz string) (specs.TimeWindowSpec, error) {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return specs.TimeWindowSpec{}, fmt.Errorf("invalid timezone %q: %w", tz, err)
+	}
+	start := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	return specs.TimeWindowSpec{Start: start, End: start.AddDate(0, 1, 0)}, nil
+}
+
+// windowSequence builds windows from start, advancing each boundary with next,
+// until end. The final window is clipped to end.
+func windowSequence(start, end time.Time, next func(time.Time) time.Time) []specs.TimeWindowSpec {
+	var windows []specs.TimeWindowSpec
+	for windowStart := start; windowStart.Before(end); {
+		windowEnd := next(windowStart)
+		if windowEnd.After(end) {
+			windowEnd = end
+		}
+		windows = append(windows, specs.TimeWindowSpec{Start: windowStart, End: windowEnd})
+		windowStart = windowEnd
+	}
+	return windows
+}
+
+// CalendarUnit is the period of a calendar-aligned window sequence.
+type CalendarUnit struct {
+	value string
+}
+
+func NewCalendarUnit(value string) (CalendarUnit, error) {
+	switch value {
+	case "hourly", "daily", "monthly":
+		return CalendarUnit{value: value}, nil
+	case "":
+		return CalendarUnit{}, fmt.Errorf("calendar unit is required")
+	default:
+		return CalendarUnit{}, fmt.Errorf("invalid calendar unit: %q", value)
+	}
+}
+
+func (u CalendarUnit) ToString() string {
+	return u.value
+}
+
+// nextBoundary returns the first calendar boundary strictly after t, in t's
+// location.
+func (u CalendarUnit) nextBoundary(t time.Time) time.Time {
+	return u.nextBoundaryFrom(t, 0)
+}
+
+// nextBoundaryFrom returns the first calendar boundary strictly after t, in
+// t's location, with daily and monthly boundaries at origin after midnight.
+func (u CalendarUnit) nextBoundaryFrom(t time.Time, origin time.Duration) time.Time {
+	year, month, day := t.Date()
+	loc := t.Location()
+	switch u.value {
+	case "hourly":
+		// Elapsed-time arithmetic keeps repeated DST hours as separate windows.
+		return t.Truncate(time.Hour).Add(time.Hour)
+	case "daily":
+		if boundary := originAfterMidnight(year, month, day, origin, loc); boundary.After(t) {
+			return boundary
+		}
+		return originAfterMidnight(year, month, day+1, origin, loc)
+	default:
+		if boundary := originAfterMidnight(year, month, 1, origin, loc); boundary.After(t) {
+			return boundary
+		}
+		return originAfterMidnight(year, month+1, 1, origin, loc)
+	}
+}
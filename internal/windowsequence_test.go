@@ -0,0 +1,271 @@
+package internal
+
+import (
+	"github.com/chrisconley/metron/specs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowSequence(t *testing.T) {
+	t.Run("exact fit produces one window per step", func(t *testing.T) {
+		start := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+		end := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+		windows, err := WindowSequence(start, end, 24*time.Hour)
+
+		require.NoError(t, err)
+		require.Len(t, windows, 29)
+		assert.Equal(t, start, windows[0].Start)
+		assert.Equal(t, end, windows[28].End)
+		for i := 1; i < len(windows); i++ {
+			assert.Equal(t, windows[i-1].End, windows[i].Start)
+		}
+	})
+
+	t.Run("uneven range shortens final window", func(t *testing.T) {
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		end := start.Add(150 * time.Minute)
+
+		windows, err := WindowSequence(start, end, time.Hour)
+
+		require.NoError(t, err)
+		assert.Equal(t, []specs.TimeWindowSpec{
+			{Start: start, End: start.Add(time.Hour)},
+			{Start: start.Add(time.Hour), End: start.Add(2 * time.Hour)},
+			{Start: start.Add(2 * time.Hour), End: end},
+		}, windows)
+	})
+
+	t.Run("rejects non-positive step", func(t *testing.T) {
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		_, err := WindowSequence(start, start.Add(time.Hour), 0)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects start not before end", func(t *testing.T) {
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		_, err := WindowSequence(start, start, time.Hour)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestCalendarWindowSequence(t *testing.T) {
+	t.Run("monthly sequence across year boundary", func(t *testing.T) {
+		unit, err := NewCalendarUnit("monthly")
+		require.NoError(t, err)
+		start := time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC)
+		end := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+		windows, err := CalendarWindowSequence(start, end, unit)
+
+		require.NoError(t, err)
+		require.Len(t, windows, 4)
+		var days []int
+		for _, window := range windows {
+			days = append(days, int(window.End.Sub(window.Start).Hours()/24))
+		}
+		assert.Equal(t, []int{30, 31, 31, 29}, days)
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), windows[2].Start)
+	})
+
+	t.Run("unaligned start runs to next boundary", func(t *testing.T) {
+		unit, err := NewCalendarUnit("daily")
+		require.NoError(t, err)
+		start := time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC)
+		end := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+
+		windows, err := CalendarWindowSequence(start, end, unit)
+
+		require.NoError(t, err)
+		assert.Equal(t, []specs.TimeWindowSpec{
+			{Start: start, End: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+			{Start: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), End: end},
+		}, windows)
+	})
+
+	t.Run("hourly sequence", func(t *testing.T) {
+		unit, err := NewCalendarUnit("hourly")
+		require.NoError(t, err)
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		windows, err := CalendarWindowSequence(start, start.Add(24*time.Hour), unit)
+
+		require.NoError(t, err)
+		assert.Len(t, windows, 24)
+	})
+
+	t.Run("rejects invalid unit", func(t *testing.T) {
+		_, err := NewCalendarUnit("weekly")
+
+		assert.Error(t, err)
+	})
+}
+
+func TestLocalMonthlyWindow(t *testing.T) {
+	t.Run("UTC month", func(t *testing.T) {
+		window, err := LocalMonthlyWindow(2024, time.January, "UTC")
+
+		require.NoError(t, err)
+		assert.True(t, window.Start.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+		assert.True(t, window.End.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("US/Pacific month spanning DST start", func(t *testing.T) {
+		window, err := LocalMonthlyWindow(2024, time.March, "America/Los_Angeles")
+
+		require.NoError(t, err)
+		assert.True(t, window.Start.Equal(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)), "PST is UTC-8")
+		assert.True(t, window.End.Equal(time.Date(2024, 4, 1, 7, 0, 0, 0, time.UTC)), "PDT is UTC-7")
+		assert.Equal(t, 31*24*time.Hour-time.Hour, window.End.Sub(window.Start))
+	})
+
+	t.Run("invalid timezone returns error", func(t *testing.T) {
+		_, err := LocalMonthlyWindow(2024, time.January, "Not/A_Zone")
+
+		require.Error(t, err)
+	})
+
+	t.Run("aggregate stores output timezone on the reading", func(t *testing.T) {
+		window, err := LocalMonthlyWindow(2024, time.January, "America/Los_Angeles")
+		require.NoError(t, err)
+		config := specs.AggregateConfigSpec{Aggregation: "sum", Window: window, OutputTimezone: "America/Los_Angeles"}
+		records := []specs.MeterRecordSpec{
+			newTestRecordSpec("event-1", "100", "tokens", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)),
+		}
+
+		reading, err := Aggregate(records, nil, config)
+
+		require.NoError(t, err)
+		assert.Equal(t, "America/Los_Angeles", reading.Timezone)
+		assert.Contains(t, reading.Format(), "Period: Jan 2024")
+		roundTripped, err := NewMeterReading(reading)
+		require.NoError(t, err)
+		assert.Equal(t, "America/Los_Angeles", roundTripped.Timezone)
+	})
+
+	t.Run("aggregate rejects invalid output timezone", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.OutputTimezone = "Not/A_Zone"
+
+		_, err := NewAggregationConfig(config)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid output timezone")
+	})
+}
+
+func TestDailyWindowWithOrigin(t *testing.T) {
+	date := time.Date(2024, 1, 15, 14, 0, 0, 0, time.UTC)
+
+	t.Run("standard midnight", func(t *testing.T) {
+		window, err := DailyWindowWithOrigin(date, 0, time.UTC)
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), window.Start().ToTime())
+		assert.Equal(t, time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC), window.End().ToTime())
+	})
+
+	t.Run("9am origin", func(t *testing.T) {
+		window, err := DailyWindowWithOrigin(date, 9*time.Hour, time.UTC)
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC), window.Start().ToTime())
+		assert.Equal(t, time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC), window.End().ToTime())
+	})
+
+	t.Run("negative origin is taken modulo 24h", func(t *testing.T) {
+		window, err := DailyWindowWithOrigin(date, -3*time.Hour, time.UTC)
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 1, 15, 21, 0, 0, 0, time.UTC), window.Start().ToTime())
+		assert.Equal(t, time.Date(2024, 1, 16, 21, 0, 0, 0, time.UTC), window.End().ToTime())
+	})
+
+	t.Run("origin of 24h or more returns error", func(t *testing.T) {
+		_, err := DailyWindowWithOrigin(date, 25*time.Hour, time.UTC)
+		assert.ErrorContains(t, err, "window origin must be less than 24h")
+
+		_, err = DailyWindowWithOrigin(date, -24*time.Hour, time.UTC)
+		assert.Error(t, err)
+	})
+
+	t.Run("origin is a wall clock time across DST", func(t *testing.T) {
+		newYork, err := time.LoadLocation("America/New_York")
+		require.NoError(t, err)
+
+		window, err := DailyWindowWithOrigin(time.Date(2024, 3, 9, 12, 0, 0, 0, newYork), 9*time.Hour, newYork)
+
+		require.NoError(t, err)
+		assert.Equal(t, 9, window.Start().ToTime().Hour())
+		assert.Equal(t, 9, window.End().ToTime().Hour())
+		assert.Equal(t, 23*time.Hour, window.End().ToTime().Sub(window.Start().ToTime()))
+	})
+}
+
+func TestCalendarWindowSequenceWithOrigin(t *testing.T) {
+	t.Run("daily boundaries shift by origin", func(t *testing.T) {
+		daily, err := NewCalendarUnit("daily")
+		require.NoError(t, err)
+
+		windows, err := CalendarWindowSequenceWithOrigin(
+			time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC), daily, 9*time.Hour)
+
+		require.NoError(t, err)
+		assert.Equal(t, []specs.TimeWindowSpec{
+			{Start: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), End: time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)},
+			{Start: time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC), End: time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC)},
+		}, windows)
+	})
+
+	t.Run("monthly boundaries shift by origin", func(t *testing.T) {
+		monthly, err := NewCalendarUnit("monthly")
+		require.NoError(t, err)
+
+		windows, err := CalendarWindowSequenceWithOrigin(
+			time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC), monthly, 9*time.Hour)
+
+		require.NoError(t, err)
+		require.Len(t, windows, 3)
+		assert.Equal(t, time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), windows[0].End)
+		assert.Equal(t, time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC), windows[1].End)
+	})
+
+	t.Run("aggregate config validates origin", func(t *testing.T) {
+		config := newTestAggregateConfig("sum")
+		config.WindowOrigin = -3 * time.Hour
+		validated, err := NewAggregationConfig(config)
+		require.NoError(t, err)
+		assert.Equal(t, 21*time.Hour, validated.WindowOrigin())
+
+		config.WindowOrigin = 24 * time.Hour
+		_, err = NewAggregationConfig(config)
+		assert.ErrorContains(t, err, "invalid window origin")
+	})
+
+	t.Run("aggregate config windows follow its origin and timezone", func(t *testing.T) {
+		daily, err := NewCalendarUnit("daily")
+		require.NoError(t, err)
+		newYork, err := time.LoadLocation("America/New_York")
+		require.NoError(t, err)
+		spec := newTestAggregateConfig("sum")
+		spec.WindowOrigin = 9 * time.Hour
+		spec.OutputTimezone = "America/New_York"
+		config, err := NewAggregationConfig(spec)
+		require.NoError(t, err)
+
+		windows, err := config.CalendarWindowSequence(
+			time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC), time.Date(2024, 1, 3, 14, 0, 0, 0, time.UTC), daily)
+
+		require.NoError(t, err)
+		require.Len(t, windows, 2)
+		assert.True(t, time.Date(2024, 1, 2, 9, 0, 0, 0, newYork).Equal(windows[0].End))
+		assert.True(t, time.Date(2024, 1, 3, 9, 0, 0, 0, newYork).Equal(windows[1].End))
+	})
+}
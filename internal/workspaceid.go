@@ -0,0 +1,100 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// WorkspaceIDValidator checks that a workspace ID has the format a deployment
+// expects, such as a UUID or a prefixed ID.
+type WorkspaceIDValidator interface {
+	Validate(id string) error
+}
+
+// WorkspaceIDValidatorFunc adapts a function to a WorkspaceIDValidator.
+type WorkspaceIDValidatorFunc func(id string) error
+
+func (f WorkspaceIDValidatorFunc) Validate(id string) error {
+	return f(id)
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// UUIDWorkspaceIDValidator accepts workspace IDs in canonical 8-4-4-4-12
+// hexadecimal UUID form.
+var UUIDWorkspaceIDValidator WorkspaceIDValidator = WorkspaceIDValidatorFunc(func(id string) error {
+	if !uuidPattern.MatchString(id) {
+		return fmt.Errorf("workspace ID %q is not a UUID", id)
+	}
+	return nil
+})
+
+// NumericWorkspaceIDValidator accepts workspace IDs made only of ASCII digits.
+var NumericWorkspaceIDValidator WorkspaceIDValidator = WorkspaceIDValidatorFunc(func(id string) error {
+	for _, r := range id {
+		if r < '0' || r > '9' {
+			return fmt.Errorf("workspace ID %q is not numeric", id)
+		}
+	}
+	return nil
+})
+
+// PrefixedWorkspaceIDValidator accepts workspace IDs that start with prefix
+// followed by at least one character, such as "ws_a1b2c3d4" for prefix "ws_".
+func PrefixedWorkspaceIDValidator(prefix string) WorkspaceIDValidator {
+	return WorkspaceIDValidatorFunc(func(id string) error {
+		if !strings.HasPrefix(id, prefix) || len(id) == len(prefix) {
+			return fmt.Errorf("workspace ID %q does not have prefix %q", id, prefix)
+		}
+		return nil
+	})
+}
+
+// workspaceIDValidators are applied by NewEventPayloadWorkspaceID. None are
+// registered by default, so any non-empty ID is accepted.
+var (
+	workspaceIDValidatorsMu sync.RWMutex
+	workspaceIDValidators   []WorkspaceIDValidator
+)
+
+// RegisterWorkspaceIDValidator adds v to the validators every workspace ID
+// must pass. A nil v is ignored. Safe for concurrent use.
+func RegisterWorkspaceIDValidator(v WorkspaceIDValidator) {
+	if v == nil {
+		return
+	}
+	workspaceIDValidatorsMu.Lock()
+	defer workspaceIDValidatorsMu.Unlock()
+	workspaceIDValidators = append(workspaceIDValidators, v)
+}
+
+// SetWorkspaceIDValidator replaces all registered validators with v. A nil v
+// removes validation. Safe for concurrent use.
+func SetWorkspaceIDValidator(v WorkspaceIDValidator) {
+	workspaceIDValidatorsMu.Lock()
+	defer workspaceIDValidatorsMu.Unlock()
+	workspaceIDValidators = nil
+	if v != nil {
+		workspaceIDValidators = []WorkspaceIDValidator{v}
+	}
+}
+
+// ResetWorkspaceIDValidator removes all registered validators, restoring the
+// default of accepting any non-empty workspace ID. Intended for test cleanup.
+func ResetWorkspaceIDValidator() {
+	SetWorkspaceIDValidator(nil)
+}
+
+// validateWorkspaceID runs id through every registered validator.
+func validateWorkspaceID(id string) error {
+	workspaceIDValidatorsMu.RLock()
+	defer workspaceIDValidatorsMu.RUnlock()
+	for _, v := range workspaceIDValidators {
+		if err := v.Validate(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withWorkspaceIDValidator sets the workspace ID validator for the duration of the test.
+func withWorkspaceIDValidator(t *testing.T, v WorkspaceIDValidator) {
+	t.Helper()
+	SetWorkspaceIDValidator(v)
+	t.Cleanup(ResetWorkspaceIDValidator)
+}
+
+func TestWorkspaceIDValidator(t *testing.T) {
+	t.Run("UUID format accepted", func(t *testing.T) {
+		withWorkspaceIDValidator(t, UUIDWorkspaceIDValidator)
+
+		_, err := NewEventPayloadWorkspaceID("550e8400-e29b-41d4-a716-446655440000")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("UUID format rejected", func(t *testing.T) {
+		withWorkspaceIDValidator(t, UUIDWorkspaceIDValidator)
+
+		_, err := NewEventPayloadWorkspaceID("workspace-test")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is not a UUID")
+	})
+
+	t.Run("prefix format", func(t *testing.T) {
+		withWorkspaceIDValidator(t, PrefixedWorkspaceIDValidator("ws_"))
+
+		_, err := NewEventPayloadWorkspaceID("ws_a1b2c3d4")
+		assert.NoError(t, err)
+
+		_, err = NewEventPayloadWorkspaceID("a1b2c3d4")
+		assert.ErrorContains(t, err, `does not have prefix "ws_"`)
+
+		_, err = NewEventPayloadWorkspaceID("ws_")
+		assert.Error(t, err)
+	})
+
+	t.Run("numeric format", func(t *testing.T) {
+		withWorkspaceIDValidator(t, NumericWorkspaceIDValidator)
+
+		_, err := NewEventPayloadWorkspaceID("10042")
+		assert.NoError(t, err)
+
+		_, err = NewEventPayloadWorkspaceID("10042a")
+		assert.ErrorContains(t, err, "is not numeric")
+	})
+
+	t.Run("nil validator passes all", func(t *testing.T) {
+		withWorkspaceIDValidator(t, nil)
+
+		_, err := NewEventPayloadWorkspaceID("anything goes")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("registered validators must all pass", func(t *testing.T) {
+		withWorkspaceIDValidator(t, PrefixedWorkspaceIDValidator("ws_"))
+		RegisterWorkspaceIDValidator(WorkspaceIDValidatorFunc(func(id string) error {
+			if len(id) > 8 {
+				return fmt.Errorf("workspace ID %q is too long", id)
+			}
+			return nil
+		}))
+
+		_, err := NewEventPayloadWorkspaceID("ws_12345")
+		assert.NoError(t, err)
+
+		_, err = NewEventPayloadWorkspaceID("ws_123456")
+		assert.ErrorContains(t, err, "too long")
+	})
+
+	t.Run("concurrent registration is safe", func(t *testing.T) {
+		t.Cleanup(ResetWorkspaceIDValidator)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				RegisterWorkspaceIDValidator(PrefixedWorkspaceIDValidator("ws_"))
+			}()
+			go func() {
+				defer wg.Done()
+				_, _ = NewEventPayloadWorkspaceID("ws_a1b2c3d4")
+			}()
+		}
+		wg.Wait()
+
+		_, err := NewEventPayloadWorkspaceID("ws_a1b2c3d4")
+		assert.NoError(t, err)
+	})
+}
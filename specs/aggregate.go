@@ -1,9 +1,11 @@
 package specs
 
+import "time"
+
 // Aggregate transforms MeterRecords into a MeterReading by applying aggregation over a time window.
 //
 // Process:
-//  1. Apply aggregation type (sum, max, time-weighted-avg, latest, min)
+//  1. Apply aggregation type (sum, max, time-weighted-avg, latest, min, first-non-zero, mode, count, average)
 //  2. For gauges (time-weighted-avg): use lastBeforeWindow to carry forward initial state
 //  3. Compute aggregated measurement
 //  4. Create MeterReading with result
@@ -32,6 +34,14 @@ type AggregateConfigSpec struct {
 	//   - "max": Take the maximum quantity (e.g., peak concurrent connections)
 	//   - "min": Take the minimum quantity
 	//   - "latest": Use the most recent quantity by RecordedAt timestamp
+	//   - "first-non-zero": Use the earliest positive quantity by RecordedAt timestamp
+	//     (e.g., initializing a gauge whose carried-forward state is zero)
+	//   - "mode": Use the most frequent quantity, breaking ties by the smallest
+	//     quantity string (e.g., the predominant plan tier in the window)
+	//   - "count": Use the number of records, ignoring their quantities (e.g.,
+	//     billing per API call regardless of payload size)
+	//   - "average": Use the arithmetic mean of quantities, weighting each record
+	//     equally regardless of time between records (e.g., mean response size)
 	//   - "time-weighted-avg": Compute average weighted by duration between records
 	//     (e.g., average seat count, treating each record as a step function until the next)
 	Aggregation string `json:"aggregation"`
@@ -42,4 +52,145 @@ type AggregateConfigSpec struct {
 	// Only meter records with RecordedAt within this window are included. Typically
 	// corresponds to a billing period (hour, day, month).
 	Window TimeWindowSpec `json:"window"`
+
+	// Optional free quantity subtracted once from the aggregated value.
+	//
+	// Implements per-window free tiers ("the first 1000 API calls each month
+	// are free"). The computed value is floored at zero. Must be a non-negative
+	// decimal string. Empty means no free tier.
+	FreeQuantity string `json:"freeQuantity,omitempty"`
+
+	// Optional maximum for the aggregated value as a decimal string.
+	//
+	// Implements billing caps ("no more than 1M tokens billed per month"). The
+	// computed value is the minimum of the aggregated value and MaxValue, and the
+	// reading's WasCapped flag reports whether the cap took effect. Applied after
+	// FreeQuantity. For "sum", "count", and "time-weighted-avg" this caps billable
+	// usage; for "max", "min", "latest", "first-non-zero", "mode", and "average"
+	// it caps the resulting value, so a capped "max" reports MaxValue rather than
+	// the true peak. Empty means no cap.
+	MaxValue string `json:"maxValue,omitempty"`
+
+	// Optional dimension names to partition records by.
+	//
+	// When set, records are grouped by their values for these dimensions and
+	// each group produces its own reading (e.g., ["model"] yields one reading per
+	// model; ["model", "region"] one per model and region combination present).
+	// Records missing a dimension group under the empty value. Use an
+	// implementation that returns multiple readings (internal.AggregateGrouped in
+	// the reference implementation). Empty means one reading for all records.
+	GroupBy []string `json:"groupBy,omitempty"`
+
+	// Optional operational metadata to set on every output reading.
+	//
+	// Copied to MeterReadingSpec.Metadata, for annotations like
+	// {"pipeline_run_id": "run-42"}. Does not affect aggregation or reading IDs.
+	OutputMetadata map[string]string `json:"outputMetadata,omitempty"`
+
+	// Optional minimum RecordCount for a trustworthy reading.
+	//
+	// A data quality gate for billing-critical aggregations: a reading built
+	// from fewer records may reflect data loss. When RecordCount falls below
+	// MinRecordCount, the reading is still returned along with an error
+	// (internal.InsufficientDataError in the reference implementation), so
+	// callers decide whether to treat it as a warning or a failure. A
+	// carried-forward lastBeforeWindow record counts when the aggregation
+	// uses it. Zero means no minimum.
+	MinRecordCount int `json:"minRecordCount,omitempty"`
+
+	// Whether to record the IDs of contributing records on the reading.
+	//
+	// Populates MeterReadingSpec.SourceRecordIDs for forensic billing audits.
+	// Opt-in because a window may hold millions of records.
+	IncludeSourceIDs bool `json:"includeSourceIDs,omitempty"`
+
+	// Optional limit on the number of SourceRecordIDs stored.
+	//
+	// Only the first MaxSourceIDs IDs in sorted order are kept, and the
+	// reading's SourceRecordIDsTruncated flag is set. Only valid with
+	// IncludeSourceIDs. Zero means no limit.
+	MaxSourceIDs int `json:"maxSourceIDs,omitempty"`
+
+	// Optional map from unit aliases to canonical unit names.
+	//
+	// Applied to input record units before aggregation, so records metered
+	// under different aliases (e.g., "tk" and "tokens") aggregate together
+	// instead of failing as mixed units. Uses the same format as
+	// MeteringConfigSpec.UnitAliases. Empty means units are used as is.
+	UnitAliases map[string]string `json:"unitAliases,omitempty"`
+
+	// Optional drop size, as a decimal string, that marks a counter reset in
+	// a time-weighted-avg.
+	//
+	// Cumulative counters restart from zero when their process restarts. When
+	// a record's quantity is more than ResetDetectionThreshold below the
+	// previous record's, the drop is treated as a reset rather than a real
+	// change: the pre-reset value becomes the baseline that later quantities
+	// are added to, so the counter continues where it left off. The reading's
+	// ResetCount reports how many resets were found. A negative threshold
+	// disables detection. Only valid with "time-weighted-avg". Empty means no
+	// reset detection.
+	ResetDetectionThreshold string `json:"resetDetectionThreshold,omitempty"`
+
+	// How far outside the window a record's ObservedAt may fall and still
+	// be aggregated, absorbing clock skew between producers.
+	//
+	// Records observed up to ClockSkewTolerance before Window.Start or after
+	// Window.End are included; records further out are excluded and reported
+	// as warnings rather than failing the aggregation. Zero means only records
+	// within [Start, End) are aggregated. Must not be negative.
+	ClockSkewTolerance time.Duration `json:"clockSkewTolerance,omitempty"`
+
+	// Whether records outside the window (beyond ClockSkewTolerance) fail the
+	// aggregation instead of being excluded with a warning.
+	StrictMode bool `json:"strictMode,omitempty"`
+
+	// Same as StrictMode: out-of-window records fail the aggregation. Either
+	// field enables it.
+	StrictWindowValidation bool `json:"strictWindowValidation,omitempty"`
+
+	// Optional IANA timezone name to set as the reading's Timezone.
+	//
+	// Records which timezone the window's billing period is defined in, such
+	// as a window built with LocalMonthlyWindow in the reference
+	// implementation. Does not change which records are aggregated. Empty
+	// means UTC.
+	OutputTimezone string `json:"outputTimezone,omitempty"`
+
+	// Optional limit on how many records are aggregated at once.
+	//
+	// Inputs with more records are split into chunks of this size, each
+	// aggregated on its own, and the chunk results combined, bounding the
+	// memory aggregation needs beyond the input itself. Only valid with "sum",
+	// "max", and "min", whose results combine exactly, and without GroupBy.
+	// Zero means no limit.
+	MaxRecordsPerAggregate int `json:"maxRecordsPerAggregate,omitempty"`
+
+	// Optional strategy for assigning the reading's ID: "sha256",
+	// "ulid", or "passthrough".
+	//
+	// "sha256" derives the ID from the subject, unit, window, aggregation,
+	// and group-by dimensions, so recomputing a reading yields the same ID.
+	// "ulid" assigns a new time-ordered ID on each call, letting readings
+	// computed in parallel be inserted in sorted order. "passthrough" uses
+	// OutputID, and is not valid with GroupBy since every group would share
+	// it. Empty means "sha256".
+	OutputIDStrategy string `json:"outputIDStrategy,omitempty"`
+
+	// The reading ID to use with the "passthrough" OutputIDStrategy.
+	//
+	// Required with "passthrough" and only valid with it.
+	OutputID string `json:"outputID,omitempty"`
+
+	// Optional offset from midnight at which daily and monthly billing
+	// periods begin, for periods that do not start at midnight.
+	//
+	// With 9*time.Hour, a daily period runs from 09:00 to 09:00 the next day.
+	// Window sequences generated for the config (CalendarWindowSequence on
+	// the reference implementation's AggregationConfig) have their daily and
+	// monthly boundaries shifted by it, in OutputTimezone. Aggregating one
+	// window still uses Window as given. A negative offset is taken modulo 24
+	// hours. Must be less than 24 hours in either direction. Zero means
+	// midnight.
+	WindowOrigin time.Duration `json:"windowOrigin,omitempty"`
 }
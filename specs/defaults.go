@@ -0,0 +1,48 @@
+package specs
+
+// Default implementations of the spec-level operations.
+//
+// The specs package defines only signatures and primitive types, so it cannot
+// import an implementation without creating an import cycle. Instead, an
+// implementation package registers itself here (the Go reference implementation
+// does so from internal's init), and callers obtain it through DefaultMeter and
+// DefaultAggregate without depending on the implementation directly.
+var (
+	defaultMeter     Meter
+	defaultAggregate Aggregate
+)
+
+// SetDefaultMeter registers the Meter implementation returned by DefaultMeter.
+//
+// Typically called once from an implementation package's init function.
+// Passing nil clears the registration.
+func SetDefaultMeter(fn Meter) {
+	defaultMeter = fn
+}
+
+// DefaultMeter returns the registered Meter implementation.
+//
+// Returns nil if no implementation has been registered. Importing the
+// reference implementation (github.com/chrisconley/metron/internal)
+// registers internal.Meter.
+func DefaultMeter() Meter {
+	return defaultMeter
+}
+
+// SetDefaultAggregate registers the Aggregate implementation returned by
+// DefaultAggregate.
+//
+// Typically called once from an implementation package's init function.
+// Passing nil clears the registration.
+func SetDefaultAggregate(fn Aggregate) {
+	defaultAggregate = fn
+}
+
+// DefaultAggregate returns the registered Aggregate implementation.
+//
+// Returns nil if no implementation has been registered. Importing the
+// reference implementation (github.com/chrisconley/metron/internal)
+// registers internal.Aggregate.
+func DefaultAggregate() Aggregate {
+	return defaultAggregate
+}
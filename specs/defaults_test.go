@@ -0,0 +1,72 @@
+package specs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultMeter(t *testing.T) {
+	t.Run("returns the registered implementation", func(t *testing.T) {
+		previous := DefaultMeter()
+		t.Cleanup(func() { SetDefaultMeter(previous) })
+
+		var called bool
+		SetDefaultMeter(func(payload EventPayloadSpec, config MeteringConfigSpec) ([]MeterRecordSpec, error) {
+			called = true
+			return []MeterRecordSpec{{ID: payload.ID}}, nil
+		})
+
+		records, err := DefaultMeter()(EventPayloadSpec{ID: "event-123"}, MeteringConfigSpec{})
+
+		require.NoError(t, err)
+		assert.True(t, called, "registered meter should be called")
+		require.Len(t, records, 1)
+		assert.Equal(t, "event-123", records[0].ID)
+	})
+
+	t.Run("with nil registration returns nil", func(t *testing.T) {
+		previous := DefaultMeter()
+		t.Cleanup(func() { SetDefaultMeter(previous) })
+
+		SetDefaultMeter(nil)
+
+		assert.Nil(t, DefaultMeter())
+	})
+}
+
+func TestDefaultAggregate(t *testing.T) {
+	t.Run("returns the registered implementation", func(t *testing.T) {
+		previous := DefaultAggregate()
+		t.Cleanup(func() { SetDefaultAggregate(previous) })
+
+		window := TimeWindowSpec{
+			Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		}
+
+		var called bool
+		SetDefaultAggregate(func(recordsInWindow []MeterRecordSpec, lastBeforeWindow *MeterRecordSpec, config AggregateConfigSpec) (MeterReadingSpec, error) {
+			called = true
+			return MeterReadingSpec{Window: config.Window, Aggregation: config.Aggregation}, nil
+		})
+
+		reading, err := DefaultAggregate()(nil, nil, AggregateConfigSpec{Aggregation: "sum", Window: window})
+
+		require.NoError(t, err)
+		assert.True(t, called, "registered aggregate should be called")
+		assert.Equal(t, "sum", reading.Aggregation)
+		assert.Equal(t, window, reading.Window)
+	})
+
+	t.Run("with nil registration returns nil", func(t *testing.T) {
+		previous := DefaultAggregate()
+		t.Cleanup(func() { SetDefaultAggregate(previous) })
+
+		SetDefaultAggregate(nil)
+
+		assert.Nil(t, DefaultAggregate())
+	})
+}
@@ -0,0 +1,37 @@
+package specs
+
+import "errors"
+
+// Sentinel errors returned, wrapped, by the spec helpers, for callers that
+// branch on the kind of failure with errors.Is.
+var (
+	// ErrInvalidSpan is returned by NewSpanObservation when end is not after start.
+	ErrInvalidSpan = errors.New("end must be after start")
+
+	// ErrInvalidTimezone is returned by TimeWindowSpec.InTimezone for an
+	// unknown timezone name.
+	ErrInvalidTimezone = errors.New("invalid timezone")
+
+	// ErrInvalidTimestamp is returned when decoding a JSON timestamp that is
+	// neither a valid time string nor a Unix timestamp.
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+)
+
+// sentinelError reports a failure with its own message while matching both
+// sentinel and the underlying cause, if any, under errors.Is and errors.As.
+type sentinelError struct {
+	sentinel error
+	cause    error
+	message  string
+}
+
+func (e *sentinelError) Error() string {
+	return e.message
+}
+
+func (e *sentinelError) Unwrap() []error {
+	if e.cause == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.cause}
+}
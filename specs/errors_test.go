@@ -0,0 +1,56 @@
+package specs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	t.Run("span observation with end before start", func(t *testing.T) {
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		_, err := NewSpanObservation("1", "seconds", start, start)
+
+		require.Error(t, err)
+		assert.ErrorIs(t, fmt.Errorf("outer: %w", err), ErrInvalidSpan)
+		assert.Contains(t, err.Error(), "span observation: end must be after start (start=")
+	})
+
+	t.Run("unknown timezone", func(t *testing.T) {
+		_, err := TimeWindowSpec{}.InTimezone("Mars/Olympus_Mons")
+
+		assert.ErrorIs(t, err, ErrInvalidTimezone)
+		assert.Contains(t, err.Error(), `invalid timezone "Mars/Olympus_Mons": `)
+	})
+
+	t.Run("invalid timestamp string", func(t *testing.T) {
+		var record MeterRecordSpec
+
+		err := json.Unmarshal([]byte(`{"observedAt": "yesterday"}`), &record)
+
+		assert.ErrorIs(t, err, ErrInvalidTimestamp)
+		assert.Contains(t, err.Error(), `invalid timestamp "yesterday": `)
+	})
+
+	t.Run("invalid unix timestamp keeps its cause", func(t *testing.T) {
+		var record MeterRecordSpec
+
+		err := json.Unmarshal([]byte(`{"observedAt": 1.0000000001}`), &record)
+
+		assert.ErrorIs(t, err, ErrInvalidTimestamp)
+		assert.Contains(t, err.Error(), "invalid unix timestamp 1.0000000001: more than nanosecond precision")
+	})
+
+	t.Run("sentinels are not confused", func(t *testing.T) {
+		_, err := TimeWindowSpec{}.InTimezone("Mars/Olympus_Mons")
+
+		assert.False(t, errors.Is(err, ErrInvalidTimestamp))
+		assert.False(t, errors.Is(err, ErrInvalidSpan))
+	})
+}
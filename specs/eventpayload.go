@@ -1,6 +1,11 @@
 package specs
 
-import "time"
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 // EventPayloadSpec represents a raw usage event submitted for metering.
 //
@@ -78,4 +83,81 @@ type EventPayloadSpec struct {
 	//   - LLM completion: {"model": "gpt-4", "input_tokens": "450", "output_tokens": "890", "cached": "true"}
 	//   - Storage: {"bucket": "prod-assets", "bytes_stored": "1073741824", "region": "us-east-1"}
 	Properties map[string]string `json:"properties,omitempty"`
+
+	// Optional position of this event in its producer's stream.
+	//
+	// Producers number their events consecutively so consumers can process them
+	// in order and detect events lost under at-least-once delivery: a missing
+	// number is a lost event, a repeated one a redelivery. Only comparable among
+	// events from the same producer. Zero means the producer does not number
+	// its events.
+	SequenceNumber int64 `json:"sequenceNumber,omitempty"`
+
+	// Optional ID of the event that caused this one.
+	//
+	// Links child events to their parent in hierarchical workloads, such as the
+	// spans produced while serving a single HTTP request. The parent is another
+	// event in the same workspace and universe. Empty means the event has no
+	// parent.
+	ParentID string `json:"parentID,omitempty"`
+}
+
+// UnmarshalJSON decodes an event payload, accepting property values of any
+// JSON type so producers need not quote numbers.
+//
+// Numbers keep their exact JSON text ("1250" stays "1250", "0.1" stays "0.1")
+// rather than passing through float64. Booleans become "true" or "false",
+// objects and arrays keep their compact JSON text (readable by property
+// paths), and null properties are omitted.
+func (p *EventPayloadSpec) UnmarshalJSON(data []byte) error {
+	type plain EventPayloadSpec
+	aux := struct {
+		*plain
+		Properties map[string]json.RawMessage `json:"properties,omitempty"`
+	}{plain: (*plain)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.Properties == nil {
+		p.Properties = nil
+		return nil
+	}
+
+	properties := make(map[string]string, len(aux.Properties))
+	for key, raw := range aux.Properties {
+		value, ok, err := propertyString(raw)
+		if err != nil {
+			return fmt.Errorf("property %q: %w", key, err)
+		}
+		if ok {
+			properties[key] = value
+		}
+	}
+	p.Properties = properties
+	return nil
+}
+
+// propertyString converts a JSON property value to its string form. Returns
+// false for null.
+func propertyString(raw json.RawMessage) (string, bool, error) {
+	raw = bytes.TrimSpace(raw)
+	switch {
+	case bytes.Equal(raw, []byte("null")):
+		return "", false, nil
+	case len(raw) > 0 && raw[0] == '"':
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return "", false, err
+		}
+		return s, true, nil
+	case len(raw) > 0 && (raw[0] == '{' || raw[0] == '['):
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, raw); err != nil {
+			return "", false, err
+		}
+		return compact.String(), true, nil
+	default:
+		// Numbers and booleans, already validated by json.Unmarshal
+		return string(raw), true, nil
+	}
 }
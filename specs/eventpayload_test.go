@@ -0,0 +1,82 @@
+package specs
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventPayloadSpec_UnmarshalJSON(t *testing.T) {
+	decode := func(t *testing.T, properties string) EventPayloadSpec {
+		t.Helper()
+		data := `{"id": "event-123", "type": "api.request", "subject": "customer:acme",
+			"time": "2024-01-15T14:30:00Z", "properties": ` + properties + `}`
+		var payload EventPayloadSpec
+		require.NoError(t, json.Unmarshal([]byte(data), &payload))
+		return payload
+	}
+
+	t.Run("integer property preserved exactly", func(t *testing.T) {
+		payload := decode(t, `{"tokens": 1250, "bytes": 9007199254740993}`)
+
+		assert.Equal(t, "1250", payload.Properties["tokens"])
+		assert.Equal(t, "9007199254740993", payload.Properties["bytes"])
+	})
+
+	t.Run("float property preserved without rounding", func(t *testing.T) {
+		payload := decode(t, `{"cost": 0.1, "duration": 1.5e3}`)
+
+		assert.Equal(t, "0.1", payload.Properties["cost"])
+		assert.Equal(t, "1.5e3", payload.Properties["duration"])
+	})
+
+	t.Run("string property unchanged", func(t *testing.T) {
+		payload := decode(t, `{"model": "gpt-4", "tokens": "450"}`)
+
+		assert.Equal(t, "gpt-4", payload.Properties["model"])
+		assert.Equal(t, "450", payload.Properties["tokens"])
+	})
+
+	t.Run("boolean property becomes true or false", func(t *testing.T) {
+		payload := decode(t, `{"cached": true, "streamed": false}`)
+
+		assert.Equal(t, "true", payload.Properties["cached"])
+		assert.Equal(t, "false", payload.Properties["streamed"])
+	})
+
+	t.Run("null property is excluded", func(t *testing.T) {
+		payload := decode(t, `{"tokens": 100, "region": null}`)
+
+		assert.NotContains(t, payload.Properties, "region")
+		assert.Len(t, payload.Properties, 1)
+	})
+
+	t.Run("nested object keeps compact JSON", func(t *testing.T) {
+		payload := decode(t, `{"metadata": {"model": "gpt-4", "choices": [1, 2]}}`)
+
+		assert.Equal(t, `{"model":"gpt-4","choices":[1,2]}`, payload.Properties["metadata"])
+	})
+
+	t.Run("other fields decode normally", func(t *testing.T) {
+		payload := decode(t, `{}`)
+
+		assert.Equal(t, "event-123", payload.ID)
+		assert.Equal(t, "customer:acme", payload.Subject)
+		assert.Equal(t, time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC), payload.Time)
+		assert.Empty(t, payload.Properties)
+	})
+
+	t.Run("round-trips through Marshal", func(t *testing.T) {
+		original := EventPayloadSpec{ID: "event-123", Properties: map[string]string{"tokens": "1250"}}
+		data, err := json.Marshal(original)
+		require.NoError(t, err)
+
+		var decoded EventPayloadSpec
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		assert.Equal(t, original, decoded)
+	})
+}
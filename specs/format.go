@@ -0,0 +1,178 @@
+package specs
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format renders the reading for debugging, one line per computed value:
+//
+//	Subject: customer:acme | Unit: tokens | Quantity: 12,500 | Period: Jan 2024 | Aggregation: sum | Records: 1250
+//
+// A reading without computed values renders as a single line with an empty
+// unit and quantity. Not intended for machine consumption; use JSON or
+// FormatCSVRow for that.
+func (r MeterReadingSpec) Format() string {
+	lines := make([]string, 0, len(r.ComputedValues))
+	for _, row := range r.formatRows() {
+		lines = append(lines, fmt.Sprintf(
+			"Subject: %s | Unit: %s | Quantity: %s | Period: %s | Aggregation: %s | Records: %s",
+			row[0], row[1], row[2], row[3], row[4], row[5],
+		))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatCSVRow renders the reading as CSV with the columns listed in
+// MeterReadingCSVColumns. Readings with a single computed value produce a
+// single line; each additional computed value adds a line. Quantities are
+// written without thousands separators and times in RFC 3339 so that rows
+// parse back losslessly.
+func (r MeterReadingSpec) FormatCSVRow() string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	for _, v := range r.valuesOrEmpty() {
+		_ = w.Write([]string{
+			r.ID,
+			r.WorkspaceID,
+			r.UniverseID,
+			r.Subject,
+			v.Unit,
+			v.Quantity,
+			r.Aggregation,
+			r.Window.Start.UTC().Format(time.RFC3339Nano),
+			r.Window.End.UTC().Format(time.RFC3339Nano),
+			strconv.Itoa(r.RecordCount),
+		})
+	}
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// MeterReadingCSVColumns names the fields written by FormatCSVRow, in order.
+var MeterReadingCSVColumns = []string{
+	"id", "workspace_id", "universe_id", "subject", "unit", "quantity",
+	"aggregation", "window_start", "window_end", "record_count",
+}
+
+// formatTableColumns names the columns rendered by Format and FormatTable.
+var formatTableColumns = []string{"Subject", "Unit", "Quantity", "Period", "Aggregation", "Records"}
+
+// FormatTable renders readings as an ASCII table with one row per computed
+// value, columns padded to align:
+//
+//	| Subject       | Unit   | Quantity | Period   | Aggregation | Records |
+//	|---------------|--------|----------|----------|-------------|---------|
+//	| customer:acme | tokens | 12,500   | Jan 2024 | sum         | 1250    |
+func FormatTable(readings []MeterReadingSpec) string {
+	rows := [][]string{formatTableColumns}
+	for _, r := range readings {
+		rows = append(rows, r.formatRows()...)
+	}
+
+	widths := make([]int, len(formatTableColumns))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len([]rune(cell)))
+		}
+	}
+
+	var b strings.Builder
+	writeRow := func(row []string) {
+		for i, cell := range row {
+			b.WriteString("| ")
+			b.WriteString(cell)
+			b.WriteString(strings.Repeat(" ", widths[i]-len([]rune(cell))))
+			b.WriteString(" ")
+		}
+		b.WriteString("|\n")
+	}
+
+	writeRow(rows[0])
+	for _, w := range widths {
+		b.WriteString("|")
+		b.WriteString(strings.Repeat("-", w+2))
+	}
+	b.WriteString("|\n")
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// formatRows returns the display cells for each computed value, in the order
+// of formatTableColumns.
+func (r MeterReadingSpec) formatRows() [][]string {
+	period := formatPeriod(r.Window, r.Timezone)
+	records := strconv.Itoa(r.RecordCount)
+	values := r.valuesOrEmpty()
+	rows := make([][]string, 0, len(values))
+	for _, v := range values {
+		rows = append(rows, []string{r.Subject, v.Unit, formatQuantity(v.Quantity), period, r.Aggregation, records})
+	}
+	return rows
+}
+
+func (r MeterReadingSpec) valuesOrEmpty() []ComputedValueSpec {
+	if len(r.ComputedValues) == 0 {
+		return []ComputedValueSpec{{}}
+	}
+	return r.ComputedValues
+}
+
+// formatQuantity inserts thousands separators into the integer part of a
+// decimal string. Strings that are not plain decimals are returned unchanged.
+func formatQuantity(quantity string) string {
+	sign := ""
+	digits := quantity
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		sign, digits = digits[:1], digits[1:]
+	}
+	intPart, fracPart, hasFrac := strings.Cut(digits, ".")
+	if intPart == "" || strings.Trim(intPart, "0123456789") != "" {
+		return quantity
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	if hasFrac {
+		b.WriteByte('.')
+		b.WriteString(fracPart)
+	}
+	return b.String()
+}
+
+// formatPeriod renders a window as "Jan 2024" when it spans exactly one
+// calendar month, "2024-01-15" when it spans exactly one day, and as an
+// RFC 3339 range otherwise. Calendar boundaries are taken in the timezone tz,
+// or UTC when tz is empty or unknown.
+func formatPeriod(w TimeWindowSpec, tz string) string {
+	if tz == "" {
+		tz = "UTC"
+	}
+	local, err := w.InTimezone(tz)
+	if err != nil {
+		local, _ = w.InTimezone("UTC")
+	}
+	start, end := local.Start, local.End
+	year, month, day := start.Date()
+	midnight := start.Equal(time.Date(year, month, day, 0, 0, 0, 0, start.Location()))
+	switch {
+	case midnight && day == 1 && end.Equal(start.AddDate(0, 1, 0)):
+		return start.Format("Jan 2006")
+	case midnight && end.Equal(start.AddDate(0, 0, 1)):
+		return start.Format("2006-01-02")
+	default:
+		return start.Format(time.RFC3339) + " to " + end.Format(time.RFC3339)
+	}
+}
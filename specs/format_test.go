@@ -0,0 +1,112 @@
+package specs
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFormatTestReading(subject, quantity string) MeterReadingSpec {
+	return MeterReadingSpec{
+		ID:          "reading-1",
+		WorkspaceID: "ws-1",
+		UniverseID:  "production",
+		Subject:     subject,
+		Window: TimeWindowSpec{
+			Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		ComputedValues: []ComputedValueSpec{{Quantity: quantity, Unit: "tokens"}},
+		Aggregation:    "sum",
+		RecordCount:    1250,
+	}
+}
+
+func TestMeterReadingSpec_Format(t *testing.T) {
+	t.Run("renders fields on one line", func(t *testing.T) {
+		reading := newFormatTestReading("customer:acme", "12500")
+
+		out := reading.Format()
+
+		assert.Equal(t, "Subject: customer:acme | Unit: tokens | Quantity: 12,500 | Period: Jan 2024 | Aggregation: sum | Records: 1250", out)
+	})
+
+	t.Run("groups thousands and keeps fraction and sign", func(t *testing.T) {
+		reading := newFormatTestReading("customer:acme", "-1234567.50")
+
+		out := reading.Format()
+
+		assert.Contains(t, out, "Quantity: -1,234,567.50")
+	})
+
+	t.Run("renders a day window as a date and other windows as a range", func(t *testing.T) {
+		reading := newFormatTestReading("customer:acme", "1")
+		reading.Window = TimeWindowSpec{
+			Start: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC),
+		}
+		assert.Contains(t, reading.Format(), "Period: 2024-01-15 |")
+
+		reading.Window.End = time.Date(2024, 1, 15, 6, 0, 0, 0, time.UTC)
+		assert.Contains(t, reading.Format(), "Period: 2024-01-15T00:00:00Z to 2024-01-15T06:00:00Z |")
+	})
+
+	t.Run("renders one line per computed value", func(t *testing.T) {
+		reading := newFormatTestReading("customer:acme", "1")
+		reading.ComputedValues = append(reading.ComputedValues, ComputedValueSpec{Quantity: "2", Unit: "requests"})
+
+		lines := strings.Split(reading.Format(), "\n")
+
+		require.Len(t, lines, 2)
+		assert.Contains(t, lines[1], "Unit: requests | Quantity: 2 |")
+	})
+}
+
+func TestMeterReadingSpec_FormatCSVRow(t *testing.T) {
+	t.Run("parses back to fields", func(t *testing.T) {
+		reading := newFormatTestReading("customer:acme, inc", "12500.5")
+
+		row := reading.FormatCSVRow()
+
+		assert.NotContains(t, row, "\n")
+		records, err := csv.NewReader(strings.NewReader(row)).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		require.Len(t, records[0], len(MeterReadingCSVColumns))
+		assert.Equal(t, []string{
+			"reading-1", "ws-1", "production", "customer:acme, inc", "tokens", "12500.5",
+			"sum", "2024-01-01T00:00:00Z", "2024-02-01T00:00:00Z", "1250",
+		}, records[0])
+	})
+}
+
+func TestFormatTable(t *testing.T) {
+	t.Run("aligns columns across readings", func(t *testing.T) {
+		readings := []MeterReadingSpec{
+			newFormatTestReading("customer:acme", "12500"),
+			newFormatTestReading("customer:a", "7"),
+		}
+
+		out := FormatTable(readings)
+
+		lines := strings.Split(out, "\n")
+		require.Len(t, lines, 4, "header, separator and one line per reading")
+		for _, line := range lines {
+			assert.Equal(t, len(lines[0]), len(line), "every line should have the same width")
+			assert.Equal(t, 7, strings.Count(line, "|"), "six columns need seven separators")
+		}
+		assert.Contains(t, lines[0], "Subject")
+		assert.Contains(t, lines[2], "| customer:acme | tokens | 12,500   |")
+		assert.Contains(t, lines[3], "| customer:a    | tokens | 7        |")
+	})
+
+	t.Run("renders header only for no readings", func(t *testing.T) {
+		out := FormatTable(nil)
+
+		assert.Len(t, strings.Split(out, "\n"), 2)
+	})
+}
@@ -2,11 +2,11 @@ package specs
 
 // Meter transforms an EventPayload into MeterRecords by applying the metering configuration.
 //
-// For each measurement extraction in the config:
+// For each observation extraction in the config:
 //  1. Check if filter matches (if filter exists)
 //  2. Extract the source property value (string)
 //  3. Cast string to Decimal
-//  4. Attach the configured unit to create Measurement
+//  4. Attach the configured unit to create an Observation (formerly Measurement)
 //  5. Pass through all non-extracted properties as dimensions
 //  6. Create a MeterRecord
 //
@@ -5,6 +5,26 @@ package specs
 // One EventPayload can produce multiple MeterRecords (one per observation extraction).
 // All properties not extracted as observations are passed through as dimensions.
 type MeteringConfigSpec struct {
+	// Optional config this config inherits from.
+	//
+	// Lets many workspaces share a company-wide base config and add their own
+	// extractions. The effective config is the base merged with this one: this
+	// config's observations are appended to the base's, replacing any base
+	// observations for the same source property or path, and its computed
+	// properties, dimension paths, and dimension transforms are added after the
+	// base's (computed properties and dimension paths replace base entries with
+	// the same key). A base may itself have a base. See internal.MergeConfigs in
+	// the reference implementation.
+	BaseConfig *MeteringConfigSpec `json:"baseConfig,omitempty"`
+
+	// Optional properties computed from other properties before extraction.
+	//
+	// Computed properties are added to the event's properties, so observations,
+	// filters, and dimensions can use them like any other property. A computed
+	// property may reference other computed properties in any order, as long as
+	// the references are not circular.
+	ComputedProperties []ComputedPropertySpec `json:"computedProperties,omitempty"`
+
 	// List of observations to extract from the event payload.
 	//
 	// Each extraction defines which property to extract, what unit to assign,
@@ -13,12 +33,218 @@ type MeteringConfigSpec struct {
 	// LLM completion event might extract both "input_tokens" and "output_tokens"
 	// as separate observations with the "tokens" unit.
 	Observations []ObservationExtractionSpec `json:"observations"`
+
+	// Optional transforms applied to meter record dimensions.
+	//
+	// Dimensions are built from the event properties that are not extracted as
+	// observations. Transforms then derive normalized dimensions from them, for
+	// example mapping model_version "gpt-4-0314" to model "gpt-4". Each
+	// transform reads the untransformed dimensions, so transforms are
+	// independent of one another and of their order.
+	DimensionTransforms []DimensionTransformSpec `json:"dimensionTransforms,omitempty"`
+
+	// Optional dimensions extracted from JSON-encoded properties.
+	//
+	// Each path is evaluated against the event properties and, if it resolves,
+	// stored under its key before DimensionTransforms run, so transforms can
+	// normalize extracted values.
+	DimensionPaths []DimensionPathSpec `json:"dimensionPaths,omitempty"`
+
+	// Optional lookup-table rewrites of dimension values.
+	//
+	// Applied after DimensionTransforms to bucket raw values into coarser ones,
+	// for example status_code "200" to status_class "2xx". Like transforms,
+	// each coercion reads the dimensions as they were before any coercion ran.
+	DimensionCoercions []DimensionCoercionSpec `json:"dimensionCoercions,omitempty"`
+
+	// Optional map from unit aliases to canonical unit names.
+	//
+	// Producers often name the same unit differently ("tokens", "token",
+	// "api-tokens", "tk"). Extracted observation units found as keys are
+	// replaced by their values, so records carry a single canonical unit.
+	// Aliases resolve in one step: a canonical unit cannot itself be an alias.
+	// Units not in the map are kept as is. Two extractions may not declare
+	// different units that resolve to the same canonical unit.
+	UnitAliases map[string]string `json:"unitAliases,omitempty"`
+
+	// Optional schema the event's properties must match.
+	//
+	// When set, Meter checks the raw event properties against the schema before
+	// computing properties or extracting observations, and rejects the event if
+	// a required property is missing or a property's value does not parse as
+	// its declared type. Properties not in the schema are allowed.
+	PropertySchema *PropertySchemaSpec `json:"propertySchema,omitempty"`
+
+	// Optional map from event type to the properties its events must carry.
+	//
+	// Catches malformed events before metering, such as an "api.request"
+	// without "tokens" or a "storage.upload" without "bytes". Events missing a
+	// required property fail metering. Event types not in the map are not
+	// checked. Examples: {"api.request": ["tokens", "model"]}.
+	RequiredProperties map[string][]string `json:"requiredProperties,omitempty"`
+
+	// Whether to drop repeated observations from each meter record.
+	//
+	// Overlapping extractions that read the same property with the same unit
+	// produce identical observations, which would count the usage twice. When
+	// set, observations with the same quantity, unit, and window as an
+	// earlier one in the record are removed.
+	DeduplicateObservations bool `json:"deduplicateObservations,omitempty"`
+
+	// Optional dimension keys whose values are masked in meter records.
+	//
+	// Keeps personal data such as email or IP addresses out of stored
+	// dimensions. Masking is applied last, after dimension paths, transforms,
+	// and coercions, so it also covers keys they produce. Filters and
+	// extraction still see the original property values. Examples: "email",
+	// "ip_address".
+	MaskedProperties []string `json:"maskedProperties,omitempty"`
+
+	// How masked values are replaced:
+	//   - "redact": the fixed string "***MASKED***"
+	//   - "hash": the hex HMAC-SHA256 of the value under MaskingKey, so
+	//     records of the same user can still be linked without storing the
+	//     value. Requires MaskingKey.
+	//   - "truncate:<n>": the first <n> characters, e.g. "truncate:3"
+	//
+	// Empty means "redact".
+	MaskingStrategy string `json:"maskingStrategy,omitempty"`
+
+	// Secret key for the "hash" masking strategy.
+	//
+	// A plain digest of a low-entropy value such as an email address can be
+	// reversed by hashing candidate values, so hashes are keyed. Load it from
+	// a secret store rather than committing it with the config.
+	MaskingKey string `json:"maskingKey,omitempty"`
+}
+
+// PropertySchemaSpec declares the properties an event type is expected to
+// carry and their types.
+type PropertySchemaSpec struct {
+	// Declared properties. Names must be unique.
+	Fields []PropertyFieldSpec `json:"fields"`
+}
+
+// PropertyFieldSpec declares one event property.
+type PropertyFieldSpec struct {
+	// The property key in EventPayload.Properties.
+	//
+	// Examples: "input_tokens", "model", "cached".
+	Name string `json:"name"`
+
+	// The type the property's string value must parse as:
+	//   - "string": any value
+	//   - "decimal": a decimal number, e.g. "1250.5"
+	//   - "integer": a whole number, e.g. "1250"
+	//   - "boolean": "true" or "false" (also "1", "0", "t", "f")
+	Type string `json:"type"`
+
+	// Whether the property must be present.
+	Required bool `json:"required,omitempty"`
+}
+
+// DimensionPathSpec extracts a dimension from a value nested in a
+// JSON-encoded event property.
+type DimensionPathSpec struct {
+	// The dimension key to store the value under.
+	//
+	// Examples: "model", "region".
+	Key string `json:"key"`
+
+	// JSONPath to the value, using the same syntax as
+	// ObservationExtractionSpec.SourcePath. Examples: "$.metadata.model",
+	// "$.metadata.regions[0]". Missing paths add no dimension.
+	Path string `json:"path"`
+}
+
+// DimensionTransformSpec derives a dimension from an event property.
+//
+// The transform only applies when FromProperty is present among the record's
+// dimensions; properties extracted as observations are not dimensions and
+// cannot be transformed.
+type DimensionTransformSpec struct {
+	// The dimension (event property) key to read.
+	//
+	// Examples: "model_version", "region_code".
+	FromProperty string `json:"fromProperty"`
+
+	// The dimension key to write the transformed value to.
+	//
+	// Empty means FromProperty, replacing the original value in place.
+	// Examples: "model", "region".
+	ToKey string `json:"toKey,omitempty"`
+
+	// How the value is transformed:
+	//   - "passthrough": copies the value unchanged (a rename when ToKey differs)
+	//   - "prefix-strip:<prefix>": removes <prefix> from the start of the value
+	//     if present, e.g. "prefix-strip:gpt-"
+	//   - "regex:<pattern>:<replacement>": replaces all matches of the Go regular
+	//     expression <pattern> with <replacement>, which may reference capture
+	//     groups as $1. The replacement is everything after the last colon, so
+	//     the pattern may contain colons but the replacement cannot.
+	Transform string `json:"transform"`
+
+	// Whether to remove FromProperty from the dimensions after transforming.
+	//
+	// The removal happens once all transforms have run, so other transforms
+	// still read FromProperty, and it is skipped if another transform writes
+	// FromProperty as its ToKey. Ignored when ToKey is empty or equals
+	// FromProperty.
+	DropOriginal bool `json:"dropOriginal,omitempty"`
+}
+
+// DimensionCoercionSpec maps a dimension's values to new values through an
+// exact-match lookup table.
+//
+// The coercion only applies when FromKey is present among the record's
+// dimensions.
+type DimensionCoercionSpec struct {
+	// The dimension key to read.
+	//
+	// Examples: "status_code", "plan".
+	FromKey string `json:"fromKey"`
+
+	// The dimension key to write the coerced value to.
+	//
+	// Empty means FromKey, replacing the value in place. When ToKey differs
+	// from FromKey, FromKey is removed from the dimensions. Examples:
+	// "status_class", "tier".
+	ToKey string `json:"toKey,omitempty"`
+
+	// Exact, case-sensitive value to new value lookup table.
+	//
+	// Example: {"200": "2xx", "201": "2xx", "404": "4xx"}. Must not be empty.
+	Mapping map[string]string `json:"mapping"`
+
+	// Value to write when the value has no entry in Mapping.
+	//
+	// Empty means unmapped values are written unchanged.
+	DefaultValue string `json:"defaultValue,omitempty"`
+}
+
+// ComputedPropertySpec defines a property derived from an arithmetic expression.
+type ComputedPropertySpec struct {
+	// The property key to set to the expression's result.
+	//
+	// Overwrites any event property with the same key. Examples: "total_tokens",
+	// "total_bytes".
+	Key string `json:"key"`
+
+	// Arithmetic expression over other properties.
+	//
+	// References properties as {property_name} and supports decimal literals,
+	// + - * /, unary minus, and parentheses. Evaluated with exact decimal
+	// arithmetic. Examples: "{input_tokens} + {output_tokens}",
+	// "({bytes_in} + {bytes_out}) / 1073741824".
+	Expression string `json:"expression"`
 }
 
 // FilterSpec defines a filter condition on EventPayload properties.
 //
-// Currently supports only simple equality matching. More complex filter operations
-// (inequality, regex, existence checks) can be added as needed.
+// Supports exactly one of: equality matching (Equals), list membership
+// (MatchesAny, equivalent to SQL IN), or list exclusion (MatchesNone, equivalent
+// to SQL NOT IN). More complex filter operations (regex, existence checks) can be
+// added as needed.
 type FilterSpec struct {
 	// The property key in EventPayload.Properties to check.
 	//
@@ -28,8 +254,25 @@ type FilterSpec struct {
 	// The exact value the property must equal for the filter to match.
 	//
 	// Comparison is case-sensitive string equality. Examples: "premium",
-	// "us-east-1", "200", "gpt-4".
+	// "us-east-1", "200", "gpt-4". Must be empty when MatchesAny or MatchesNone
+	// is set.
 	Equals string `json:"equals"`
+
+	// Values the property may equal for the filter to match (OR-equality).
+	//
+	// Semantically equivalent to SQL `property IN (...)`. Replaces one extraction
+	// per value when several values should meter the same way, for example
+	// ["gpt-4", "gpt-4-turbo", "gpt-4o"]. Comparison is case-sensitive. Must not
+	// be empty when set, and cannot be combined with Equals or MatchesNone.
+	MatchesAny []string `json:"matchesAny,omitempty"`
+
+	// Values the property must not equal for the filter to match.
+	//
+	// Semantically equivalent to SQL `property NOT IN (...)`, useful for exclusion
+	// lists such as internal test accounts. As with SQL, an event missing the
+	// property does not match. Must not be empty when set, and cannot be combined
+	// with Equals or MatchesAny.
+	MatchesNone []string `json:"matchesNone,omitempty"`
 }
 
 // ObservationExtractionSpec defines how to extract an observation from EventPayload.
@@ -38,15 +281,43 @@ type FilterSpec struct {
 // and optionally a filter to conditionally extract the observation only when certain
 // criteria are met.
 //
-// Note: This is the new naming aligned with domain terminology. Use this instead of
-// MeasurementExtractionSpec for new code.
+// Note: This is the new naming aligned with domain terminology. It replaces the
+// removed MeasurementExtractionSpec; Measurement values are now Observations.
 type ObservationExtractionSpec struct {
 	// The property key in EventPayload.Properties to extract as an observation.
 	//
 	// Must exist in the event's properties map and contain a value parseable as
 	// a decimal number. Examples: "response_time_ms", "tokens", "bytes_transferred".
+	// Required unless SourcePath is set; the two are mutually exclusive.
 	SourceProperty string `json:"sourceProperty"`
 
+	// Optional namespace that SourceProperty is read from.
+	//
+	// Events from different subsystems may prefix their properties, such as
+	// "llm.input_tokens" and "storage.bytes". When set, the property key is
+	// Namespace + "." + SourceProperty, and the record's dimensions take other
+	// properties in the namespace with the prefix stripped (a namespaced
+	// property wins over an unprefixed one of the same name). Only valid with
+	// SourceProperty. Examples: "llm", "storage".
+	Namespace string `json:"namespace,omitempty"`
+
+	// JSONPath to a value nested in a JSON-encoded property, as an alternative
+	// to SourceProperty.
+	//
+	// The first key names the event property; its value is parsed as JSON and
+	// the rest of the path is evaluated against it. Supports ".key" and
+	// "[index]" steps. Examples: "$.metadata.tokens", "$.usage.items[0].count".
+	// If the path does not resolve, SourceDefault is used; without a default
+	// no observation is extracted. Like a SourceProperty, the property the
+	// path reads is not copied into dimensions; use DimensionPaths to keep
+	// values nested in it.
+	SourcePath string `json:"sourcePath,omitempty"`
+
+	// Value to extract when SourcePath does not resolve, as a decimal string.
+	//
+	// Only valid with SourcePath. Examples: "0", "1".
+	SourceDefault string `json:"sourceDefault,omitempty"`
+
 	// Unit identifier to assign to the extracted observation.
 	//
 	// Determines how this observation aggregates with others and how it gets rated
@@ -54,6 +325,23 @@ type ObservationExtractionSpec struct {
 	// "tokens", "gb-hours", "seats".
 	Unit string `json:"unit"`
 
+	// Optional inclusive lower bound on the extracted quantity, as a decimal
+	// string.
+	//
+	// Guards physically bounded values, such as a byte count that cannot be
+	// negative. Checked against the extracted value before any free tier is
+	// subtracted; an out-of-range quantity fails metering with an error
+	// (internal.QuantityValidationError in the reference implementation).
+	// Empty means no lower bound.
+	MinQuantity string `json:"minQuantity,omitempty"`
+
+	// Optional inclusive upper bound on the extracted quantity, as a decimal
+	// string.
+	//
+	// For example "100" for a percentage. Must not be less than MinQuantity.
+	// Empty means no upper bound.
+	MaxQuantity string `json:"maxQuantity,omitempty"`
+
 	// Optional filter condition to apply before extracting the observation.
 	//
 	// If specified, the observation is only extracted when the filter matches.
@@ -62,4 +350,23 @@ type ObservationExtractionSpec struct {
 	// the "tier" property equals "premium". If nil, the observation is always
 	// extracted.
 	Filter *FilterSpec `json:"filter,omitempty"`
+
+	// Optional quantity of free usage as a decimal string.
+	//
+	// Models free tiers such as "the first 1000 tokens of each request are free".
+	// How it applies depends on FreeTierStrategy. Must be non-negative. Empty
+	// means no free tier.
+	FreeQuantity string `json:"freeQuantity,omitempty"`
+
+	// How FreeQuantity is applied:
+	//   - "per-event" (default): subtracted from each extracted quantity during
+	//     metering. If the net quantity is zero or less, no observation is
+	//     extracted for the event.
+	//   - "per-window": metering leaves quantities unchanged; the free quantity
+	//     is subtracted once from each window's aggregated value. Carry it into
+	//     AggregateConfigSpec.FreeQuantity for the unit (WithPerWindowFreeTier
+	//     in the reference implementation).
+	//
+	// Must be empty when FreeQuantity is empty.
+	FreeTierStrategy string `json:"freeTierStrategy,omitempty"`
 }
@@ -1,6 +1,12 @@
 package specs
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
 
 // TimeWindowSpec represents a half-open time interval [Start, End).
 //
@@ -22,6 +28,66 @@ type TimeWindowSpec struct {
 	End time.Time `json:"end"`
 }
 
+// InTimezone returns the window with Start and End expressed in the IANA
+// timezone tz (e.g., "America/Los_Angeles"). The instants are unchanged; only
+// their wall clock representation differs. Returns error if tz is not a known
+// timezone name.
+func (w TimeWindowSpec) InTimezone(tz string) (TimeWindowSpec, error) {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return TimeWindowSpec{}, fmt.Errorf("%w %q: %w", ErrInvalidTimezone, tz, err)
+	}
+	return TimeWindowSpec{Start: w.Start.In(loc), End: w.End.In(loc)}, nil
+}
+
+// FloorTo returns the smallest window aligned to calendar periods in loc that
+// contains w: Start is moved back to the start of its "hour", "day", or
+// "month", and End forward to the next boundary unless it is already on one.
+// A nil loc means UTC. The returned times are expressed in loc.
+//
+// Days and months follow the local calendar, so a day spanning a daylight
+// saving change is 23 or 25 hours long. Returns error if period is unknown or
+// End is before Start.
+func (w TimeWindowSpec) FloorTo(period string, loc *time.Location) (TimeWindowSpec, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	var floor func(time.Time) time.Time
+	var next func(time.Time) time.Time
+	switch period {
+	case "hour":
+		// Subtracting elapsed minutes keeps repeated DST hours distinct and
+		// respects zones offset by a fraction of an hour.
+		floor = func(t time.Time) time.Time {
+			return t.Add(-time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+		}
+		next = func(t time.Time) time.Time { return t.Add(time.Hour) }
+	case "day":
+		floor = func(t time.Time) time.Time {
+			year, month, day := t.Date()
+			return time.Date(year, month, day, 0, 0, 0, 0, loc)
+		}
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	case "month":
+		floor = func(t time.Time) time.Time {
+			return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		}
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	default:
+		return TimeWindowSpec{}, fmt.Errorf("invalid period %q: must be hour, day, or month", period)
+	}
+	if w.End.Before(w.Start) {
+		return TimeWindowSpec{}, fmt.Errorf("window end %s is before start %s", w.End.Format(time.RFC3339), w.Start.Format(time.RFC3339))
+	}
+
+	start := floor(w.Start.In(loc))
+	end := floor(w.End.In(loc))
+	if end.Before(w.End) || !end.After(start) {
+		end = next(end)
+	}
+	return TimeWindowSpec{Start: start, End: end}, nil
+}
+
 // MeterReadingSpec represents an aggregated usage value over a time window.
 //
 // Meter readings are created by aggregating meter records that share the same
@@ -56,6 +122,13 @@ type MeterReadingSpec struct {
 	// entity for the aggregated usage.
 	Subject string `json:"subject"`
 
+	// Group-by dimension values shared by all aggregated meter records.
+	//
+	// Set when the aggregation config specifies GroupBy, identifying which
+	// partition this reading covers (e.g., {"model": "gpt-4"}). Part of the
+	// deterministic reading ID. Empty for ungrouped readings.
+	Dimensions map[string]string `json:"dimensions,omitempty"`
+
 	// Time window over which meter records were aggregated.
 	//
 	// Defines the half-open interval [Window.Start, Window.End) for this reading.
@@ -63,6 +136,15 @@ type MeterReadingSpec struct {
 	// with RecordedAt within this window contribute to the aggregation.
 	Window TimeWindowSpec `json:"window"`
 
+	// Optional IANA timezone name the billing period is defined in.
+	//
+	// A customer billed on US/Pacific months has windows that start at local
+	// midnight, which is 08:00 UTC (07:00 during daylight saving time). Window
+	// stays an absolute interval; Timezone records how to display it (see
+	// TimeWindowSpec.InTimezone). Examples: "America/Los_Angeles", "UTC".
+	// Empty means UTC.
+	Timezone string `json:"timezone,omitempty"`
+
 	// Computed values array (one per unit).
 	//
 	// Each ComputedValue contains {quantity, unit, aggregation}, making the computation
@@ -70,6 +152,12 @@ type MeterReadingSpec struct {
 	// observations with different units (e.g., input-tokens and output-tokens).
 	ComputedValues []ComputedValueSpec `json:"computedValues"`
 
+	// Unit of the first computed value, denormalized for filtering and grouping
+	// readings without reaching into ComputedValues.
+	//
+	// Populated by Aggregate. Empty on readings built before this field existed.
+	Unit string `json:"unit,omitempty"`
+
 	// Aggregation strategy applied to compute the measurement.
 	//
 	// Determines how individual meter record quantities combine:
@@ -77,6 +165,10 @@ type MeterReadingSpec struct {
 	//   - "max": Maximum quantity in window (e.g., peak concurrent users)
 	//   - "min": Minimum quantity in window
 	//   - "latest": Most recent quantity by RecordedAt
+	//   - "first-non-zero": Earliest positive quantity by RecordedAt
+	//   - "mode": Most frequent quantity (e.g., predominant plan tier)
+	//   - "count": Number of records, ignoring quantities (e.g., API calls)
+	//   - "average": Arithmetic mean of quantities, each record weighted equally
 	//   - "time-weighted-avg": Average weighted by time between records (e.g., seat count)
 	Aggregation string `json:"aggregation"`
 
@@ -100,4 +192,306 @@ type MeterReadingSpec struct {
 	// Used for watermarking in incremental aggregation pipelines to determine
 	// which records have been processed. Enables exactly-once aggregation semantics.
 	MaxMeteredAt time.Time `json:"maxMeteredAt"`
+
+	// Revision number of this reading for optimistic concurrency.
+	//
+	// Aggregate produces readings at version 1. Each re-aggregation of the same
+	// reading ID should increment the version (see IncrementVersion). Storage
+	// adapters implementing upsert semantics should only overwrite a stored
+	// reading when its version matches the expected one, for example:
+	//
+	//	UPDATE meter_readings SET ..., version = $new_version
+	//	WHERE id = $id AND version = $expected_version
+	//
+	// A zero version means the reading was not versioned.
+	Version int64 `json:"version"`
+
+	// Computed values of the same series for the preceding period.
+	//
+	// Optional context for billing UIs that display period-over-period change
+	// ("this month: X, up Y from last month"). Nil when no previous period
+	// exists. Populated by EnrichWithPreviousPeriod; not set by Aggregate.
+	PreviousValues []ComputedValueSpec `json:"previousValues,omitempty"`
+
+	// Whether the aggregated value exceeded AggregateConfigSpec.MaxValue and was
+	// truncated to it.
+	//
+	// A value exactly equal to the cap is not considered capped. Always false
+	// when no cap is configured.
+	WasCapped bool `json:"wasCapped,omitempty"`
+
+	// Lower bound of the confidence interval around the computed value.
+	//
+	// Statistical aggregations such as time-weighted-avg estimate a value from
+	// samples; the interval expresses that uncertainty. Set together with
+	// ConfidenceIntervalUpper and ConfidenceLevel, or not at all. Not set by
+	// Aggregate; populated by implementations that estimate intervals
+	// (internal.MeterReadingAggregation.AggregateWithCI in the reference
+	// implementation). Decimal string, e.g. "41.7".
+	ConfidenceIntervalLower string `json:"confidenceIntervalLower,omitempty"`
+
+	// Upper bound of the confidence interval, as a decimal string.
+	ConfidenceIntervalUpper string `json:"confidenceIntervalUpper,omitempty"`
+
+	// Probability that the interval contains the true value, as a decimal
+	// string between 0 and 1 (e.g., "0.95").
+	ConfidenceLevel string `json:"confidenceLevel,omitempty"`
+
+	// Operational annotations such as pipeline run ID, aggregation job ID, or
+	// source shard.
+	//
+	// Purely informational: unlike Dimensions, metadata does not partition
+	// aggregation and is not part of the reading ID, so the same usage yields the
+	// same reading regardless of which job produced it. Must not carry billing
+	// data. Set by Aggregate from AggregateConfigSpec.OutputMetadata, or with
+	// WithMetadata.
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// IDs of the meter records that contributed to the reading, sorted.
+	//
+	// Supports forensic billing audits by tracing a reading back to its
+	// records. Includes a carried-forward lastBeforeWindow record when the
+	// aggregation used it. Set by Aggregate only when
+	// AggregateConfigSpec.IncludeSourceIDs is true; nil otherwise.
+	SourceRecordIDs []string `json:"sourceRecordIDs,omitempty"`
+
+	// Whether SourceRecordIDs was cut off at AggregateConfigSpec.MaxSourceIDs,
+	// omitting some contributing records.
+	SourceRecordIDsTruncated bool `json:"sourceRecordIDsTruncated,omitempty"`
+
+	// Number of counter resets detected while aggregating.
+	//
+	// Set when AggregateConfigSpec.ResetDetectionThreshold is configured for a
+	// time-weighted-avg; zero otherwise.
+	ResetCount int `json:"resetCount,omitempty"`
+
+	// HMAC-SHA256 of the reading's billing content, as a hex string.
+	//
+	// Makes stored readings tamper-evident in regulated billing systems: any
+	// change to the signed fields invalidates the checksum. Covers workspace,
+	// universe, subject, window, aggregation, dimensions, and computed values,
+	// but not IDs, system timestamps, or metadata. Not set by Aggregate;
+	// populated by signing with a deployment-held key (internal.SignReading in
+	// the reference implementation).
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// MarshalJSON encodes the reading with CreatedAt in DefaultTimeFormat. Use
+// TimeFormat.MarshalReading for another format.
+func (r MeterReadingSpec) MarshalJSON() ([]byte, error) {
+	return DefaultTimeFormat.MarshalReading(r)
+}
+
+// UnmarshalJSON decodes a reading with CreatedAt as an RFC 3339 string or a
+// Unix timestamp.
+func (r *MeterReadingSpec) UnmarshalJSON(data []byte) error {
+	return DefaultTimeFormat.UnmarshalReading(data, r)
+}
+
+// MarshalReading encodes r with CreatedAt in format f.
+func (f TimeFormat) MarshalReading(r MeterReadingSpec) ([]byte, error) {
+	type plain MeterReadingSpec
+	return json.Marshal(struct {
+		plain
+		CreatedAt jsonTime `json:"createdAt"`
+	}{plain(r), jsonTime{r.CreatedAt, f}})
+}
+
+// UnmarshalReading decodes data into r, accepting CreatedAt as a string in
+// format f or RFC 3339, or as a Unix timestamp.
+func (f TimeFormat) UnmarshalReading(data []byte, r *MeterReadingSpec) error {
+	type plain MeterReadingSpec
+	aux := struct {
+		*plain
+		CreatedAt jsonTime `json:"createdAt"`
+	}{plain: (*plain)(r), CreatedAt: jsonTime{r.CreatedAt, f}}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.CreatedAt = aux.CreatedAt.time
+	return nil
+}
+
+// WithVersion returns a copy of the reading with Version set to v.
+func (r MeterReadingSpec) WithVersion(v int64) MeterReadingSpec {
+	r.Version = v
+	return r
+}
+
+// WithMetadata returns a copy of the reading with the metadata key set to value.
+// The original reading's Metadata map is not modified.
+func (r MeterReadingSpec) WithMetadata(key, value string) MeterReadingSpec {
+	metadata := make(map[string]string, len(r.Metadata)+1)
+	for k, v := range r.Metadata {
+		metadata[k] = v
+	}
+	metadata[key] = value
+	r.Metadata = metadata
+	return r
+}
+
+// QuantityAsDecimal returns the quantity of the reading's first computed
+// value as an exact rational number, for comparisons the helpers below do not
+// cover.
+//
+// The spec package has no decimal type of its own (the reference
+// implementation's is internal.Decimal), and float64 cannot represent most
+// decimal fractions exactly, so a big.Rat is used: it holds any decimal
+// string without loss. Returns error if the reading has no computed values or
+// the quantity is not a valid decimal.
+func (r MeterReadingSpec) QuantityAsDecimal() (*big.Rat, error) {
+	if len(r.ComputedValues) == 0 {
+		return nil, fmt.Errorf("reading has no computed values")
+	}
+	quantity, err := parseDecimal(r.ComputedValues[0].Quantity)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quantity: %w", err)
+	}
+	return quantity, nil
+}
+
+// ExceedsQuantity reports whether the reading's quantity is greater than
+// threshold, for quota checks. A quantity equal to threshold does not exceed
+// it. Returns error if threshold or the quantity is not a valid decimal.
+func (r MeterReadingSpec) ExceedsQuantity(threshold string) (bool, error) {
+	cmp, err := r.compareQuantity(threshold)
+	return cmp > 0, err
+}
+
+// BelowQuantity reports whether the reading's quantity is less than
+// threshold. Returns error if threshold or the quantity is not a valid
+// decimal.
+func (r MeterReadingSpec) BelowQuantity(threshold string) (bool, error) {
+	cmp, err := r.compareQuantity(threshold)
+	return cmp < 0, err
+}
+
+// QuantityBetween reports whether the reading's quantity is within [low,
+// high], inclusive. Returns error if low is greater than high, or any value
+// is not a valid decimal.
+func (r MeterReadingSpec) QuantityBetween(low, high string) (bool, error) {
+	lowValue, err := parseDecimal(low)
+	if err != nil {
+		return false, fmt.Errorf("invalid low: %w", err)
+	}
+	highValue, err := parseDecimal(high)
+	if err != nil {
+		return false, fmt.Errorf("invalid high: %w", err)
+	}
+	if lowValue.Cmp(highValue) > 0 {
+		return false, fmt.Errorf("low %s is greater than high %s", low, high)
+	}
+	quantity, err := r.QuantityAsDecimal()
+	if err != nil {
+		return false, err
+	}
+	return quantity.Cmp(lowValue) >= 0 && quantity.Cmp(highValue) <= 0, nil
+}
+
+// compareQuantity returns -1, 0, or +1 as the reading's quantity is less
+// than, equal to, or greater than threshold.
+func (r MeterReadingSpec) compareQuantity(threshold string) (int, error) {
+	thresholdValue, err := parseDecimal(threshold)
+	if err != nil {
+		return 0, fmt.Errorf("invalid threshold: %w", err)
+	}
+	quantity, err := r.QuantityAsDecimal()
+	if err != nil {
+		return 0, err
+	}
+	return quantity.Cmp(thresholdValue), nil
+}
+
+// parseDecimal parses a plain decimal string exactly. big.Rat alone would
+// also accept fractions ("1/3") and hexadecimal, which quantities never use.
+func parseDecimal(s string) (*big.Rat, error) {
+	if !IsPlainDecimal(s) {
+		return nil, fmt.Errorf("%q is not a valid decimal", s)
+	}
+	value, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a valid decimal", s)
+	}
+	return value, nil
+}
+
+// EnrichWithPreviousPeriod returns a copy of current with PreviousValues set to
+// the computed values of previous.
+//
+// The caller is responsible for passing the reading of the same subject and
+// series for the immediately preceding window.
+//
+// A previous reading without computed values leaves PreviousValues nil, so
+// "no previous period" has a single representation in Go and in JSON.
+func EnrichWithPreviousPeriod(current MeterReadingSpec, previous MeterReadingSpec) MeterReadingSpec {
+	if len(previous.ComputedValues) == 0 {
+		current.PreviousValues = nil
+		return current
+	}
+	values := make([]ComputedValueSpec, len(previous.ComputedValues))
+	copy(values, previous.ComputedValues)
+	current.PreviousValues = values
+	return current
+}
+
+// Delta returns the change of each computed value since the previous period:
+// ComputedValues minus PreviousValues, matched by unit.
+//
+// Quantities are subtracted exactly as big.Rat and written with as many
+// fractional digits as the more precise operand, so "1.50" minus "0.25" is
+// "1.25". Each delta keeps the unit and aggregation of the current value.
+// Returns nil if the reading has no PreviousValues. Returns error if a current
+// value has no previous value with the same unit, or a quantity is not a
+// valid decimal.
+func (r MeterReadingSpec) Delta() ([]ComputedValueSpec, error) {
+	if len(r.PreviousValues) == 0 {
+		return nil, nil
+	}
+
+	previousByUnit := make(map[string]ComputedValueSpec, len(r.PreviousValues))
+	for _, previous := range r.PreviousValues {
+		previousByUnit[previous.Unit] = previous
+	}
+
+	deltas := make([]ComputedValueSpec, len(r.ComputedValues))
+	for i, current := range r.ComputedValues {
+		previous, ok := previousByUnit[current.Unit]
+		if !ok {
+			return nil, fmt.Errorf("unit mismatch: no previous value for unit %q", current.Unit)
+		}
+
+		currentQuantity, err := parseDecimal(current.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("invalid computed value %d quantity: %w", i, err)
+		}
+		previousQuantity, err := parseDecimal(previous.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("invalid previous value quantity for unit %q: %w", current.Unit, err)
+		}
+
+		delta := new(big.Rat).Sub(currentQuantity, previousQuantity)
+		deltas[i] = ComputedValueSpec{
+			Quantity:    delta.FloatString(max(decimalScale(current.Quantity), decimalScale(previous.Quantity))),
+			Unit:        current.Unit,
+			Aggregation: current.Aggregation,
+		}
+	}
+
+	return deltas, nil
+}
+
+// decimalScale returns the number of fractional digits in a plain decimal.
+func decimalScale(s string) int {
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+// IncrementVersion returns a copy of the reading with Version increased by one.
+//
+// Use when re-aggregating a reading that has already been stored, so the
+// storage adapter can detect concurrent writers.
+func IncrementVersion(reading MeterReadingSpec) MeterReadingSpec {
+	return reading.WithVersion(reading.Version + 1)
 }
@@ -0,0 +1,313 @@
+package specs
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeterReadingSpec_WithVersion(t *testing.T) {
+	t.Run("sets exact version on a copy", func(t *testing.T) {
+		reading := MeterReadingSpec{ID: "reading-123", Version: 1}
+
+		updated := reading.WithVersion(7)
+
+		assert.Equal(t, int64(7), updated.Version)
+		assert.Equal(t, "reading-123", updated.ID)
+		assert.Equal(t, int64(1), reading.Version, "original reading should be unchanged")
+	})
+}
+
+func TestMeterReadingSpec_WithMetadata(t *testing.T) {
+	t.Run("sets key on a copy", func(t *testing.T) {
+		reading := MeterReadingSpec{ID: "reading-123", Metadata: map[string]string{"job_id": "job-1"}}
+
+		updated := reading.WithMetadata("shard", "7")
+
+		assert.Equal(t, map[string]string{"job_id": "job-1", "shard": "7"}, updated.Metadata)
+		assert.Equal(t, map[string]string{"job_id": "job-1"}, reading.Metadata, "original reading should be unchanged")
+	})
+
+	t.Run("metadata is preserved through JSON", func(t *testing.T) {
+		reading := MeterReadingSpec{ID: "reading-123"}.WithMetadata("pipeline_run_id", "run-42")
+
+		data, err := json.Marshal(reading)
+		require.NoError(t, err)
+		var decoded MeterReadingSpec
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		assert.Equal(t, map[string]string{"pipeline_run_id": "run-42"}, decoded.Metadata)
+	})
+}
+
+func TestIncrementVersion(t *testing.T) {
+	t.Run("adds one to the version", func(t *testing.T) {
+		reading := MeterReadingSpec{ID: "reading-123", Version: 1}
+
+		updated := IncrementVersion(reading)
+
+		assert.Equal(t, int64(2), updated.Version)
+		assert.Equal(t, int64(1), reading.Version, "original reading should be unchanged")
+	})
+
+	t.Run("increments unversioned reading to version 1", func(t *testing.T) {
+		updated := IncrementVersion(MeterReadingSpec{ID: "reading-123"})
+
+		assert.Equal(t, int64(1), updated.Version)
+	})
+}
+
+func TestEnrichWithPreviousPeriod(t *testing.T) {
+	t.Run("copies previous computed values without modifying inputs", func(t *testing.T) {
+		current := MeterReadingSpec{ID: "reading-january"}
+		previous := MeterReadingSpec{
+			ID:             "reading-december",
+			ComputedValues: []ComputedValueSpec{{Quantity: "1000", Unit: "api-calls", Aggregation: "sum"}},
+		}
+
+		enriched := EnrichWithPreviousPeriod(current, previous)
+
+		assert.Equal(t, "reading-january", enriched.ID)
+		assert.Equal(t, previous.ComputedValues, enriched.PreviousValues)
+		assert.Nil(t, current.PreviousValues, "original reading should be unchanged")
+
+		enriched.PreviousValues[0].Quantity = "0"
+		assert.Equal(t, "1000", previous.ComputedValues[0].Quantity, "previous reading should not share storage")
+	})
+
+	t.Run("previous reading without values leaves PreviousValues nil", func(t *testing.T) {
+		current := MeterReadingSpec{ID: "reading-january"}
+
+		enriched := EnrichWithPreviousPeriod(current, MeterReadingSpec{ComputedValues: []ComputedValueSpec{}})
+
+		assert.Nil(t, enriched.PreviousValues)
+		data, err := json.Marshal(enriched)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "previousValues")
+	})
+}
+
+func TestMeterReadingSpec_Delta(t *testing.T) {
+	withPrevious := func(current, previous string) MeterReadingSpec {
+		return MeterReadingSpec{
+			ComputedValues: []ComputedValueSpec{{Quantity: current, Unit: "gb-hours", Aggregation: "sum"}},
+			PreviousValues: []ComputedValueSpec{{Quantity: previous, Unit: "gb-hours", Aggregation: "sum"}},
+		}
+	}
+
+	t.Run("subtracts exactly", func(t *testing.T) {
+		tests := []struct {
+			current, previous, want string
+		}{
+			{"1250", "1000", "250"},
+			{"0.3", "0.1", "0.2"},
+			{"1.50", "0.25", "1.25"},
+			{"900", "1000.5", "-100.5"},
+			{"12345678901234567890.123456789", "0.000000001", "12345678901234567890.123456788"},
+		}
+		for _, tt := range tests {
+			deltas, err := withPrevious(tt.current, tt.previous).Delta()
+
+			require.NoError(t, err)
+			require.Len(t, deltas, 1)
+			assert.Equal(t, tt.want, deltas[0].Quantity, "%s - %s", tt.current, tt.previous)
+			assert.Equal(t, "gb-hours", deltas[0].Unit)
+			assert.Equal(t, "sum", deltas[0].Aggregation)
+		}
+	})
+
+	t.Run("without previous values returns nil", func(t *testing.T) {
+		reading := withPrevious("1250", "1000")
+
+		reading.PreviousValues = nil
+		nilDeltas, nilErr := reading.Delta()
+		reading.PreviousValues = []ComputedValueSpec{}
+		emptyDeltas, emptyErr := reading.Delta()
+
+		assert.NoError(t, nilErr)
+		assert.Nil(t, nilDeltas)
+		assert.NoError(t, emptyErr)
+		assert.Nil(t, emptyDeltas)
+	})
+
+	t.Run("with unit mismatch returns error", func(t *testing.T) {
+		reading := withPrevious("12", "10")
+		reading.PreviousValues[0].Unit = "seats"
+
+		_, err := reading.Delta()
+
+		assert.ErrorContains(t, err, "unit mismatch")
+	})
+
+	t.Run("with invalid quantity returns error", func(t *testing.T) {
+		_, err := withPrevious("1/3", "0").Delta()
+
+		assert.ErrorContains(t, err, "not a valid decimal")
+	})
+}
+
+func TestTimeWindowSpec_InTimezone(t *testing.T) {
+	window := TimeWindowSpec{
+		Start: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 2, 1, 8, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("keeps instants and changes wall clock", func(t *testing.T) {
+		local, err := window.InTimezone("America/Los_Angeles")
+
+		require.NoError(t, err)
+		assert.True(t, local.Start.Equal(window.Start))
+		assert.True(t, local.End.Equal(window.End))
+		assert.Equal(t, 0, local.Start.Hour(), "08:00 UTC is local midnight in January")
+		assert.Equal(t, "America/Los_Angeles", local.Start.Location().String())
+	})
+
+	t.Run("invalid timezone returns error", func(t *testing.T) {
+		_, err := window.InTimezone("Mars/Olympus_Mons")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid timezone")
+	})
+}
+
+func TestTimeWindowSpec_FloorTo(t *testing.T) {
+	t.Run("snaps start down and end up", func(t *testing.T) {
+		w := TimeWindowSpec{
+			Start: time.Date(2024, 1, 15, 10, 20, 0, 0, time.UTC),
+			End:   time.Date(2024, 1, 15, 12, 5, 0, 0, time.UTC),
+		}
+
+		floored, err := w.FloorTo("hour", nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), floored.Start)
+		assert.Equal(t, time.Date(2024, 1, 15, 13, 0, 0, 0, time.UTC), floored.End)
+	})
+
+	t.Run("aligned window is unchanged", func(t *testing.T) {
+		w := TimeWindowSpec{
+			Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		}
+
+		floored, err := w.FloorTo("month", time.UTC)
+
+		require.NoError(t, err)
+		assert.Equal(t, w, floored)
+	})
+
+	t.Run("hours follow zones offset by half an hour", func(t *testing.T) {
+		kolkata, err := time.LoadLocation("Asia/Kolkata")
+		require.NoError(t, err)
+		w := TimeWindowSpec{
+			Start: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+			End:   time.Date(2024, 1, 15, 10, 10, 0, 0, time.UTC),
+		}
+
+		floored, err := w.FloorTo("hour", kolkata)
+
+		require.NoError(t, err)
+		assert.True(t, floored.Start.Equal(time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC)))
+		assert.True(t, floored.End.Equal(time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)))
+	})
+
+	t.Run("days follow the local calendar across DST", func(t *testing.T) {
+		newYork, err := time.LoadLocation("America/New_York")
+		require.NoError(t, err)
+		w := TimeWindowSpec{
+			Start: time.Date(2024, 3, 10, 12, 0, 0, 0, newYork),
+			End:   time.Date(2024, 3, 10, 13, 0, 0, 0, newYork),
+		}
+
+		floored, err := w.FloorTo("day", newYork)
+
+		require.NoError(t, err)
+		assert.Equal(t, 23*time.Hour, floored.End.Sub(floored.Start))
+	})
+
+	t.Run("rejects unknown period and reversed window", func(t *testing.T) {
+		w := TimeWindowSpec{Start: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+		_, err := w.FloorTo("week", nil)
+		assert.ErrorContains(t, err, `invalid period "week"`)
+
+		_, err = w.FloorTo("day", nil)
+		assert.ErrorContains(t, err, "is before start")
+	})
+}
+
+func TestMeterReadingSpec_QuantityComparisons(t *testing.T) {
+	reading := MeterReadingSpec{
+		ComputedValues: []ComputedValueSpec{{Quantity: "1000.50", Unit: "api-calls", Aggregation: "sum"}},
+	}
+
+	t.Run("exceeds", func(t *testing.T) {
+		exceeds, err := reading.ExceedsQuantity("1000")
+
+		require.NoError(t, err)
+		assert.True(t, exceeds)
+	})
+
+	t.Run("at limit does not exceed", func(t *testing.T) {
+		exceeds, err := reading.ExceedsQuantity("1000.5")
+		require.NoError(t, err)
+		below, err := reading.BelowQuantity("1000.500")
+		require.NoError(t, err)
+
+		assert.False(t, exceeds)
+		assert.False(t, below)
+	})
+
+	t.Run("below", func(t *testing.T) {
+		below, err := reading.BelowQuantity("1e4")
+
+		require.NoError(t, err)
+		assert.True(t, below)
+	})
+
+	t.Run("between is inclusive", func(t *testing.T) {
+		within, err := reading.QuantityBetween("1000.50", "2000")
+		require.NoError(t, err)
+		outside, err := reading.QuantityBetween("0", "1000.49")
+		require.NoError(t, err)
+
+		assert.True(t, within)
+		assert.False(t, outside)
+	})
+
+	t.Run("compares exactly", func(t *testing.T) {
+		precise := MeterReadingSpec{ComputedValues: []ComputedValueSpec{{Quantity: "0.30000000000000000001"}}}
+
+		exceeds, err := precise.ExceedsQuantity("0.3")
+
+		require.NoError(t, err)
+		assert.True(t, exceeds)
+	})
+
+	t.Run("invalid threshold error", func(t *testing.T) {
+		_, err := reading.ExceedsQuantity("1,000")
+		assert.ErrorContains(t, err, `invalid threshold: "1,000" is not a valid decimal`)
+
+		_, err = reading.BelowQuantity("1/3")
+		assert.Error(t, err)
+
+		_, err = reading.QuantityBetween("10", "5")
+		assert.ErrorContains(t, err, "low 10 is greater than high 5")
+	})
+
+	t.Run("reading without computed values error", func(t *testing.T) {
+		_, err := MeterReadingSpec{}.ExceedsQuantity("1")
+
+		assert.ErrorContains(t, err, "no computed values")
+	})
+
+	t.Run("quantity as decimal", func(t *testing.T) {
+		quantity, err := reading.QuantityAsDecimal()
+
+		require.NoError(t, err)
+		assert.Equal(t, "2001/2", quantity.String())
+	})
+}
@@ -1,6 +1,9 @@
 package specs
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // MeterRecordSpec represents a single metered usage record.
 //
@@ -76,6 +79,20 @@ type MeterRecordSpec struct {
 	// has a one-to-one relationship with the record ID.
 	SourceEventID string `json:"sourceEventID"`
 
+	// Identifier of the source event's parent event, if any.
+	//
+	// Copied from the event payload's ParentID so records can be traced back
+	// through the event hierarchy (e.g., a span's usage to the request that
+	// produced it). Empty when the source event has no parent.
+	ParentEventID string `json:"parentEventID,omitempty"`
+
+	// Position of the source event in its producer's stream.
+	//
+	// Copied from the event payload's SequenceNumber so consumers can reject
+	// records replayed under at-least-once delivery. Zero when the producer
+	// does not number its events.
+	SequenceNumber int64 `json:"sequenceNumber,omitempty"`
+
 	// System timestamp indicating when this record was created by the metering process.
 	//
 	// Used for incremental processing and watermarking in streaming systems.
@@ -83,4 +100,108 @@ type MeterRecordSpec struct {
 	// to support exactly-once processing semantics. Distinct from RecordedAt
 	// which represents business time.
 	MeteredAt time.Time `json:"meteredAt"`
+
+	// Fraction of source events this record was sampled from, as a decimal string.
+	//
+	// High-throughput producers may keep only a sample of events (e.g., "0.01" for
+	// 1%). Each sampled record then stands for 1/SampleRate events, and aggregation
+	// reports RecordCount as that estimated event count. Must be in (0, 1]. Empty
+	// means the record was not sampled ("1").
+	SampleRate string `json:"sampleRate,omitempty"`
+
+	// Confidence in the observation quantities as a decimal string in [0, 1].
+	//
+	// A confidence below 1 marks the quantities as estimates that have already been
+	// scaled up by 1/SampleRate (see NormalizeForSampling in the reference
+	// implementation). Empty means exact quantities ("1").
+	Confidence string `json:"confidence,omitempty"`
+
+	// SHA-256 hex digest of the record's content, populated by Meter.
+	//
+	// Covers workspace, universe, subject, ObservedAt, observations (sorted by
+	// unit), and dimensions (sorted by key), but not IDs or system timestamps.
+	// Two records with the same content hash describe identical usage even when
+	// they came from different source events, which lets pipelines detect
+	// duplicates that event-level idempotency (SourceEventID) cannot.
+	ContentHash string `json:"contentHash,omitempty"`
+
+	// IDs of sibling records produced from the same source event.
+	//
+	// Observations extracted from one event are bundled into a single record (see
+	// Observations), so records produced by Meter have no siblings and leave this
+	// empty. Producers that emit one record per observation instead (e.g., legacy
+	// pipelines or external systems) use this to preserve the relationship, such
+	// as output tokens that cannot exist without their input tokens.
+	LinkedRecordIDs []string `json:"linkedRecordIDs,omitempty"`
+
+	// Kind of record.
+	//
+	// Empty for records metered directly from an event. "rollup" marks a copy of
+	// a metered record attributed to an ancestor of its subject (e.g., an
+	// organization above a customer; see SubjectHierarchySpec). Consumers that
+	// aggregate one level of a hierarchy filter on this to avoid counting usage
+	// twice.
+	RecordType string `json:"recordType,omitempty"`
+}
+
+// MarshalJSON encodes the record with ObservedAt and MeteredAt in
+// DefaultTimeFormat. Use TimeFormat.MarshalRecord for another format.
+func (r MeterRecordSpec) MarshalJSON() ([]byte, error) {
+	return DefaultTimeFormat.MarshalRecord(r)
+}
+
+// UnmarshalJSON decodes a record with ObservedAt and MeteredAt as RFC 3339
+// strings or Unix timestamps.
+func (r *MeterRecordSpec) UnmarshalJSON(data []byte) error {
+	return DefaultTimeFormat.UnmarshalRecord(data, r)
+}
+
+// MarshalRecord encodes r with ObservedAt and MeteredAt in format f.
+func (f TimeFormat) MarshalRecord(r MeterRecordSpec) ([]byte, error) {
+	type plain MeterRecordSpec
+	return json.Marshal(struct {
+		plain
+		ObservedAt jsonTime `json:"observedAt"`
+		MeteredAt  jsonTime `json:"meteredAt"`
+	}{plain(r), jsonTime{r.ObservedAt, f}, jsonTime{r.MeteredAt, f}})
+}
+
+// UnmarshalRecord decodes data into r, accepting ObservedAt and MeteredAt as
+// strings in format f or RFC 3339, or as Unix timestamps.
+func (f TimeFormat) UnmarshalRecord(data []byte, r *MeterRecordSpec) error {
+	type plain MeterRecordSpec
+	aux := struct {
+		*plain
+		ObservedAt jsonTime `json:"observedAt"`
+		MeteredAt  jsonTime `json:"meteredAt"`
+	}{plain: (*plain)(r), ObservedAt: jsonTime{r.ObservedAt, f}, MeteredAt: jsonTime{r.MeteredAt, f}}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.ObservedAt = aux.ObservedAt.time
+	r.MeteredAt = aux.MeteredAt.time
+	return nil
+}
+
+// ObservationsToMap returns the record's quantities keyed by unit, for
+// consumers that read specific units. When several observations share a unit,
+// the last one wins, as with ObservationByUnit.
+func (r MeterRecordSpec) ObservationsToMap() map[string]string {
+	quantities := make(map[string]string, len(r.Observations))
+	for _, o := range r.Observations {
+		quantities[o.Unit] = o.Quantity
+	}
+	return quantities
+}
+
+// ObservationByUnit returns the record's observation of unit, and false if it
+// has none. When several observations share the unit, the last one is
+// returned.
+func (r MeterRecordSpec) ObservationByUnit(unit string) (ObservationSpec, bool) {
+	for i := len(r.Observations) - 1; i >= 0; i-- {
+		if r.Observations[i].Unit == unit {
+			return r.Observations[i], true
+		}
+	}
+	return ObservationSpec{}, false
 }
@@ -0,0 +1,60 @@
+package specs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newObservationsTestRecord(observations ...ObservationSpec) MeterRecordSpec {
+	return MeterRecordSpec{ID: "record-1", Subject: "customer:acme", Observations: observations}
+}
+
+func TestMeterRecordSpec_ObservationsToMap(t *testing.T) {
+	at := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	t.Run("maps each unit to its quantity", func(t *testing.T) {
+		record := newObservationsTestRecord(
+			NewInstantObservation("450", "input-tokens", at),
+			NewInstantObservation("890", "output-tokens", at),
+		)
+
+		quantities := record.ObservationsToMap()
+
+		assert.Equal(t, map[string]string{"input-tokens": "450", "output-tokens": "890"}, quantities)
+	})
+
+	t.Run("last observation of a duplicate unit wins", func(t *testing.T) {
+		record := newObservationsTestRecord(
+			NewInstantObservation("450", "input-tokens", at),
+			NewInstantObservation("500", "input-tokens", at),
+		)
+
+		quantities := record.ObservationsToMap()
+
+		assert.Equal(t, map[string]string{"input-tokens": "500"}, quantities)
+	})
+}
+
+func TestMeterRecordSpec_ObservationByUnit(t *testing.T) {
+	at := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	record := newObservationsTestRecord(
+		NewInstantObservation("450", "input-tokens", at),
+		NewInstantObservation("890", "output-tokens", at),
+	)
+
+	t.Run("returns observation when found", func(t *testing.T) {
+		observation, ok := record.ObservationByUnit("output-tokens")
+
+		assert.True(t, ok)
+		assert.Equal(t, NewInstantObservation("890", "output-tokens", at), observation)
+	})
+
+	t.Run("returns false when not found", func(t *testing.T) {
+		observation, ok := record.ObservationByUnit("cached-tokens")
+
+		assert.False(t, ok)
+		assert.Zero(t, observation)
+	})
+}
@@ -2,6 +2,7 @@ package specs
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -41,13 +42,22 @@ type ObservationSpec struct {
 	// The window captures when the observation occurred, enabling downstream use
 	// cases like proration across billing periods and time-weighted aggregation.
 	Window TimeWindowSpec `json:"window"`
+
+	// Audit trail of a post-hoc correction to Quantity, if any.
+	//
+	// Set when the quantity was multiplied by a correction factor after
+	// metering (internal.ScaleRecords in the reference implementation). Nil
+	// for quantities as metered.
+	Scaling *ScalingSpec `json:"scaling,omitempty"`
 }
 
 // ComputedValueSpec represents a computed value from observations.
 //
 // This is the new naming aligned with domain terminology. ComputedValues are produced
-// by applying aggregation or transformation strategies to observations. Unlike AggregateSpec,
-// ComputedValueSpec includes the aggregation type, making the computation strategy explicit.
+// by applying aggregation or transformation strategies to observations. It replaces the
+// removed AggregateSpec and MeasurementSpec (MeterReadingSpec.Measurement is now
+// MeterReadingSpec.ComputedValues); unlike them, ComputedValueSpec includes the
+// aggregation type, making the computation strategy explicit.
 //
 // The term "computed" is more general than "aggregate"—values may be computed through
 // aggregation, transformation, or other means. This flexibility accommodates future
@@ -72,11 +82,39 @@ type ComputedValueSpec struct {
 	//   - "max": Maximum quantity
 	//   - "min": Minimum quantity
 	//   - "latest": Most recent quantity
+	//   - "first-non-zero": Earliest positive quantity
+	//   - "mode": Most frequent quantity
+	//   - "count": Number of records
+	//   - "average": Arithmetic mean of quantities
 	//   - "time-weighted-avg": Average weighted by time
 	//
 	// Including the aggregation type makes the computation strategy explicit,
 	// which is essential for understanding and validating the computed result.
 	Aggregation string `json:"aggregation"`
+
+	// Audit trail of a post-hoc correction to Quantity, if any.
+	//
+	// Set when the value was multiplied by a correction factor after
+	// aggregation (internal.ScaleReadings in the reference implementation).
+	// Nil for values as computed.
+	Scaling *ScalingSpec `json:"scaling,omitempty"`
+}
+
+// ScalingSpec records a correction factor applied to a quantity after the
+// fact, such as multiplying a batch by 0.9 to fix a pricing error.
+//
+// Scaling an already scaled quantity keeps the first OriginalQuantity and
+// multiplies the factors, so the audit trail always leads back to the value
+// as originally produced. All fields are decimal strings.
+type ScalingSpec struct {
+	// The quantity before any scaling.
+	OriginalQuantity string `json:"originalQuantity"`
+
+	// The overall factor applied: AdjustedQuantity = OriginalQuantity × Factor.
+	Factor string `json:"factor"`
+
+	// The quantity after scaling; equal to the scaled value's Quantity.
+	AdjustedQuantity string `json:"adjustedQuantity"`
 }
 
 // NewInstantObservation creates an observation at a single point in time.
@@ -110,7 +148,7 @@ func NewInstantObservation(quantity, unit string, instant time.Time) Observation
 //   - Active session: "1 active-user from 9:00am to 9:45am"
 func NewSpanObservation(quantity, unit string, start, end time.Time) (ObservationSpec, error) {
 	if !end.After(start) {
-		return ObservationSpec{}, fmt.Errorf("span observation: end must be after start (start=%v, end=%v)", start, end)
+		return ObservationSpec{}, fmt.Errorf("span observation: %w (start=%v, end=%v)", ErrInvalidSpan, start, end)
 	}
 	return ObservationSpec{
 		Quantity: quantity,
@@ -121,3 +159,98 @@ func NewSpanObservation(quantity, unit string, start, end time.Time) (Observatio
 		},
 	}, nil
 }
+
+// IsPlainDecimal reports whether s is a plain decimal string: an optional
+// minus sign, digits, an optional fraction, and an optional exponent, such as
+// "42", "-1.5", or "2.5e3". Forms like ".5", "+5", "1,000", and "Infinity" are
+// not plain decimals.
+func IsPlainDecimal(s string) bool {
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	i, ok := skipDigits(s, i)
+	if !ok {
+		return false
+	}
+	if i < len(s) && s[i] == '.' {
+		if i, ok = skipDigits(s, i+1); !ok {
+			return false
+		}
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		if i, ok = skipDigits(s, i); !ok {
+			return false
+		}
+	}
+	return i == len(s)
+}
+
+// skipDigits returns the index after the run of ASCII digits starting at i,
+// and false if there is none.
+func skipDigits(s string, i int) (int, bool) {
+	start := i
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return i, i > start
+}
+
+// ObservationValidationError lists every problem found with an observation.
+type ObservationValidationError struct {
+	ValidationErrors []string
+}
+
+func (e *ObservationValidationError) Error() string {
+	return strings.Join(e.ValidationErrors, "; ")
+}
+
+// Validate checks that the observation is well formed: Quantity is a plain
+// decimal string (negative values are allowed; whitespace and thousands
+// separators are not), Unit is set, and Window has a non-zero Start and End
+// with Start not after End.
+//
+// Returns nil if valid, or an *ObservationValidationError listing every
+// problem found.
+func (o ObservationSpec) Validate() error {
+	var problems []string
+	switch {
+	case o.Quantity == "":
+		problems = append(problems, "quantity is required")
+	case !IsPlainDecimal(o.Quantity):
+		problems = append(problems, fmt.Sprintf("quantity %q is not a valid decimal", o.Quantity))
+	}
+	if o.Unit == "" {
+		problems = append(problems, "unit is required")
+	}
+	if o.Window.Start.IsZero() {
+		problems = append(problems, "window start is required")
+	}
+	if o.Window.End.IsZero() {
+		problems = append(problems, "window end is required")
+	}
+	if !o.Window.Start.IsZero() && !o.Window.End.IsZero() && o.Window.Start.After(o.Window.End) {
+		problems = append(problems, "window start must not be after end")
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ObservationValidationError{ValidationErrors: problems}
+}
+
+// ValidateObservations validates each observation, returning an error naming
+// the index of each invalid one, or nil if all are valid.
+func ValidateObservations(observations []ObservationSpec) []error {
+	var errs []error
+	for i, observation := range observations {
+		if err := observation.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("observation[%d]: %w", i, err))
+		}
+	}
+	return errs
+}
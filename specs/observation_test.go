@@ -67,3 +67,81 @@ func TestNewSpanObservation(t *testing.T) {
 		assert.Contains(t, err.Error(), "end must be after start")
 	})
 }
+
+func TestIsPlainDecimal(t *testing.T) {
+	for _, s := range []string{"0", "42", "-1.5", "2.5e3", "1E-2", "-0.000", "7e+10"} {
+		assert.True(t, IsPlainDecimal(s), s)
+	}
+	for _, s := range []string{"", "-", ".5", "5.", "+5", "1,000", " 1", "1e", "1e+", "1.2.3", "Infinity", "NaN", "0x10", "١٢"} {
+		assert.False(t, IsPlainDecimal(s), s)
+	}
+}
+
+func TestObservationSpec_Validate(t *testing.T) {
+	instant := time.Date(2024, 2, 15, 9, 47, 0, 0, time.UTC)
+
+	t.Run("valid observation", func(t *testing.T) {
+		span, err := NewSpanObservation("8", "compute-hours", instant, instant.Add(8*time.Hour))
+		require.NoError(t, err)
+
+		assert.NoError(t, NewInstantObservation("15", "seats", instant).Validate())
+		assert.NoError(t, span.Validate())
+		assert.NoError(t, NewInstantObservation("1.5e3", "tokens", instant).Validate())
+	})
+
+	t.Run("negative quantity is a valid decimal", func(t *testing.T) {
+		assert.NoError(t, NewInstantObservation("-42.5", "credits", instant).Validate())
+	})
+
+	t.Run("quantity with comma returns error", func(t *testing.T) {
+		err := NewInstantObservation("1,000", "tokens", instant).Validate()
+
+		var invalid *ObservationValidationError
+		require.ErrorAs(t, err, &invalid)
+		assert.Equal(t, []string{`quantity "1,000" is not a valid decimal`}, invalid.ValidationErrors)
+	})
+
+	t.Run("quantity with whitespace returns error", func(t *testing.T) {
+		assert.ErrorContains(t, NewInstantObservation(" 15", "seats", instant).Validate(), "is not a valid decimal")
+	})
+
+	t.Run("zero window start returns error", func(t *testing.T) {
+		obs := ObservationSpec{Quantity: "15", Unit: "seats", Window: TimeWindowSpec{End: instant}}
+
+		assert.EqualError(t, obs.Validate(), "window start is required")
+	})
+
+	t.Run("start after end returns error", func(t *testing.T) {
+		obs := ObservationSpec{Quantity: "15", Unit: "seats", Window: TimeWindowSpec{Start: instant.Add(time.Hour), End: instant}}
+
+		assert.EqualError(t, obs.Validate(), "window start must not be after end")
+	})
+
+	t.Run("all problems are reported", func(t *testing.T) {
+		err := ObservationSpec{}.Validate()
+
+		var invalid *ObservationValidationError
+		require.ErrorAs(t, err, &invalid)
+		assert.Equal(t, []string{
+			"quantity is required",
+			"unit is required",
+			"window start is required",
+			"window end is required",
+		}, invalid.ValidationErrors)
+	})
+}
+
+func TestValidateObservations(t *testing.T) {
+	instant := time.Date(2024, 2, 15, 9, 47, 0, 0, time.UTC)
+
+	errs := ValidateObservations([]ObservationSpec{
+		NewInstantObservation("15", "seats", instant),
+		NewInstantObservation("abc", "seats", instant),
+		NewInstantObservation("3", "", instant),
+	})
+
+	require.Len(t, errs, 2)
+	assert.EqualError(t, errs[0], `observation[1]: quantity "abc" is not a valid decimal`)
+	assert.EqualError(t, errs[1], "observation[2]: unit is required")
+	assert.Nil(t, ValidateObservations([]ObservationSpec{NewInstantObservation("15", "seats", instant)}))
+}
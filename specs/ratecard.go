@@ -0,0 +1,17 @@
+package specs
+
+// RateCardSpec prices one unit of metered usage.
+//
+// Rate cards turn meter readings into amounts owed, for invoice-like
+// summaries. A flat per-unit price covers the common case; tiered and
+// volume pricing are left to a billing system downstream.
+type RateCardSpec struct {
+	// Unit this rate applies to, matching ComputedValueSpec.Unit.
+	Unit string `json:"unit"`
+
+	// Price of one unit as a decimal string, in the billing currency.
+	//
+	// Stored as string to preserve precision, like all quantities. Examples:
+	// "0.002" per token, "15" per seat.
+	PricePerUnit string `json:"pricePerUnit"`
+}
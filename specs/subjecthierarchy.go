@@ -0,0 +1,23 @@
+package specs
+
+// SubjectHierarchySpec defines which subjects roll up to a parent subject, for
+// billing at more than one level (e.g., customers within an organization).
+//
+// Usage metered for a child subject is also attributed to every ancestor, so
+// an organization's readings include its customers' usage without querying
+// each customer.
+type SubjectHierarchySpec struct {
+	// The parent subject (e.g., "org:acme").
+	Subject string `json:"subject"`
+
+	// Subjects that roll up directly to Subject (e.g., "customer:123").
+	Children []string `json:"children,omitempty"`
+
+	// Nested hierarchies whose subjects roll up directly to Subject.
+	//
+	// Each subgroup's Subject is a child of this Subject with children of its
+	// own, forming deeper hierarchies (e.g., organization > team > customer).
+	// Usage of the subgroup's children rolls up to both the subgroup's Subject
+	// and this Subject.
+	Subgroups []SubjectHierarchySpec `json:"subgroups,omitempty"`
+}
@@ -0,0 +1,122 @@
+package specs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeFormat is how MeterRecordSpec.ObservedAt, MeterRecordSpec.MeteredAt,
+// and MeterReadingSpec.CreatedAt are encoded to JSON: a time.Format layout, or
+// UnixTimeFormat.
+//
+// The format is passed to each call rather than set process-wide, so
+// encoders with different formats can run concurrently. Global setters
+// (SetDefaultTimeFormat, UseUnixTimestamps) are intentionally not provided:
+// one caller switching to Unix timestamps would silently change the output of
+// every other encoder in the process, mid-flight. Use MarshalRecord and
+// MarshalReading with the format each output needs. MarshalJSON on the
+// specs always uses DefaultTimeFormat. A layout without fractional seconds
+// (such as time.RFC3339) truncates to whole seconds. Decoding accepts either a
+// Unix number or a string in the format or RFC 3339, whatever the format.
+type TimeFormat string
+
+const (
+	// DefaultTimeFormat is Go's standard encoding, which keeps nanosecond
+	// precision.
+	DefaultTimeFormat TimeFormat = time.RFC3339Nano
+
+	// UnixTimeFormat encodes timestamps as Unix seconds, with a fractional
+	// part for sub-second precision (e.g. 1705329000.123456789).
+	UnixTimeFormat TimeFormat = "unix"
+)
+
+// jsonTime encodes a time.Time according to format. An empty format means
+// DefaultTimeFormat.
+type jsonTime struct {
+	time   time.Time
+	format TimeFormat
+}
+
+func (t jsonTime) MarshalJSON() ([]byte, error) {
+	tt := t.time
+	format := t.format
+	if format == "" {
+		format = DefaultTimeFormat
+	}
+	if format != UnixTimeFormat {
+		return json.Marshal(tt.Format(string(format)))
+	}
+
+	sec, nsec := tt.Unix(), tt.Nanosecond()
+	var b strings.Builder
+	if sec < 0 && nsec > 0 {
+		// Unix floors toward the past; render -5.5s as "-5.5", not "-6" + 0.5
+		sec, nsec = sec+1, 1e9-nsec
+		b.WriteString("-")
+		b.WriteString(strconv.FormatInt(-sec, 10))
+	} else {
+		b.WriteString(strconv.FormatInt(sec, 10))
+	}
+	if nsec > 0 {
+		b.WriteString(".")
+		b.WriteString(strings.TrimRight(fmt.Sprintf("%09d", nsec), "0"))
+	}
+	return []byte(b.String()), nil
+}
+
+func (t *jsonTime) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		format := t.format
+		if format == "" || format == UnixTimeFormat {
+			format = DefaultTimeFormat
+		}
+		parsed, err := time.Parse(string(format), s)
+		if err != nil {
+			parsed, err = time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return fmt.Errorf("%w %q: %w", ErrInvalidTimestamp, s, err)
+			}
+		}
+		t.time = parsed
+		return nil
+	}
+
+	// Parse Unix seconds as text; float64 cannot hold nanosecond precision
+	text := string(data)
+	negative := strings.HasPrefix(text, "-")
+	whole, frac, _ := strings.Cut(strings.TrimPrefix(text, "-"), ".")
+	sec, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return invalidUnixTimestamp(text, err)
+	}
+	var nsec int64
+	if frac != "" {
+		if len(frac) > 9 {
+			return invalidUnixTimestamp(text, errors.New("more than nanosecond precision"))
+		}
+		nsec, err = strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+		if err != nil {
+			return invalidUnixTimestamp(text, err)
+		}
+	}
+	if negative {
+		sec, nsec = -sec, -nsec
+	}
+	t.time = time.Unix(sec, nsec).UTC()
+	return nil
+}
+
+// invalidUnixTimestamp reports text as an invalid Unix timestamp because of
+// cause, matching ErrInvalidTimestamp.
+func invalidUnixTimestamp(text string, cause error) error {
+	return &sentinelError{sentinel: ErrInvalidTimestamp, cause: cause, message: "invalid unix timestamp " + text + ": " + cause.Error()}
+}
@@ -0,0 +1,99 @@
+package specs
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeFormat(t *testing.T) {
+	observedAt := time.Date(2024, 1, 15, 14, 30, 0, 123456789, time.UTC)
+	record := MeterRecordSpec{
+		ID:         "rec-1",
+		ObservedAt: observedAt,
+		MeteredAt:  observedAt.Add(time.Second),
+	}
+
+	t.Run("default format round-trips nanosecond precision", func(t *testing.T) {
+		data, err := json.Marshal(record)
+		require.NoError(t, err)
+		var decoded MeterRecordSpec
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		assert.Contains(t, string(data), `"observedAt":"2024-01-15T14:30:00.123456789Z"`)
+		assert.True(t, observedAt.Equal(decoded.ObservedAt))
+		assert.True(t, record.MeteredAt.Equal(decoded.MeteredAt))
+		assert.Equal(t, "rec-1", decoded.ID)
+	})
+
+	t.Run("custom layout", func(t *testing.T) {
+		format := TimeFormat("2006-01-02 15:04:05.000")
+
+		data, err := format.MarshalRecord(record)
+		require.NoError(t, err)
+		var decoded MeterRecordSpec
+		require.NoError(t, format.UnmarshalRecord(data, &decoded))
+
+		assert.Contains(t, string(data), `"observedAt":"2024-01-15 14:30:00.123"`)
+		assert.True(t, observedAt.Truncate(time.Millisecond).Equal(decoded.ObservedAt))
+	})
+
+	t.Run("unix timestamps round-trip nanosecond precision", func(t *testing.T) {
+		data, err := UnixTimeFormat.MarshalRecord(record)
+		require.NoError(t, err)
+		var decoded MeterRecordSpec
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		assert.Contains(t, string(data), `"observedAt":1705329000.123456789`)
+		assert.Contains(t, string(data), `"meteredAt":1705329001.123456789`)
+		assert.True(t, observedAt.Equal(decoded.ObservedAt))
+	})
+
+	t.Run("whole and negative unix timestamps", func(t *testing.T) {
+		whole, err := json.Marshal(jsonTime{time.Unix(1705329000, 0), UnixTimeFormat})
+		require.NoError(t, err)
+		negative, err := json.Marshal(jsonTime{time.Unix(-5, -500000000), UnixTimeFormat})
+		require.NoError(t, err)
+		var decoded jsonTime
+		require.NoError(t, json.Unmarshal(negative, &decoded))
+
+		assert.Equal(t, "1705329000", string(whole))
+		assert.Equal(t, "-5.5", string(negative))
+		assert.True(t, time.Unix(-5, -500000000).Equal(decoded.time))
+	})
+
+	t.Run("decoding accepts either format regardless of setting", func(t *testing.T) {
+		var fromString, fromUnix MeterRecordSpec
+		require.NoError(t, json.Unmarshal([]byte(`{"observedAt":"2024-01-15T14:30:00.123456789Z"}`), &fromString))
+		require.NoError(t, json.Unmarshal([]byte(`{"observedAt":1705329000.123456789}`), &fromUnix))
+
+		assert.True(t, observedAt.Equal(fromString.ObservedAt))
+		assert.True(t, observedAt.Equal(fromUnix.ObservedAt))
+	})
+
+	t.Run("reading CreatedAt uses the format", func(t *testing.T) {
+		reading := MeterReadingSpec{ID: "reading-1", CreatedAt: observedAt, MaxMeteredAt: observedAt}
+
+		data, err := UnixTimeFormat.MarshalReading(reading)
+		require.NoError(t, err)
+		var decoded MeterReadingSpec
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		assert.Contains(t, string(data), `"createdAt":1705329000.123456789`)
+		assert.True(t, observedAt.Equal(decoded.CreatedAt))
+		assert.True(t, observedAt.Equal(decoded.MaxMeteredAt))
+	})
+
+	t.Run("formats are independent of each other", func(t *testing.T) {
+		unix, err := UnixTimeFormat.MarshalRecord(record)
+		require.NoError(t, err)
+		standard, err := json.Marshal(record)
+		require.NoError(t, err)
+
+		assert.Contains(t, string(unix), `"observedAt":1705329000.123456789`)
+		assert.Contains(t, string(standard), `"observedAt":"2024-01-15T14:30:00.123456789Z"`)
+	})
+}